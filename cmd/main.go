@@ -1,73 +1,187 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"pvz/internal/api/grpchandler"
 	"pvz/internal/api/handler"
+	"pvz/internal/app"
+	"pvz/internal/config"
 	"pvz/internal/db"
+	"pvz/internal/jwtkeys"
+	"pvz/internal/live"
 	"pvz/internal/logger"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/outbox"
+	"pvz/internal/rbac"
 	"pvz/internal/repository"
 	"pvz/internal/service"
 	"pvz/metrics"
-	"pvz/server"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
-
-	"github.com/spf13/viper"
 )
 
 func main() {
 	gin.SetMode(gin.ReleaseMode)
-	metrics.Init()
+	metrics.Register()
+
+	// Загрузка конфигурации: config.yaml, окружение (.env) и флаги запуска
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Error initializing configs: %v", err)
+	}
 
 	// Инициализация логгера
-	if err := logger.Init(); err != nil {
+	if err := logger.Init(logger.Config{Level: cfg.Log.Level, File: cfg.Log.File}); err != nil {
 		log.Fatalf("Logger initialization error: %v", err)
 	}
-	defer logger.Log.Sync()
 
-	logger.Log.Infow("The application is running")
+	logger.Log.Infow("The application is running", "config", cfg)
 
-	// Загрузка конфигурации
-	if err := initConfig(); err != nil {
-		logger.Log.Fatalw("Error initializing configs", "error", err)
-	}
-
-	// Загрузка .env файла
-	if err := godotenv.Load(); err != nil {
-		logger.Log.Fatalw("Error loading env file", "error", err)
+	// Выход только после того, как отработают отложенные вызовы run
+	if err := run(cfg); err != nil {
+		logger.Log.Fatalw("Application stopped with error", "error", err)
 	}
+}
 
+func run(cfg config.Config) error {
 	// Инициализация БД
 	postgresDb, err := db.NewPostgresDB(db.Config{
-		Host:     os.Getenv("DB_HOST"),
-		Port:     os.Getenv("DB_PORT"),
-		Username: os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_DB"),
-		SSLMode:  os.Getenv("SSL_MODE"),
+		Host:     cfg.DB.Host,
+		Port:     cfg.DB.Port,
+		Username: cfg.DB.Username,
+		Password: string(cfg.DB.Password),
+		DBName:   cfg.DB.DBName,
+		SSLMode:  cfg.DB.SSLMode,
 	})
 	if err != nil {
-		logger.Log.Fatalw("Failed initializing DB", "error", err)
+		return fmt.Errorf("failed initializing DB: %w", err)
 	}
 
+	// Ключи подписи токенов
+	keys, err := loadSigningKeys(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("failed loading JWT signing keys: %w", err)
+	}
+	activeKid, err := keys.ActiveKeyID()
+	if err != nil {
+		return fmt.Errorf("no active JWT signing key: %w", err)
+	}
+	logger.Log.Infow("JWT signing keys loaded", "algorithm", keys.Algorithm(), "activeKid", activeKid)
+
 	// Инициализация слоев приложения
 	repos := repository.NewRepository(postgresDb, logger.Log)
-	services := service.NewService(repos, logger.Log)
+
+	// Политика прав ролей
+	policy, err := loadPolicy(cfg.RBAC, repos.Permission)
+	if err != nil {
+		return fmt.Errorf("failed loading RBAC policy from %q: %w", cfg.RBAC.Source, err)
+	}
+	logger.Log.Infow("RBAC policy loaded", "source", cfg.RBAC.Source, "roles", policy.Roles())
+
+	// Получатель доменных событий
+	publisher, err := newPublisher(cfg.Outbox)
+	if err != nil {
+		return fmt.Errorf("failed initializing %q event publisher: %w", cfg.Outbox.Publisher, err)
+	}
+	defer publisher.Close()
+
+	// Рассылка событий ПВЗ в SSE-потоки
+	hub := live.NewHub(live.Config{
+		ReplayBuffer:      cfg.Live.ReplayBuffer,
+		ClientBuffer:      cfg.Live.ClientBuffer,
+		HeartbeatInterval: cfg.Live.HeartbeatInterval,
+	}, logger.Log)
+
+	services := service.NewService(repos, service.Config{
+		Tokens: service.TokenConfig{
+			Signer:     keys,
+			AccessTTL:  cfg.JWT.AccessTokenTTL,
+			RefreshTTL: cfg.JWT.RefreshTokenTTL,
+		},
+		IdempotencyTTL:        cfg.Idempotency.TTL,
+		ReceptionReopenWindow: cfg.Receptions.ReopenWindow,
+		StaleReceptions: service.StaleReceptionConfig{
+			IdleFor:   cfg.Receptions.StaleAfter,
+			Action:    cfg.Receptions.StaleAction,
+			BatchSize: cfg.Receptions.StaleBatchSize,
+		},
+		Outbox: service.OutboxConfig{
+			Publisher:  publisher,
+			BatchSize:  cfg.Outbox.BatchSize,
+			MaxBackoff: cfg.Outbox.MaxBackoff,
+			Retention:  cfg.Outbox.Retention,
+		},
+		Webhooks: service.WebhookConfig{
+			Timeout:     cfg.Webhooks.Timeout,
+			BatchSize:   cfg.Webhooks.BatchSize,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		},
+		Hub:    hub,
+		Policy: policy,
+	}, logger.Log)
+	auth := jwt.NewAuth(keys, services.Revocation, policy)
 	handlers := handler.NewHandler(services, logger.Log)
+	grpcHandlers := grpchandler.NewHandler(services, logger.Log)
+
+	// Запуск серверов до получения SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Запуск сервера
-	srv := new(server.Server)
-	if err := srv.Run(viper.GetString("port"), handlers.InitRoutes()); err != nil {
-		logger.Log.Fatalw("Error occurred while running server", "error", err)
+	application := app.New(cfg, handlers.InitRoutes(auth), grpcHandlers.InitServer(auth), postgresDb, logger.Log)
+	application.OnShutdown(hub.Close)
+	application.AddTask("token-sweeper", cfg.JWT.SweepInterval, services.DeleteExpiredTokens)
+	application.AddTask("idempotency-sweeper", cfg.Idempotency.SweepInterval, services.DeleteExpiredIdempotencyKeys)
+	application.AddTask("outbox-relay", cfg.Outbox.RelayInterval, services.PublishOutboxEvents)
+	application.AddTask("outbox-sweeper", cfg.Outbox.SweepInterval, services.DeletePublishedOutboxEvents)
+	application.AddTask("webhook-delivery", cfg.Webhooks.DeliveryInterval, services.DeliverWebhooks)
+	application.AddTask("stale-receptions", cfg.Receptions.StaleInterval, services.ProcessStaleReceptions)
+
+	return application.Run(ctx)
+}
+
+func loadSigningKeys(cfg config.JWTConfig) (*jwtkeys.KeySet, error) {
+	if cfg.Algorithm == jwtkeys.AlgHS256 {
+		return jwtkeys.NewHMAC([]byte(cfg.SigningKey)), nil
+	}
+
+	keyConfigs := make([]jwtkeys.KeyConfig, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		keyConfigs = append(keyConfigs, jwtkeys.KeyConfig{
+			ID:             key.ID,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PublicKeyFile:  key.PublicKeyFile,
+			ActiveFrom:     key.ActiveFrom,
+		})
 	}
+	return jwtkeys.Load(cfg.Algorithm, keyConfigs)
 }
 
-func initConfig() error {
-	viper.AddConfigPath("config")
-	viper.SetConfigName("config")
-	return viper.ReadInConfig()
+func loadPolicy(cfg config.RBACConfig, permissions repository.Permission) (*rbac.Policy, error) {
+	switch cfg.Source {
+	case "file":
+		return rbac.LoadFile(cfg.File)
+	case "db":
+		roles, err := permissions.GetRolePermissions(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return rbac.New(roles)
+	default:
+		return rbac.Default(), nil
+	}
+}
+
+func newPublisher(cfg config.OutboxConfig) (*outbox.WriterPublisher, error) {
+	if cfg.Publisher == "file" {
+		return outbox.NewFilePublisher(cfg.File)
+	}
+	return outbox.NewWriterPublisher(os.Stdout), nil
 }
@@ -0,0 +1,155 @@
+package grpchandler_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"pvz/internal/api/grpchandler"
+	"pvz/internal/api/response"
+	"pvz/internal/jwtkeys"
+	"pvz/internal/logger"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/rbac"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+	"pvz/pkg/pvz_v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGrpcHandler_GetPVZList_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+
+	services := &service.Service{Pvz: mockPvzService}
+	h := grpchandler.NewHandler(services, mockLogger)
+
+	expected := []response.PvzFullResponse{
+		{
+			Pvz: response.PvzResponse{Id: "pvz-1", RegistrationDate: "2025-04-01 10:00:00", City: "Москва"},
+			Receptions: []response.ReceptionWrapper{
+				{
+					Reception: response.ReceptionResponse{Id: "rec-1", DateTime: "2025-04-02 10:00:00", PvzId: "pvz-1", Status: "close"},
+					Products: []response.ProductResponse{
+						{Id: "prod-1", DateTime: "2025-04-02 10:05:00", Type: "обувь", ReceptionId: "rec-1"},
+					},
+				},
+			},
+		},
+	}
+
+	mockPvzService.EXPECT().
+		GetPvzList(gomock.Any(), 10, 0, model.PvzFilter{}).
+		Return(expected, nil)
+
+	resp, err := h.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Pvzs, 1)
+	assert.Equal(t, "pvz-1", resp.Pvzs[0].Pvz.Id)
+	assert.Equal(t, "Москва", resp.Pvzs[0].Pvz.City)
+	assert.Len(t, resp.Pvzs[0].Receptions, 1)
+	assert.Equal(t, "close", resp.Pvzs[0].Receptions[0].Reception.Status)
+	assert.Equal(t, "обувь", resp.Pvzs[0].Receptions[0].Products[0].Type)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGrpcHandler_GetPVZList_InvalidStartDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	logger.Log = mockLogger
+
+	services := &service.Service{Pvz: mockPvzService}
+	h := grpchandler.NewHandler(services, mockLogger)
+
+	mockLogger.On("Warnw", "Failed to parse time", "input", "bad-date").Once()
+	mockLogger.On("Warnw", "Invalid startDate", "startDate", "bad-date", "error", mock.Anything).Once()
+
+	resp, err := h.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{StartDate: "bad-date"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGrpcHandler_GetPVZList_ServiceError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+
+	services := &service.Service{Pvz: mockPvzService}
+	h := grpchandler.NewHandler(services, mockLogger)
+
+	expectedErr := errors.New("db error")
+
+	mockPvzService.EXPECT().
+		GetPvzList(gomock.Any(), 5, 10, model.PvzFilter{}).
+		Return(nil, expectedErr)
+
+	mockLogger.On("Errorw", "Failed to get Pvz list", "error", expectedErr).Once()
+
+	resp, err := h.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{Limit: 5, Offset: 10})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGrpcHandler_GetPVZList_MissingToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	logger.Log = mockLogger
+
+	services := &service.Service{Pvz: mockPvzService}
+	h := grpchandler.NewHandler(services, mockLogger)
+
+	mockLogger.On("Warnw", "Authorization metadata missing", "method", pvz_v1.PVZService_GetPVZList_FullMethodName).Once()
+	mockLogger.On("Warnw", "gRPC request failed", "method", pvz_v1.PVZService_GetPVZList_FullMethodName,
+		"code", codes.Unauthenticated.String(), "duration", mock.Anything, "error", mock.Anything).Once()
+
+	client := newClient(t, h.InitServer(jwt.NewAuth(jwtkeys.NewHMAC([]byte("test-signing-key")), nil, rbac.Default())))
+
+	resp, err := client.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockLogger.AssertExpectations(t)
+}
+
+func newClient(t *testing.T, server *grpc.Server) pvz_v1.PVZServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pvz_v1.NewPVZServiceClient(conn)
+}
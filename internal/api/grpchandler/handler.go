@@ -0,0 +1,39 @@
+package grpchandler
+
+import (
+	"google.golang.org/grpc"
+	"pvz/internal/logger"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/rbac"
+	"pvz/internal/service"
+	"pvz/pkg/pvz_v1"
+)
+
+type Handler struct {
+	pvz_v1.UnimplementedPVZServiceServer
+	service *service.Service
+	logger  logger.Logger
+}
+
+func NewHandler(services *service.Service, log logger.Logger) *Handler {
+	return &Handler{
+		service: services,
+		logger:  log,
+	}
+}
+
+func (h *Handler) InitServer(auth *jwt.Auth) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			h.trackMetrics,
+			h.logRequests,
+			auth.UnaryAuthInterceptor(map[string]rbac.Permission{
+				pvz_v1.PVZService_GetPVZList_FullMethodName: rbac.PvzRead,
+			}),
+		),
+	)
+
+	pvz_v1.RegisterPVZServiceServer(server, h)
+
+	return server
+}
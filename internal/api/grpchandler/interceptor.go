@@ -0,0 +1,43 @@
+package grpchandler
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"pvz/metrics"
+)
+
+const metricsMethod = "GRPC"
+
+func (h *Handler) trackMetrics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	// Записываем метрики до выполнения запроса
+	start := time.Now()
+
+	// Выполняем хендлер
+	resp, err := handler(ctx, req)
+
+	// Измеряем продолжительность запроса
+	duration := time.Since(start).Seconds()
+
+	// Инкрементируем технические метрики
+	metrics.RequestCount.WithLabelValues(metricsMethod, info.FullMethod).Inc()
+	metrics.ResponseDuration.WithLabelValues(metricsMethod, info.FullMethod).Observe(duration)
+
+	return resp, err
+}
+
+func (h *Handler) logRequests(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		h.logger.Warnw("gRPC request failed", "method", info.FullMethod,
+			"code", status.Code(err).String(), "duration", time.Since(start), "error", err)
+		return resp, err
+	}
+
+	h.logger.Infow("gRPC request handled", "method", info.FullMethod, "duration", time.Since(start))
+	return resp, nil
+}
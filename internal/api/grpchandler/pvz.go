@@ -0,0 +1,98 @@
+package grpchandler
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"pvz/internal/api/handler"
+	"pvz/internal/api/response"
+	"pvz/internal/repository/model"
+	"pvz/pkg/pvz_v1"
+)
+
+const defaultLimit = 10
+
+func (h *Handler) GetPVZList(ctx context.Context, req *pvz_v1.GetPVZListRequest) (*pvz_v1.GetPVZListResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	offset := int(req.GetOffset())
+
+	if limit < 0 || offset < 0 {
+		h.logger.Warnw("Invalid pagination", "limit", limit, "offset", offset)
+		return nil, status.Error(codes.InvalidArgument, "invalid limit or offset")
+	}
+
+	var startDate, endDate *time.Time
+
+	if req.GetStartDate() != "" {
+		t, err := handler.ParseFlexibleTime(req.GetStartDate())
+		if err != nil {
+			h.logger.Warnw("Invalid startDate", "startDate", req.GetStartDate(), "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid start_date")
+		}
+		startDate = t
+	}
+
+	if req.GetEndDate() != "" {
+		t, err := handler.ParseFlexibleTime(req.GetEndDate())
+		if err != nil {
+			h.logger.Warnw("Invalid endDate", "endDate", req.GetEndDate(), "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid end_date")
+		}
+		endDate = t
+	}
+
+	result, err := h.service.GetPvzList(ctx, limit, offset, model.PvzFilter{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		h.logger.Errorw("Failed to get Pvz list", "error", err)
+		return nil, status.Error(codes.Internal, "failed to get pvz list")
+	}
+
+	return toPvzListResponse(result), nil
+}
+
+func toPvzListResponse(list []response.PvzFullResponse) *pvz_v1.GetPVZListResponse {
+	resp := &pvz_v1.GetPVZListResponse{
+		Pvzs: make([]*pvz_v1.PVZWithReceptions, 0, len(list)),
+	}
+
+	for _, item := range list {
+		receptions := make([]*pvz_v1.ReceptionWithProducts, 0, len(item.Receptions))
+		for _, rec := range item.Receptions {
+			products := make([]*pvz_v1.Product, 0, len(rec.Products))
+			for _, p := range rec.Products {
+				products = append(products, &pvz_v1.Product{
+					Id:          p.Id,
+					DateTime:    p.DateTime,
+					Type:        p.Type,
+					ReceptionId: p.ReceptionId,
+				})
+			}
+
+			receptions = append(receptions, &pvz_v1.ReceptionWithProducts{
+				Reception: &pvz_v1.Reception{
+					Id:       rec.Reception.Id,
+					DateTime: rec.Reception.DateTime,
+					PvzId:    rec.Reception.PvzId,
+					Status:   rec.Reception.Status,
+				},
+				Products: products,
+			})
+		}
+
+		resp.Pvzs = append(resp.Pvzs, &pvz_v1.PVZWithReceptions{
+			Pvz: &pvz_v1.PVZ{
+				Id:               item.Pvz.Id,
+				RegistrationDate: item.Pvz.RegistrationDate,
+				City:             item.Pvz.City,
+			},
+			Receptions: receptions,
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"pvz/internal/api/mapper"
+	"pvz/internal/apperror"
+)
+
+func (h *Handler) GetAssignedPvz(c *gin.Context) {
+	userId, ok := h.userIdParam(c)
+	if !ok {
+		return
+	}
+
+	pvzIds, err := h.service.GetAssignedPvzIds(c.Request.Context(), userId)
+	if err != nil {
+		h.logger.Errorw("Failed to get assigned pvz", "UserId", userId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToAssignedPvzResponse(pvzIds))
+}
+
+func (h *Handler) AssignPvz(c *gin.Context) {
+	userId, ok := h.userIdParam(c)
+	if !ok {
+		return
+	}
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.AssignPvz(c.Request.Context(), userId, pvzId); err != nil {
+		h.logger.Errorw("Failed to assign pvz", "UserId", userId, "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) UnassignPvz(c *gin.Context) {
+	userId, ok := h.userIdParam(c)
+	if !ok {
+		return
+	}
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnassignPvz(c.Request.Context(), userId, pvzId); err != nil {
+		h.logger.Errorw("Failed to unassign pvz", "UserId", userId, "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) userIdParam(c *gin.Context) (uuid.UUID, bool) {
+	userIdParam := c.Param("userId")
+	userId, err := uuid.Parse(userIdParam)
+	if err != nil {
+		h.logger.Warnw("Invalid UserId format", "UserId", userIdParam, "error", err)
+		c.Error(apperror.Validation("invalid userId format"))
+		return uuid.Nil, false
+	}
+	return userId, true
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"pvz/internal/api/mapper"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
+)
+
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	limit, offset, ok := h.paginationQuery(c, c.DefaultQuery("limit", "10"), c.DefaultQuery("offset", "0"))
+	if !ok {
+		return
+	}
+
+	filter := model.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entityType"),
+		EntityId:   c.Query("entityId"),
+	}
+	if actorIdParam := c.Query("actorId"); actorIdParam != "" {
+		actorId, err := uuid.Parse(actorIdParam)
+		if err != nil {
+			h.logger.Warnw("Invalid actorId", "actorId", actorIdParam, "error", err)
+			c.Error(apperror.Validation("invalid actorId format"))
+			return
+		}
+		filter.ActorId = &actorId
+	}
+	if filter.StartDate, ok = h.timeQuery(c, "startDate"); !ok {
+		return
+	}
+	if filter.EndDate, ok = h.timeQuery(c, "endDate"); !ok {
+		return
+	}
+
+	entries, err := h.service.GetAuditLog(c.Request.Context(), limit, offset, filter)
+	if err != nil {
+		h.logger.Errorw("Failed to get audit log", "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToAuditLogResponse(entries))
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pvz/internal/api/mapper"
+	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
+)
+
+// Справочники устроены одинаково, поэтому хендлеры параметризованы видом справочника
+
+func (h *Handler) ListCatalog(kind model.CatalogKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, err := h.service.ListCatalog(c.Request.Context(), kind)
+		if err != nil {
+			h.logger.Errorw("Failed to list catalog", "catalog", kind, "error", err)
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, mapper.ToCatalogResponse(items))
+	}
+}
+
+func (h *Handler) AddCatalogItem(kind model.CatalogKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req response.CatalogItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warnw("Invalid CatalogItemRequest", "catalog", kind, "error", err)
+			c.Error(apperror.Validation("invalid request body"))
+			return
+		}
+
+		item, err := h.service.AddCatalogItem(c.Request.Context(), kind, req.Name)
+		if err != nil {
+			h.logger.Errorw("Failed to add catalog item", "catalog", kind, "name", req.Name, "error", err)
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, mapper.ToCatalogItemResponse(item))
+	}
+}
+
+func (h *Handler) UpdateCatalogItem(kind model.CatalogKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+
+		var req response.CatalogItemUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.Active == nil {
+			h.logger.Warnw("Invalid CatalogItemUpdateRequest", "catalog", kind, "error", err)
+			c.Error(apperror.Validation("invalid request body"))
+			return
+		}
+
+		item, err := h.service.SetCatalogItemActive(c.Request.Context(), kind, name, *req.Active)
+		if err != nil {
+			h.logger.Errorw("Failed to update catalog item", "catalog", kind, "name", name, "error", err)
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, mapper.ToCatalogItemResponse(item))
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+)
+
+const internalErrorMessage = "internal server error"
+
+// ErrorMiddleware переводит ошибки, добавленные через c.Error, в HTTP-ответ
+// со схемой Error из swagger. Внутренние ошибки не раскрываются клиенту.
+func (h *Handler) ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() {
+			return
+		}
+		h.writeError(c)
+	}
+}
+
+// writeError отвечает последней ошибкой из c.Errors, если она есть
+func (h *Handler) writeError(c *gin.Context) {
+	if len(c.Errors) == 0 {
+		return
+	}
+
+	err := c.Errors.Last().Err
+	status, message := toHTTPError(err)
+	c.AbortWithStatusJSON(status, response.Error{Message: message, Errors: itemErrors(err, status)})
+}
+
+func toHTTPError(err error) (int, string) {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		return http.StatusInternalServerError, internalErrorMessage
+	}
+
+	switch {
+	case errors.Is(err, apperror.ErrValidation):
+		return http.StatusBadRequest, appErr.Message()
+	case errors.Is(err, apperror.ErrUnauthorized):
+		return http.StatusUnauthorized, appErr.Message()
+	case errors.Is(err, apperror.ErrForbidden):
+		return http.StatusForbidden, appErr.Message()
+	case errors.Is(err, apperror.ErrNotFound):
+		return http.StatusNotFound, appErr.Message()
+	case errors.Is(err, apperror.ErrConflict):
+		return http.StatusConflict, appErr.Message()
+	default:
+		return http.StatusInternalServerError, internalErrorMessage
+	}
+}
+
+// itemErrors возвращает ошибки элементов пакетного запроса. Для 500
+// они не отдаются, как и само сообщение.
+func itemErrors(err error, status int) []response.ItemError {
+	var appErr *apperror.Error
+	if status == http.StatusInternalServerError || !errors.As(err, &appErr) {
+		return nil
+	}
+
+	items := appErr.Items()
+	if len(items) == 0 {
+		return nil
+	}
+
+	result := make([]response.ItemError, 0, len(items))
+	for _, item := range items {
+		result = append(result, response.ItemError{Index: item.Index, Message: item.Message})
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/api/handler"
+	"pvz/internal/apperror"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func newAssignmentContext(method, userId, pvzId string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(method, "/users/"+userId+"/pvz/"+pvzId, nil)
+	ctx.Params = gin.Params{{Key: "userId", Value: userId}, {Key: "pvzId", Value: pvzId}}
+	return w, ctx
+}
+
+func TestHandler_AssignPvz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAssignment := mocks.NewMockAssignment(ctrl)
+	h := handler.NewHandler(&service.Service{Assignment: mockAssignment}, new(mocks.MockLogger))
+
+	userId, pvzId := uuid.New(), uuid.New()
+	mockAssignment.EXPECT().AssignPvz(gomock.Any(), userId, pvzId).Return(nil)
+
+	_, ctx := newAssignmentContext(http.MethodPut, userId.String(), pvzId.String())
+	serve(h, ctx, h.AssignPvz)
+
+	assert.Equal(t, http.StatusNoContent, ctx.Writer.Status())
+}
+
+func TestHandler_UnassignPvz_NotAssigned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAssignment := mocks.NewMockAssignment(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Assignment: mockAssignment}, mockLogger)
+
+	userId, pvzId := uuid.New(), uuid.New()
+	mockAssignment.EXPECT().UnassignPvz(gomock.Any(), userId, pvzId).Return(apperror.NotFound("not assigned"))
+	mockLogger.On("Errorw", "Failed to unassign pvz", "UserId", userId, "PvzId", pvzId, "error", mock.Anything)
+
+	w, ctx := newAssignmentContext(http.MethodDelete, userId.String(), pvzId.String())
+	serve(h, ctx, h.UnassignPvz)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandler_AssignPvz_InvalidUserId(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+	mockLogger.On("Warnw", "Invalid UserId format", "UserId", "bad", "error", mock.Anything)
+
+	w, ctx := newAssignmentContext(http.MethodPut, "bad", uuid.NewString())
+	serve(h, ctx, h.AssignPvz)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid userId format")
+}
+
+func TestHandler_GetAssignedPvz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAssignment := mocks.NewMockAssignment(ctrl)
+	h := handler.NewHandler(&service.Service{Assignment: mockAssignment}, new(mocks.MockLogger))
+
+	userId, pvzId := uuid.New(), uuid.New()
+	mockAssignment.EXPECT().GetAssignedPvzIds(gomock.Any(), userId).Return([]uuid.UUID{pvzId}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/users/"+userId.String()+"/pvz", nil)
+	ctx.Params = gin.Params{{Key: "userId", Value: userId.String()}}
+
+	serve(h, ctx, h.GetAssignedPvz)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"pvzIds":["`+pvzId.String()+`"]}`, w.Body.String())
+}
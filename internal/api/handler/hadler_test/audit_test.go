@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/api/handler"
+	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	authjwt "pvz/internal/middleware/jwt"
+	"pvz/internal/middleware/requestid"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func TestHandler_GetAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAudit := mocks.NewMockAudit(ctrl)
+	h := handler.NewHandler(&service.Service{Audit: mockAudit}, new(mocks.MockLogger))
+
+	actorId := uuid.New()
+	entry := model.AuditEntry{
+		Id:         uuid.New(),
+		CreatedAt:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		ActorId:    &actorId,
+		ActorRole:  "moderator",
+		Action:     model.AuditPvzUpdate,
+		EntityType: model.AuditEntityPvz,
+		EntityId:   "pvz-1",
+		Before:     json.RawMessage(`{"Name":"old"}`),
+		After:      json.RawMessage(`{"Name":"new"}`),
+		RequestId:  "req-1",
+	}
+	mockAudit.EXPECT().
+		GetAuditLog(gomock.Any(), 5, 10, model.AuditFilter{ActorId: &actorId, Action: model.AuditPvzUpdate, EntityType: model.AuditEntityPvz}).
+		Return([]model.AuditEntry{entry}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet,
+		"/audit?limit=5&offset=10&actorId="+actorId.String()+"&action=pvz.update&entityType=pvz", nil)
+
+	serve(h, ctx, h.GetAuditLog)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []response.AuditEntryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, "2026-03-01 12:00:00", resp[0].CreatedAt)
+	assert.Equal(t, actorId.String(), *resp[0].ActorId)
+	assert.JSONEq(t, `{"Name":"old"}`, string(resp[0].Before))
+	assert.JSONEq(t, `{"Name":"new"}`, string(resp[0].After))
+	assert.Equal(t, "req-1", resp[0].RequestId)
+}
+
+func TestHandler_GetAuditLog_InvalidActorId(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+	mockLogger.On("Warnw", "Invalid actorId", "actorId", "bad", "error", mock.Anything)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/audit?actorId=bad", nil)
+
+	serve(h, ctx, h.GetAuditLog)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid actorId format")
+	assert.ErrorIs(t, ctx.Errors.Last().Err, apperror.ErrValidation)
+}
+
+// Сервисы пишут в аудит пользователя и X-Request-Id из контекста запроса
+func TestRouter_PassesAuditContext(t *testing.T) {
+	f := newIdempotencyFixture(t)
+	pvzId := uuid.New()
+
+	f.products.EXPECT().AddProduct(gomock.Any(), pvzId, model.Product{Type: "обувь"}).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID, product model.Product) (model.Product, error) {
+			claims, ok := authjwt.ClaimsFromContext(ctx)
+			assert.True(t, ok)
+			if ok {
+				assert.Equal(t, f.userId, claims.UserId)
+			}
+			assert.Equal(t, "req-42", requestid.FromContext(ctx))
+			return model.Product{Id: uuid.New(), Type: product.Type, DateTime: time.Now()}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewBufferString(`{"type":"обувь","pvzId":"`+pvzId.String()+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.token)
+	req.Header.Set(requestid.Header, "req-42")
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-42", w.Header().Get(requestid.Header))
+
+	// Без заголовка идентификатор генерируется
+	w = httptest.NewRecorder()
+	f.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.NotEmpty(t, w.Header().Get(requestid.Header))
+}
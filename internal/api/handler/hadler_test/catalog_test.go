@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/api/handler"
+	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ListCatalog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCatalog := mocks.NewMockCatalog(ctrl)
+	h := handler.NewHandler(&service.Service{Catalog: mockCatalog}, new(mocks.MockLogger))
+
+	mockCatalog.EXPECT().ListCatalog(gomock.Any(), model.CatalogCity).
+		Return([]model.CatalogItem{{Name: "Москва", Active: true}, {Name: "Тверь", Active: false}}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/catalog/cities", nil)
+
+	serve(h, ctx, h.ListCatalog(model.CatalogCity))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []response.CatalogItemResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []response.CatalogItemResponse{{Name: "Москва", Active: true}, {Name: "Тверь", Active: false}}, resp)
+}
+
+func TestHandler_AddCatalogItem_Conflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCatalog := mocks.NewMockCatalog(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Catalog: mockCatalog}, mockLogger)
+
+	conflict := apperror.Conflict("product_type %q already exists", "обувь")
+	mockCatalog.EXPECT().AddCatalogItem(gomock.Any(), model.CatalogProductType, "обувь").
+		Return(model.CatalogItem{}, conflict)
+	mockLogger.On("Errorw", "Failed to add catalog item", "catalog", model.CatalogProductType, "name", "обувь", "error", conflict).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/catalog/product-types", bytes.NewBufferString(`{"name":"обувь"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.AddCatalogItem(model.CatalogProductType))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_UpdateCatalogItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCatalog := mocks.NewMockCatalog(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Catalog: mockCatalog}, mockLogger)
+
+	mockCatalog.EXPECT().SetCatalogItemActive(gomock.Any(), model.CatalogCity, "Тверь", false).
+		Return(model.CatalogItem{Name: "Тверь", Active: false}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "name", Value: "Тверь"}}
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/catalog/cities/Тверь", bytes.NewBufferString(`{"active":false}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.UpdateCatalogItem(model.CatalogCity))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"Тверь","active":false}`, w.Body.String())
+
+	// Без поля active запрос не имеет смысла
+	w = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "name", Value: "Тверь"}}
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/catalog/cities/Тверь", bytes.NewBufferString(`{}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	mockLogger.On("Warnw", "Invalid CatalogItemUpdateRequest", "catalog", model.CatalogCity, "error", mock.Anything).Once()
+
+	serve(h, ctx, h.UpdateCatalogItem(model.CatalogCity))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockLogger.AssertExpectations(t)
+}
@@ -0,0 +1,55 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/api/handler"
+	"pvz/internal/apperror"
+	"pvz/internal/service"
+	"pvz/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ErrorMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"validation", apperror.Validation("invalid limit"), http.StatusBadRequest, `{"message":"invalid limit"}`},
+		{"unauthorized", apperror.Unauthorized("missing token"), http.StatusUnauthorized, `{"message":"missing token"}`},
+		{"forbidden", apperror.Forbidden("access denied"), http.StatusForbidden, `{"message":"access denied"}`},
+		{"not found", apperror.NotFound("pvz not found"), http.StatusNotFound, `{"message":"pvz not found"}`},
+		{"conflict", apperror.Conflict("reception already in progress"), http.StatusConflict, `{"message":"reception already in progress"}`},
+		{"wrapped", fmt.Errorf("service: %w", apperror.Conflict("no open reception")), http.StatusConflict, `{"message":"no open reception"}`},
+		{"cause hidden", apperror.Wrap(apperror.ErrNotFound, errors.New("sql: no rows"), "pvz not found"), http.StatusNotFound, `{"message":"pvz not found"}`},
+		{"batch items", apperror.WithItems(apperror.ErrValidation, []apperror.ItemError{{Index: 2, Message: "invalid id format"}}, "product batch contains invalid items"),
+			http.StatusBadRequest, `{"message":"product batch contains invalid items","errors":[{"index":2,"message":"invalid id format"}]}`},
+		{"internal", errors.New("pq: connection refused"), http.StatusInternalServerError, `{"message":"internal server error"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewHandler(&service.Service{}, new(mocks.MockLogger))
+
+			w := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(w)
+			router.Use(h.ErrorMiddleware())
+			router.GET("/test", func(c *gin.Context) {
+				c.Error(tt.err)
+			})
+
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package handler_test
+
+import (
+	"pvz/internal/api/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serve выполняет хендлер и затем ErrorMiddleware, как это делает роутер,
+// чтобы ошибки, добавленные через c.Error, превратились в HTTP-ответ
+func serve(h *handler.Handler, ctx *gin.Context, handlerFunc gin.HandlerFunc) {
+	handlerFunc(ctx)
+	h.ErrorMiddleware()(ctx)
+}
@@ -0,0 +1,206 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/api/handler"
+	"pvz/internal/apperror"
+	"pvz/internal/jwtkeys"
+	"pvz/internal/logger"
+	authjwt "pvz/internal/middleware/jwt"
+	"pvz/internal/rbac"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+type idempotencyFixture struct {
+	router      *gin.Engine
+	keys        *jwtkeys.KeySet
+	token       string
+	userId      uuid.UUID
+	products    *mocks.MockProduct
+	idempotency *mocks.MockIdempotency
+	logger      *mocks.MockLogger
+}
+
+// newIdempotencyFixture собирает роутер целиком, чтобы проверить
+// Idempotency-Key вместе с авторизацией и ErrorMiddleware
+func newIdempotencyFixture(t *testing.T) *idempotencyFixture {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	f := &idempotencyFixture{
+		userId:      uuid.New(),
+		products:    mocks.NewMockProduct(ctrl),
+		idempotency: mocks.NewMockIdempotency(ctrl),
+		logger:      new(mocks.MockLogger),
+	}
+	logger.Log = f.logger
+
+	revocations := mocks.NewMockRevocation(ctrl)
+	revocations.EXPECT().IsRevoked(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+
+	keys := jwtkeys.NewHMAC([]byte("test-signing-key"))
+	token, err := keys.Sign(model.TokenClaims{
+		StandardClaims: jwt.StandardClaims{Id: uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		UserId:         f.userId,
+		Role:           "employee",
+	})
+	require.NoError(t, err)
+	f.token = token
+	f.keys = keys
+
+	h := handler.NewHandler(&service.Service{Product: f.products, Idempotency: f.idempotency}, f.logger)
+	f.router = h.InitRoutes(authjwt.NewAuth(keys, revocations, rbac.Default()))
+
+	f.logger.On("Infow", "Token verified", "userId", f.userId, "role", "employee")
+	return f
+}
+
+func (f *idempotencyFixture) post(path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.token)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_StoresAndReplaysResponse(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	pvzId := uuid.New()
+	body := `{"type":"обувь","pvzId":"` + pvzId.String() + `"}`
+	product := model.Product{Id: uuid.New(), Type: "обувь", ReceptionId: uuid.New(), DateTime: time.Now()}
+
+	var saved []byte
+	gomock.InOrder(
+		f.idempotency.EXPECT().AcquireIdempotencyKey(gomock.Any(), f.userId, "key-1", gomock.Any()).Return(nil, nil),
+		f.products.EXPECT().AddProduct(gomock.Any(), pvzId, model.Product{Type: "обувь"}).Return(product, nil),
+		f.idempotency.EXPECT().SaveIdempotentResponse(gomock.Any(), f.userId, "key-1", http.StatusOK, gomock.Any()).
+			DoAndReturn(func(_, _, _, _ interface{}, body []byte) error {
+				saved = body
+				return nil
+			}),
+	)
+
+	first := f.post("/products", "key-1", body)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, first.Body.Bytes(), saved)
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	// Повтор получает сохранённый ответ, товар повторно не создаётся
+	f.idempotency.EXPECT().AcquireIdempotencyKey(gomock.Any(), f.userId, "key-1", gomock.Any()).
+		Return(&service.IdempotentResponse{StatusCode: http.StatusOK, Body: saved}, nil)
+
+	retry := f.post("/products", "key-1", body)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+}
+
+func TestIdempotency_SavesErrorResponse(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	pvzId := uuid.New()
+	serviceErr := apperror.Conflict("no open reception for pvz %s", pvzId)
+
+	f.idempotency.EXPECT().AcquireIdempotencyKey(gomock.Any(), f.userId, "key-1", gomock.Any()).Return(nil, nil)
+	f.products.EXPECT().AddProduct(gomock.Any(), pvzId, model.Product{Type: "обувь"}).Return(model.Product{}, serviceErr)
+	f.idempotency.EXPECT().SaveIdempotentResponse(gomock.Any(), f.userId, "key-1", http.StatusConflict,
+		[]byte(`{"message":"no open reception for pvz `+pvzId.String()+`"}`)).Return(nil)
+	f.logger.On("Errorw", "Failed to add product", "error", serviceErr, "PvzId", pvzId, "type", "обувь")
+
+	w := f.post("/products", "key-1", `{"type":"обувь","pvzId":"`+pvzId.String()+`"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"message":"no open reception for pvz `+pvzId.String()+`"}`, w.Body.String())
+}
+
+func TestIdempotency_KeyConflict(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	f.idempotency.EXPECT().AcquireIdempotencyKey(gomock.Any(), f.userId, "key-1", gomock.Any()).
+		Return(nil, apperror.Conflict("idempotency key is already used for a different request"))
+
+	w := f.post("/products", "key-1", `{"type":"обувь","pvzId":"`+uuid.NewString()+`"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"message":"idempotency key is already used for a different request"}`, w.Body.String())
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	w := f.post("/products", strings.Repeat("k", 256), `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	pvzId := uuid.New()
+	f.products.EXPECT().AddProduct(gomock.Any(), pvzId, model.Product{Type: "обувь"}).Return(model.Product{Id: uuid.New()}, nil)
+
+	w := f.post("/products", "", `{"type":"обувь","pvzId":"`+pvzId.String()+`"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestIdempotency_DummyTokensDoNotShareKeys(t *testing.T) {
+	f := newIdempotencyFixture(t)
+	f.logger.On("Infow", "Token verified", "userId", uuid.Nil, "role", "employee")
+
+	// У токенов /dummyLogin нет пользователя: ключи принадлежат самому токену
+	pvzId := uuid.New()
+	for i := 0; i < 2; i++ {
+		jti := uuid.New()
+		token, err := f.keys.Sign(model.TokenClaims{
+			StandardClaims: jwt.StandardClaims{Id: jti.String(), ExpiresAt: time.Now().Add(time.Hour).Unix()},
+			Role:           "employee",
+		})
+		require.NoError(t, err)
+		f.token = token
+
+		gomock.InOrder(
+			f.idempotency.EXPECT().AcquireIdempotencyKey(gomock.Any(), jti, "key-1", gomock.Any()).Return(nil, nil),
+			f.products.EXPECT().AddProduct(gomock.Any(), pvzId, model.Product{Type: "обувь"}).Return(model.Product{Id: uuid.New()}, nil),
+			f.idempotency.EXPECT().SaveIdempotentResponse(gomock.Any(), jti, "key-1", http.StatusOK, gomock.Any()).Return(nil),
+		)
+
+		w := f.post("/products", "key-1", `{"type":"обувь","pvzId":"`+pvzId.String()+`"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func TestIdempotency_ReleasesKeyOnPanic(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	pvzId := uuid.New()
+	f.idempotency.EXPECT().AcquireIdempotencyKey(gomock.Any(), f.userId, "key-1", gomock.Any()).Return(nil, nil)
+	f.products.EXPECT().AddProduct(gomock.Any(), pvzId, gomock.Any()).DoAndReturn(
+		func(_, _, _ interface{}) (model.Product, error) { panic("boom") })
+	f.idempotency.EXPECT().ReleaseIdempotencyKey(gomock.Any(), f.userId, "key-1").Return(nil)
+
+	// Паника уходит дальше по стеку, но ключ уже освобождён
+	assert.PanicsWithValue(t, "boom", func() {
+		f.post("/products", "key-1", `{"type":"обувь","pvzId":"`+pvzId.String()+`"}`)
+	})
+}
@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/api/handler"
+	"pvz/internal/apperror"
+	"pvz/internal/live"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func newLiveContext(ctx context.Context, pvzId, lastEventId string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/pvz/"+pvzId+"/events", nil).WithContext(ctx)
+	if lastEventId != "" {
+		c.Request.Header.Set("Last-Event-ID", lastEventId)
+	}
+	c.Params = gin.Params{{Key: "pvzId", Value: pvzId}}
+	return w, c
+}
+
+func newLiveHub(heartbeat time.Duration) *live.Hub {
+	return live.NewHub(live.Config{ReplayBuffer: 10, ClientBuffer: 10, HeartbeatInterval: heartbeat}, new(mocks.MockLogger))
+}
+
+func TestHandler_StreamPvzEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLive := mocks.NewMockLive(ctrl)
+	h := handler.NewHandler(&service.Service{Live: mockLive}, new(mocks.MockLogger))
+	hub := newLiveHub(time.Minute)
+
+	pvzId := uuid.New()
+	first := hub.Subscribe(pvzId, "")
+	hub.Publish(model.Event{Id: uuid.New(), Type: model.EventReceptionOpened, PvzId: pvzId, Payload: []byte(`{}`)})
+	hub.Publish(model.Event{Id: uuid.New(), Type: model.EventProductAdded, PvzId: pvzId, Payload: []byte(`{}`)})
+	opened, added := <-first.Messages(), <-first.Messages()
+	first.Close()
+
+	var removed live.Message
+	mockLive.EXPECT().SubscribePvzEvents(gomock.Any(), pvzId, opened.Id).
+		DoAndReturn(func(_ context.Context, pvzId uuid.UUID, lastEventId string) (*live.Subscription, error) {
+			sub := hub.Subscribe(pvzId, lastEventId)
+			hub.Publish(model.Event{Id: uuid.New(), Type: model.EventProductRemoved, PvzId: pvzId, Payload: []byte(`{}`)})
+			removed = sub.Replay[0]
+			// Остановка хаба завершает поток после уже разосланных событий
+			hub.Close()
+			return sub, nil
+		})
+
+	w, c := newLiveContext(context.Background(), pvzId.String(), opened.Id)
+	serve(h, c, h.StreamPvzEvents)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	body := w.Body.String()
+	assert.Equal(t, added.Id, removed.Id)
+	assert.True(t, strings.HasPrefix(body, "id:"+added.Id+"\nevent:ProductAdded\ndata:{\"id\":\""+added.Event.Id.String()+"\""), body)
+	assert.Contains(t, body, "\nevent:ProductRemoved\ndata:")
+	assert.NotContains(t, body, "ReceptionOpened")
+}
+
+func TestHandler_StreamPvzEvents_Resync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLive := mocks.NewMockLive(ctrl)
+	h := handler.NewHandler(&service.Service{Live: mockLive}, new(mocks.MockLogger))
+	hub := newLiveHub(time.Minute)
+
+	pvzId := uuid.New()
+	mockLive.EXPECT().SubscribePvzEvents(gomock.Any(), pvzId, "stale").
+		DoAndReturn(func(_ context.Context, pvzId uuid.UUID, lastEventId string) (*live.Subscription, error) {
+			sub := hub.Subscribe(pvzId, lastEventId)
+			hub.Close()
+			return sub, nil
+		})
+
+	w, c := newLiveContext(context.Background(), pvzId.String(), "stale")
+	serve(h, c, h.StreamPvzEvents)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "event:resync\ndata:\n\n", w.Body.String())
+}
+
+func TestHandler_StreamPvzEvents_Heartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLive := mocks.NewMockLive(ctrl)
+	h := handler.NewHandler(&service.Service{Live: mockLive}, new(mocks.MockLogger))
+	hub := newLiveHub(10 * time.Millisecond)
+
+	pvzId := uuid.New()
+	mockLive.EXPECT().SubscribePvzEvents(gomock.Any(), pvzId, "").
+		DoAndReturn(func(_ context.Context, pvzId uuid.UUID, lastEventId string) (*live.Subscription, error) {
+			return hub.Subscribe(pvzId, lastEventId), nil
+		})
+
+	// Клиент отключается: поток завершается, подписка закрывается
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w, c := newLiveContext(ctx, pvzId.String(), "")
+	serve(h, c, h.StreamPvzEvents)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "event:ping\ndata:\n\n")
+	assert.NotContains(t, w.Body.String(), "id:")
+}
+
+func TestHandler_StreamPvzEvents_PvzNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLive := mocks.NewMockLive(ctrl)
+	log := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Live: mockLive}, log)
+
+	pvzId := uuid.New()
+	notFound := apperror.NotFound("pvz %s not found", pvzId)
+	mockLive.EXPECT().SubscribePvzEvents(gomock.Any(), pvzId, "").Return(nil, notFound)
+	log.On("Errorw", "Failed to subscribe to PVZ events", "pvzId", pvzId, "error", notFound)
+
+	w, c := newLiveContext(context.Background(), pvzId.String(), "")
+	serve(h, c, h.StreamPvzEvents)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
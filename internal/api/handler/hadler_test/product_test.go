@@ -11,6 +11,7 @@ import (
 
 	"pvz/internal/api/handler"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
@@ -50,7 +51,7 @@ func TestHandler_AddProduct_Success(t *testing.T) {
 
 	// Mock expectations
 	mockProductService.EXPECT().
-		AddProduct(gomock.Any(), pvzID, productType).
+		AddProduct(gomock.Any(), pvzID, model.Product{Type: productType}).
 		Return(expectedProduct, nil)
 
 	// Execute
@@ -59,7 +60,7 @@ func TestHandler_AddProduct_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.AddProduct(ctx)
+	serve(h, ctx, h.AddProduct)
 
 	// Verify
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -106,7 +107,7 @@ func TestHandler_AddProduct_InvalidInput(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/products", bytes.NewBufferString(invalidJSON))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.AddProduct(ctx)
+	serve(h, ctx, h.AddProduct)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -114,8 +115,8 @@ func TestHandler_AddProduct_InvalidInput(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid request body", resp["error"])
-	assert.Contains(t, resp, "details")
+	assert.Equal(t, "invalid request body", resp["message"])
+	assert.NotContains(t, resp, "details")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -146,7 +147,7 @@ func TestHandler_AddProduct_InvalidPvzId(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.AddProduct(ctx)
+	serve(h, ctx, h.AddProduct)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -154,7 +155,7 @@ func TestHandler_AddProduct_InvalidPvzId(t *testing.T) {
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid PvzId format", resp["error"])
+	assert.Equal(t, "invalid pvzId format", resp["message"])
 
 	mockLogger.AssertExpectations(t)
 }
@@ -181,7 +182,7 @@ func TestHandler_AddProduct_ServiceError(t *testing.T) {
 
 	// Mock expectations
 	mockProductService.EXPECT().
-		AddProduct(gomock.Any(), pvzID, productType).
+		AddProduct(gomock.Any(), pvzID, model.Product{Type: productType}).
 		Return(model.Product{}, expectedErr)
 
 	mockLogger.On("Errorw", "Failed to add product", "error", expectedErr, "PvzId", pvzID, "type", productType).Once()
@@ -192,7 +193,7 @@ func TestHandler_AddProduct_ServiceError(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.AddProduct(ctx)
+	serve(h, ctx, h.AddProduct)
 
 	// Verify
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -200,7 +201,8 @@ func TestHandler_AddProduct_ServiceError(t *testing.T) {
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Failed to add product", resp["error"])
+	assert.Equal(t, "internal server error", resp["message"])
+	assert.NotContains(t, resp["message"], expectedErr.Error())
 
 	mockLogger.AssertExpectations(t)
 }
@@ -217,10 +219,11 @@ func TestHandler_DeleteLastProduct_Success(t *testing.T) {
 
 	// Test data
 	pvzID := uuid.New()
+	userID := uuid.New()
 
 	// Mock expectations
 	mockProductService.EXPECT().
-		DeleteLastProduct(gomock.Any(), pvzID).
+		DeleteLastProduct(gomock.Any(), pvzID, userID).
 		Return(nil)
 
 	mockLogger.On("Infow", "Attempting to delete last product", "PvzId", pvzID).Once()
@@ -231,8 +234,9 @@ func TestHandler_DeleteLastProduct_Success(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(w)
 	ctx.Request = httptest.NewRequest(http.MethodDelete, "/products/last/"+pvzID.String(), nil)
 	ctx.Params = gin.Params{gin.Param{Key: "pvzId", Value: pvzID.String()}}
+	ctx.Set("userClaims", &model.TokenClaims{UserId: userID, Role: "employee"})
 
-	h.DeleteLastProduct(ctx)
+	serve(h, ctx, h.DeleteLastProduct)
 
 	// Verify
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -267,7 +271,7 @@ func TestHandler_DeleteLastProduct_InvalidPvzId(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodDelete, "/products/last/"+invalidPvzId, nil)
 	ctx.Params = gin.Params{gin.Param{Key: "pvzId", Value: invalidPvzId}}
 
-	h.DeleteLastProduct(ctx)
+	serve(h, ctx, h.DeleteLastProduct)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -275,7 +279,7 @@ func TestHandler_DeleteLastProduct_InvalidPvzId(t *testing.T) {
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid PvzId format", resp["error"])
+	assert.Equal(t, "invalid pvzId format", resp["message"])
 
 	mockLogger.AssertExpectations(t)
 }
@@ -292,11 +296,12 @@ func TestHandler_DeleteLastProduct_ServiceError(t *testing.T) {
 
 	// Test data
 	pvzID := uuid.New()
-	expectedErr := errors.New("no products found")
+	userID := uuid.New()
+	expectedErr := apperror.Conflict("no products found for current reception")
 
 	// Mock expectations
 	mockProductService.EXPECT().
-		DeleteLastProduct(gomock.Any(), pvzID).
+		DeleteLastProduct(gomock.Any(), pvzID, userID).
 		Return(expectedErr)
 
 	mockLogger.On("Infow", "Attempting to delete last product", "PvzId", pvzID).Once()
@@ -307,16 +312,282 @@ func TestHandler_DeleteLastProduct_ServiceError(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(w)
 	ctx.Request = httptest.NewRequest(http.MethodDelete, "/products/last/"+pvzID.String(), nil)
 	ctx.Params = gin.Params{gin.Param{Key: "pvzId", Value: pvzID.String()}}
+	ctx.Set("userClaims", &model.TokenClaims{UserId: userID, Role: "employee"})
 
-	h.DeleteLastProduct(ctx)
+	serve(h, ctx, h.DeleteLastProduct)
 
 	// Verify
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusConflict, w.Code)
 
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["error"], expectedErr.Error())
+	assert.Equal(t, "no products found for current reception", resp["message"])
+
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_AddProducts_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	pvzID := uuid.New()
+	clientID := uuid.New()
+	receptionID := uuid.New()
+	body := `{"pvzId":"` + pvzID.String() + `","products":[{"type":"обувь"},{"id":"` + clientID.String() + `","type":"одежда"}]}`
+
+	mockProductService.EXPECT().
+		AddProducts(gomock.Any(), pvzID, []model.Product{{Type: "обувь"}, {Id: clientID, Type: "одежда"}}).
+		Return([]model.Product{
+			{Id: uuid.New(), Type: "обувь", ReceptionId: receptionID, DateTime: time.Now()},
+			{Id: clientID, Type: "одежда", ReceptionId: receptionID, DateTime: time.Now()},
+		}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/products/batch", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.AddProducts)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp response.ProductBatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Products, 2)
+	assert.Equal(t, clientID.String(), resp.Products[1].Id)
+	assert.Equal(t, receptionID.String(), resp.Products[0].ReceptionId)
+}
+
+func TestHandler_AddProducts_InvalidItemId(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	body := `{"pvzId":"` + uuid.NewString() + `","products":[{"type":"обувь"},{"id":"not-a-uuid","type":"обувь"}]}`
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/products/batch", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.AddProducts)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"product batch contains invalid items","errors":[{"index":1,"message":"invalid id format"}]}`, w.Body.String())
+}
+
+func TestHandler_AddProducts_ServiceItemErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	pvzID := uuid.New()
+	body := `{"pvzId":"` + pvzID.String() + `","products":[{"type":"мебель"}]}`
+	serviceErr := apperror.WithItems(apperror.ErrValidation,
+		[]apperror.ItemError{{Index: 0, Message: `unknown product_type "мебель"`}}, "product batch contains invalid items")
+
+	mockProductService.EXPECT().AddProducts(gomock.Any(), pvzID, gomock.Any()).Return(nil, serviceErr)
+	mockLogger.On("Errorw", "Failed to add product batch", "error", serviceErr, "PvzId", pvzID, "count", 1).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/products/batch", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.AddProducts)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"product batch contains invalid items","errors":[{"index":0,"message":"unknown product_type \"мебель\""}]}`, w.Body.String())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_FindProducts_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	barcode := "4600000000017"
+	weight := 350
+	pvzID := uuid.New()
+	product := model.Product{
+		Id:          uuid.New(),
+		Type:        "обувь",
+		DateTime:    time.Now(),
+		ReceptionId: uuid.New(),
+		Barcode:     &barcode,
+		Weight:      &weight,
+		Attributes:  json.RawMessage(`{"size":42}`),
+	}
+
+	mockProductService.EXPECT().
+		FindProductsByBarcode(gomock.Any(), barcode).
+		Return([]model.ProductWithPvz{{Product: product, PvzId: pvzID}}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/products?barcode="+barcode, nil)
+
+	serve(h, ctx, h.FindProducts)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp []response.ProductLookupResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+	assert.Equal(t, pvzID.String(), resp[0].PvzId)
+	assert.Equal(t, product.Id.String(), resp[0].Id)
+	assert.Equal(t, barcode, *resp[0].Barcode)
+	assert.Equal(t, weight, *resp[0].Weight)
+	assert.JSONEq(t, `{"size":42}`, string(resp[0].Attributes))
+	assert.Nil(t, resp[0].OrderId)
+}
+
+func TestHandler_FindProducts_MissingBarcode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	serviceErr := apperror.Validation("barcode is required")
+	mockProductService.EXPECT().FindProductsByBarcode(gomock.Any(), "").Return(nil, serviceErr)
+	mockLogger.On("Errorw", "Failed to find products", "barcode", "", "error", serviceErr).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/products", nil)
+
+	serve(h, ctx, h.FindProducts)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"barcode is required"}`, w.Body.String())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_UpdateProduct_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	productID := uuid.New()
+	userID := uuid.New()
+	productType := "одежда"
+
+	mockProductService.EXPECT().
+		UpdateProduct(gomock.Any(), productID, model.ProductUpdate{Type: &productType}, userID).
+		Return(model.Product{Id: productID, Type: productType, ReceptionId: uuid.New(), DateTime: time.Now()}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/products/"+productID.String(), bytes.NewBufferString(`{"type":"одежда"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "productId", Value: productID.String()}}
+	ctx.Set("userClaims", &model.TokenClaims{UserId: userID, Role: "employee"})
+
+	serve(h, ctx, h.UpdateProduct)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.ProductResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, productID.String(), resp.Id)
+	assert.Equal(t, productType, resp.Type)
+}
+
+func TestHandler_DeleteProduct_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProduct(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+	productID := uuid.New()
+	userID := uuid.New()
+
+	mockProductService.EXPECT().DeleteProduct(gomock.Any(), productID, userID).Return(nil)
+	mockLogger.On("Infow", "Product deleted", "ProductId", productID).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodDelete, "/products/"+productID.String(), nil)
+	ctx.Params = gin.Params{gin.Param{Key: "productId", Value: productID.String()}}
+	ctx.Set("userClaims", &model.TokenClaims{UserId: userID, Role: "employee"})
 
+	serve(h, ctx, h.DeleteProduct)
+
+	assert.Equal(t, http.StatusNoContent, ctx.Writer.Status())
 	mockLogger.AssertExpectations(t)
 }
+
+func TestHandler_DeleteProduct_Errors(t *testing.T) {
+	productID := uuid.New()
+
+	tests := []struct {
+		name       string
+		param      string
+		serviceErr error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid id", param: "not-a-uuid", wantStatus: http.StatusBadRequest, wantBody: `{"message":"invalid productId format"}`},
+		{
+			name:       "closed reception",
+			param:      productID.String(),
+			serviceErr: apperror.Conflict("reception is closed"),
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"message":"reception is closed"}`,
+		},
+		{
+			name:       "not found",
+			param:      productID.String(),
+			serviceErr: apperror.NotFound("product %s not found", productID),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"message":"product ` + productID.String() + ` not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockProductService := mocks.NewMockProduct(ctrl)
+			mockLogger := new(mocks.MockLogger)
+			mockLogger.On("Warnw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+			mockLogger.On("Errorw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+			h := handler.NewHandler(&service.Service{Product: mockProductService}, mockLogger)
+
+			if tt.serviceErr != nil {
+				mockProductService.EXPECT().DeleteProduct(gomock.Any(), productID, gomock.Any()).Return(tt.serviceErr)
+			}
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodDelete, "/products/"+tt.param, nil)
+			ctx.Params = gin.Params{gin.Param{Key: "productId", Value: tt.param}}
+			ctx.Set("userClaims", &model.TokenClaims{UserId: uuid.New(), Role: "employee"})
+
+			serve(h, ctx, h.DeleteProduct)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
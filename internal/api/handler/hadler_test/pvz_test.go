@@ -13,6 +13,8 @@ import (
 
 	"pvz/internal/api/handler"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/logger"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
@@ -64,7 +66,7 @@ func TestHandler_CreatePvz_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/pvz", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreatePvz(ctx)
+	serve(h, ctx, h.CreatePvz)
 
 	// Verify
 	assert.Equal(t, http.StatusCreated, w.Code)
@@ -106,11 +108,11 @@ func TestHandler_CreatePvz_InvalidInput(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/pvz", bytes.NewBufferString(invalidJSON))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreatePvz(ctx)
+	serve(h, ctx, h.CreatePvz)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "неверный формат данных")
+	assert.JSONEq(t, `{"message":"invalid request body"}`, w.Body.String())
 
 	mockLogger.AssertExpectations(t)
 }
@@ -146,11 +148,11 @@ func TestHandler_CreatePvz_ServiceError(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/pvz", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreatePvz(ctx)
+	serve(h, ctx, h.CreatePvz)
 
 	// Verify
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), expectedErr.Error())
+	assert.JSONEq(t, `{"message":"internal server error"}`, w.Body.String())
 
 	mockLogger.AssertExpectations(t)
 }
@@ -202,11 +204,11 @@ func TestHandler_GetPvz_Success(t *testing.T) {
 
 	// Mock expectations - важно передать указатели на time.Time
 	mockPvzService.EXPECT().
-		GetPvzList(gomock.Any(), limit, offset, &startDate, &endDate).
+		GetPvzList(gomock.Any(), limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate}).
 		Return(expectedResult, nil)
 
 	mockLogger.On("Infow", "Received request for Pvz list",
-		"limit", "10", "offset", "0", "startDate", startDateStr, "endDate", endDateStr).Once()
+		"limit", "10", "offset", "0", "startDate", startDateStr, "endDate", endDateStr, "status", "").Once()
 	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", len(expectedResult)).Once()
 
 	// Execute
@@ -214,7 +216,7 @@ func TestHandler_GetPvz_Success(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(w)
 	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?limit=10&offset=0&startDate="+url.QueryEscape(startDateStr)+"&endDate="+url.QueryEscape(endDateStr), nil)
 
-	h.GetPvz(ctx)
+	serve(h, ctx, h.GetPvz)
 
 	// Verify
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -239,7 +241,7 @@ func TestHandler_GetPvz_InvalidLimit(t *testing.T) {
 
 	// Mock expectations
 	mockLogger.On("Infow", "Received request for Pvz list",
-		"limit", "invalid", "offset", "0", "startDate", "", "endDate", "").Once()
+		"limit", "invalid", "offset", "0", "startDate", "", "endDate", "", "status", "").Once()
 	mockLogger.On("Warnw", "Invalid limit", "error", mock.Anything).Once()
 
 	// Execute
@@ -247,7 +249,7 @@ func TestHandler_GetPvz_InvalidLimit(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(w)
 	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?limit=invalid&offset=0", nil)
 
-	h.GetPvz(ctx)
+	serve(h, ctx, h.GetPvz)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -256,6 +258,122 @@ func TestHandler_GetPvz_InvalidLimit(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestHandler_GetPvz_CursorMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Pvz: mockPvzService}, mockLogger)
+
+	next := "next-cursor"
+	page := response.PvzPageResponse{
+		Items:      []response.PvzFullResponse{{Pvz: response.PvzResponse{Id: uuid.NewString(), City: "Москва"}}},
+		NextCursor: &next,
+	}
+
+	mockPvzService.EXPECT().
+		GetPvzPage(gomock.Any(), 5, "abc", model.PvzFilter{}).
+		Return(page, nil)
+
+	mockLogger.On("Infow", "Received request for Pvz list",
+		"limit", "5", "offset", "0", "startDate", "", "endDate", "", "status", "").Once()
+	mockLogger.On("Infow", "Successfully retrieved Pvz page", "count", 1).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?limit=5&cursor=abc", nil)
+
+	serve(h, ctx, h.GetPvz)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.PvzPageResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, page, resp)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_GetPvz_Filters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Pvz: mockPvzService}, mockLogger)
+
+	registeredFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedFilter := model.PvzFilter{
+		ReceptionStatus: model.ReceptionStatusInProgress,
+		ProductType:     "обувь",
+		IncludeEmpty:    true,
+		City:            "Казань",
+		RegisteredFrom:  &registeredFrom,
+	}
+
+	mockPvzService.EXPECT().
+		GetPvzList(gomock.Any(), 10, 0, expectedFilter).
+		Return([]response.PvzFullResponse{}, nil)
+
+	mockLogger.On("Infow", "Received request for Pvz list",
+		"limit", "10", "offset", "0", "startDate", "", "endDate", "", "status", "").Once()
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 0).Once()
+
+	query := url.Values{
+		"includeEmpty":    {"true"},
+		"city":            {"Казань"},
+		"receptionStatus": {"in_progress"},
+		"productType":     {"обувь"},
+		"registeredFrom":  {"2025-01-01T00:00:00Z"},
+	}
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?"+query.Encode(), nil)
+
+	serve(h, ctx, h.GetPvz)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_GetPvz_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{name: "zero limit", query: "limit=0", message: "invalid limit"},
+		{name: "negative offset", query: "offset=-1", message: "invalid offset"},
+		{name: "cursor with offset", query: "cursor=&offset=10", message: "cursor and offset cannot be used together"},
+		{name: "reception status", query: "receptionStatus=open", message: "invalid receptionStatus"},
+		{name: "include empty", query: "includeEmpty=maybe", message: "invalid includeEmpty"},
+		{name: "registered to", query: "registeredTo=yesterday", message: "invalid registeredTo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := new(mocks.MockLogger)
+			mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+				mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockLogger.On("Warnw", mock.Anything, mock.Anything, mock.Anything).Maybe()
+			mockLogger.On("Warnw", mock.Anything).Maybe()
+			logger.Log = mockLogger
+			mockLogger.On("Warnw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+			h := handler.NewHandler(&service.Service{}, mockLogger)
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?"+tt.query, nil)
+
+			serve(h, ctx, h.GetPvz)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"message":"`+tt.message+`"}`, w.Body.String())
+		})
+	}
+}
+
 func TestHandler_GetPvz_ServiceError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -272,11 +390,11 @@ func TestHandler_GetPvz_ServiceError(t *testing.T) {
 
 	// Mock expectations
 	mockPvzService.EXPECT().
-		GetPvzList(gomock.Any(), limit, offset, nil, nil).
+		GetPvzList(gomock.Any(), limit, offset, model.PvzFilter{}).
 		Return(nil, expectedErr)
 
 	mockLogger.On("Infow", "Received request for Pvz list",
-		"limit", "10", "offset", "0", "startDate", "", "endDate", "").Once()
+		"limit", "10", "offset", "0", "startDate", "", "endDate", "", "status", "").Once()
 	mockLogger.On("Errorw", "Failed to get Pvz list", "error", expectedErr).Once()
 
 	// Execute
@@ -284,11 +402,140 @@ func TestHandler_GetPvz_ServiceError(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(w)
 	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?limit=10&offset=0", nil)
 
-	h.GetPvz(ctx)
+	serve(h, ctx, h.GetPvz)
 
 	// Verify
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), expectedErr.Error())
+	assert.JSONEq(t, `{"message":"internal server error"}`, w.Body.String())
 
 	mockLogger.AssertExpectations(t)
 }
+
+func TestHandler_GetPvz_InvalidStatus(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+
+	mockLogger.On("Infow", "Received request for Pvz list",
+		"limit", "10", "offset", "0", "startDate", "", "endDate", "", "status", "closed").Once()
+	mockLogger.On("Warnw", "Invalid status", "status", "closed").Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz?status=closed", nil)
+
+	serve(h, ctx, h.GetPvz)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"invalid status"}`, w.Body.String())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_GetPvzById_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Pvz: mockPvzService}, mockLogger)
+
+	pvzID := uuid.New()
+	mockPvzService.EXPECT().GetPvz(gomock.Any(), pvzID).
+		Return(model.Pvz{}, apperror.NotFound("pvz %s not found", pvzID))
+	mockLogger.On("Errorw", "Failed to get PVZ", "PvzId", pvzID, "error", mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "pvzId", Value: pvzID.String()}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String(), nil)
+
+	serve(h, ctx, h.GetPvzById)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_UpdatePvz_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPvzService := mocks.NewMockPvz(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Pvz: mockPvzService}, mockLogger)
+
+	pvzID := uuid.New()
+	address := "ул. Тверская, 1"
+	updated := model.Pvz{Id: pvzID, City: "Москва", Address: address, Status: model.PvzStatusActive}
+
+	mockPvzService.EXPECT().UpdatePvz(gomock.Any(), pvzID, model.PvzUpdate{Address: &address}).Return(updated, nil)
+	mockLogger.On("Infow", "Updating PVZ", "PvzId", pvzID, "request", mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "pvzId", Value: pvzID.String()}}
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/pvz/"+pvzID.String(), bytes.NewBufferString(`{"address":"ул. Тверская, 1"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.UpdatePvz)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.PvzResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, address, resp.Address)
+	assert.Equal(t, model.PvzStatusActive, resp.Status)
+	assert.Nil(t, resp.DeactivatedAt)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_DeactivatePvz_InvalidId(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+
+	mockLogger.On("Warnw", "Invalid PvzId format", "PvzId", "not-a-uuid", "error", mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "pvzId", Value: "not-a-uuid"}}
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/pvz/not-a-uuid/deactivate", nil)
+
+	serve(h, ctx, h.DeactivatePvz)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"invalid pvzId format"}`, w.Body.String())
+}
+
+func TestHandler_DeletePvz(t *testing.T) {
+	tests := []struct {
+		name         string
+		serviceErr   error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusNoContent},
+		{name: "has receptions", serviceErr: apperror.Conflict("pvz has receptions, deactivate it instead"), expectedCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPvzService := mocks.NewMockPvz(ctrl)
+			mockLogger := new(mocks.MockLogger)
+			h := handler.NewHandler(&service.Service{Pvz: mockPvzService}, mockLogger)
+
+			pvzID := uuid.New()
+			mockPvzService.EXPECT().DeletePvz(gomock.Any(), pvzID).Return(tt.serviceErr)
+			mockLogger.On("Infow", "PVZ deleted", "PvzId", pvzID).Maybe()
+			mockLogger.On("Errorw", "Failed to delete PVZ", "PvzId", pvzID, "error", tt.serviceErr).Maybe()
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Params = gin.Params{{Key: "pvzId", Value: pvzID.String()}}
+			ctx.Request = httptest.NewRequest(http.MethodDelete, "/pvz/"+pvzID.String(), nil)
+
+			serve(h, ctx, h.DeletePvz)
+
+			assert.Equal(t, tt.expectedCode, ctx.Writer.Status())
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"pvz/internal/api/handler"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
@@ -61,7 +62,7 @@ func TestHandler_CreateReception_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/receptions", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreateReception(ctx)
+	serve(h, ctx, h.CreateReception)
 
 	// Verify
 	assert.Equal(t, http.StatusCreated, w.Code)
@@ -108,7 +109,7 @@ func TestHandler_CreateReception_InvalidInput(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/receptions", bytes.NewBufferString(invalidJSON))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreateReception(ctx)
+	serve(h, ctx, h.CreateReception)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -116,8 +117,8 @@ func TestHandler_CreateReception_InvalidInput(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["message"], "Invalid input data")
-	assert.Contains(t, resp, "error")
+	assert.Contains(t, resp["message"], "invalid")
+	assert.NotContains(t, resp, "error")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -147,7 +148,7 @@ func TestHandler_CreateReception_InvalidPvzId(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/receptions", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreateReception(ctx)
+	serve(h, ctx, h.CreateReception)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -155,8 +156,8 @@ func TestHandler_CreateReception_InvalidPvzId(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["message"], "Invalid input data")
-	assert.Contains(t, resp, "error")
+	assert.Contains(t, resp["message"], "invalid")
+	assert.NotContains(t, resp, "error")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -192,7 +193,7 @@ func TestHandler_CreateReception_ServiceError(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/receptions", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.CreateReception(ctx)
+	serve(h, ctx, h.CreateReception)
 
 	// Verify
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -200,8 +201,8 @@ func TestHandler_CreateReception_ServiceError(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Failed to create reception", resp["message"])
-	assert.Contains(t, resp["error"], expectedErr.Error())
+	assert.Equal(t, "internal server error", resp["message"])
+	assert.NotContains(t, resp, "error")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -233,7 +234,7 @@ func TestHandler_CloseReception_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPut, "/receptions/"+pvzID.String()+"/close", nil)
 	ctx.Params = gin.Params{gin.Param{Key: "pvzId", Value: pvzID.String()}}
 
-	h.CloseReception(ctx)
+	serve(h, ctx, h.CloseReception)
 
 	// Verify
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -268,7 +269,7 @@ func TestHandler_CloseReception_InvalidPvzId(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPut, "/receptions/"+invalidPvzId+"/close", nil)
 	ctx.Params = gin.Params{gin.Param{Key: "pvzId", Value: invalidPvzId}}
 
-	h.CloseReception(ctx)
+	serve(h, ctx, h.CloseReception)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -276,7 +277,7 @@ func TestHandler_CloseReception_InvalidPvzId(t *testing.T) {
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid PvzId format", resp["error"])
+	assert.Equal(t, "invalid pvzId format", resp["message"])
 
 	mockLogger.AssertExpectations(t)
 }
@@ -293,7 +294,7 @@ func TestHandler_CloseReception_ServiceError(t *testing.T) {
 
 	// Test data
 	pvzID := uuid.New()
-	expectedErr := errors.New("reception not found")
+	expectedErr := apperror.NotFound("pvz %s not found", pvzID)
 
 	// Mock expectations
 	mockReceptionService.EXPECT().
@@ -309,15 +310,264 @@ func TestHandler_CloseReception_ServiceError(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPut, "/receptions/"+pvzID.String()+"/close", nil)
 	ctx.Params = gin.Params{gin.Param{Key: "pvzId", Value: pvzID.String()}}
 
-	h.CloseReception(ctx)
+	serve(h, ctx, h.CloseReception)
 
 	// Verify
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["error"], expectedErr.Error())
+	assert.Equal(t, expectedErr.Error(), resp["message"])
+
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_GetReception_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceptionService := mocks.NewMockReception(ctrl)
+	h := handler.NewHandler(&service.Service{Reception: mockReceptionService}, new(mocks.MockLogger))
+
+	reception := model.Reception{Id: uuid.New(), DateTime: time.Now(), PvzId: uuid.New(), Status: model.ReceptionStatusClose}
+	product := model.Product{Id: uuid.New(), DateTime: time.Now(), Type: "обувь", ReceptionId: reception.Id}
+
+	mockReceptionService.EXPECT().
+		GetReception(gomock.Any(), reception.Id).
+		Return(model.ReceptionWithProducts{Reception: reception, Products: []model.Product{product}}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "receptionId", Value: reception.Id.String()}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/receptions/"+reception.Id.String(), nil)
+
+	serve(h, ctx, h.GetReception)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.ReceptionWrapper
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, reception.Id.String(), resp.Reception.Id)
+	assert.Len(t, resp.Products, 1)
+	assert.Equal(t, "обувь", resp.Products[0].Type)
+}
+
+func TestHandler_GetReception_InvalidId(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+
+	mockLogger.On("Warnw", "Invalid ReceptionId format", "ReceptionId", "42", "error", mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "receptionId", Value: "42"}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/receptions/42", nil)
+
+	serve(h, ctx, h.GetReception)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_GetReceptionList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceptionService := mocks.NewMockReception(ctrl)
+	h := handler.NewHandler(&service.Service{Reception: mockReceptionService}, new(mocks.MockLogger))
+
+	pvzId := uuid.New()
+	startDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	receptions := []model.Reception{
+		{Id: uuid.New(), PvzId: pvzId, Status: model.ReceptionStatusClose},
+		{Id: uuid.New(), PvzId: pvzId, Status: model.ReceptionStatusClose},
+	}
+
+	mockReceptionService.EXPECT().
+		GetReceptionList(gomock.Any(), pvzId, 2, 4, model.ReceptionFilter{Status: model.ReceptionStatusClose, StartDate: &startDate}).
+		Return(receptions, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "pvzId", Value: pvzId.String()}}
+	ctx.Request = httptest.NewRequest(http.MethodGet,
+		"/pvz/"+pvzId.String()+"/receptions?status=close&limit=2&offset=4&startDate=2025-03-01T00:00:00Z", nil)
+
+	serve(h, ctx, h.GetReceptionList)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp []response.ReceptionResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 2)
+}
+
+func TestHandler_GetReceptionList_InvalidStatus(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
 
+	pvzId := uuid.New()
+	mockLogger.On("Warnw", "Invalid status", "status", "open").Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "pvzId", Value: pvzId.String()}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz/"+pvzId.String()+"/receptions?status=open", nil)
+
+	serve(h, ctx, h.GetReceptionList)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_GetCurrentReception_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceptionService := mocks.NewMockReception(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Reception: mockReceptionService}, mockLogger)
+
+	pvzId := uuid.New()
+	notFound := apperror.NotFound("no in-progress reception for pvz %s", pvzId)
+
+	mockReceptionService.EXPECT().GetCurrentReception(gomock.Any(), pvzId).Return(model.ReceptionWithProducts{}, notFound)
+	mockLogger.On("Errorw", "Failed to get current reception", "PvzId", pvzId, "error", notFound).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Params = gin.Params{{Key: "pvzId", Value: pvzId.String()}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/pvz/"+pvzId.String()+"/receptions/current", nil)
+
+	serve(h, ctx, h.GetCurrentReception)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func newTransitionContext(receptionId, action string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/receptions/"+receptionId+"/"+action, nil)
+	ctx.Params = gin.Params{gin.Param{Key: "receptionId", Value: receptionId}}
+	return w, ctx
+}
+
+func TestHandler_ReceptionTransitions(t *testing.T) {
+	receptionID, actorID := uuid.New(), uuid.New()
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		action    string
+		reception model.Reception
+		expect    func(m *mocks.MockReception) *gomock.Call
+		handler   func(h *handler.Handler) gin.HandlerFunc
+		check     func(t *testing.T, resp response.ReceptionResponse)
+	}{
+		{
+			action:    "close",
+			reception: model.Reception{Id: receptionID, Status: model.ReceptionStatusClose, ClosedAt: &at, ClosedBy: &actorID},
+			expect: func(m *mocks.MockReception) *gomock.Call {
+				return m.EXPECT().CloseReceptionById(gomock.Any(), receptionID)
+			},
+			handler: func(h *handler.Handler) gin.HandlerFunc { return h.CloseReceptionById },
+			check: func(t *testing.T, resp response.ReceptionResponse) {
+				assert.Equal(t, "2024-05-01 10:00:00", *resp.ClosedAt)
+				assert.Equal(t, actorID.String(), *resp.ClosedBy)
+			},
+		},
+		{
+			action:    "verify",
+			reception: model.Reception{Id: receptionID, Status: model.ReceptionStatusVerified, VerifiedAt: &at, VerifiedBy: &actorID},
+			expect: func(m *mocks.MockReception) *gomock.Call {
+				return m.EXPECT().VerifyReception(gomock.Any(), receptionID)
+			},
+			handler: func(h *handler.Handler) gin.HandlerFunc { return h.VerifyReception },
+			check: func(t *testing.T, resp response.ReceptionResponse) {
+				assert.Equal(t, actorID.String(), *resp.VerifiedBy)
+			},
+		},
+		{
+			action:    "cancel",
+			reception: model.Reception{Id: receptionID, Status: model.ReceptionStatusCancelled, CancelledAt: &at, CancelledBy: &actorID},
+			expect: func(m *mocks.MockReception) *gomock.Call {
+				return m.EXPECT().CancelReception(gomock.Any(), receptionID)
+			},
+			handler: func(h *handler.Handler) gin.HandlerFunc { return h.CancelReception },
+			check: func(t *testing.T, resp response.ReceptionResponse) {
+				assert.Equal(t, "2024-05-01 10:00:00", *resp.CancelledAt)
+			},
+		},
+		{
+			action:    "reopen",
+			reception: model.Reception{Id: receptionID, Status: model.ReceptionStatusInProgress, ReopenedAt: &at, ReopenedBy: &actorID},
+			expect: func(m *mocks.MockReception) *gomock.Call {
+				return m.EXPECT().ReopenReception(gomock.Any(), receptionID)
+			},
+			handler: func(h *handler.Handler) gin.HandlerFunc { return h.ReopenReception },
+			check: func(t *testing.T, resp response.ReceptionResponse) {
+				assert.Equal(t, actorID.String(), *resp.ReopenedBy)
+				assert.Nil(t, resp.ClosedAt)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockReceptionService := mocks.NewMockReception(ctrl)
+			mockLogger := new(mocks.MockLogger)
+			h := handler.NewHandler(&service.Service{Reception: mockReceptionService}, mockLogger)
+
+			tt.expect(mockReceptionService).Return(tt.reception, nil)
+			mockLogger.On("Infow", "Reception status changed", "ReceptionId", receptionID, "status", tt.reception.Status).Once()
+
+			w, ctx := newTransitionContext(receptionID.String(), tt.action)
+			serve(h, ctx, tt.handler(h))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var resp response.ReceptionResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.reception.Status, resp.Status)
+			tt.check(t, resp)
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ReceptionTransition_Conflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockReceptionService := mocks.NewMockReception(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Reception: mockReceptionService}, mockLogger)
+
+	receptionID := uuid.New()
+	expectedErr := apperror.Conflict("cannot verify reception %s in status in_progress", receptionID)
+	mockReceptionService.EXPECT().VerifyReception(gomock.Any(), receptionID).Return(model.Reception{}, expectedErr)
+	mockLogger.On("Errorw", "Failed to change reception status", "ReceptionId", receptionID, "action", "verify", "error", expectedErr).Once()
+
+	w, ctx := newTransitionContext(receptionID.String(), "verify")
+	serve(h, ctx, h.VerifyReception)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, expectedErr.Error(), resp["message"])
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_ReceptionTransition_InvalidReceptionId(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockReceptionService := mocks.NewMockReception(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{Reception: mockReceptionService}, mockLogger)
+
+	mockLogger.On("Warnw", "Invalid ReceptionId format", "ReceptionId", "invalid-uuid", "error", mock.Anything).Once()
+
+	w, ctx := newTransitionContext("invalid-uuid", "cancel")
+	serve(h, ctx, h.CancelReception)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 	mockLogger.AssertExpectations(t)
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/google/uuid"
 	"pvz/internal/api/handler"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/jwtkeys"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
@@ -40,7 +42,7 @@ func TestHandler_DummyLogin_Success(t *testing.T) {
 
 	// Mock expectations
 	mockService.EXPECT().
-		DummyLogin(gomock.Any(), role).
+		DummyLogin(gomock.Any(), role, nil).
 		Return(token, nil)
 
 	mockLogger.On("Infow", "Dummy login successful", "role", role).Once()
@@ -51,7 +53,7 @@ func TestHandler_DummyLogin_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/dummy-login", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.DummyLogin(ctx)
+	serve(h, ctx, h.DummyLogin)
 
 	// Verify
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -64,6 +66,41 @@ func TestHandler_DummyLogin_Success(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestHandler_DummyLogin_PvzScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockUser(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{User: mockService}, mockLogger)
+
+	pvzId := uuid.New()
+	mockService.EXPECT().DummyLogin(gomock.Any(), "employee", []uuid.UUID{pvzId}).Return("scoped-token", nil)
+	mockLogger.On("Infow", "Dummy login successful", "role", "employee").Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/dummyLogin",
+		bytes.NewBufferString(`{"role":"employee","pvzIds":["`+pvzId.String()+`"]}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.DummyLogin)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "scoped-token")
+
+	// Некорректный id ПВЗ в области токена
+	mockLogger.On("Warnw", "Invalid pvzIds for dummy login", "pvzId", "bad", "error", mock.Anything).Once()
+
+	w = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/dummyLogin", bytes.NewBufferString(`{"role":"employee","pvzIds":["bad"]}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.DummyLogin)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
 func TestHandler_DummyLogin_InvalidInput(t *testing.T) {
 	// Setup
 	ctrl := gomock.NewController(t)
@@ -86,7 +123,7 @@ func TestHandler_DummyLogin_InvalidInput(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/dummy-login", bytes.NewBufferString(invalidJSON))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.DummyLogin(ctx)
+	serve(h, ctx, h.DummyLogin)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -94,8 +131,8 @@ func TestHandler_DummyLogin_InvalidInput(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["message"], "Invalid input data")
-	assert.Contains(t, resp, "error")
+	assert.Equal(t, "invalid request body", resp["message"])
+	assert.NotContains(t, resp, "error")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -118,7 +155,7 @@ func TestHandler_DummyLogin_ServiceError(t *testing.T) {
 
 	// Mock expectations
 	mockService.EXPECT().
-		DummyLogin(gomock.Any(), role).
+		DummyLogin(gomock.Any(), role, nil).
 		Return("", expectedErr)
 
 	mockLogger.On("Warnw", "Dummy login failed", "error", expectedErr).Once()
@@ -129,15 +166,15 @@ func TestHandler_DummyLogin_ServiceError(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/dummy-login", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.DummyLogin(ctx)
+	serve(h, ctx, h.DummyLogin)
 
 	// Verify
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Failed to generate token", resp["message"])
+	assert.Equal(t, "internal server error", resp["message"])
 
 	mockLogger.AssertExpectations(t)
 }
@@ -158,12 +195,12 @@ func TestHandler_Login_Success(t *testing.T) {
 		Password: "password123",
 	}
 	jsonBody, _ := json.Marshal(reqBody)
-	token := "test-token"
+	pair := model.TokenPair{AccessToken: "test-token", RefreshToken: "test-refresh-token"}
 
 	// Mock expectations
 	mockUserService.EXPECT().
 		LoginUser(gomock.Any(), reqBody.Email, reqBody.Password).
-		Return(token, nil)
+		Return(pair, nil)
 
 	mockLogger.On("Infow", "Login successful", "email", reqBody.Email).Once()
 
@@ -173,7 +210,7 @@ func TestHandler_Login_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.Login(ctx)
+	serve(h, ctx, h.Login)
 
 	// Verify
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -181,7 +218,8 @@ func TestHandler_Login_Success(t *testing.T) {
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, token, resp["token"])
+	assert.Equal(t, pair.AccessToken, resp["token"])
+	assert.Equal(t, pair.RefreshToken, resp["refresh_token"])
 
 	mockLogger.AssertExpectations(t)
 }
@@ -208,7 +246,7 @@ func TestHandler_Login_InvalidInput(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(invalidJSON))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.Login(ctx)
+	serve(h, ctx, h.Login)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -216,8 +254,8 @@ func TestHandler_Login_InvalidInput(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["message"], "Invalid input data")
-	assert.Contains(t, resp, "error")
+	assert.Equal(t, "invalid request body", resp["message"])
+	assert.NotContains(t, resp, "error")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -238,12 +276,12 @@ func TestHandler_Login_InvalidCredentials(t *testing.T) {
 		Password: "wrongpassword",
 	}
 	jsonBody, _ := json.Marshal(reqBody)
-	expectedErr := errors.New("invalid credentials")
+	expectedErr := apperror.Unauthorized("invalid credentials")
 
 	// Mock expectations
 	mockUserService.EXPECT().
 		LoginUser(gomock.Any(), reqBody.Email, reqBody.Password).
-		Return("", expectedErr)
+		Return(model.TokenPair{}, expectedErr)
 
 	mockLogger.On("Warnw", "Login failed", "email", reqBody.Email, "error", expectedErr).Once()
 
@@ -253,7 +291,7 @@ func TestHandler_Login_InvalidCredentials(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.Login(ctx)
+	serve(h, ctx, h.Login)
 
 	// Verify
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
@@ -261,7 +299,7 @@ func TestHandler_Login_InvalidCredentials(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid email or password", resp["message"])
+	assert.Equal(t, "invalid credentials", resp["message"])
 
 	mockLogger.AssertExpectations(t)
 }
@@ -310,7 +348,7 @@ func TestHandler_Register_Success(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.Register(ctx)
+	serve(h, ctx, h.Register)
 
 	// Verify
 	assert.Equal(t, http.StatusCreated, w.Code)
@@ -351,7 +389,7 @@ func TestHandler_Register_InvalidInput(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(invalidJSON))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.Register(ctx)
+	serve(h, ctx, h.Register)
 
 	// Verify
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -359,8 +397,8 @@ func TestHandler_Register_InvalidInput(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Contains(t, resp["message"], "Invalid input data")
-	assert.Contains(t, resp, "error")
+	assert.Equal(t, "invalid request body", resp["message"])
+	assert.NotContains(t, resp, "error")
 
 	mockLogger.AssertExpectations(t)
 }
@@ -396,7 +434,7 @@ func TestHandler_Register_ServiceError(t *testing.T) {
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonBody))
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	h.Register(ctx)
+	serve(h, ctx, h.Register)
 
 	// Verify
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -404,8 +442,206 @@ func TestHandler_Register_ServiceError(t *testing.T) {
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Failed to create user", resp["message"])
-	assert.Contains(t, resp["error"], expectedErr.Error())
+	assert.Equal(t, "internal server error", resp["message"])
+	assert.NotContains(t, resp, "error")
+
+	mockLogger.AssertExpectations(t)
+}
+
+func TestHandler_Refresh_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUser(ctrl)
+	mockLogger := new(mocks.MockLogger)
+
+	services := &service.Service{User: mockUserService}
+	h := handler.NewHandler(services, mockLogger)
+
+	jsonBody, _ := json.Marshal(response.RefreshPostRequest{RefreshToken: "old-refresh"})
+	pair := model.TokenPair{AccessToken: "new-token", RefreshToken: "new-refresh"}
+
+	mockUserService.EXPECT().
+		RefreshTokens(gomock.Any(), "old-refresh").
+		Return(pair, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewBuffer(jsonBody))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.Refresh)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.TokenPairResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "new-token", resp.Token)
+	assert.Equal(t, "new-refresh", resp.RefreshToken)
+}
+
+func TestHandler_Refresh_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+		wantMsg    string
+	}{
+		{
+			name:       "missing refresh token",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantMsg:    "invalid request body",
+		},
+		{
+			name:       "revoked refresh token",
+			body:       `{"refresh_token": "stolen"}`,
+			serviceErr: apperror.Unauthorized("invalid refresh token"),
+			wantStatus: http.StatusUnauthorized,
+			wantMsg:    "invalid refresh token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserService := mocks.NewMockUser(ctrl)
+			mockLogger := new(mocks.MockLogger)
+
+			services := &service.Service{User: mockUserService}
+			h := handler.NewHandler(services, mockLogger)
+
+			if tt.serviceErr != nil {
+				mockUserService.EXPECT().
+					RefreshTokens(gomock.Any(), gomock.Any()).
+					Return(model.TokenPair{}, tt.serviceErr)
+				mockLogger.On("Warnw", "Token refresh failed", "error", tt.serviceErr).Once()
+			} else {
+				mockLogger.On("Warnw", "Invalid input data for token refresh", "error", mock.Anything).Once()
+			}
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewBufferString(tt.body))
+			ctx.Request.Header.Set("Content-Type", "application/json")
+
+			serve(h, ctx, h.Refresh)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var resp map[string]string
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMsg, resp["message"])
+
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_Logout_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUser(ctrl)
+	mockLogger := new(mocks.MockLogger)
+
+	services := &service.Service{User: mockUserService}
+	h := handler.NewHandler(services, mockLogger)
+
+	claims := &model.TokenClaims{UserId: uuid.New(), Role: "employee"}
+	jsonBody, _ := json.Marshal(response.LogoutPostRequest{RefreshToken: "refresh"})
+
+	mockUserService.EXPECT().
+		Logout(gomock.Any(), claims, "refresh").
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/logout", bytes.NewBuffer(jsonBody))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Set("userClaims", claims)
+
+	serve(h, ctx, h.Logout)
+
+	// Заголовок без тела gin отправляет после цепочки хендлеров, поэтому проверяем статус writer'а
+	assert.Equal(t, http.StatusNoContent, ctx.Writer.Status())
+	assert.Empty(t, w.Body.String())
+}
+
+func TestHandler_Logout_WithoutBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUser(ctrl)
+	mockLogger := new(mocks.MockLogger)
+
+	services := &service.Service{User: mockUserService}
+	h := handler.NewHandler(services, mockLogger)
+
+	claims := &model.TokenClaims{UserId: uuid.New(), Role: "moderator"}
+
+	mockUserService.EXPECT().
+		Logout(gomock.Any(), claims, "").
+		Return(nil)
 
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/logout", nil)
+	ctx.Set("userClaims", claims)
+
+	serve(h, ctx, h.Logout)
+
+	assert.Equal(t, http.StatusNoContent, ctx.Writer.Status())
+}
+
+func TestHandler_JWKS(t *testing.T) {
+	h := handler.NewHandler(&service.Service{}, new(mocks.MockLogger))
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+	serve(h, ctx, h.JWKS(jwtkeys.NewHMAC([]byte("test-signing-key"))))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+}
+
+func TestHandler_CreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUser(ctrl)
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{User: mockUserService}, mockLogger)
+
+	reqBody := response.RegisterPostRequest{Email: "auditor@example.com", Password: "password123", Role: model.RoleAuditor}
+	jsonBody, _ := json.Marshal(reqBody)
+	created := model.User{Id: uuid.New(), Email: reqBody.Email, Role: reqBody.Role}
+
+	mockUserService.EXPECT().
+		CreateUserWithRole(gomock.Any(), model.User{Email: reqBody.Email, Password: reqBody.Password, Role: reqBody.Role}).
+		Return(created, nil)
+	mockLogger.On("Infow", "User created", "userID", created.Id, "role", model.RoleAuditor).Once()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(jsonBody))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	serve(h, ctx, h.CreateUser)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp response.RegisterResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, created.Id.String(), resp.Id)
+	assert.Equal(t, model.RoleAuditor, resp.Role)
 	mockLogger.AssertExpectations(t)
 }
@@ -0,0 +1,160 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/api/handler"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func newWebhookContext(method, target, body string, params gin.Params) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = params
+	return w, ctx
+}
+
+func TestHandler_CreateWebhookSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhook := mocks.NewMockWebhook(ctrl)
+	h := handler.NewHandler(&service.Service{Webhook: mockWebhook}, new(mocks.MockLogger))
+
+	pvzId := uuid.New()
+	created := model.WebhookSubscription{
+		Id:         uuid.New(),
+		Url:        "https://partner.example/hook",
+		Secret:     "generated-secret",
+		EventTypes: []string{model.EventReceptionClosed},
+		PvzId:      &pvzId,
+		Active:     true,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	mockWebhook.EXPECT().CreateWebhookSubscription(gomock.Any(), model.WebhookSubscription{
+		Url:        "https://partner.example/hook",
+		EventTypes: []string{model.EventReceptionClosed},
+		PvzId:      &pvzId,
+		Active:     true,
+	}).Return(created, nil)
+
+	body := `{"url":"https://partner.example/hook","eventTypes":["ReceptionClosed"],"pvzId":"` + pvzId.String() + `"}`
+	w, ctx := newWebhookContext(http.MethodPost, "/webhooks", body, nil)
+	serve(h, ctx, h.CreateWebhookSubscription)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	// Секрет виден только в ответе на создание
+	assert.Equal(t, "generated-secret", resp["secret"])
+	assert.Equal(t, pvzId.String(), resp["pvzId"])
+	assert.Equal(t, []interface{}{model.EventReceptionClosed}, resp["eventTypes"])
+}
+
+func TestHandler_GetWebhookSubscription_HidesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhook := mocks.NewMockWebhook(ctrl)
+	h := handler.NewHandler(&service.Service{Webhook: mockWebhook}, new(mocks.MockLogger))
+
+	id := uuid.New()
+	mockWebhook.EXPECT().GetWebhookSubscription(gomock.Any(), id).
+		Return(model.WebhookSubscription{Id: id, Url: "https://partner.example/hook", Secret: "secret"}, nil)
+
+	w, ctx := newWebhookContext(http.MethodGet, "/webhooks/"+id.String(), "", gin.Params{{Key: "webhookId", Value: id.String()}})
+	serve(h, ctx, h.GetWebhookSubscription)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+	assert.Contains(t, w.Body.String(), `"eventTypes":[]`)
+	assert.Contains(t, w.Body.String(), `"pvzId":null`)
+}
+
+func TestHandler_UpdateWebhookSubscription_ClearPvzId(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhook := mocks.NewMockWebhook(ctrl)
+	h := handler.NewHandler(&service.Service{Webhook: mockWebhook}, new(mocks.MockLogger))
+
+	id := uuid.New()
+	active := false
+	mockWebhook.EXPECT().UpdateWebhookSubscription(gomock.Any(), id, model.WebhookSubscriptionUpdate{
+		ClearPvzId: true,
+		Active:     &active,
+	}).Return(model.WebhookSubscription{Id: id}, nil)
+
+	w, ctx := newWebhookContext(http.MethodPatch, "/webhooks/"+id.String(), `{"pvzId":"","active":false}`,
+		gin.Params{{Key: "webhookId", Value: id.String()}})
+	serve(h, ctx, h.UpdateWebhookSubscription)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandler_CreateWebhookSubscription_InvalidPvzId(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+	mockLogger.On("Warnw", "Invalid PvzId format", "PvzId", "bad", "error", mock.Anything)
+
+	w, ctx := newWebhookContext(http.MethodPost, "/webhooks", `{"url":"https://partner.example/hook","pvzId":"bad"}`, nil)
+	serve(h, ctx, h.CreateWebhookSubscription)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid pvzId format")
+}
+
+func TestHandler_GetWebhookDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWebhook := mocks.NewMockWebhook(ctrl)
+	h := handler.NewHandler(&service.Service{Webhook: mockWebhook}, new(mocks.MockLogger))
+
+	id := uuid.New()
+	statusCode := 500
+	lastError := "webhook receiver responded with status 500"
+	mockWebhook.EXPECT().GetWebhookDeliveries(gomock.Any(), id, model.WebhookDeliveryDead, 10, 0).
+		Return([]model.WebhookDelivery{{
+			Id:             uuid.New(),
+			SubscriptionId: id,
+			EventType:      model.EventReceptionClosed,
+			Status:         model.WebhookDeliveryDead,
+			Attempts:       10,
+			LastStatusCode: &statusCode,
+			LastError:      &lastError,
+		}}, nil)
+
+	w, ctx := newWebhookContext(http.MethodGet, "/webhooks/"+id.String()+"/deliveries?status=dead", "",
+		gin.Params{{Key: "webhookId", Value: id.String()}})
+	serve(h, ctx, h.GetWebhookDeliveries)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, "dead", resp[0]["status"])
+	assert.Equal(t, float64(500), resp[0]["lastStatusCode"])
+	assert.Nil(t, resp[0]["nextAttemptAt"])
+}
+
+func TestHandler_GetWebhookDeliveries_InvalidStatus(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	h := handler.NewHandler(&service.Service{}, mockLogger)
+	mockLogger.On("Warnw", "Invalid status", "status", "lost")
+
+	id := uuid.NewString()
+	w, ctx := newWebhookContext(http.MethodGet, "/webhooks/"+id+"/deliveries?status=lost", "",
+		gin.Params{{Key: "webhookId", Value: id}})
+	serve(h, ctx, h.GetWebhookDeliveries)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
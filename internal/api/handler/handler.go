@@ -6,6 +6,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"pvz/internal/logger"
 	"pvz/internal/middleware/jwt"
+	"pvz/internal/middleware/requestid"
+	"pvz/internal/rbac"
+	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/metrics"
 )
@@ -22,18 +25,63 @@ func NewHandler(services *service.Service, log logger.Logger) *Handler {
 	}
 }
 
-func (h *Handler) InitRoutes() *gin.Engine {
+func (h *Handler) InitRoutes(auth *jwt.Auth) *gin.Engine {
 	router := gin.New()
+	// Сервисы получают *gin.Context как context.Context; с fallback он отдаёт
+	// значения контекста запроса (claims, X-Request-Id) для журнала аудита
+	router.ContextWithFallback = true
+	router.Use(requestid.Middleware(), h.ErrorMiddleware())
 
+	router.GET("/.well-known/jwks.json", h.trackMetrics(h.JWKS(auth.Keys())))
 	router.POST("/dummyLogin", h.trackMetrics(h.DummyLogin))
 	router.POST("/register", h.trackMetrics(h.Register))
 	router.POST("/login", h.trackMetrics(h.Login))
-	router.POST("/pvz", jwt.AuthMiddleware("moderator"), h.trackMetrics(h.CreatePvz))
-	router.POST("/receptions", jwt.AuthMiddleware("employee"), h.trackMetrics(h.CreateReception))
-	router.POST("/products", jwt.AuthMiddleware("employee"), h.trackMetrics(h.AddProduct))
-	router.DELETE("/pvz/:pvzId/delete_last_product", jwt.AuthMiddleware("employee"), h.trackMetrics(h.DeleteLastProduct))
-	router.PATCH("/pvz/:pvzId/close_last_reception", jwt.AuthMiddleware("employee"), h.trackMetrics(h.CloseReception))
-	router.GET("/pvz", jwt.AuthMiddleware("moderator", "employee"), h.trackMetrics(h.GetPvz))
+	router.POST("/refresh", h.trackMetrics(h.Refresh))
+	// Мутирующие маршруты с авторизацией принимают заголовок Idempotency-Key.
+	// Вход и регистрация его не поддерживают: ключ привязан к пользователю,
+	// а ответы с токенами не должны храниться в БД.
+	router.POST("/logout", auth.Authorize(rbac.SessionLogout), h.idempotent(), h.trackMetrics(h.Logout))
+	router.POST("/pvz", auth.Authorize(rbac.PvzCreate), h.idempotent(), h.trackMetrics(h.CreatePvz))
+	router.POST("/receptions", auth.Authorize(rbac.ReceptionCreate), h.idempotent(), h.trackMetrics(h.CreateReception))
+	router.GET("/receptions/:receptionId", auth.Authorize(rbac.ReceptionRead), h.trackMetrics(h.GetReception))
+	router.POST("/receptions/:receptionId/close", auth.Authorize(rbac.ReceptionClose), h.idempotent(), h.trackMetrics(h.CloseReceptionById))
+	router.POST("/receptions/:receptionId/verify", auth.Authorize(rbac.ReceptionVerify), h.idempotent(), h.trackMetrics(h.VerifyReception))
+	router.POST("/receptions/:receptionId/cancel", auth.Authorize(rbac.ReceptionCancel), h.idempotent(), h.trackMetrics(h.CancelReception))
+	router.POST("/receptions/:receptionId/reopen", auth.Authorize(rbac.ReceptionReopen), h.idempotent(), h.trackMetrics(h.ReopenReception))
+	router.POST("/products", auth.Authorize(rbac.ProductCreate), h.idempotent(), h.trackMetrics(h.AddProduct))
+	router.GET("/products", auth.Authorize(rbac.ProductRead), h.trackMetrics(h.FindProducts))
+	router.POST("/products/batch", auth.Authorize(rbac.ProductCreate), h.idempotent(), h.trackMetrics(h.AddProducts))
+	router.PATCH("/products/:productId", auth.Authorize(rbac.ProductUpdate), h.idempotent(), h.trackMetrics(h.UpdateProduct))
+	router.DELETE("/products/:productId", auth.Authorize(rbac.ProductDelete), h.idempotent(), h.trackMetrics(h.DeleteProduct))
+	router.DELETE("/pvz/:pvzId/delete_last_product", auth.Authorize(rbac.ProductDelete), h.idempotent(), h.trackMetrics(h.DeleteLastProduct))
+	router.PATCH("/pvz/:pvzId/close_last_reception", auth.Authorize(rbac.ReceptionClose), h.idempotent(), h.trackMetrics(h.CloseReception))
+	router.GET("/pvz", auth.Authorize(rbac.PvzRead), h.trackMetrics(h.GetPvz))
+	router.GET("/pvz/:pvzId", auth.Authorize(rbac.PvzRead), h.trackMetrics(h.GetPvzById))
+	router.PATCH("/pvz/:pvzId", auth.Authorize(rbac.PvzUpdate), h.idempotent(), h.trackMetrics(h.UpdatePvz))
+	router.POST("/pvz/:pvzId/deactivate", auth.Authorize(rbac.PvzUpdate), h.idempotent(), h.trackMetrics(h.DeactivatePvz))
+	router.POST("/pvz/:pvzId/activate", auth.Authorize(rbac.PvzUpdate), h.idempotent(), h.trackMetrics(h.ActivatePvz))
+	router.DELETE("/pvz/:pvzId", auth.Authorize(rbac.PvzDelete), h.idempotent(), h.trackMetrics(h.DeletePvz))
+	router.GET("/pvz/:pvzId/receptions", auth.Authorize(rbac.ReceptionRead), h.trackMetrics(h.GetReceptionList))
+	router.GET("/pvz/:pvzId/receptions/current", auth.Authorize(rbac.ReceptionRead), h.trackMetrics(h.GetCurrentReception))
+	// Поток событий без trackMetrics: его длительность исказила бы гистограмму времени ответа
+	router.GET("/pvz/:pvzId/events", auth.Authorize(rbac.ReceptionRead), h.StreamPvzEvents)
+	router.GET("/catalog/cities", auth.Authorize(rbac.CatalogRead), h.trackMetrics(h.ListCatalog(model.CatalogCity)))
+	router.POST("/catalog/cities", auth.Authorize(rbac.CatalogManage), h.idempotent(), h.trackMetrics(h.AddCatalogItem(model.CatalogCity)))
+	router.PATCH("/catalog/cities/:name", auth.Authorize(rbac.CatalogManage), h.idempotent(), h.trackMetrics(h.UpdateCatalogItem(model.CatalogCity)))
+	router.GET("/catalog/product-types", auth.Authorize(rbac.CatalogRead), h.trackMetrics(h.ListCatalog(model.CatalogProductType)))
+	router.POST("/catalog/product-types", auth.Authorize(rbac.CatalogManage), h.idempotent(), h.trackMetrics(h.AddCatalogItem(model.CatalogProductType)))
+	router.PATCH("/catalog/product-types/:name", auth.Authorize(rbac.CatalogManage), h.idempotent(), h.trackMetrics(h.UpdateCatalogItem(model.CatalogProductType)))
+	router.GET("/audit", auth.Authorize(rbac.AuditRead), h.trackMetrics(h.GetAuditLog))
+	router.POST("/users", auth.Authorize(rbac.UserCreate), h.idempotent(), h.trackMetrics(h.CreateUser))
+	router.GET("/users/:userId/pvz", auth.Authorize(rbac.AssignmentRead), h.trackMetrics(h.GetAssignedPvz))
+	router.PUT("/users/:userId/pvz/:pvzId", auth.Authorize(rbac.AssignmentManage), h.idempotent(), h.trackMetrics(h.AssignPvz))
+	router.DELETE("/users/:userId/pvz/:pvzId", auth.Authorize(rbac.AssignmentManage), h.idempotent(), h.trackMetrics(h.UnassignPvz))
+	router.POST("/webhooks", auth.Authorize(rbac.WebhookManage), h.idempotent(), h.trackMetrics(h.CreateWebhookSubscription))
+	router.GET("/webhooks", auth.Authorize(rbac.WebhookRead), h.trackMetrics(h.GetWebhookSubscriptions))
+	router.GET("/webhooks/:webhookId", auth.Authorize(rbac.WebhookRead), h.trackMetrics(h.GetWebhookSubscription))
+	router.PATCH("/webhooks/:webhookId", auth.Authorize(rbac.WebhookManage), h.idempotent(), h.trackMetrics(h.UpdateWebhookSubscription))
+	router.DELETE("/webhooks/:webhookId", auth.Authorize(rbac.WebhookManage), h.idempotent(), h.trackMetrics(h.DeleteWebhookSubscription))
+	router.GET("/webhooks/:webhookId/deliveries", auth.Authorize(rbac.WebhookRead), h.trackMetrics(h.GetWebhookDeliveries))
 
 	return router
 }
@@ -54,3 +102,8 @@ func (h *Handler) trackMetrics(handlerFunc gin.HandlerFunc) gin.HandlerFunc {
 		metrics.ResponseDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(duration)
 	}
 }
+
+// userClaims возвращает claims токена, сохранённые Authorize
+func userClaims(c *gin.Context) *model.TokenClaims {
+	return c.MustGet("userClaims").(*model.TokenClaims)
+}
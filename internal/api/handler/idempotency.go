@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotent повторяет сохранённый ответ на запрос с уже использованным
+// заголовком Idempotency-Key. Ключи принадлежат владельцу токена (см.
+// idempotencyOwner), поэтому middleware ставится после Authorize. Запросы
+// без заголовка выполняются как обычно.
+func (h *Handler) idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Error(apperror.Validation("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		owner, ok := idempotencyOwner(userClaims(c))
+		if !ok {
+			c.Error(apperror.Validation("%s is not supported for this token", idempotencyKeyHeader))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			h.logger.Errorw("Failed to read request body", "error", err)
+			c.Error(apperror.Validation("invalid request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := h.service.AcquireIdempotencyKey(c, owner, key, requestHash(c.Request, body))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			c.Header(idempotentReplayHeader, "true")
+			c.Data(stored.StatusCode, gin.MIMEJSON, stored.Body)
+			c.Abort()
+			return
+		}
+
+		// Ответ уже отправлен, поэтому отмена запроса не должна помешать сохранить
+		// его или освободить ключ
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Если хендлер запаникует, ключ освобождается, иначе повторы с ним
+		// получали бы "in progress" до истечения ttl. Паника идёт дальше.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := h.service.ReleaseIdempotencyKey(ctx, owner, key); err != nil {
+				h.logger.Errorw("Failed to release idempotency key", "owner", owner, "key", key, "error", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		completed = true
+
+		// Ошибку отрисовываем здесь, а не в ErrorMiddleware, чтобы сохранить ответ
+		if !c.Writer.Written() {
+			h.writeError(c)
+		}
+
+		if err := h.service.SaveIdempotentResponse(ctx, owner, key, c.Writer.Status(), recorder.body.Bytes()); err != nil {
+			h.logger.Errorw("Failed to save idempotent response", "owner", owner, "key", key, "error", err)
+		}
+	}
+}
+
+// idempotencyOwner возвращает владельца ключей идемпотентности: пользователя,
+// а для токенов /dummyLogin без пользователя - сам токен (jti). Иначе все
+// клиенты /dummyLogin делили бы одно пространство ключей.
+func idempotencyOwner(claims *model.TokenClaims) (uuid.UUID, bool) {
+	if claims.UserId != uuid.Nil {
+		return claims.UserId, true
+	}
+	jti, err := uuid.Parse(claims.Id)
+	if err != nil || jti == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return jti, true
+}
+
+// requestHash отличает повтор запроса от другого запроса с тем же ключом
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder копирует тело ответа для сохранения
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
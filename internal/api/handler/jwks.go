@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pvz/internal/jwtkeys"
+)
+
+// JWKS публикует открытые ключи, чтобы другие сервисы могли проверять наши токены
+func (h *Handler) JWKS(keys *jwtkeys.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKS())
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Служебные события потока, у них нет Id
+const (
+	liveEventPing   = "ping"
+	liveEventResync = "resync"
+)
+
+// StreamPvzEvents отдаёт события ПВЗ как Server-Sent Events: открытие и
+// закрытие приёмок, добавление и удаление товаров. После обрыва клиент
+// продолжает поток с заголовком Last-Event-ID; событие resync означает, что
+// продолжить нельзя и состояние ПВЗ нужно загрузить заново.
+func (h *Handler) StreamPvzEvents(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.service.SubscribePvzEvents(c.Request.Context(), pvzId, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		h.logger.Errorw("Failed to subscribe to PVZ events", "pvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	// Поток живёт дольше http.write_timeout. Ошибку игнорируем: без
+	// поддержки дедлайнов (например, в тестах) он и не ограничен.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	// Не даём nginx буферизовать поток
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+
+	if sub.Resync {
+		c.Render(-1, sse.Event{Event: liveEventResync, Data: ""})
+	}
+	for _, msg := range sub.Replay {
+		c.Render(-1, sse.Event{Id: msg.Id, Event: msg.Event.Type, Data: msg.Event})
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sub.Heartbeat)
+	defer heartbeat.Stop()
+
+	for !c.IsAborted() {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case msg, ok := <-sub.Messages():
+			// Хаб отключил клиента: тот отстал или сервер останавливается
+			if !ok {
+				return
+			}
+			c.Render(-1, sse.Event{Id: msg.Id, Event: msg.Event.Type, Data: msg.Event})
+		case <-heartbeat.C:
+			c.Render(-1, sse.Event{Event: liveEventPing, Data: ""})
+		}
+		c.Writer.Flush()
+	}
+}
@@ -1,13 +1,14 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"pvz/internal/api/mapper"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
 )
 
 func (h *Handler) AddProduct(c *gin.Context) {
@@ -15,23 +16,23 @@ func (h *Handler) AddProduct(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Errorw("Failed to bind product request", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		c.Error(apperror.Validation("invalid request body"))
 		return
 	}
 
 	pvzId, err := uuid.Parse(req.PvzId)
 	if err != nil {
 		h.logger.Errorw("Invalid PvzId format", "PvzId", req.PvzId, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid PvzId format"})
+		c.Error(apperror.Validation("invalid pvzId format"))
 		return
 	}
 
 	product := mapper.ToProduct(req)
 
-	createdProduct, err := h.service.AddProduct(c, pvzId, product.Type)
+	createdProduct, err := h.service.AddProduct(c, pvzId, product)
 	if err != nil {
 		h.logger.Errorw("Failed to add product", "error", err, "PvzId", pvzId, "type", product.Type)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product"})
+		c.Error(err)
 		return
 	}
 
@@ -40,21 +41,63 @@ func (h *Handler) AddProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, productResponse)
 }
 
+func (h *Handler) AddProducts(c *gin.Context) {
+	var req response.ProductBatchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Errorw("Failed to bind product batch request", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	pvzId, err := uuid.Parse(req.PvzId)
+	if err != nil {
+		h.logger.Errorw("Invalid PvzId format", "PvzId", req.PvzId, "error", err)
+		c.Error(apperror.Validation("invalid pvzId format"))
+		return
+	}
+
+	products := make([]model.Product, len(req.Products))
+	var invalid []apperror.ItemError
+	for i, item := range req.Products {
+		products[i] = mapper.ToBatchProduct(item)
+		if item.Id == "" {
+			continue
+		}
+		if products[i].Id, err = uuid.Parse(item.Id); err != nil {
+			invalid = append(invalid, apperror.ItemError{Index: i, Message: "invalid id format"})
+		}
+	}
+	if len(invalid) > 0 {
+		c.Error(apperror.WithItems(apperror.ErrValidation, invalid, "product batch contains invalid items"))
+		return
+	}
+
+	created, err := h.service.AddProducts(c, pvzId, products)
+	if err != nil {
+		h.logger.Errorw("Failed to add product batch", "error", err, "PvzId", pvzId, "count", len(products))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapper.ToProductBatchResponse(created))
+}
+
 func (h *Handler) DeleteLastProduct(c *gin.Context) {
 	pvzIdParam := c.Param("pvzId")
 	pvzId, err := uuid.Parse(pvzIdParam)
 	if err != nil {
 		h.logger.Errorw("Invalid PvzId format", "PvzId", pvzIdParam, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid PvzId format"})
+		c.Error(apperror.Validation("invalid pvzId format"))
 		return
 	}
 
 	h.logger.Infow("Attempting to delete last product", "PvzId", pvzId)
 
-	err = h.service.DeleteLastProduct(c, pvzId)
+	err = h.service.DeleteLastProduct(c, pvzId, userClaims(c).UserId)
 	if err != nil {
 		h.logger.Errorw("Failed to delete last product", "PvzId", pvzId, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete last product: %v", err)})
+		c.Error(err)
 		return
 	}
 
@@ -62,3 +105,67 @@ func (h *Handler) DeleteLastProduct(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Last product deleted successfully"})
 }
+
+func (h *Handler) UpdateProduct(c *gin.Context) {
+	productId, ok := h.productIdParam(c)
+	if !ok {
+		return
+	}
+
+	var req response.ProductUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnw("Invalid ProductUpdateRequest", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	product, err := h.service.UpdateProduct(c.Request.Context(), productId, mapper.ToProductUpdate(req), userClaims(c).UserId)
+	if err != nil {
+		h.logger.Errorw("Failed to update product", "ProductId", productId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToProductResponse(product))
+}
+
+func (h *Handler) DeleteProduct(c *gin.Context) {
+	productId, ok := h.productIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteProduct(c.Request.Context(), productId, userClaims(c).UserId); err != nil {
+		h.logger.Errorw("Failed to delete product", "ProductId", productId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	h.logger.Infow("Product deleted", "ProductId", productId)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) FindProducts(c *gin.Context) {
+	barcode := c.Query("barcode")
+
+	products, err := h.service.FindProductsByBarcode(c, barcode)
+	if err != nil {
+		h.logger.Errorw("Failed to find products", "barcode", barcode, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToProductLookupResponse(products))
+}
+
+// productIdParam разбирает productId из пути; при ошибке записывает её в контекст
+func (h *Handler) productIdParam(c *gin.Context) (uuid.UUID, bool) {
+	productIdParam := c.Param("productId")
+	productId, err := uuid.Parse(productIdParam)
+	if err != nil {
+		h.logger.Warnw("Invalid ProductId format", "ProductId", productIdParam, "error", err)
+		c.Error(apperror.Validation("invalid productId format"))
+		return uuid.Nil, false
+	}
+	return productId, true
+}
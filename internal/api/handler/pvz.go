@@ -3,13 +3,17 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"pvz/internal/api/mapper"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
 	"pvz/internal/logger"
+	"pvz/internal/repository/model"
 )
 
 func (h *Handler) CreatePvz(c *gin.Context) {
@@ -17,7 +21,7 @@ func (h *Handler) CreatePvz(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warnw("Invalid PvzRequest", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат данных"})
+		c.Error(apperror.Validation("invalid request body"))
 		return
 	}
 
@@ -28,7 +32,7 @@ func (h *Handler) CreatePvz(c *gin.Context) {
 	createdPvz, err := h.service.CreatePvz(c.Request.Context(), pvz)
 	if err != nil {
 		h.logger.Errorw("Failed to create PVZ", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -43,50 +47,87 @@ func (h *Handler) GetPvz(c *gin.Context) {
 	offsetStr := c.DefaultQuery("offset", "0")
 	startDateStr := c.Query("startDate")
 	endDateStr := c.Query("endDate")
+	status := c.Query("status")
 
 	h.logger.Infow("Received request for Pvz list",
-		"limit", limitStr, "offset", offsetStr, "startDate", startDateStr, "endDate", endDateStr)
+		"limit", limitStr, "offset", offsetStr, "startDate", startDateStr, "endDate", endDateStr, "status", status)
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		h.logger.Warnw("Invalid limit", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+	limit, offset, ok := h.paginationQuery(c, limitStr, offsetStr)
+	if !ok {
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		h.logger.Warnw("Invalid offset", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+	// Наличие параметра cursor (даже пустого) включает выборку по курсору.
+	// Без него список отдаётся по-старому: массивом со сдвигом offset.
+	cursor, cursorMode := c.GetQuery("cursor")
+	if _, hasOffset := c.GetQuery("offset"); cursorMode && hasOffset {
+		h.logger.Warnw("Both cursor and offset passed")
+		c.Error(apperror.Validation("cursor and offset cannot be used together"))
 		return
 	}
 
-	var startDate, endDate *time.Time
+	if status != "" && status != model.PvzStatusActive && status != model.PvzStatusInactive {
+		h.logger.Warnw("Invalid status", "status", status)
+		c.Error(apperror.Validation("invalid status"))
+		return
+	}
+
+	receptionStatus := c.Query("receptionStatus")
+	if receptionStatus != "" && !slices.Contains(model.ReceptionStatuses, receptionStatus) {
+		h.logger.Warnw("Invalid receptionStatus", "receptionStatus", receptionStatus)
+		c.Error(apperror.Validation("invalid receptionStatus"))
+		return
+	}
 
-	if startDateStr != "" {
-		t, err := ParseFlexibleTime(startDateStr)
+	includeEmpty := false
+	if value := c.Query("includeEmpty"); value != "" {
+		var err error
+		includeEmpty, err = strconv.ParseBool(value)
 		if err != nil {
-			h.logger.Warnw("Invalid startDate", "startDate", startDateStr, "error", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate"})
+			h.logger.Warnw("Invalid includeEmpty", "includeEmpty", value, "error", err)
+			c.Error(apperror.Validation("invalid includeEmpty"))
 			return
 		}
-		startDate = t
 	}
 
-	if endDateStr != "" {
-		t, err := ParseFlexibleTime(endDateStr)
+	filter := model.PvzFilter{
+		ReceptionStatus: receptionStatus,
+		ProductType:     c.Query("productType"),
+		IncludeEmpty:    includeEmpty,
+		Status:          status,
+		City:            c.Query("city"),
+	}
+
+	if filter.StartDate, ok = h.timeQuery(c, "startDate"); !ok {
+		return
+	}
+	if filter.EndDate, ok = h.timeQuery(c, "endDate"); !ok {
+		return
+	}
+	if filter.RegisteredFrom, ok = h.timeQuery(c, "registeredFrom"); !ok {
+		return
+	}
+	if filter.RegisteredTo, ok = h.timeQuery(c, "registeredTo"); !ok {
+		return
+	}
+
+	if cursorMode {
+		page, err := h.service.GetPvzPage(c.Request.Context(), limit, cursor, filter)
 		if err != nil {
-			h.logger.Warnw("Invalid endDate", "endDate", endDateStr, "error", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endDate"})
+			h.logger.Errorw("Failed to get Pvz page", "error", err)
+			c.Error(err)
 			return
 		}
-		endDate = t
+
+		h.logger.Infow("Successfully retrieved Pvz page", "count", len(page.Items))
+		c.JSON(http.StatusOK, page)
+		return
 	}
 
-	result, err := h.service.GetPvzList(c.Request.Context(), limit, offset, startDate, endDate)
+	result, err := h.service.GetPvzList(c.Request.Context(), limit, offset, filter)
 	if err != nil {
 		h.logger.Errorw("Failed to get Pvz list", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -94,6 +135,150 @@ func (h *Handler) GetPvz(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (h *Handler) GetPvzById(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	pvz, err := h.service.GetPvz(c.Request.Context(), pvzId)
+	if err != nil {
+		h.logger.Errorw("Failed to get PVZ", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToPvzResponse(pvz))
+}
+
+func (h *Handler) UpdatePvz(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	var req response.PvzUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnw("Invalid PvzUpdateRequest", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	h.logger.Infow("Updating PVZ", "PvzId", pvzId, "request", req)
+
+	pvz, err := h.service.UpdatePvz(c.Request.Context(), pvzId, mapper.ToPvzUpdate(req))
+	if err != nil {
+		h.logger.Errorw("Failed to update PVZ", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToPvzResponse(pvz))
+}
+
+func (h *Handler) DeactivatePvz(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	pvz, err := h.service.DeactivatePvz(c.Request.Context(), pvzId)
+	if err != nil {
+		h.logger.Errorw("Failed to deactivate PVZ", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	h.logger.Infow("PVZ deactivated", "PvzId", pvzId)
+	c.JSON(http.StatusOK, mapper.ToPvzResponse(pvz))
+}
+
+func (h *Handler) ActivatePvz(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	pvz, err := h.service.ActivatePvz(c.Request.Context(), pvzId)
+	if err != nil {
+		h.logger.Errorw("Failed to activate PVZ", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	h.logger.Infow("PVZ activated", "PvzId", pvzId)
+	c.JSON(http.StatusOK, mapper.ToPvzResponse(pvz))
+}
+
+func (h *Handler) DeletePvz(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePvz(c.Request.Context(), pvzId); err != nil {
+		h.logger.Errorw("Failed to delete PVZ", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	h.logger.Infow("PVZ deleted", "PvzId", pvzId)
+	c.Status(http.StatusNoContent)
+}
+
+// pvzIdParam разбирает pvzId из пути; при ошибке записывает её в контекст
+func (h *Handler) pvzIdParam(c *gin.Context) (uuid.UUID, bool) {
+	pvzIdParam := c.Param("pvzId")
+	pvzId, err := uuid.Parse(pvzIdParam)
+	if err != nil {
+		h.logger.Warnw("Invalid PvzId format", "PvzId", pvzIdParam, "error", err)
+		c.Error(apperror.Validation("invalid pvzId format"))
+		return uuid.Nil, false
+	}
+	return pvzId, true
+}
+
+// paginationQuery разбирает limit и offset: limit должен быть положительным, offset - неотрицательным
+func (h *Handler) paginationQuery(c *gin.Context, limitStr, offsetStr string) (int, int, bool) {
+	limit, err := strconv.Atoi(limitStr)
+	if err == nil && limit < 1 {
+		err = fmt.Errorf("limit must be positive, got %d", limit)
+	}
+	if err != nil {
+		h.logger.Warnw("Invalid limit", "error", err)
+		c.Error(apperror.Validation("invalid limit"))
+		return 0, 0, false
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err == nil && offset < 0 {
+		err = fmt.Errorf("offset must not be negative, got %d", offset)
+	}
+	if err != nil {
+		h.logger.Warnw("Invalid offset", "error", err)
+		c.Error(apperror.Validation("invalid offset"))
+		return 0, 0, false
+	}
+
+	return limit, offset, true
+}
+
+// timeQuery разбирает необязательный query-параметр с датой
+func (h *Handler) timeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := ParseFlexibleTime(value)
+	if err != nil {
+		h.logger.Warnw("Invalid "+name, name, value, "error", err)
+		c.Error(apperror.Validation("invalid " + name))
+		return nil, false
+	}
+	return t, true
+}
+
 func ParseFlexibleTime(str string) (*time.Time, error) {
 	formats := []string{
 		"2006-01-02 15:04:05.999999",
@@ -1,13 +1,16 @@
 package handler
 
 import (
-	"fmt"
+	"context"
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"pvz/internal/api/mapper"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
 )
 
 func (h *Handler) CreateReception(c *gin.Context) {
@@ -15,16 +18,13 @@ func (h *Handler) CreateReception(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warnw("Invalid input data for reception creation", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data", "error": err.Error()})
+		c.Error(apperror.Validation("invalid request body"))
 		return
 	}
 
 	if _, err := uuid.Parse(req.PvzId); err != nil {
 		h.logger.Warnw("Invalid input data for reception creation", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Invalid input data",
-			"error":   "PvzId is not a valid UUID",
-		})
+		c.Error(apperror.Validation("invalid pvzId format"))
 		return
 	}
 
@@ -33,7 +33,7 @@ func (h *Handler) CreateReception(c *gin.Context) {
 	createdReception, err := h.service.CreateReception(c.Request.Context(), reception.PvzId)
 	if err != nil {
 		h.logger.Errorw("Failed to create reception", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create reception", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -48,7 +48,7 @@ func (h *Handler) CloseReception(c *gin.Context) {
 	pvzId, err := uuid.Parse(pvzIdParam)
 	if err != nil {
 		h.logger.Errorw("Invalid PvzId format", "PvzId", pvzIdParam, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid PvzId format"})
+		c.Error(apperror.Validation("invalid pvzId format"))
 		return
 	}
 
@@ -57,7 +57,7 @@ func (h *Handler) CloseReception(c *gin.Context) {
 	err = h.service.CloseReception(c, pvzId)
 	if err != nil {
 		h.logger.Errorw("Failed to close reception", "PvzId", pvzId, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to close reception: %v", err)})
+		c.Error(err)
 		return
 	}
 
@@ -65,3 +65,118 @@ func (h *Handler) CloseReception(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Reception closed successfully"})
 }
+
+func (h *Handler) CloseReceptionById(c *gin.Context) {
+	h.changeReceptionStatus(c, "close", h.service.CloseReceptionById)
+}
+
+func (h *Handler) VerifyReception(c *gin.Context) {
+	h.changeReceptionStatus(c, "verify", h.service.VerifyReception)
+}
+
+func (h *Handler) CancelReception(c *gin.Context) {
+	h.changeReceptionStatus(c, "cancel", h.service.CancelReception)
+}
+
+func (h *Handler) ReopenReception(c *gin.Context) {
+	h.changeReceptionStatus(c, "reopen", h.service.ReopenReception)
+}
+
+// changeReceptionStatus выполняет переход приёмки из пути и отдаёт её новое
+// состояние. Недопустимый переход сервис возвращает как Conflict.
+func (h *Handler) changeReceptionStatus(c *gin.Context, action string, change func(context.Context, uuid.UUID) (model.Reception, error)) {
+	receptionId, ok := h.receptionIdParam(c)
+	if !ok {
+		return
+	}
+
+	reception, err := change(c.Request.Context(), receptionId)
+	if err != nil {
+		h.logger.Errorw("Failed to change reception status", "ReceptionId", receptionId, "action", action, "error", err)
+		c.Error(err)
+		return
+	}
+
+	h.logger.Infow("Reception status changed", "ReceptionId", receptionId, "status", reception.Status)
+	c.JSON(http.StatusOK, mapper.ToReceptionResponse(reception))
+}
+
+func (h *Handler) GetReception(c *gin.Context) {
+	receptionIdParam := c.Param("receptionId")
+	receptionId, err := uuid.Parse(receptionIdParam)
+	if err != nil {
+		h.logger.Warnw("Invalid ReceptionId format", "ReceptionId", receptionIdParam, "error", err)
+		c.Error(apperror.Validation("invalid receptionId format"))
+		return
+	}
+
+	reception, err := h.service.GetReception(c.Request.Context(), receptionId)
+	if err != nil {
+		h.logger.Errorw("Failed to get reception", "ReceptionId", receptionId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToReceptionWrapper(reception))
+}
+
+func (h *Handler) GetReceptionList(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := h.paginationQuery(c, c.DefaultQuery("limit", "10"), c.DefaultQuery("offset", "0"))
+	if !ok {
+		return
+	}
+
+	filter := model.ReceptionFilter{Status: c.Query("status")}
+	if filter.Status != "" && !slices.Contains(model.ReceptionStatuses, filter.Status) {
+		h.logger.Warnw("Invalid status", "status", filter.Status)
+		c.Error(apperror.Validation("invalid status"))
+		return
+	}
+	if filter.StartDate, ok = h.timeQuery(c, "startDate"); !ok {
+		return
+	}
+	if filter.EndDate, ok = h.timeQuery(c, "endDate"); !ok {
+		return
+	}
+
+	receptions, err := h.service.GetReceptionList(c.Request.Context(), pvzId, limit, offset, filter)
+	if err != nil {
+		h.logger.Errorw("Failed to get reception list", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToReceptionListResponse(receptions))
+}
+
+func (h *Handler) GetCurrentReception(c *gin.Context) {
+	pvzId, ok := h.pvzIdParam(c)
+	if !ok {
+		return
+	}
+
+	reception, err := h.service.GetCurrentReception(c.Request.Context(), pvzId)
+	if err != nil {
+		h.logger.Errorw("Failed to get current reception", "PvzId", pvzId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToReceptionWrapper(reception))
+}
+
+func (h *Handler) receptionIdParam(c *gin.Context) (uuid.UUID, bool) {
+	receptionIdParam := c.Param("receptionId")
+	receptionId, err := uuid.Parse(receptionIdParam)
+	if err != nil {
+		h.logger.Warnw("Invalid ReceptionId format", "ReceptionId", receptionIdParam, "error", err)
+		c.Error(apperror.Validation("invalid receptionId format"))
+		return uuid.Nil, false
+	}
+	return receptionId, true
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"pvz/internal/api/mapper"
 	"pvz/internal/api/response"
+	"pvz/internal/apperror"
 )
 
 func (h *Handler) DummyLogin(c *gin.Context) {
@@ -13,14 +15,25 @@ func (h *Handler) DummyLogin(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warnw("Invalid input data for dummy login", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data", "error": err.Error()})
+		c.Error(apperror.Validation("invalid request body"))
 		return
 	}
 
-	token, err := h.service.DummyLogin(c, req.Role)
+	var pvzScope []uuid.UUID
+	for _, pvzIdParam := range req.PvzIds {
+		pvzId, err := uuid.Parse(pvzIdParam)
+		if err != nil {
+			h.logger.Warnw("Invalid pvzIds for dummy login", "pvzId", pvzIdParam, "error", err)
+			c.Error(apperror.Validation("invalid pvzIds format"))
+			return
+		}
+		pvzScope = append(pvzScope, pvzId)
+	}
+
+	token, err := h.service.DummyLogin(c, req.Role, pvzScope)
 	if err != nil {
 		h.logger.Warnw("Dummy login failed", "error", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "Failed to generate token"})
+		c.Error(err)
 		return
 	}
 
@@ -33,7 +46,7 @@ func (h *Handler) Register(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warnw("Invalid input data for registration", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data", "error": err.Error()})
+		c.Error(apperror.Validation("invalid request body"))
 		return
 	}
 
@@ -42,7 +55,7 @@ func (h *Handler) Register(c *gin.Context) {
 	createdUser, err := h.service.CreateUser(c, user)
 	if err != nil {
 		h.logger.Errorw("User registration failed", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create user", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -52,22 +65,86 @@ func (h *Handler) Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, resp)
 }
 
+// CreateUser создаёт пользователя с любой ролью политики. В отличие от
+// Register доступен только администратору.
+func (h *Handler) CreateUser(c *gin.Context) {
+	var req response.RegisterPostRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnw("Invalid input data for user creation", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	createdUser, err := h.service.CreateUserWithRole(c, mapper.ToUser(req))
+	if err != nil {
+		h.logger.Errorw("User creation failed", "error", err)
+		c.Error(err)
+		return
+	}
+
+	h.logger.Infow("User created", "userID", createdUser.Id, "role", createdUser.Role)
+	c.JSON(http.StatusCreated, mapper.ToRegisterResponse(createdUser))
+}
+
 func (h *Handler) Login(c *gin.Context) {
 	var req response.LoginPostRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warnw("Invalid input data for login", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data", "error": err.Error()})
+		c.Error(apperror.Validation("invalid request body"))
 		return
 	}
 
-	token, err := h.service.LoginUser(c, req.Email, req.Password)
+	pair, err := h.service.LoginUser(c, req.Email, req.Password)
 	if err != nil {
 		h.logger.Warnw("Login failed", "email", req.Email, "error", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid email or password"})
+		c.Error(err)
 		return
 	}
 
 	h.logger.Infow("Login successful", "email", req.Email)
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, mapper.ToTokenPairResponse(pair))
+}
+
+func (h *Handler) Refresh(c *gin.Context) {
+	var req response.RefreshPostRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		h.logger.Warnw("Invalid input data for token refresh", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	pair, err := h.service.RefreshTokens(c, req.RefreshToken)
+	if err != nil {
+		h.logger.Warnw("Token refresh failed", "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToTokenPairResponse(pair))
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	var req response.LogoutPostRequest
+
+	// Тело необязательно: без refresh-токена отзывается только текущий access-токен
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warnw("Invalid input data for logout", "error", err)
+			c.Error(apperror.Validation("invalid request body"))
+			return
+		}
+	}
+
+	claims := userClaims(c)
+
+	if err := h.service.Logout(c, claims, req.RefreshToken); err != nil {
+		h.logger.Warnw("Logout failed", "userID", claims.UserId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
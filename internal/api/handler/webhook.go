@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"pvz/internal/api/mapper"
+	"pvz/internal/api/response"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
+)
+
+func (h *Handler) CreateWebhookSubscription(c *gin.Context) {
+	var req response.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnw("Invalid WebhookSubscriptionRequest", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	subscription := model.WebhookSubscription{
+		Url:        req.Url,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     true,
+	}
+	if req.PvzId != nil {
+		pvzId, err := uuid.Parse(*req.PvzId)
+		if err != nil {
+			h.logger.Warnw("Invalid PvzId format", "PvzId", *req.PvzId, "error", err)
+			c.Error(apperror.Validation("invalid pvzId format"))
+			return
+		}
+		subscription.PvzId = &pvzId
+	}
+
+	created, err := h.service.CreateWebhookSubscription(c.Request.Context(), subscription)
+	if err != nil {
+		h.logger.Errorw("Failed to create webhook subscription", "url", req.Url, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapper.ToCreatedWebhookSubscriptionResponse(created))
+}
+
+func (h *Handler) GetWebhookSubscriptions(c *gin.Context) {
+	subscriptions, err := h.service.GetWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		h.logger.Errorw("Failed to get webhook subscriptions", "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToWebhookSubscriptionsResponse(subscriptions))
+}
+
+func (h *Handler) GetWebhookSubscription(c *gin.Context) {
+	webhookId, ok := h.webhookIdParam(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.GetWebhookSubscription(c.Request.Context(), webhookId)
+	if err != nil {
+		h.logger.Errorw("Failed to get webhook subscription", "WebhookId", webhookId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToWebhookSubscriptionResponse(subscription))
+}
+
+func (h *Handler) UpdateWebhookSubscription(c *gin.Context) {
+	webhookId, ok := h.webhookIdParam(c)
+	if !ok {
+		return
+	}
+
+	var req response.WebhookSubscriptionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnw("Invalid WebhookSubscriptionUpdateRequest", "error", err)
+		c.Error(apperror.Validation("invalid request body"))
+		return
+	}
+
+	update := model.WebhookSubscriptionUpdate{
+		Url:        req.Url,
+		EventTypes: req.EventTypes,
+		Active:     req.Active,
+	}
+	if req.PvzId != nil {
+		if *req.PvzId == "" {
+			update.ClearPvzId = true
+		} else {
+			pvzId, err := uuid.Parse(*req.PvzId)
+			if err != nil {
+				h.logger.Warnw("Invalid PvzId format", "PvzId", *req.PvzId, "error", err)
+				c.Error(apperror.Validation("invalid pvzId format"))
+				return
+			}
+			update.PvzId = &pvzId
+		}
+	}
+
+	subscription, err := h.service.UpdateWebhookSubscription(c.Request.Context(), webhookId, update)
+	if err != nil {
+		h.logger.Errorw("Failed to update webhook subscription", "WebhookId", webhookId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToWebhookSubscriptionResponse(subscription))
+}
+
+func (h *Handler) DeleteWebhookSubscription(c *gin.Context) {
+	webhookId, ok := h.webhookIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhookSubscription(c.Request.Context(), webhookId); err != nil {
+		h.logger.Errorw("Failed to delete webhook subscription", "WebhookId", webhookId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	webhookId, ok := h.webhookIdParam(c)
+	if !ok {
+		return
+	}
+	limit, offset, ok := h.paginationQuery(c, c.DefaultQuery("limit", "10"), c.DefaultQuery("offset", "0"))
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryDead:
+	default:
+		h.logger.Warnw("Invalid status", "status", status)
+		c.Error(apperror.Validation("invalid status"))
+		return
+	}
+
+	deliveries, err := h.service.GetWebhookDeliveries(c.Request.Context(), webhookId, status, limit, offset)
+	if err != nil {
+		h.logger.Errorw("Failed to get webhook deliveries", "WebhookId", webhookId, "error", err)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.ToWebhookDeliveriesResponse(deliveries))
+}
+
+func (h *Handler) webhookIdParam(c *gin.Context) (uuid.UUID, bool) {
+	webhookIdParam := c.Param("webhookId")
+	webhookId, err := uuid.Parse(webhookIdParam)
+	if err != nil {
+		h.logger.Warnw("Invalid WebhookId format", "WebhookId", webhookIdParam, "error", err)
+		c.Error(apperror.Validation("invalid webhookId format"))
+		return uuid.Nil, false
+	}
+	return webhookId, true
+}
@@ -0,0 +1,14 @@
+package mapper
+
+import (
+	"github.com/google/uuid"
+	"pvz/internal/api/response"
+)
+
+func ToAssignedPvzResponse(pvzIds []uuid.UUID) response.AssignedPvzResponse {
+	result := make([]string, 0, len(pvzIds))
+	for _, pvzId := range pvzIds {
+		result = append(result, pvzId.String())
+	}
+	return response.AssignedPvzResponse{PvzIds: result}
+}
@@ -0,0 +1,33 @@
+package mapper
+
+import (
+	"pvz/internal/api/response"
+	"pvz/internal/repository/model"
+)
+
+func ToAuditEntryResponse(entry model.AuditEntry) response.AuditEntryResponse {
+	resp := response.AuditEntryResponse{
+		Id:         entry.Id.String(),
+		CreatedAt:  entry.CreatedAt.Format("2006-01-02 15:04:05"),
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		Before:     entry.Before,
+		After:      entry.After,
+		RequestId:  entry.RequestId,
+	}
+	if entry.ActorId != nil {
+		actorId := entry.ActorId.String()
+		resp.ActorId = &actorId
+	}
+	return resp
+}
+
+func ToAuditLogResponse(entries []model.AuditEntry) []response.AuditEntryResponse {
+	result := make([]response.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, ToAuditEntryResponse(entry))
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+package mapper
+
+import (
+	"pvz/internal/api/response"
+	"pvz/internal/repository/model"
+)
+
+func ToCatalogItemResponse(item model.CatalogItem) response.CatalogItemResponse {
+	return response.CatalogItemResponse{
+		Name:   item.Name,
+		Active: item.Active,
+	}
+}
+
+func ToCatalogResponse(items []model.CatalogItem) []response.CatalogItemResponse {
+	resp := make([]response.CatalogItemResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, ToCatalogItemResponse(item))
+	}
+	return resp
+}
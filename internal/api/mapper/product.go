@@ -7,15 +7,57 @@ import (
 
 func ToProduct(req response.ProductRequest) model.Product {
 	return model.Product{
-		Type: req.Type,
+		Type:       req.Type,
+		Barcode:    req.Barcode,
+		OrderId:    req.OrderId,
+		Weight:     req.Weight,
+		Attributes: req.Attributes,
 	}
 }
 
+// ToBatchProduct переносит поля товара пакета, кроме id: его разбирает хендлер
+func ToBatchProduct(item response.ProductBatchItemRequest) model.Product {
+	return model.Product{
+		Type:       item.Type,
+		Barcode:    item.Barcode,
+		OrderId:    item.OrderId,
+		Weight:     item.Weight,
+		Attributes: item.Attributes,
+	}
+}
+
+func ToProductUpdate(req response.ProductUpdateRequest) model.ProductUpdate {
+	return model.ProductUpdate{Type: req.Type}
+}
+
 func ToProductResponse(product model.Product) response.ProductResponse {
 	return response.ProductResponse{
 		Id:          product.Id.String(),
 		DateTime:    product.DateTime.Format("2006-01-02 15:04:05"),
 		Type:        product.Type,
 		ReceptionId: product.ReceptionId.String(),
+		Barcode:     product.Barcode,
+		OrderId:     product.OrderId,
+		Weight:      product.Weight,
+		Attributes:  product.Attributes,
+	}
+}
+
+func ToProductBatchResponse(products []model.Product) response.ProductBatchResponse {
+	result := make([]response.ProductResponse, 0, len(products))
+	for _, product := range products {
+		result = append(result, ToProductResponse(product))
+	}
+	return response.ProductBatchResponse{Products: result}
+}
+
+func ToProductLookupResponse(products []model.ProductWithPvz) []response.ProductLookupResponse {
+	result := make([]response.ProductLookupResponse, 0, len(products))
+	for _, product := range products {
+		result = append(result, response.ProductLookupResponse{
+			ProductResponse: ToProductResponse(product.Product),
+			PvzId:           product.PvzId.String(),
+		})
 	}
+	return result
 }
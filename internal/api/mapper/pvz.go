@@ -11,10 +11,39 @@ func ToPvz(pvz response.PvzRequest) model.Pvz {
 	}
 }
 
+func ToPvzUpdate(req response.PvzUpdateRequest) model.PvzUpdate {
+	return model.PvzUpdate{
+		City:    req.City,
+		Name:    req.Name,
+		Address: req.Address,
+	}
+}
+
 func ToPvzResponse(pvz model.Pvz) response.PvzResponse {
-	return response.PvzResponse{
+	resp := response.PvzResponse{
 		Id:               pvz.Id.String(),
 		RegistrationDate: pvz.RegistrationDate.Format("2006-01-02 15:04:05"),
 		City:             pvz.City,
+		Name:             pvz.Name,
+		Address:          pvz.Address,
+		Status:           pvz.Status,
+	}
+	if pvz.DeactivatedAt != nil {
+		deactivatedAt := pvz.DeactivatedAt.Format("2006-01-02 15:04:05")
+		resp.DeactivatedAt = &deactivatedAt
+	}
+	return resp
+}
+
+func ToPvzFullResponse(pvz model.PvzWithReceptions) response.PvzFullResponse {
+	var receptionWrappers []response.ReceptionWrapper
+
+	for _, rec := range pvz.Receptions {
+		receptionWrappers = append(receptionWrappers, ToReceptionWrapper(rec))
+	}
+
+	return response.PvzFullResponse{
+		Pvz:        ToPvzResponse(pvz.Pvz),
+		Receptions: receptionWrappers,
 	}
 }
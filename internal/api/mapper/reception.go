@@ -1,6 +1,8 @@
 package mapper
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"pvz/internal/api/response"
 	"pvz/internal/repository/model"
@@ -19,9 +21,55 @@ func ToReception(req response.ReceptionRequest) model.Reception {
 
 func ToReceptionResponse(reception model.Reception) response.ReceptionResponse {
 	return response.ReceptionResponse{
-		Id:       reception.Id.String(),
-		DateTime: reception.DateTime.Format("2006-01-02 15:04:05"),
-		PvzId:    reception.PvzId.String(),
-		Status:   reception.Status,
+		Id:          reception.Id.String(),
+		DateTime:    reception.DateTime.Format("2006-01-02 15:04:05"),
+		PvzId:       reception.PvzId.String(),
+		Status:      reception.Status,
+		StaleAt:     optionalTime(reception.StaleAt),
+		StaleReason: reception.StaleReason,
+		ClosedAt:    optionalTime(reception.ClosedAt),
+		ClosedBy:    optionalId(reception.ClosedBy),
+		VerifiedAt:  optionalTime(reception.VerifiedAt),
+		VerifiedBy:  optionalId(reception.VerifiedBy),
+		CancelledAt: optionalTime(reception.CancelledAt),
+		CancelledBy: optionalId(reception.CancelledBy),
+		ReopenedAt:  optionalTime(reception.ReopenedAt),
+		ReopenedBy:  optionalId(reception.ReopenedBy),
+	}
+}
+
+func ToReceptionWrapper(reception model.ReceptionWithProducts) response.ReceptionWrapper {
+	var productResponses []response.ProductResponse
+	for _, p := range reception.Products {
+		productResponses = append(productResponses, ToProductResponse(p))
+	}
+
+	return response.ReceptionWrapper{
+		Reception: ToReceptionResponse(reception.Reception),
+		Products:  productResponses,
+	}
+}
+
+func ToReceptionListResponse(receptions []model.Reception) []response.ReceptionResponse {
+	result := make([]response.ReceptionResponse, 0, len(receptions))
+	for _, reception := range receptions {
+		result = append(result, ToReceptionResponse(reception))
+	}
+	return result
+}
+
+func optionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
+
+func optionalId(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
 	}
+	formatted := id.String()
+	return &formatted
 }
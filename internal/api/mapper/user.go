@@ -20,3 +20,10 @@ func ToRegisterResponse(user model.User) response.RegisterResponse {
 		Role:  user.Role,
 	}
 }
+
+func ToTokenPairResponse(pair model.TokenPair) response.TokenPairResponse {
+	return response.TokenPairResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+	}
+}
@@ -0,0 +1,68 @@
+package mapper
+
+import (
+	"pvz/internal/api/response"
+	"pvz/internal/repository/model"
+)
+
+func ToWebhookSubscriptionResponse(subscription model.WebhookSubscription) response.WebhookSubscriptionResponse {
+	resp := response.WebhookSubscriptionResponse{
+		Id:         subscription.Id.String(),
+		Url:        subscription.Url,
+		EventTypes: append([]string{}, subscription.EventTypes...),
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  subscription.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if subscription.PvzId != nil {
+		pvzId := subscription.PvzId.String()
+		resp.PvzId = &pvzId
+	}
+	return resp
+}
+
+// ToCreatedWebhookSubscriptionResponse - ответ на создание подписки, единственный с секретом
+func ToCreatedWebhookSubscriptionResponse(subscription model.WebhookSubscription) response.WebhookSubscriptionResponse {
+	resp := ToWebhookSubscriptionResponse(subscription)
+	resp.Secret = subscription.Secret
+	return resp
+}
+
+func ToWebhookSubscriptionsResponse(subscriptions []model.WebhookSubscription) []response.WebhookSubscriptionResponse {
+	result := make([]response.WebhookSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, ToWebhookSubscriptionResponse(subscription))
+	}
+	return result
+}
+
+func ToWebhookDeliveryResponse(delivery model.WebhookDelivery) response.WebhookDeliveryResponse {
+	resp := response.WebhookDeliveryResponse{
+		Id:             delivery.Id.String(),
+		EventId:        delivery.EventId.String(),
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	// Время следующей попытки имеет смысл только для ожидающей доставки
+	if delivery.Status == model.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt.Format("2006-01-02 15:04:05")
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := delivery.DeliveredAt.Format("2006-01-02 15:04:05")
+		resp.DeliveredAt = &deliveredAt
+	}
+	return resp
+}
+
+func ToWebhookDeliveriesResponse(deliveries []model.WebhookDelivery) []response.WebhookDeliveryResponse {
+	result := make([]response.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, ToWebhookDeliveryResponse(delivery))
+	}
+	return result
+}
@@ -0,0 +1,6 @@
+package response
+
+// AssignedPvzResponse - ПВЗ, на которые назначен сотрудник
+type AssignedPvzResponse struct {
+	PvzIds []string `json:"pvzIds"`
+}
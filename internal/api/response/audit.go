@@ -0,0 +1,18 @@
+package response
+
+import "encoding/json"
+
+// AuditEntryResponse - запись журнала аудита. Before и After - снимки
+// сущности до и после изменения, null если снимка нет.
+type AuditEntryResponse struct {
+	Id         string          `json:"id"`
+	CreatedAt  string          `json:"createdAt"`
+	ActorId    *string         `json:"actorId"`
+	ActorRole  string          `json:"actorRole"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityId   string          `json:"entityId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestId  string          `json:"requestId"`
+}
@@ -0,0 +1,14 @@
+package response
+
+type CatalogItemRequest struct {
+	Name string `json:"name"`
+}
+
+type CatalogItemUpdateRequest struct {
+	Active *bool `json:"active"`
+}
+
+type CatalogItemResponse struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
@@ -1,5 +1,6 @@
 package response
 
 type DummyLoginPostRequest struct {
-	Role string `json:"role"`
+	Role   string   `json:"role"`
+	PvzIds []string `json:"pvzIds"`
 }
@@ -0,0 +1,11 @@
+package response
+
+type Error struct {
+	Message string      `json:"message"`
+	Errors  []ItemError `json:"errors,omitempty"`
+}
+
+type ItemError struct {
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
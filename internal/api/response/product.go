@@ -1,13 +1,54 @@
 package response
 
+import "encoding/json"
+
 type ProductRequest struct {
-	Type  string `json:"Type"`
-	PvzId string `json:"PvzId"`
+	Type       string          `json:"Type"`
+	PvzId      string          `json:"PvzId"`
+	Barcode    *string         `json:"Barcode,omitempty"`
+	OrderId    *string         `json:"OrderId,omitempty"`
+	Weight     *int            `json:"Weight,omitempty"`
+	Attributes json.RawMessage `json:"Attributes,omitempty"`
 }
 
 type ProductResponse struct {
-	Id          string `json:"Id"`
-	DateTime    string `json:"DateTime"`
-	Type        string `json:"Type"`
-	ReceptionId string `json:"ReceptionId"`
+	Id          string          `json:"Id"`
+	DateTime    string          `json:"DateTime"`
+	Type        string          `json:"Type"`
+	ReceptionId string          `json:"ReceptionId"`
+	Barcode     *string         `json:"Barcode,omitempty"`
+	OrderId     *string         `json:"OrderId,omitempty"`
+	Weight      *int            `json:"Weight,omitempty"`
+	Attributes  json.RawMessage `json:"Attributes,omitempty"`
+}
+
+// ProductUpdateRequest - поля для PATCH /products/{productId}; отсутствующие поля не меняются
+type ProductUpdateRequest struct {
+	Type *string `json:"type"`
+}
+
+// ProductLookupResponse - товар, найденный по штрихкоду, и его ПВЗ
+type ProductLookupResponse struct {
+	ProductResponse
+	PvzId string `json:"PvzId"`
+}
+
+type ProductBatchRequest struct {
+	PvzId    string                    `json:"pvzId"`
+	Products []ProductBatchItemRequest `json:"products"`
+}
+
+// ProductBatchItemRequest - товар пакета. Id необязателен: его передаёт
+// сканер, если хочет знать id товара заранее.
+type ProductBatchItemRequest struct {
+	Id         string          `json:"id"`
+	Type       string          `json:"type"`
+	Barcode    *string         `json:"barcode"`
+	OrderId    *string         `json:"orderId"`
+	Weight     *int            `json:"weight"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+type ProductBatchResponse struct {
+	Products []ProductResponse `json:"products"`
 }
@@ -4,15 +4,33 @@ type PvzRequest struct {
 	City string `json:"City"`
 }
 
+// PvzUpdateRequest - поля для PATCH /pvz/{pvzId}; отсутствующие поля не меняются
+type PvzUpdateRequest struct {
+	City    *string `json:"city"`
+	Name    *string `json:"name"`
+	Address *string `json:"address"`
+}
+
 type PvzFullResponse struct {
 	Pvz        PvzResponse        `json:"pvz"`
 	Receptions []ReceptionWrapper `json:"receptions"`
 }
 
+// PvzPageResponse - страница списка ПВЗ при постраничной выборке по курсору.
+// NextCursor равен null на последней странице.
+type PvzPageResponse struct {
+	Items      []PvzFullResponse `json:"items"`
+	NextCursor *string           `json:"nextCursor"`
+}
+
 type PvzResponse struct {
-	Id               string `json:"id"`
-	RegistrationDate string `json:"registrationDate"`
-	City             string `json:"city"`
+	Id               string  `json:"id"`
+	RegistrationDate string  `json:"registrationDate"`
+	City             string  `json:"city"`
+	Name             string  `json:"name"`
+	Address          string  `json:"address"`
+	Status           string  `json:"status"`
+	DeactivatedAt    *string `json:"deactivatedAt,omitempty"`
 }
 
 type ReceptionWrapper struct {
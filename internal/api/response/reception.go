@@ -5,8 +5,18 @@ type ReceptionRequest struct {
 }
 
 type ReceptionResponse struct {
-	Id       string `json:"Id"`
-	DateTime string `json:"DateTime"`
-	PvzId    string `json:"PvzId"`
-	Status   string `json:"Status"`
+	Id          string  `json:"Id"`
+	DateTime    string  `json:"DateTime"`
+	PvzId       string  `json:"PvzId"`
+	Status      string  `json:"Status"`
+	StaleAt     *string `json:"StaleAt,omitempty"`
+	StaleReason *string `json:"StaleReason,omitempty"`
+	ClosedAt    *string `json:"ClosedAt,omitempty"`
+	ClosedBy    *string `json:"ClosedBy,omitempty"`
+	VerifiedAt  *string `json:"VerifiedAt,omitempty"`
+	VerifiedBy  *string `json:"VerifiedBy,omitempty"`
+	CancelledAt *string `json:"CancelledAt,omitempty"`
+	CancelledBy *string `json:"CancelledBy,omitempty"`
+	ReopenedAt  *string `json:"ReopenedAt,omitempty"`
+	ReopenedBy  *string `json:"ReopenedBy,omitempty"`
 }
@@ -0,0 +1,14 @@
+package response
+
+type RefreshPostRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutPostRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenPairResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
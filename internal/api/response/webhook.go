@@ -0,0 +1,45 @@
+package response
+
+// WebhookSubscriptionRequest - тело POST /webhooks. Пустой eventTypes -
+// все типы событий, без pvzId - все ПВЗ. Без secret он генерируется.
+type WebhookSubscriptionRequest struct {
+	Url        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+	PvzId      *string  `json:"pvzId"`
+}
+
+// WebhookSubscriptionUpdateRequest - поля для PATCH /webhooks/{webhookId};
+// отсутствующие поля не меняются, пустой pvzId снимает фильтр по ПВЗ
+type WebhookSubscriptionUpdateRequest struct {
+	Url        *string  `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	PvzId      *string  `json:"pvzId"`
+	Active     *bool    `json:"active"`
+}
+
+// WebhookSubscriptionResponse - подписка на вебхуки. Secret отдаётся только при создании.
+type WebhookSubscriptionResponse struct {
+	Id         string   `json:"id"`
+	Url        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"eventTypes"`
+	PvzId      *string  `json:"pvzId"`
+	Active     bool     `json:"active"`
+	CreatedAt  string   `json:"createdAt"`
+	UpdatedAt  string   `json:"updatedAt"`
+}
+
+// WebhookDeliveryResponse - запись журнала доставок
+type WebhookDeliveryResponse struct {
+	Id             string  `json:"id"`
+	EventId        string  `json:"eventId"`
+	EventType      string  `json:"eventType"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  *string `json:"nextAttemptAt"`
+	LastStatusCode *int    `json:"lastStatusCode"`
+	LastError      *string `json:"lastError"`
+	CreatedAt      string  `json:"createdAt"`
+	DeliveredAt    *string `json:"deliveredAt"`
+}
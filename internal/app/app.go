@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"pvz/internal/config"
+	"pvz/internal/logger"
+	"pvz/metrics"
+	"pvz/server"
+)
+
+// App управляет жизненным циклом сервиса: запускает HTTP, gRPC, сервер метрик
+// и фоновые задачи, а при отмене контекста корректно останавливает их и освобождает ресурсы.
+type App struct {
+	cfg           config.Config
+	httpServer    *server.Server
+	grpcServer    *server.GrpcServer
+	metricsServer *server.Server
+	db            io.Closer
+	logger        logger.Logger
+
+	tasks     []task
+	stopTasks context.CancelFunc
+	tasksWg   sync.WaitGroup
+}
+
+// task - фоновая задача, которая выполняется раз в interval
+type task struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+func New(cfg config.Config, httpHandler http.Handler, grpcServer *grpc.Server, db io.Closer, log logger.Logger) *App {
+	return &App{
+		cfg:           cfg,
+		httpServer:    server.NewServer(cfg.HTTP.Port, httpHandler, cfg.HTTP.ReadTimeout, cfg.HTTP.WriteTimeout),
+		grpcServer:    server.NewGrpcServer(cfg.GRPC.Port, grpcServer),
+		metricsServer: server.NewServer(cfg.Metrics.Port, metrics.Handler(), cfg.HTTP.ReadTimeout, cfg.HTTP.WriteTimeout),
+		db:            db,
+		logger:        log,
+	}
+}
+
+// AddTask регистрирует фоновую задачу, выполняемую раз в interval, пока
+// приложение работает. Задачи останавливаются до закрытия БД. Вызывать до Run.
+func (a *App) AddTask(name string, interval time.Duration, run func(ctx context.Context) error) {
+	a.tasks = append(a.tasks, task{name: name, interval: interval, run: run})
+}
+
+// OnShutdown регистрирует f, которая вызывается в начале остановки
+// HTTP-сервера, например чтобы завершить открытые потоки событий. Вызывать до Run.
+func (a *App) OnShutdown(f func()) {
+	a.httpServer.RegisterOnShutdown(f)
+}
+
+// Run блокируется до отмены ctx или падения одного из серверов,
+// после чего выполняет остановку. Возвращает ошибку, если что-то пошло не так.
+func (a *App) Run(ctx context.Context) error {
+	errCh := make(chan error, 3)
+
+	a.start(errCh, "HTTP", a.cfg.HTTP.Port, a.httpServer.Run)
+	a.start(errCh, "gRPC", a.cfg.GRPC.Port, a.grpcServer.Run)
+	a.start(errCh, "metrics", a.cfg.Metrics.Port, a.metricsServer.Run)
+
+	tasksCtx, stopTasks := context.WithCancel(context.Background())
+	a.stopTasks = stopTasks
+	for _, t := range a.tasks {
+		a.startTask(tasksCtx, t)
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Infow("Shutdown signal received")
+	case runErr = <-errCh:
+		a.logger.Errorw("Server stopped unexpectedly", "error", runErr)
+	}
+
+	return errors.Join(runErr, a.shutdown())
+}
+
+func (a *App) start(errCh chan<- error, name, port string, run func() error) {
+	a.logger.Infow("Starting server", "server", name, "port", port)
+
+	go func() {
+		if err := run(); err != nil {
+			errCh <- fmt.Errorf("%s server: %w", name, err)
+		}
+	}()
+}
+
+func (a *App) startTask(ctx context.Context, t task) {
+	a.logger.Infow("Starting background task", "task", t.name, "interval", t.interval)
+
+	a.tasksWg.Add(1)
+	go func() {
+		defer a.tasksWg.Done()
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := t.run(ctx); err != nil && ctx.Err() == nil {
+					a.logger.Errorw("Background task failed", "task", t.name, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (a *App) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancel()
+
+	a.logger.Infow("Shutting down", "timeout", a.cfg.ShutdownTimeout)
+
+	var errs []error
+
+	if err := a.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("HTTP server shutdown: %w", err))
+	}
+	if err := a.grpcServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("gRPC server shutdown: %w", err))
+	}
+	if err := a.metricsServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("metrics server shutdown: %w", err))
+	}
+	// Задачи работают с БД, поэтому дожидаемся их до её закрытия
+	a.stopTasks()
+	a.tasksWg.Wait()
+
+	if err := a.db.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("closing database: %w", err))
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		a.logger.Errorw("Shutdown completed with errors", "error", err)
+	} else {
+		a.logger.Infow("Shutdown completed")
+	}
+
+	// Ошибку Sync игнорируем: для stdout она возникает на большинстве платформ
+	_ = a.logger.Sync()
+
+	return err
+}
@@ -0,0 +1,292 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"pvz/internal/app"
+	"pvz/internal/config"
+	"pvz/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type fakeDB struct {
+	closed bool
+}
+
+func (db *fakeDB) Close() error {
+	db.closed = true
+	return nil
+}
+
+func testConfig(httpPort, grpcPort, metricsPort string, shutdownTimeout time.Duration) config.Config {
+	return config.Config{
+		HTTP: config.HTTPConfig{
+			Port:         httpPort,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+		},
+		GRPC:            config.GRPCConfig{Port: grpcPort},
+		Metrics:         config.MetricsConfig{Port: metricsPort},
+		ShutdownTimeout: shutdownTimeout,
+	}
+}
+
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+// slowHandler сообщает о начале запроса и отвечает через delay
+func slowHandler(started chan<- struct{}, delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func newLogger() *mocks.MockLogger {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Infow", "Starting server", "server", mock.Anything, "port", mock.Anything).Times(3)
+	mockLogger.On("Infow", "Shutdown signal received").Once()
+	mockLogger.On("Infow", "Shutting down", "timeout", mock.Anything).Once()
+	mockLogger.On("Sync").Return(nil).Once()
+	return mockLogger
+}
+
+func TestApp_Run_GracefulShutdown(t *testing.T) {
+	mockLogger := newLogger()
+	mockLogger.On("Infow", "Shutdown completed").Once()
+
+	db := &fakeDB{}
+	httpPort := freePort(t)
+	started := make(chan struct{})
+
+	a := app.New(testConfig(httpPort, freePort(t), freePort(t), 5*time.Second),
+		slowHandler(started, 300*time.Millisecond), grpc.NewServer(), db, mockLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- a.Run(ctx) }()
+
+	// Запрос, который будет в обработке в момент получения сигнала
+	respCh := make(chan *http.Response, 1)
+	go func() {
+		for {
+			resp, err := http.Get("http://127.0.0.1:" + httpPort + "/")
+			if err == nil {
+				respCh <- resp
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the server")
+	}
+	cancel()
+
+	resp := <-respCh
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+
+	assert.True(t, db.closed)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestApp_Run_ShutdownTimeout(t *testing.T) {
+	mockLogger := newLogger()
+	mockLogger.On("Errorw", "Shutdown completed with errors", "error", mock.Anything).Once()
+
+	db := &fakeDB{}
+	httpPort := freePort(t)
+	started := make(chan struct{})
+
+	a := app.New(testConfig(httpPort, freePort(t), freePort(t), 50*time.Millisecond),
+		slowHandler(started, time.Second), grpc.NewServer(), db, mockLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- a.Run(ctx) }()
+
+	go func() {
+		for {
+			resp, err := http.Get("http://127.0.0.1:" + httpPort + "/")
+			if err == nil {
+				resp.Body.Close()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the server")
+	}
+	cancel()
+
+	select {
+	case err := <-runErr:
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown timeout")
+	}
+
+	// Даже при превышении дедлайна ресурсы должны быть освобождены
+	assert.True(t, db.closed)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestApp_Run_ServerFailure(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Infow", "Starting server", "server", mock.Anything, "port", mock.Anything).Times(3)
+	mockLogger.On("Errorw", "Server stopped unexpectedly", "error", mock.Anything).Once()
+	mockLogger.On("Infow", "Shutting down", "timeout", mock.Anything).Once()
+	mockLogger.On("Infow", "Shutdown completed").Once()
+	mockLogger.On("Sync").Return(nil).Once()
+
+	// Занимаем порт, чтобы HTTP-сервер не смог стартовать
+	busy, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	db := &fakeDB{}
+	busyPort := strconv.Itoa(busy.Addr().(*net.TCPAddr).Port)
+	a := app.New(testConfig(busyPort, freePort(t), freePort(t), time.Second),
+		http.NotFoundHandler(), grpc.NewServer(), db, mockLogger)
+
+	select {
+	case err := <-runAsync(a):
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after server failure")
+	}
+
+	assert.True(t, db.closed)
+	mockLogger.AssertExpectations(t)
+}
+
+func runAsync(a *app.App) <-chan error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- a.Run(context.Background()) }()
+	return errCh
+}
+
+func TestApp_Tasks(t *testing.T) {
+	mockLogger := newLogger()
+	mockLogger.On("Infow", "Starting background task", "task", "sweeper", "interval", 10*time.Millisecond).Once()
+	mockLogger.On("Errorw", "Background task failed", "task", "sweeper", "error", mock.Anything)
+	mockLogger.On("Infow", "Shutdown completed").Once()
+
+	db := &fakeDB{}
+	a := app.New(testConfig(freePort(t), freePort(t), freePort(t), time.Second),
+		http.NotFoundHandler(), grpc.NewServer(), db, mockLogger)
+
+	runs := make(chan struct{}, 10)
+	a.AddTask("sweeper", 10*time.Millisecond, func(ctx context.Context) error {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+		return errors.New("task failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- a.Run(ctx) }()
+
+	// Задача выполняется повторно и после ошибки
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("task did not run")
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+
+	assert.True(t, db.closed)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestApp_OnShutdown(t *testing.T) {
+	mockLogger := newLogger()
+	mockLogger.On("Infow", "Shutdown completed").Once()
+
+	httpPort := freePort(t)
+	started := make(chan struct{})
+	stopStreams := make(chan struct{})
+
+	// Долгий поток, который завершается только по сигналу остановки
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-stopStreams
+		w.WriteHeader(http.StatusOK)
+	})
+
+	a := app.New(testConfig(httpPort, freePort(t), freePort(t), 5*time.Second), stream, grpc.NewServer(), &fakeDB{}, mockLogger)
+	a.OnShutdown(func() { close(stopStreams) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- a.Run(ctx) }()
+
+	go func() {
+		for {
+			resp, err := http.Get("http://127.0.0.1:" + httpPort + "/")
+			if err == nil {
+				resp.Body.Close()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the server")
+	}
+	cancel()
+
+	// Без OnShutdown Run ждал бы shutdown_timeout
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+	mockLogger.AssertExpectations(t)
+}
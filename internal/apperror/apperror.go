@@ -0,0 +1,85 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Категории ошибок предметной области. Проверяются через errors.Is
+// и определяют HTTP-статус ответа.
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+// Error - ошибка, сообщение которой можно безопасно вернуть клиенту.
+// Причина (cause) остаётся доступной через errors.Is/As, но клиенту не показывается.
+type Error struct {
+	kind    error
+	message string
+	cause   error
+	items   []ItemError
+}
+
+// ItemError - ошибка отдельного элемента пакетного запроса, Index - его позиция в запросе
+type ItemError struct {
+	Index   int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.message, e.cause)
+	}
+	return e.message
+}
+
+// Message возвращает сообщение для клиента
+func (e *Error) Message() string {
+	return e.message
+}
+
+// Items возвращает ошибки элементов пакетного запроса, если они есть
+func (e *Error) Items() []ItemError {
+	return e.items
+}
+
+func (e *Error) Unwrap() []error {
+	if e.cause != nil {
+		return []error{e.kind, e.cause}
+	}
+	return []error{e.kind}
+}
+
+// Wrap создаёт ошибку категории kind с сообщением для клиента и исходной причиной
+func Wrap(kind, cause error, format string, args ...interface{}) error {
+	return &Error{kind: kind, message: fmt.Sprintf(format, args...), cause: cause}
+}
+
+// WithItems создаёт ошибку категории kind, в которой перечислены отклонённые элементы пакета
+func WithItems(kind error, items []ItemError, format string, args ...interface{}) error {
+	return &Error{kind: kind, message: fmt.Sprintf(format, args...), items: items}
+}
+
+func Validation(format string, args ...interface{}) error {
+	return Wrap(ErrValidation, nil, format, args...)
+}
+
+func Unauthorized(format string, args ...interface{}) error {
+	return Wrap(ErrUnauthorized, nil, format, args...)
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return Wrap(ErrForbidden, nil, format, args...)
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return Wrap(ErrNotFound, nil, format, args...)
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return Wrap(ErrConflict, nil, format, args...)
+}
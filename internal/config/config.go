@@ -0,0 +1,464 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const defaultConfigPath = "config/config.yaml"
+
+// Secret - строка с чувствительными данными. При форматировании и
+// сериализации значение скрывается, исходное получается явным string(s).
+type Secret string
+
+const redacted = "***"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type Config struct {
+	HTTP            HTTPConfig        `mapstructure:"http"`
+	GRPC            GRPCConfig        `mapstructure:"grpc"`
+	Metrics         MetricsConfig     `mapstructure:"metrics"`
+	DB              DBConfig          `mapstructure:"db"`
+	JWT             JWTConfig         `mapstructure:"jwt"`
+	Log             LogConfig         `mapstructure:"log"`
+	Idempotency     IdempotencyConfig `mapstructure:"idempotency"`
+	RBAC            RBACConfig        `mapstructure:"rbac"`
+	Outbox          OutboxConfig      `mapstructure:"outbox"`
+	Webhooks        WebhooksConfig    `mapstructure:"webhooks"`
+	Live            LiveConfig        `mapstructure:"live"`
+	Receptions      ReceptionsConfig  `mapstructure:"receptions"`
+	ShutdownTimeout time.Duration     `mapstructure:"shutdown_timeout"`
+}
+
+type HTTPConfig struct {
+	Port         string        `mapstructure:"port"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+}
+
+type GRPCConfig struct {
+	Port string `mapstructure:"port"`
+}
+
+type MetricsConfig struct {
+	Port string `mapstructure:"port"`
+}
+
+type DBConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password Secret `mapstructure:"password"`
+	DBName   string `mapstructure:"dbname"`
+	SSLMode  string `mapstructure:"sslmode"`
+}
+
+// JWTConfig: для HS256 нужен SigningKey, для RS256 и EdDSA - Keys. Истёкшие
+// refresh-токены и записи об отзыве удаляются раз в SweepInterval.
+type JWTConfig struct {
+	Algorithm       string         `mapstructure:"algorithm"`
+	SigningKey      Secret         `mapstructure:"signing_key"`
+	Keys            []JWTKeyConfig `mapstructure:"keys"`
+	AccessTokenTTL  time.Duration  `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL time.Duration  `mapstructure:"refresh_token_ttl"`
+	SweepInterval   time.Duration  `mapstructure:"sweep_interval"`
+}
+
+// JWTKeyConfig - ключ в PEM-файлах. Подпись ведётся самым свежим ключом
+// с наступившим ActiveFrom, проверка - любым ключом из списка.
+type JWTKeyConfig struct {
+	ID             string    `mapstructure:"kid"`
+	PrivateKeyFile string    `mapstructure:"private_key_file"`
+	PublicKeyFile  string    `mapstructure:"public_key_file"`
+	ActiveFrom     time.Time `mapstructure:"active_from"`
+}
+
+// IdempotencyConfig: ключи Idempotency-Key хранятся TTL и удаляются
+// фоновой задачей раз в SweepInterval
+type IdempotencyConfig struct {
+	TTL           time.Duration `mapstructure:"ttl"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+// RBACConfig: права ролей берутся из встроенной политики (builtin),
+// YAML-файла File (file) или таблицы role_permission (db)
+type RBACConfig struct {
+	Source string `mapstructure:"source"`
+	File   string `mapstructure:"file"`
+}
+
+// OutboxConfig: доменные события публикуются раз в RelayInterval пачками
+// по BatchSize в stdout или файл File. Опубликованные события хранятся
+// Retention и удаляются раз в SweepInterval.
+type OutboxConfig struct {
+	Publisher     string        `mapstructure:"publisher"`
+	File          string        `mapstructure:"file"`
+	RelayInterval time.Duration `mapstructure:"relay_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	MaxBackoff    time.Duration `mapstructure:"max_backoff"`
+	Retention     time.Duration `mapstructure:"retention"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+// WebhooksConfig: раз в DeliveryInterval выполняется до BatchSize доставок
+// вебхуков. Получатель должен ответить за Timeout; после MaxAttempts неудачных
+// попыток доставка больше не повторяется.
+type WebhooksConfig struct {
+	DeliveryInterval time.Duration `mapstructure:"delivery_interval"`
+	BatchSize        int           `mapstructure:"batch_size"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	MaxAttempts      int           `mapstructure:"max_attempts"`
+	MaxBackoff       time.Duration `mapstructure:"max_backoff"`
+}
+
+// LiveConfig - параметры SSE-потока событий ПВЗ. Пока событий нет, клиенту
+// раз в HeartbeatInterval уходит ping. ReplayBuffer последних событий каждого
+// ПВЗ хранится для продолжения потока по Last-Event-ID; клиент, у которого
+// накопилось ClientBuffer неотправленных событий, отключается.
+type LiveConfig struct {
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+	ReplayBuffer      int           `mapstructure:"replay_buffer"`
+	ClientBuffer      int           `mapstructure:"client_buffer"`
+}
+
+// ReceptionsConfig: раз в StaleInterval до StaleBatchSize незакрытых
+// приёмок без активности дольше StaleAfter закрываются (StaleAction "close")
+// или только помечаются ("flag"). Закрытую приёмку модератор может открыть
+// снова в течение ReopenWindow.
+type ReceptionsConfig struct {
+	StaleAfter     time.Duration `mapstructure:"stale_after"`
+	StaleAction    string        `mapstructure:"stale_action"`
+	StaleInterval  time.Duration `mapstructure:"stale_interval"`
+	StaleBatchSize int           `mapstructure:"stale_batch_size"`
+	ReopenWindow   time.Duration `mapstructure:"reopen_window"`
+}
+
+type LogConfig struct {
+	Level string `mapstructure:"level"`
+	File  string `mapstructure:"file"`
+}
+
+// String возвращает конфигурацию в читаемом виде со скрытыми секретами
+func (c Config) String() string {
+	type plain Config
+	return fmt.Sprintf("%+v", plain(c))
+}
+
+var defaults = map[string]interface{}{
+	"http.port":                   "8080",
+	"http.read_timeout":           10 * time.Second,
+	"http.write_timeout":          10 * time.Second,
+	"grpc.port":                   "3000",
+	"metrics.port":                "9000",
+	"db.port":                     "5432",
+	"db.sslmode":                  "disable",
+	"jwt.algorithm":               "HS256",
+	"jwt.access_token_ttl":        15 * time.Minute,
+	"jwt.refresh_token_ttl":       30 * 24 * time.Hour,
+	"jwt.sweep_interval":          time.Hour,
+	"log.level":                   "info",
+	"log.file":                    "log/app.log",
+	"idempotency.ttl":             24 * time.Hour,
+	"idempotency.sweep_interval":  10 * time.Minute,
+	"rbac.source":                 "builtin",
+	"outbox.publisher":            "stdout",
+	"outbox.relay_interval":       time.Second,
+	"outbox.batch_size":           100,
+	"outbox.max_backoff":          5 * time.Minute,
+	"outbox.retention":            7 * 24 * time.Hour,
+	"outbox.sweep_interval":       time.Hour,
+	"webhooks.delivery_interval":  5 * time.Second,
+	"webhooks.batch_size":         20,
+	"webhooks.timeout":            5 * time.Second,
+	"webhooks.max_attempts":       10,
+	"webhooks.max_backoff":        time.Hour,
+	"live.heartbeat_interval":     15 * time.Second,
+	"live.replay_buffer":          256,
+	"live.client_buffer":          64,
+	"receptions.stale_after":      12 * time.Hour,
+	"receptions.stale_action":     "close",
+	"receptions.stale_interval":   5 * time.Minute,
+	"receptions.stale_batch_size": 100,
+	"receptions.reopen_window":    time.Hour,
+	"shutdown_timeout":            15 * time.Second,
+}
+
+// Имена переменных окружения сохранены прежними, чтобы не ломать .env и docker-compose
+var envBindings = map[string]string{
+	"http.port":                  "HTTP_PORT",
+	"grpc.port":                  "GRPC_PORT",
+	"metrics.port":               "METRICS_PORT",
+	"db.host":                    "DB_HOST",
+	"db.port":                    "DB_PORT",
+	"db.username":                "POSTGRES_USER",
+	"db.password":                "POSTGRES_PASSWORD",
+	"db.dbname":                  "POSTGRES_DB",
+	"db.sslmode":                 "SSL_MODE",
+	"jwt.algorithm":              "JWT_ALGORITHM",
+	"jwt.signing_key":            "SIGNING_KEY",
+	"jwt.access_token_ttl":       "JWT_ACCESS_TOKEN_TTL",
+	"jwt.refresh_token_ttl":      "JWT_REFRESH_TOKEN_TTL",
+	"jwt.sweep_interval":         "JWT_SWEEP_INTERVAL",
+	"log.level":                  "LOG_LEVEL",
+	"log.file":                   "LOG_FILE",
+	"idempotency.ttl":            "IDEMPOTENCY_TTL",
+	"idempotency.sweep_interval": "IDEMPOTENCY_SWEEP_INTERVAL",
+	"rbac.source":                "RBAC_SOURCE",
+	"rbac.file":                  "RBAC_FILE",
+	"outbox.publisher":           "OUTBOX_PUBLISHER",
+	"outbox.file":                "OUTBOX_FILE",
+	"outbox.relay_interval":      "OUTBOX_RELAY_INTERVAL",
+	"outbox.batch_size":          "OUTBOX_BATCH_SIZE",
+	"webhooks.timeout":           "WEBHOOKS_TIMEOUT",
+	"webhooks.max_attempts":      "WEBHOOKS_MAX_ATTEMPTS",
+	"live.heartbeat_interval":    "LIVE_HEARTBEAT_INTERVAL",
+	"receptions.stale_after":     "RECEPTIONS_STALE_AFTER",
+	"receptions.stale_action":    "RECEPTIONS_STALE_ACTION",
+	"receptions.reopen_window":   "RECEPTIONS_REOPEN_WINDOW",
+	"shutdown_timeout":           "SHUTDOWN_TIMEOUT",
+}
+
+var flagBindings = map[string]string{
+	"http.port":        "http-port",
+	"grpc.port":        "grpc-port",
+	"metrics.port":     "metrics-port",
+	"log.level":        "log-level",
+	"shutdown_timeout": "shutdown-timeout",
+}
+
+// Load собирает конфигурацию из файла, окружения (включая .env) и флагов
+// командной строки args. Приоритет: флаги > окружение > файл > значения по умолчанию.
+func Load(args []string) (Config, error) {
+	fs := pflag.NewFlagSet("pvz", pflag.ContinueOnError)
+	configPath := fs.String("config", defaultConfigPath, "path to the YAML config file")
+	fs.String("http-port", "", "HTTP server port")
+	fs.String("grpc-port", "", "gRPC server port")
+	fs.String("metrics-port", "", "metrics server port")
+	fs.String("log-level", "", "log level: debug, info, warn or error")
+	fs.Duration("shutdown-timeout", 0, "graceful shutdown timeout")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	// .env необязателен: в контейнере переменные приходят из окружения
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("loading .env: %w", err)
+	}
+
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	v.SetConfigFile(*configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return Config{}, fmt.Errorf("reading config file %s: %w", *configPath, err)
+	}
+
+	for key, env := range envBindings {
+		if err := v.BindEnv(key, env); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for key, name := range flagBindings {
+		flag := fs.Lookup(name)
+		if !flag.Changed {
+			continue
+		}
+		if err := v.BindPFlag(key, flag); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var cfg Config
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+		mapstructure.StringToSliceHookFunc(","),
+	))
+	if err := v.Unmarshal(&cfg, decodeHook); err != nil {
+		return Config{}, fmt.Errorf("decoding config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Validate проверяет обязательные поля и допустимость значений
+func (c Config) Validate() error {
+	var errs []error
+
+	checkPort := func(name, port string) {
+		if err := validatePort(port); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	checkRequired := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+	checkPositive := func(name string, value time.Duration) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", name))
+		}
+	}
+
+	checkPort("http.port", c.HTTP.Port)
+	checkPort("grpc.port", c.GRPC.Port)
+	checkPort("metrics.port", c.Metrics.Port)
+	checkPositive("http.read_timeout", c.HTTP.ReadTimeout)
+	checkPositive("http.write_timeout", c.HTTP.WriteTimeout)
+
+	checkRequired("db.host", c.DB.Host)
+	checkPort("db.port", c.DB.Port)
+	checkRequired("db.username", c.DB.Username)
+	checkRequired("db.dbname", c.DB.DBName)
+
+	switch c.JWT.Algorithm {
+	case "HS256":
+		checkRequired("jwt.signing_key", string(c.JWT.SigningKey))
+	case "RS256", "EdDSA":
+		errs = append(errs, c.JWT.validateKeys()...)
+	default:
+		errs = append(errs, fmt.Errorf("jwt.algorithm: unsupported algorithm %q", c.JWT.Algorithm))
+	}
+	checkPositive("jwt.access_token_ttl", c.JWT.AccessTokenTTL)
+	checkPositive("jwt.refresh_token_ttl", c.JWT.RefreshTokenTTL)
+	checkPositive("jwt.sweep_interval", c.JWT.SweepInterval)
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level: unknown level %q", c.Log.Level))
+	}
+
+	checkPositive("idempotency.ttl", c.Idempotency.TTL)
+	checkPositive("idempotency.sweep_interval", c.Idempotency.SweepInterval)
+
+	switch c.RBAC.Source {
+	case "builtin", "db":
+	case "file":
+		checkRequired("rbac.file", c.RBAC.File)
+	default:
+		errs = append(errs, fmt.Errorf("rbac.source: unknown source %q", c.RBAC.Source))
+	}
+
+	switch c.Outbox.Publisher {
+	case "stdout":
+	case "file":
+		checkRequired("outbox.file", c.Outbox.File)
+	default:
+		errs = append(errs, fmt.Errorf("outbox.publisher: unknown publisher %q", c.Outbox.Publisher))
+	}
+	if c.Outbox.BatchSize <= 0 {
+		errs = append(errs, errors.New("outbox.batch_size must be positive"))
+	}
+	checkPositive("outbox.relay_interval", c.Outbox.RelayInterval)
+	checkPositive("outbox.max_backoff", c.Outbox.MaxBackoff)
+	checkPositive("outbox.retention", c.Outbox.Retention)
+	checkPositive("outbox.sweep_interval", c.Outbox.SweepInterval)
+
+	if c.Webhooks.BatchSize <= 0 {
+		errs = append(errs, errors.New("webhooks.batch_size must be positive"))
+	}
+	if c.Webhooks.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("webhooks.max_attempts must be positive"))
+	}
+	checkPositive("webhooks.delivery_interval", c.Webhooks.DeliveryInterval)
+	checkPositive("webhooks.timeout", c.Webhooks.Timeout)
+	checkPositive("webhooks.max_backoff", c.Webhooks.MaxBackoff)
+
+	if c.Live.ReplayBuffer <= 0 {
+		errs = append(errs, errors.New("live.replay_buffer must be positive"))
+	}
+	if c.Live.ClientBuffer <= 0 {
+		errs = append(errs, errors.New("live.client_buffer must be positive"))
+	}
+	checkPositive("live.heartbeat_interval", c.Live.HeartbeatInterval)
+
+	switch c.Receptions.StaleAction {
+	case "close", "flag":
+	default:
+		errs = append(errs, fmt.Errorf("receptions.stale_action: unknown action %q", c.Receptions.StaleAction))
+	}
+	if c.Receptions.StaleBatchSize <= 0 {
+		errs = append(errs, errors.New("receptions.stale_batch_size must be positive"))
+	}
+	checkPositive("receptions.stale_after", c.Receptions.StaleAfter)
+	checkPositive("receptions.stale_interval", c.Receptions.StaleInterval)
+	checkPositive("receptions.reopen_window", c.Receptions.ReopenWindow)
+
+	checkPositive("shutdown_timeout", c.ShutdownTimeout)
+
+	return errors.Join(errs...)
+}
+
+func (c JWTConfig) validateKeys() []error {
+	var errs []error
+
+	if len(c.Keys) == 0 {
+		errs = append(errs, fmt.Errorf("jwt.keys: at least one key is required for %s", c.Algorithm))
+	}
+
+	seen := make(map[string]bool, len(c.Keys))
+	hasSigner := false
+	for i, key := range c.Keys {
+		switch {
+		case key.ID == "":
+			errs = append(errs, fmt.Errorf("jwt.keys[%d].kid is required", i))
+		case seen[key.ID]:
+			errs = append(errs, fmt.Errorf("jwt.keys[%d]: duplicate kid %q", i, key.ID))
+		}
+		seen[key.ID] = true
+
+		if key.PrivateKeyFile == "" && key.PublicKeyFile == "" {
+			errs = append(errs, fmt.Errorf("jwt.keys[%d]: private_key_file or public_key_file is required", i))
+		}
+		if key.PrivateKeyFile != "" {
+			hasSigner = true
+		}
+	}
+	if len(c.Keys) > 0 && !hasSigner {
+		errs = append(errs, errors.New("jwt.keys: at least one key needs private_key_file"))
+	}
+
+	return errs
+}
+
+func validatePort(port string) error {
+	if port == "" {
+		return errors.New("is required")
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package config_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pvz/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAML = `
+http:
+    port: "8080"
+grpc:
+    port: "3000"
+db:
+    host: "localhost"
+    username: "postgres"
+    dbname: "postgres"
+log:
+    level: "info"
+shutdown_timeout: "20s"
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_FileAndDefaults(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "secret")
+
+	cfg, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", cfg.HTTP.Port)
+	assert.Equal(t, "3000", cfg.GRPC.Port)
+	assert.Equal(t, "9000", cfg.Metrics.Port)
+	assert.Equal(t, 10*time.Second, cfg.HTTP.ReadTimeout)
+	assert.Equal(t, "5432", cfg.DB.Port)
+	assert.Equal(t, "disable", cfg.DB.SSLMode)
+	assert.Equal(t, config.Secret("secret"), cfg.JWT.SigningKey)
+	assert.Equal(t, 15*time.Minute, cfg.JWT.AccessTokenTTL)
+	assert.Equal(t, 30*24*time.Hour, cfg.JWT.RefreshTokenTTL)
+	assert.Equal(t, time.Hour, cfg.JWT.SweepInterval)
+	assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+	assert.Equal(t, 10*time.Minute, cfg.Idempotency.SweepInterval)
+	assert.Equal(t, "builtin", cfg.RBAC.Source)
+	assert.Equal(t, "stdout", cfg.Outbox.Publisher)
+	assert.Equal(t, 100, cfg.Outbox.BatchSize)
+	assert.Equal(t, 5*time.Minute, cfg.Outbox.MaxBackoff)
+	assert.Equal(t, 5*time.Second, cfg.Webhooks.Timeout)
+	assert.Equal(t, 10, cfg.Webhooks.MaxAttempts)
+	assert.Equal(t, 15*time.Second, cfg.Live.HeartbeatInterval)
+	assert.Equal(t, 256, cfg.Live.ReplayBuffer)
+	assert.Equal(t, 64, cfg.Live.ClientBuffer)
+	assert.Equal(t, 12*time.Hour, cfg.Receptions.StaleAfter)
+	assert.Equal(t, "close", cfg.Receptions.StaleAction)
+	assert.Equal(t, 5*time.Minute, cfg.Receptions.StaleInterval)
+	assert.Equal(t, 100, cfg.Receptions.StaleBatchSize)
+	assert.Equal(t, time.Hour, cfg.Receptions.ReopenWindow)
+	assert.Equal(t, 20*time.Second, cfg.ShutdownTimeout)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "secret")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("HTTP_PORT", "8081")
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("IDEMPOTENCY_TTL", "1h")
+
+	cfg, err := config.Load([]string{
+		"--config", writeConfig(t, testYAML),
+		"--http-port", "8082",
+	})
+	require.NoError(t, err)
+
+	// Флаг важнее окружения, окружение важнее файла
+	assert.Equal(t, "8082", cfg.HTTP.Port)
+	assert.Equal(t, "db", cfg.DB.Host)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, time.Hour, cfg.Idempotency.TTL)
+	assert.Equal(t, "3000", cfg.GRPC.Port)
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "")
+
+	_, err := config.Load([]string{
+		"--config", writeConfig(t, testYAML),
+		"--grpc-port", "70000",
+		"--log-level", "verbose",
+	})
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "jwt.signing_key is required")
+	assert.Contains(t, err.Error(), `grpc.port: invalid port "70000"`)
+	assert.Contains(t, err.Error(), `log.level: unknown level "verbose"`)
+}
+
+func TestLoad_RBACSource(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "secret")
+
+	t.Setenv("RBAC_SOURCE", "file")
+	_, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rbac.file is required")
+
+	t.Setenv("RBAC_FILE", "config/rbac.yaml")
+	cfg, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.NoError(t, err)
+	assert.Equal(t, "config/rbac.yaml", cfg.RBAC.File)
+
+	t.Setenv("RBAC_SOURCE", "ldap")
+	_, err = config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `rbac.source: unknown source "ldap"`)
+}
+
+func TestLoad_OutboxPublisher(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "secret")
+	t.Setenv("OUTBOX_PUBLISHER", "file")
+	t.Setenv("OUTBOX_BATCH_SIZE", "0")
+
+	_, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outbox.file is required")
+	assert.Contains(t, err.Error(), "outbox.batch_size must be positive")
+
+	t.Setenv("OUTBOX_FILE", "events.jsonl")
+	t.Setenv("OUTBOX_BATCH_SIZE", "10")
+	cfg, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.NoError(t, err)
+	assert.Equal(t, "events.jsonl", cfg.Outbox.File)
+	assert.Equal(t, 10, cfg.Outbox.BatchSize)
+}
+
+func TestLoad_WebhooksMaxAttempts(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "secret")
+	t.Setenv("WEBHOOKS_MAX_ATTEMPTS", "0")
+
+	_, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhooks.max_attempts must be positive")
+
+	t.Setenv("WEBHOOKS_MAX_ATTEMPTS", "3")
+	cfg, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.Webhooks.MaxAttempts)
+}
+
+func TestLoad_StaleReceptions(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "secret")
+	t.Setenv("RECEPTIONS_STALE_ACTION", "delete")
+	t.Setenv("RECEPTIONS_STALE_AFTER", "0s")
+
+	_, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `receptions.stale_action: unknown action "delete"`)
+	assert.Contains(t, err.Error(), "receptions.stale_after")
+
+	t.Setenv("RECEPTIONS_STALE_ACTION", "flag")
+	t.Setenv("RECEPTIONS_STALE_AFTER", "2h")
+	cfg, err := config.Load([]string{"--config", writeConfig(t, testYAML)})
+	require.NoError(t, err)
+	assert.Equal(t, "flag", cfg.Receptions.StaleAction)
+	assert.Equal(t, 2*time.Hour, cfg.Receptions.StaleAfter)
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := config.Load([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err)
+}
+
+func TestConfig_SecretsRedacted(t *testing.T) {
+	cfg := config.Config{
+		DB:  config.DBConfig{Host: "localhost", Password: "db-password"},
+		JWT: config.JWTConfig{SigningKey: "signing-key"},
+	}
+
+	formatted := []string{
+		cfg.String(),
+		fmt.Sprintf("%v", cfg),
+		fmt.Sprintf("%+v", cfg.DB),
+		fmt.Sprintf("%#v", cfg.JWT),
+	}
+	encoded, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	formatted = append(formatted, string(encoded))
+
+	for _, s := range formatted {
+		assert.NotContains(t, s, "db-password")
+		assert.NotContains(t, s, "signing-key")
+		assert.Contains(t, s, "***")
+	}
+	assert.Contains(t, cfg.String(), "localhost")
+}
+
+func TestLoad_AsymmetricKeys(t *testing.T) {
+	t.Setenv("SIGNING_KEY", "")
+
+	cfg, err := config.Load([]string{"--config", writeConfig(t, testYAML+`
+jwt:
+    algorithm: "RS256"
+    keys:
+        - kid: "old"
+          public_key_file: "keys/old.pub.pem"
+        - kid: "new"
+          private_key_file: "keys/new.pem"
+          active_from: "2024-07-01T00:00:00Z"
+`)})
+	require.NoError(t, err)
+
+	require.Len(t, cfg.JWT.Keys, 2)
+	assert.Equal(t, "RS256", cfg.JWT.Algorithm)
+	assert.Equal(t, "old", cfg.JWT.Keys[0].ID)
+	assert.Equal(t, "keys/new.pem", cfg.JWT.Keys[1].PrivateKeyFile)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), cfg.JWT.Keys[1].ActiveFrom)
+
+	_, err = config.Load([]string{"--config", writeConfig(t, testYAML+`
+jwt:
+    algorithm: "RS256"
+    keys:
+        - kid: "old"
+          public_key_file: "keys/old.pub.pem"
+        - kid: "old"
+`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `jwt.keys[1]: duplicate kid "old"`)
+	assert.Contains(t, err.Error(), "jwt.keys[1]: private_key_file or public_key_file is required")
+	assert.Contains(t, err.Error(), "jwt.keys: at least one key needs private_key_file")
+}
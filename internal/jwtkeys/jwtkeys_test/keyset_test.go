@@ -0,0 +1,258 @@
+package jwtkeys_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/jwtkeys"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func claims() jwt.StandardClaims {
+	return jwt.StandardClaims{Subject: "user", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+}
+
+func parse(t *testing.T, keys *jwtkeys.KeySet, token string) (*jwt.Token, error) {
+	t.Helper()
+	return jwt.ParseWithClaims(token, &jwt.StandardClaims{}, keys.Keyfunc)
+}
+
+// keyfuncError достаёт ошибку Keyfunc: ValidationError в jwt-go v3 не поддерживает errors.Is
+func keyfuncError(t *testing.T, err error) error {
+	t.Helper()
+	var validationErr *jwt.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	return validationErr.Inner
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		key       interface{}
+	}{
+		{name: "RS256", algorithm: jwtkeys.AlgRS256, key: newRSAKey(t)},
+		{name: "EdDSA", algorithm: jwtkeys.AlgEdDSA, key: newEd25519Key(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := jwtkeys.New(tt.algorithm, jwtkeys.NewKey("k1", time.Time{}, tt.key, nil))
+			require.NoError(t, err)
+
+			token, err := keys.Sign(claims())
+			require.NoError(t, err)
+
+			parsed, err := parse(t, keys, token)
+			require.NoError(t, err)
+			assert.True(t, parsed.Valid)
+			assert.Equal(t, tt.algorithm, parsed.Header["alg"])
+			assert.Equal(t, "k1", parsed.Header["kid"])
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, currentKey, nextKey := newRSAKey(t), newRSAKey(t), newRSAKey(t)
+	now := time.Now()
+
+	// Старый ключ выпустил токен до ротации
+	before, err := jwtkeys.New(jwtkeys.AlgRS256, jwtkeys.NewKey("old", now.Add(-48*time.Hour), oldKey, nil))
+	require.NoError(t, err)
+	oldToken, err := before.Sign(claims())
+	require.NoError(t, err)
+
+	keys, err := jwtkeys.New(jwtkeys.AlgRS256,
+		jwtkeys.NewKey("next", now.Add(24*time.Hour), nextKey, nil),
+		jwtkeys.NewKey("old", time.Time{}, nil, &oldKey.PublicKey),
+		jwtkeys.NewKey("current", now.Add(-time.Hour), currentKey, nil),
+	)
+	require.NoError(t, err)
+
+	// Будущий ключ ещё не подписывает
+	kid, err := keys.ActiveKeyID()
+	require.NoError(t, err)
+	assert.Equal(t, "current", kid)
+
+	token, err := keys.Sign(claims())
+	require.NoError(t, err)
+	parsed, err := parse(t, keys, token)
+	require.NoError(t, err)
+	assert.Equal(t, "current", parsed.Header["kid"])
+
+	// Токены старого ключа проверяются до его удаления из набора
+	_, err = parse(t, keys, oldToken)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_NoActiveKey(t *testing.T) {
+	keys, err := jwtkeys.New(jwtkeys.AlgEdDSA,
+		jwtkeys.NewKey("next", time.Now().Add(time.Hour), newEd25519Key(t), nil))
+	require.NoError(t, err)
+
+	_, err = keys.Sign(claims())
+	assert.ErrorIs(t, err, jwtkeys.ErrNoActiveKey)
+}
+
+func TestKeySet_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	keys, err := jwtkeys.New(jwtkeys.AlgRS256, jwtkeys.NewKey("k1", time.Time{}, rsaKey, nil))
+	require.NoError(t, err)
+
+	// HS256-токен, подписанный публичным ключом как секретом
+	publicDER := x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	forged.Header["kid"] = "k1"
+	token, err := forged.SignedString(publicDER)
+	require.NoError(t, err)
+
+	_, err = parse(t, keys, token)
+	assert.ErrorIs(t, keyfuncError(t, err), jwtkeys.ErrUnexpectedAlgorithm)
+
+	// И наоборот: HMAC-набор не принимает RS256
+	signed, err := keys.Sign(claims())
+	require.NoError(t, err)
+	_, err = parse(t, jwtkeys.NewHMAC([]byte("secret")), signed)
+	assert.ErrorIs(t, keyfuncError(t, err), jwtkeys.ErrUnexpectedAlgorithm)
+}
+
+func TestKeySet_UnknownKid(t *testing.T) {
+	signer, err := jwtkeys.New(jwtkeys.AlgEdDSA, jwtkeys.NewKey("foreign", time.Time{}, newEd25519Key(t), nil))
+	require.NoError(t, err)
+	keys, err := jwtkeys.New(jwtkeys.AlgEdDSA, jwtkeys.NewKey("k1", time.Time{}, newEd25519Key(t), nil))
+	require.NoError(t, err)
+
+	token, err := signer.Sign(claims())
+	require.NoError(t, err)
+
+	_, err = parse(t, keys, token)
+	assert.ErrorIs(t, keyfuncError(t, err), jwtkeys.ErrUnknownKey)
+}
+
+func TestNew_InvalidKeys(t *testing.T) {
+	rsaKey := newRSAKey(t)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		keys      []jwtkeys.Key
+	}{
+		{name: "unsupported algorithm", algorithm: "HS512", keys: []jwtkeys.Key{jwtkeys.NewKey("k1", time.Time{}, rsaKey, nil)}},
+		{name: "missing kid", algorithm: jwtkeys.AlgRS256, keys: []jwtkeys.Key{jwtkeys.NewKey("", time.Time{}, rsaKey, nil)}},
+		{name: "duplicate kid", algorithm: jwtkeys.AlgRS256, keys: []jwtkeys.Key{
+			jwtkeys.NewKey("k1", time.Time{}, rsaKey, nil),
+			jwtkeys.NewKey("k1", time.Time{}, nil, &rsaKey.PublicKey),
+		}},
+		{name: "key type mismatch", algorithm: jwtkeys.AlgEdDSA, keys: []jwtkeys.Key{jwtkeys.NewKey("k1", time.Time{}, rsaKey, nil)}},
+		{name: "verification keys only", algorithm: jwtkeys.AlgRS256, keys: []jwtkeys.Key{jwtkeys.NewKey("k1", time.Time{}, nil, &rsaKey.PublicKey)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwtkeys.New(tt.algorithm, tt.keys...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	keys, err := jwtkeys.New(jwtkeys.AlgRS256,
+		jwtkeys.NewKey("k1", time.Time{}, rsaKey, nil),
+		jwtkeys.NewKey("k2", time.Now().Add(time.Hour), newRSAKey(t), nil),
+	)
+	require.NoError(t, err)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "k1", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "sig", jwks.Keys[0].Use)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+	// Будущий ключ публикуется заранее
+	assert.Equal(t, "k2", jwks.Keys[1].Kid)
+
+	edKeys, err := jwtkeys.New(jwtkeys.AlgEdDSA, jwtkeys.NewKey("ed", time.Time{}, newEd25519Key(t), nil))
+	require.NoError(t, err)
+	edJWKS := edKeys.JWKS()
+	require.Len(t, edJWKS.Keys, 1)
+	assert.Equal(t, "OKP", edJWKS.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", edJWKS.Keys[0].Crv)
+	assert.NotEmpty(t, edJWKS.Keys[0].X)
+
+	// Симметричный ключ не публикуется
+	assert.Empty(t, jwtkeys.NewHMAC([]byte("secret")).JWKS().Keys)
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestLoad_PEMFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey := newRSAKey(t)
+	oldKey := newRSAKey(t)
+	oldPublic, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	require.NoError(t, err)
+
+	keys, err := jwtkeys.Load(jwtkeys.AlgRS256, []jwtkeys.KeyConfig{
+		{ID: "old", PublicKeyFile: writePEM(t, dir, "old.pub.pem", "PUBLIC KEY", oldPublic)},
+		{
+			ID:             "current",
+			PrivateKeyFile: writePEM(t, dir, "current.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+			ActiveFrom:     time.Now().Add(-time.Hour),
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, keys.JWKS().Keys, 2)
+
+	kid, err := keys.ActiveKeyID()
+	require.NoError(t, err)
+	assert.Equal(t, "current", kid)
+
+	edKey := newEd25519Key(t)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	edKeys, err := jwtkeys.Load(jwtkeys.AlgEdDSA, []jwtkeys.KeyConfig{
+		{ID: "ed", PrivateKeyFile: writePEM(t, dir, "ed.pem", "PRIVATE KEY", edDER)},
+	})
+	require.NoError(t, err)
+
+	token, err := edKeys.Sign(claims())
+	require.NoError(t, err)
+	_, err = parse(t, edKeys, token)
+	assert.NoError(t, err)
+
+	_, err = jwtkeys.Load(jwtkeys.AlgRS256, []jwtkeys.KeyConfig{{ID: "missing", PrivateKeyFile: filepath.Join(dir, "missing.pem")}})
+	assert.Error(t, err)
+}
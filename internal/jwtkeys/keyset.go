@@ -0,0 +1,177 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnexpectedAlgorithm = errors.New("unexpected signing algorithm")
+	ErrUnknownKey          = errors.New("unknown key id")
+	ErrNoActiveKey         = errors.New("no active signing key")
+)
+
+// Идентификатор единственного ключа в HMAC-наборе
+const hmacKeyID = "hs256"
+
+// Key - ключ подписи с идентификатором kid. Ключ без приватной части
+// используется только для проверки токенов.
+type Key struct {
+	ID         string
+	ActiveFrom time.Time
+	private    crypto.PrivateKey
+	public     crypto.PublicKey
+}
+
+// NewKey создаёт ключ. public можно не передавать, если известен private.
+func NewKey(id string, activeFrom time.Time, private crypto.PrivateKey, public crypto.PublicKey) Key {
+	if public == nil {
+		if signer, ok := private.(crypto.Signer); ok {
+			public = signer.Public()
+		}
+	}
+	return Key{ID: id, ActiveFrom: activeFrom, private: private, public: public}
+}
+
+// KeySet - ключи одного алгоритма. Подписывает самый свежий из уже
+// активных ключей, а проверяет токены любым ключом набора, поэтому новый
+// ключ можно опубликовать в JWKS заранее, а старый - держать до истечения
+// выпущенных им токенов.
+type KeySet struct {
+	method jwt.SigningMethod
+	keys   []Key // по возрастанию ActiveFrom
+}
+
+// NewHMAC возвращает набор из одного симметричного HS256-ключа
+func NewHMAC(secret []byte) *KeySet {
+	return &KeySet{
+		method: jwt.SigningMethodHS256,
+		keys:   []Key{{ID: hmacKeyID, private: secret, public: secret}},
+	}
+}
+
+// New собирает набор асимметричных ключей алгоритма RS256 или EdDSA
+func New(algorithm string, keys ...Key) (*KeySet, error) {
+	var method jwt.SigningMethod
+	switch algorithm {
+	case AlgRS256:
+		method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		method = SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedAlgorithm, algorithm)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	hasSigner := false
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("key id is required")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		seen[key.ID] = true
+
+		if err := checkKeyType(algorithm, key); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		if key.private != nil {
+			hasSigner = true
+		}
+	}
+	if !hasSigner {
+		return nil, errors.New("at least one key with a private part is required")
+	}
+
+	sorted := append([]Key(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+
+	return &KeySet{method: method, keys: sorted}, nil
+}
+
+func checkKeyType(algorithm string, key Key) error {
+	var privateOk, publicOk bool
+	switch algorithm {
+	case AlgRS256:
+		_, privateOk = key.private.(*rsa.PrivateKey)
+		_, publicOk = key.public.(*rsa.PublicKey)
+	case AlgEdDSA:
+		_, privateOk = key.private.(ed25519.PrivateKey)
+		_, publicOk = key.public.(ed25519.PublicKey)
+	}
+
+	if key.private != nil && !privateOk {
+		return fmt.Errorf("private key does not match algorithm %s", algorithm)
+	}
+	if !publicOk {
+		return fmt.Errorf("public key does not match algorithm %s", algorithm)
+	}
+	return nil
+}
+
+// Algorithm возвращает значение alg, которым подписываются токены
+func (s *KeySet) Algorithm() string {
+	return s.method.Alg()
+}
+
+// Sign подписывает claims текущим ключом и указывает его kid в заголовке
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key, err := s.current(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.private)
+}
+
+// ActiveKeyID возвращает kid ключа, которым сейчас подписываются токены
+func (s *KeySet) ActiveKeyID() (string, error) {
+	key, err := s.current(time.Now())
+	return key.ID, err
+}
+
+func (s *KeySet) current(now time.Time) (Key, error) {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		key := s.keys[i]
+		if key.private != nil && !key.ActiveFrom.After(now) {
+			return key, nil
+		}
+	}
+	return Key{}, ErrNoActiveKey
+}
+
+// Keyfunc для jwt.Parse. Принимает только настроенный алгоритм: иначе токен
+// с alg=HS256, подписанный публичным RSA-ключом как секретом, прошёл бы проверку.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != s.method.Alg() {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedAlgorithm, token.Method.Alg())
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key.public, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
@@ -0,0 +1,96 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// KeyConfig описывает ключ в PEM-файлах. Если задан только публичный ключ,
+// он используется лишь для проверки подписи (например, ключ, выведенный из ротации).
+type KeyConfig struct {
+	ID             string
+	PrivateKeyFile string
+	PublicKeyFile  string
+	ActiveFrom     time.Time
+}
+
+// Load читает ключи из PEM-файлов и собирает набор для алгоритма RS256 или EdDSA
+func Load(algorithm string, configs []KeyConfig) (*KeySet, error) {
+	keys := make([]Key, 0, len(configs))
+
+	for _, cfg := range configs {
+		var (
+			private crypto.PrivateKey
+			public  crypto.PublicKey
+			err     error
+		)
+
+		if cfg.PrivateKeyFile != "" {
+			if private, err = readPrivateKey(cfg.PrivateKeyFile); err != nil {
+				return nil, fmt.Errorf("key %q: %w", cfg.ID, err)
+			}
+		}
+		if cfg.PublicKeyFile != "" {
+			if public, err = readPublicKey(cfg.PublicKeyFile); err != nil {
+				return nil, fmt.Errorf("key %q: %w", cfg.ID, err)
+			}
+		}
+		if private == nil && public == nil {
+			return nil, fmt.Errorf("key %q: private_key_file or public_key_file is required", cfg.ID)
+		}
+
+		keys = append(keys, NewKey(cfg.ID, cfg.ActiveFrom, private, public))
+	}
+
+	return New(algorithm, keys...)
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New(path + ": no PEM data found")
+	}
+	return block, nil
+}
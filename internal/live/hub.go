@@ -0,0 +1,210 @@
+package live
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+	"pvz/metrics"
+)
+
+// Message - событие в потоке ПВЗ. Id растёт в пределах ПВЗ, клиент
+// передаёт его в Last-Event-ID, чтобы продолжить поток после переподключения.
+type Message struct {
+	Id    string
+	Event model.Event
+	seq   uint64
+}
+
+// Config - параметры хаба
+type Config struct {
+	// ReplayBuffer - сколько последних событий каждого ПВЗ хранится для продолжения потока
+	ReplayBuffer int
+	// ClientBuffer - сколько событий может ждать отправки одному клиенту.
+	// Клиент, который не успевает их забирать, отключается.
+	ClientBuffer int
+	// HeartbeatInterval - как часто слать клиенту ping, пока нет событий
+	HeartbeatInterval time.Duration
+}
+
+// Hub рассылает события ПВЗ подписчикам внутри процесса. Publish не
+// блокируется: медленный подписчик отключается и продолжает поток
+// с Last-Event-ID. Реплики друг о друге не знают, поэтому подписчик получает
+// только события, прошедшие через этот инстанс.
+type Hub struct {
+	cfg    Config
+	logger logger.Logger
+	// epoch отличает Id событий разных запусков: после рестарта
+	// старый Last-Event-ID не совпадёт с новыми номерами
+	epoch string
+
+	mu      sync.Mutex
+	streams map[uuid.UUID]*stream
+	closed  bool
+}
+
+type stream struct {
+	seq         uint64
+	replay      []Message // последние события по возрастанию seq
+	subscribers map[*Subscription]struct{}
+}
+
+func NewHub(cfg Config, log logger.Logger) *Hub {
+	return &Hub{
+		cfg:     cfg,
+		logger:  log,
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		streams: make(map[uuid.UUID]*stream),
+	}
+}
+
+// Publish рассылает событие подписчикам ПВЗ и сохраняет его в буфер
+func (h *Hub) Publish(event model.Event) {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+
+	st := h.stream(event.PvzId)
+	st.seq++
+	msg := Message{Id: h.epoch + "-" + strconv.FormatUint(st.seq, 10), Event: event, seq: st.seq}
+
+	if h.cfg.ReplayBuffer > 0 {
+		if len(st.replay) == h.cfg.ReplayBuffer {
+			st.replay = append(st.replay[:0], st.replay[1:]...)
+		}
+		st.replay = append(st.replay, msg)
+	}
+
+	var dropped int
+	for sub := range st.subscribers {
+		select {
+		case sub.ch <- msg:
+		default:
+			h.unsubscribe(st, sub)
+			dropped++
+		}
+	}
+	h.mu.Unlock()
+
+	if dropped > 0 {
+		metrics.LiveSubscribersDropped.Add(float64(dropped))
+		h.logger.Warnw("Slow live subscribers dropped", "pvzId", event.PvzId, "count", dropped)
+	}
+}
+
+// Subscribe подписывает на события ПВЗ. С непустым lastEventId в Replay
+// попадают события из буфера после него. Resync означает, что продолжить
+// поток нельзя - события вытеснены из буфера или Id не из этого запуска, -
+// и клиенту нужно заново загрузить состояние ПВЗ.
+func (h *Hub) Subscribe(pvzId uuid.UUID, lastEventId string) *Subscription {
+	sub := &Subscription{
+		Heartbeat: h.cfg.HeartbeatInterval,
+		hub:       h,
+		pvzId:     pvzId,
+		ch:        make(chan Message, h.cfg.ClientBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(sub.ch)
+		return sub
+	}
+
+	st := h.stream(pvzId)
+	if lastEventId != "" {
+		sub.Replay, sub.Resync = h.replaySince(st, lastEventId)
+	}
+	st.subscribers[sub] = struct{}{}
+	metrics.LiveSubscribers.Inc()
+
+	return sub
+}
+
+// Close отключает всех подписчиков. Вызывается при остановке сервера,
+// иначе открытые потоки не дали бы ему завершиться.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, st := range h.streams {
+		for sub := range st.subscribers {
+			h.unsubscribe(st, sub)
+		}
+	}
+}
+
+func (h *Hub) replaySince(st *stream, lastEventId string) ([]Message, bool) {
+	epoch, seqPart, ok := strings.Cut(lastEventId, "-")
+	if !ok || epoch != h.epoch {
+		return nil, true
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil || seq > st.seq {
+		return nil, true
+	}
+	if seq == st.seq {
+		return nil, false
+	}
+	// Следующее событие после seq уже вытеснено из буфера
+	if len(st.replay) == 0 || st.replay[0].seq > seq+1 {
+		return nil, true
+	}
+
+	start := int(seq + 1 - st.replay[0].seq)
+	return append([]Message(nil), st.replay[start:]...), false
+}
+
+func (h *Hub) stream(pvzId uuid.UUID) *stream {
+	st, ok := h.streams[pvzId]
+	if !ok {
+		st = &stream{subscribers: make(map[*Subscription]struct{})}
+		h.streams[pvzId] = st
+	}
+	return st
+}
+
+// unsubscribe удаляет подписчика и закрывает его канал. Вызывается под h.mu.
+func (h *Hub) unsubscribe(st *stream, sub *Subscription) {
+	if _, ok := st.subscribers[sub]; !ok {
+		return
+	}
+	delete(st.subscribers, sub)
+	close(sub.ch)
+	metrics.LiveSubscribers.Dec()
+}
+
+// Subscription - подписка на события одного ПВЗ. Сначала отправляются Replay,
+// затем события из Messages. Закрытие Messages означает, что хаб отключил
+// подписчика: клиент отстал или сервер останавливается.
+type Subscription struct {
+	Replay    []Message
+	Resync    bool
+	Heartbeat time.Duration
+
+	hub   *Hub
+	pvzId uuid.UUID
+	ch    chan Message
+}
+
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+// Close отменяет подписку. Повторный вызов ничего не делает.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if st, ok := s.hub.streams[s.pvzId]; ok {
+		s.hub.unsubscribe(st, s)
+	}
+}
@@ -0,0 +1,150 @@
+package live_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/live"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+func newHub(replayBuffer, clientBuffer int, log *mocks.MockLogger) *live.Hub {
+	return live.NewHub(live.Config{ReplayBuffer: replayBuffer, ClientBuffer: clientBuffer, HeartbeatInterval: time.Second}, log)
+}
+
+func publish(hub *live.Hub, pvzId uuid.UUID, n int) {
+	for i := 0; i < n; i++ {
+		hub.Publish(model.Event{Id: uuid.New(), Type: model.EventProductAdded, PvzId: pvzId})
+	}
+}
+
+func drain(sub *live.Subscription) []live.Message {
+	var messages []live.Message
+	for {
+		select {
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return messages
+			}
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestHub_ResumeFromLastEventId(t *testing.T) {
+	hub := newHub(10, 10, new(mocks.MockLogger))
+	pvzId := uuid.New()
+
+	first := hub.Subscribe(pvzId, "")
+	publish(hub, pvzId, 3)
+	received := drain(first)
+	require.Len(t, received, 3)
+	first.Close()
+
+	// Пока клиент переподключался, пришло ещё два события
+	publish(hub, pvzId, 2)
+
+	resumed := hub.Subscribe(pvzId, received[0].Id)
+	defer resumed.Close()
+	assert.False(t, resumed.Resync)
+	require.Len(t, resumed.Replay, 4)
+	assert.Equal(t, received[1].Id, resumed.Replay[0].Id)
+	assert.Empty(t, drain(resumed))
+
+	// Новые события идут в канал после буфера
+	publish(hub, pvzId, 1)
+	latest := drain(resumed)
+	require.Len(t, latest, 1)
+
+	upToDate := hub.Subscribe(pvzId, latest[0].Id)
+	defer upToDate.Close()
+	assert.False(t, upToDate.Resync)
+	assert.Empty(t, upToDate.Replay)
+}
+
+func TestHub_Resync(t *testing.T) {
+	hub := newHub(2, 10, new(mocks.MockLogger))
+	pvzId := uuid.New()
+
+	sub := hub.Subscribe(pvzId, "")
+	publish(hub, pvzId, 1)
+	lastId := drain(sub)[0].Id
+	sub.Close()
+
+	// Событие после lastId вытеснено из буфера
+	publish(hub, pvzId, 3)
+
+	tests := []struct {
+		name        string
+		lastEventId string
+	}{
+		{name: "evicted", lastEventId: lastId},
+		{name: "other process", lastEventId: "0-1"},
+		{name: "from the future", lastEventId: lastId[:len(lastId)-1] + "9"},
+		{name: "malformed", lastEventId: "garbage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resumed := hub.Subscribe(pvzId, tt.lastEventId)
+			defer resumed.Close()
+			assert.True(t, resumed.Resync)
+			assert.Empty(t, resumed.Replay)
+		})
+	}
+}
+
+func TestHub_DropsSlowSubscriber(t *testing.T) {
+	log := new(mocks.MockLogger)
+	hub := newHub(10, 2, log)
+	pvzId := uuid.New()
+
+	slow := hub.Subscribe(pvzId, "")
+	fast := hub.Subscribe(pvzId, "")
+	defer fast.Close()
+
+	log.On("Warnw", "Slow live subscribers dropped", "pvzId", pvzId, "count", 1).Once()
+
+	publish(hub, pvzId, 2)
+	assert.Len(t, drain(fast), 2)
+
+	// Третье событие не помещается в буфер slow: хаб закрывает его канал, не блокируясь
+	publish(hub, pvzId, 1)
+	assert.Len(t, drain(fast), 1)
+
+	messages := drain(slow)
+	assert.Len(t, messages, 2)
+	_, open := <-slow.Messages()
+	assert.False(t, open)
+	slow.Close()
+
+	// Отставший клиент продолжает поток с последнего полученного события
+	resumed := hub.Subscribe(pvzId, messages[1].Id)
+	defer resumed.Close()
+	assert.Len(t, resumed.Replay, 1)
+	log.AssertExpectations(t)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := newHub(10, 10, new(mocks.MockLogger))
+	pvzId := uuid.New()
+
+	sub := hub.Subscribe(pvzId, "")
+	hub.Close()
+
+	_, open := <-sub.Messages()
+	assert.False(t, open)
+	sub.Close()
+
+	// После остановки новые подписки сразу закрыты, а события не рассылаются
+	publish(hub, pvzId, 1)
+	late := hub.Subscribe(pvzId, "")
+	_, open = <-late.Messages()
+	assert.False(t, open)
+}
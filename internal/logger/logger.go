@@ -26,10 +26,20 @@ var (
 	Log Logger
 )
 
+type Config struct {
+	Level string
+	File  string
+}
+
 // Init инициализирует глобальный логгер
-func Init() error {
+func Init(cfg Config) error {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+
 	logFile := &lumberjack.Logger{
-		Filename:   "log/app.log",
+		Filename:   cfg.File,
 		MaxSize:    1,
 		MaxBackups: 3,
 		MaxAge:     7,
@@ -52,8 +62,8 @@ func Init() error {
 	fileEncoder := zapcore.NewConsoleEncoder(fileEncoderConfig)
 
 	core := zapcore.NewTee(
-		zapcore.NewCore(fileEncoder, zapcore.AddSync(logFile), zapcore.InfoLevel),
-		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel),
+		zapcore.NewCore(fileEncoder, zapcore.AddSync(logFile), level),
+		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), level),
 	)
 
 	zapLogger := zap.New(core, zap.AddCaller())
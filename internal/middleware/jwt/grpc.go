@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"pvz/internal/logger"
+	"pvz/internal/rbac"
+)
+
+// UnaryAuthInterceptor - gRPC-аналог Authorize.
+// rules сопоставляет полное имя метода с необходимым разрешением;
+// методы, отсутствующие в rules, отклоняются.
+func (a *Auth) UnaryAuthInterceptor(rules map[string]rbac.Permission) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		permission, ok := rules[info.FullMethod]
+		if !ok {
+			logger.Log.Warnw("Access forbidden for unknown method", "method", info.FullMethod)
+			return nil, status.Error(codes.PermissionDenied, "access denied")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || values[0] == "" {
+			logger.Log.Warnw("Authorization metadata missing", "method", info.FullMethod)
+			return nil, status.Error(codes.Unauthenticated, "missing token")
+		}
+
+		claims, err := a.Authenticate(ctx, values[0])
+		if err != nil {
+			if isTokenError(err) {
+				logger.Log.Warnw("Invalid or expired token", "method", info.FullMethod, "error", err)
+				return nil, status.Error(codes.Unauthenticated, "invalid token")
+			}
+			logger.Log.Errorw("Failed to verify token", "method", info.FullMethod, "error", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		if !a.Allowed(claims, permission) {
+			logger.Log.Warnw("Access forbidden", "permission", permission, "claims", claims)
+			return nil, status.Error(codes.PermissionDenied, "access denied")
+		}
+
+		logger.Log.Infow("Token verified", "userId", claims.UserId, "role", claims.Role)
+		return handler(ContextWithClaims(ctx, claims), req)
+	}
+}
@@ -1,58 +1,167 @@
 package jwt
 
 import (
-	"net/http"
-	"os"
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/jwtkeys"
 	"pvz/internal/logger"
+	"pvz/internal/rbac"
 	"pvz/internal/repository/model"
 )
 
-func AuthMiddleware(roles ...string) gin.HandlerFunc {
+var (
+	ErrInvalidTokenFormat = errors.New("invalid token format")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidClaims      = errors.New("invalid token claims")
+	ErrTokenRevoked       = errors.New("token revoked")
+)
+
+type claimsKey struct{}
+
+// ClaimsFromContext возвращает данные токена, сохранённые Authorize
+// или gRPC-перехватчиком
+func ClaimsFromContext(ctx context.Context) (*model.TokenClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*model.TokenClaims)
+	return claims, ok
+}
+
+// ContextWithClaims сохраняет данные токена в контексте
+func ContextWithClaims(ctx context.Context, claims *model.TokenClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// RevocationChecker сообщает, отозван ли токен с данным jti
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// Auth проверяет подпись JWT-токенов ключами из keys и их отзыв,
+// а права роли из токена - по политике policy
+type Auth struct {
+	keys        *jwtkeys.KeySet
+	revocations RevocationChecker
+	policy      *rbac.Policy
+}
+
+func NewAuth(keys *jwtkeys.KeySet, revocations RevocationChecker, policy *rbac.Policy) *Auth {
+	return &Auth{
+		keys:        keys,
+		revocations: revocations,
+		policy:      policy,
+	}
+}
+
+// Keys возвращает набор ключей, например для публикации JWKS
+func (a *Auth) Keys() *jwtkeys.KeySet {
+	return a.keys
+}
+
+// ParseToken извлекает токен из значения заголовка Authorization и проверяет его подпись
+func (a *Auth) ParseToken(authHeader string) (*model.TokenClaims, error) {
+	tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenStr == authHeader {
+		return nil, ErrInvalidTokenFormat
+	}
+
+	token, err := jwt.ParseWithClaims(tokenStr, &model.TokenClaims{}, a.keys.Keyfunc)
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(*model.TokenClaims)
+	if !ok {
+		return nil, ErrInvalidClaims
+	}
+	if _, err := uuid.Parse(claims.Id); err != nil {
+		return nil, ErrInvalidClaims
+	}
+
+	return claims, nil
+}
+
+// Authenticate разбирает токен и проверяет, что он не был отозван.
+// Ограничение роли назначенными ПВЗ записывается в claims.Unscoped.
+func (a *Auth) Authenticate(ctx context.Context, authHeader string) (*model.TokenClaims, error) {
+	claims, err := a.ParseToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := a.revocations.IsRevoked(ctx, claims.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	claims.Unscoped = a.Allowed(claims, rbac.PvzUnscoped)
+	return claims, nil
+}
+
+// Allowed проверяет, что роли из токена разрешено действие permission
+func (a *Auth) Allowed(claims *model.TokenClaims, permission rbac.Permission) bool {
+	return a.policy.Allowed(claims.Role, permission)
+}
+
+// isTokenError отличает отказ в доступе из-за самого токена от сбоя проверки отзыва
+func isTokenError(err error) bool {
+	return errors.Is(err, ErrInvalidTokenFormat) || errors.Is(err, ErrInvalidToken) ||
+		errors.Is(err, ErrInvalidClaims) || errors.Is(err, ErrTokenRevoked)
+}
+
+// Authorize пропускает запрос с действительным токеном, роли которого
+// разрешено действие permission
+func (a *Auth) Authorize(permission rbac.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			logger.Log.Warnw("Authorization header missing")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
-			return
-		}
-
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenStr == authHeader {
-			logger.Log.Warnw("Token format is invalid", "token", tokenStr)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
+			c.Error(apperror.Unauthorized("missing token"))
+			c.Abort()
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(tokenStr, &model.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("SIGNING_KEY")), nil
-		})
-		if err != nil || !token.Valid {
-			logger.Log.Warnw("Invalid or expired token", "error", err)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		claims, err := a.Authenticate(c, authHeader)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrInvalidTokenFormat):
+				logger.Log.Warnw("Token format is invalid", "token", authHeader)
+			case errors.Is(err, ErrInvalidClaims):
+				logger.Log.Warnw("Invalid token claims")
+			case errors.Is(err, ErrTokenRevoked):
+				logger.Log.Warnw("Revoked token used")
+			case errors.Is(err, ErrInvalidToken):
+				logger.Log.Warnw("Invalid or expired token", "error", err)
+				err = ErrInvalidToken
+			default:
+				logger.Log.Errorw("Failed to verify token", "error", err)
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			c.Error(apperror.Unauthorized(err.Error()))
+			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(*model.TokenClaims)
-		if !ok {
-			logger.Log.Warnw("Invalid token claims")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
+		if a.Allowed(claims, permission) {
+			logger.Log.Infow("Token verified", "userId", claims.UserId, "role", claims.Role)
+			c.Set("userClaims", claims)
+			c.Request = c.Request.WithContext(ContextWithClaims(c.Request.Context(), claims))
+			c.Next()
 			return
 		}
 
-		for _, role := range roles {
-			if claims.Role == role {
-				logger.Log.Infow("Token verified", "userId", claims.UserId, "role", claims.Role)
-				c.Set("userClaims", claims)
-				c.Next()
-				return
-			}
-		}
-
-		logger.Log.Warnw("Access forbidden", "allowedRoles", roles, "claims", claims)
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		logger.Log.Warnw("Access forbidden", "permission", permission, "claims", claims)
+		c.Error(apperror.Forbidden("access denied"))
+		c.Abort()
 	}
 }
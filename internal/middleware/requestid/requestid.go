@@ -0,0 +1,37 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Header - заголовок с идентификатором запроса в запросе и ответе
+const Header = "X-Request-Id"
+
+// Идентификатор клиента длиннее этого заменяется сгенерированным
+const maxLength = 128
+
+type requestIdKey struct{}
+
+// FromContext возвращает идентификатор запроса или пустую строку
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// Middleware берёт идентификатор из заголовка X-Request-Id или генерирует
+// новый, возвращает его в ответе и сохраняет в контексте запроса
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if id == "" || len(id) > maxLength {
+			id = uuid.NewString()
+		}
+
+		c.Header(Header, id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIdKey{}, id))
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package outbox_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/outbox"
+	"pvz/internal/repository/model"
+)
+
+func event(eventType string) model.Event {
+	return model.Event{Id: uuid.New(), Type: eventType, PvzId: uuid.New(), Payload: json.RawMessage(`{"status":"close"}`)}
+}
+
+func TestWriterPublisher_JSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := outbox.NewWriterPublisher(&buf)
+
+	first, second := event(model.EventReceptionOpened), event(model.EventReceptionClosed)
+	require.NoError(t, publisher.Publish(context.Background(), first))
+	require.NoError(t, publisher.Publish(context.Background(), second))
+
+	scanner := bufio.NewScanner(&buf)
+	var got []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		got = append(got, line)
+	}
+
+	require.Len(t, got, 2)
+	assert.Equal(t, first.Id.String(), got[0]["id"])
+	assert.Equal(t, model.EventReceptionOpened, got[0]["type"])
+	assert.Equal(t, first.PvzId.String(), got[0]["pvzId"])
+	assert.Equal(t, map[string]interface{}{"status": "close"}, got[0]["payload"])
+	assert.Equal(t, model.EventReceptionClosed, got[1]["type"])
+	assert.NoError(t, publisher.Close())
+}
+
+func TestFilePublisher_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o644))
+
+	publisher, err := outbox.NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), event(model.EventPvzCreated)))
+	require.NoError(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+	assert.Contains(t, string(data), model.EventPvzCreated)
+}
+
+func TestMemoryPublisher_SetError(t *testing.T) {
+	publisher := outbox.NewMemoryPublisher()
+	publishErr := errors.New("unavailable")
+
+	publisher.SetError(publishErr)
+	assert.ErrorIs(t, publisher.Publish(context.Background(), event(model.EventProductAdded)), publishErr)
+	assert.Empty(t, publisher.Events())
+
+	publisher.SetError(nil)
+	require.NoError(t, publisher.Publish(context.Background(), event(model.EventProductAdded)))
+	assert.Len(t, publisher.Events(), 1)
+}
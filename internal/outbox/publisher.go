@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"pvz/internal/repository/model"
+)
+
+// Publisher доставляет доменные события внешним системам. Ошибка означает,
+// что событие не доставлено, и его публикация будет повторена.
+type Publisher interface {
+	Publish(ctx context.Context, event model.Event) error
+}
+
+// WriterPublisher пишет события в w по одному JSON-объекту на строку
+type WriterPublisher struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// NewFilePublisher дописывает события в файл path. Файл закрывает Close.
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening events file: %w", err)
+	}
+	return &WriterPublisher{w: file, closer: file}, nil
+}
+
+func (p *WriterPublisher) Publish(ctx context.Context, event model.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает файл, открытый NewFilePublisher. Переданный в
+// NewWriterPublisher writer не закрывается.
+func (p *WriterPublisher) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// MemoryPublisher хранит опубликованные события в памяти, например для тестов
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []model.Event
+	err    error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// SetError заставляет Publish возвращать err; nil снова включает доставку
+func (p *MemoryPublisher) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event model.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events возвращает опубликованные события в порядке публикации
+func (p *MemoryPublisher) Events() []model.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]model.Event(nil), p.events...)
+}
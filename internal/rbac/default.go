@@ -0,0 +1,41 @@
+package rbac
+
+import "pvz/internal/repository/model"
+
+// defaultRoles повторяет права, которые раньше были зашиты в маршруты,
+// и добавляет администратора и аудитора (только чтение и журнал аудита)
+var defaultRoles = map[string][]Permission{
+	model.RoleEmployee: {
+		PvzRead,
+		ReceptionCreate, ReceptionRead, ReceptionClose, ReceptionCancel,
+		ProductCreate, ProductRead, ProductUpdate, ProductDelete,
+		CatalogRead,
+		SessionLogout,
+	},
+	model.RoleModerator: {
+		PvzCreate, PvzRead, PvzUpdate, PvzDelete, PvzUnscoped,
+		ReceptionRead, ReceptionVerify, ReceptionReopen,
+		ProductRead,
+		CatalogRead, CatalogManage,
+		AuditRead,
+		AssignmentRead, AssignmentManage,
+		WebhookRead, WebhookManage,
+		SessionLogout,
+	},
+	model.RoleAdmin: {All},
+	model.RoleAuditor: {
+		PvzRead,
+		ReceptionRead,
+		ProductRead,
+		CatalogRead,
+		AuditRead,
+		AssignmentRead,
+		WebhookRead,
+		SessionLogout,
+	},
+}
+
+// Default возвращает встроенную политику
+func Default() *Policy {
+	return newPolicy(defaultRoles)
+}
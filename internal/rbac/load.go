@@ -0,0 +1,36 @@
+package rbac
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig - формат файла политики:
+//
+//	roles:
+//	    employee: ["pvz:read", "reception:create"]
+//	    admin: ["*"]
+type fileConfig struct {
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// LoadFile читает политику из YAML-файла
+func LoadFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rbac policy: %w", err)
+	}
+
+	var cfg fileConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing rbac policy %s: %w", path, err)
+	}
+
+	policy, err := New(cfg.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("rbac policy %s: %w", path, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,133 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Permission - действие над ресурсом в виде "ресурс:действие"
+type Permission string
+
+const (
+	PvzCreate Permission = "pvz:create"
+	PvzRead   Permission = "pvz:read"
+	PvzUpdate Permission = "pvz:update"
+	PvzDelete Permission = "pvz:delete"
+	// PvzUnscoped - работа с любым ПВЗ без назначения. Без него пользователь
+	// меняет данные только назначенных ему ПВЗ.
+	PvzUnscoped Permission = "pvz:unscoped"
+
+	ReceptionCreate Permission = "reception:create"
+	ReceptionRead   Permission = "reception:read"
+	ReceptionClose  Permission = "reception:close"
+	ReceptionCancel Permission = "reception:cancel"
+	ReceptionVerify Permission = "reception:verify"
+	ReceptionReopen Permission = "reception:reopen"
+
+	ProductCreate Permission = "product:create"
+	ProductRead   Permission = "product:read"
+	ProductUpdate Permission = "product:update"
+	ProductDelete Permission = "product:delete"
+
+	CatalogRead   Permission = "catalog:read"
+	CatalogManage Permission = "catalog:manage"
+
+	AuditRead Permission = "audit:read"
+
+	AssignmentRead   Permission = "assignment:read"
+	AssignmentManage Permission = "assignment:manage"
+
+	WebhookRead   Permission = "webhook:read"
+	WebhookManage Permission = "webhook:manage"
+
+	SessionLogout Permission = "session:logout"
+
+	UserCreate Permission = "user:create"
+)
+
+// All - разрешение на любое действие, например для администратора
+const All Permission = "*"
+
+var ErrUnknownPermission = errors.New("unknown permission")
+
+var known = map[Permission]bool{
+	PvzCreate: true, PvzRead: true, PvzUpdate: true, PvzDelete: true, PvzUnscoped: true,
+	ReceptionCreate: true, ReceptionRead: true, ReceptionClose: true,
+	ReceptionCancel: true, ReceptionVerify: true, ReceptionReopen: true,
+	ProductCreate: true, ProductRead: true, ProductUpdate: true, ProductDelete: true,
+	CatalogRead: true, CatalogManage: true,
+	AuditRead:      true,
+	AssignmentRead: true, AssignmentManage: true,
+	WebhookRead: true, WebhookManage: true,
+	SessionLogout: true,
+	UserCreate:    true,
+	All:           true,
+}
+
+// Policy сопоставляет роли с разрешёнными им действиями. Роль, которой
+// нет в политике, не может ни войти в систему, ни вызвать защищённый маршрут.
+type Policy struct {
+	roles map[string]map[Permission]bool
+}
+
+// New собирает политику из списка разрешений для каждой роли. Неизвестное
+// разрешение - ошибка: опечатка в конфигурации иначе молча закрыла бы доступ.
+func New(roles map[string][]string) (*Policy, error) {
+	if len(roles) == 0 {
+		return nil, errors.New("at least one role is required")
+	}
+
+	granted := make(map[string][]Permission, len(roles))
+	for role, permissions := range roles {
+		if role == "" {
+			return nil, errors.New("role name is required")
+		}
+
+		list := make([]Permission, 0, len(permissions))
+		for _, name := range permissions {
+			permission := Permission(name)
+			if !known[permission] {
+				return nil, fmt.Errorf("role %q: %w %q", role, ErrUnknownPermission, name)
+			}
+			list = append(list, permission)
+		}
+		granted[role] = list
+	}
+
+	return newPolicy(granted), nil
+}
+
+func newPolicy(roles map[string][]Permission) *Policy {
+	policy := &Policy{roles: make(map[string]map[Permission]bool, len(roles))}
+	for role, permissions := range roles {
+		granted := make(map[Permission]bool, len(permissions))
+		for _, permission := range permissions {
+			granted[permission] = true
+		}
+		policy.roles[role] = granted
+	}
+	return policy
+}
+
+// Allowed сообщает, разрешено ли роли действие permission
+func (p *Policy) Allowed(role string, permission Permission) bool {
+	granted := p.roles[role]
+	return granted[All] || granted[permission]
+}
+
+// HasRole сообщает, описана ли роль в политике
+func (p *Policy) HasRole(role string) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Roles возвращает роли политики по алфавиту
+func (p *Policy) Roles() []string {
+	roles := make([]string, 0, len(p.roles))
+	for role := range p.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
@@ -0,0 +1,96 @@
+package rbac_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/rbac"
+	"pvz/internal/repository/model"
+)
+
+var permissions = []rbac.Permission{
+	rbac.PvzCreate, rbac.PvzRead, rbac.PvzUpdate, rbac.PvzDelete,
+	rbac.ReceptionCreate, rbac.ReceptionRead, rbac.ReceptionClose,
+	rbac.ProductCreate, rbac.ProductRead, rbac.ProductUpdate, rbac.ProductDelete,
+	rbac.CatalogRead, rbac.CatalogManage,
+	rbac.AuditRead,
+	rbac.AssignmentRead, rbac.AssignmentManage,
+	rbac.SessionLogout,
+}
+
+func TestDefault(t *testing.T) {
+	policy := rbac.Default()
+
+	assert.Equal(t, []string{model.RoleAdmin, model.RoleAuditor, model.RoleEmployee, model.RoleModerator}, policy.Roles())
+
+	// Администратору разрешено всё
+	for _, permission := range permissions {
+		assert.True(t, policy.Allowed(model.RoleAdmin, permission), permission)
+	}
+
+	// Аудитор только читает
+	assert.True(t, policy.Allowed(model.RoleAuditor, rbac.AuditRead))
+	assert.True(t, policy.Allowed(model.RoleAuditor, rbac.PvzRead))
+	assert.False(t, policy.Allowed(model.RoleAuditor, rbac.PvzCreate))
+	assert.False(t, policy.Allowed(model.RoleAuditor, rbac.ProductDelete))
+
+	assert.True(t, policy.Allowed(model.RoleEmployee, rbac.ReceptionClose))
+	assert.False(t, policy.Allowed(model.RoleEmployee, rbac.PvzCreate))
+	assert.True(t, policy.Allowed(model.RoleModerator, rbac.PvzCreate))
+	assert.False(t, policy.Allowed(model.RoleModerator, rbac.ProductDelete))
+
+	assert.False(t, policy.HasRole("guest"))
+	assert.False(t, policy.Allowed("guest", rbac.PvzRead))
+}
+
+func TestNew(t *testing.T) {
+	policy, err := rbac.New(map[string][]string{
+		"support": {"audit:read", "pvz:read"},
+		"root":    {"*"},
+		"nobody":  {},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, policy.Allowed("support", rbac.AuditRead))
+	assert.False(t, policy.Allowed("support", rbac.PvzUpdate))
+	assert.True(t, policy.Allowed("root", rbac.PvzDelete))
+	assert.True(t, policy.HasRole("nobody"))
+	assert.False(t, policy.Allowed("nobody", rbac.PvzRead))
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := rbac.New(map[string][]string{"support": {"pvz:raed"}})
+	assert.ErrorIs(t, err, rbac.ErrUnknownPermission)
+
+	_, err = rbac.New(map[string][]string{"": {"pvz:read"}})
+	assert.Error(t, err)
+
+	_, err = rbac.New(nil)
+	assert.Error(t, err)
+}
+
+func TestLoadFile(t *testing.T) {
+	// Пример из config совпадает со встроенной политикой
+	policy, err := rbac.LoadFile(filepath.Join("..", "..", "..", "config", "rbac.yaml"))
+	require.NoError(t, err)
+
+	builtin := rbac.Default()
+	assert.Equal(t, builtin.Roles(), policy.Roles())
+	for _, role := range builtin.Roles() {
+		for _, permission := range permissions {
+			assert.Equal(t, builtin.Allowed(role, permission), policy.Allowed(role, permission), "%s %s", role, permission)
+		}
+	}
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "rbac.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("roles:\n    support: [\"reports:read\"]\n"), 0o600))
+	_, err = rbac.LoadFile(invalid)
+	assert.ErrorIs(t, err, rbac.ErrUnknownPermission)
+
+	_, err = rbac.LoadFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
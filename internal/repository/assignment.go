@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"pvz/internal/logger"
+)
+
+type AssignmentPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewAssignmentPostgres(db DB, log logger.Logger) *AssignmentPostgres {
+	return &AssignmentPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+// AssignPvz назначает сотрудника на ПВЗ. Возвращает false, если назначение уже было.
+func (r *AssignmentPostgres) AssignPvz(ctx context.Context, userId, pvzId uuid.UUID) (bool, error) {
+	query := `
+		INSERT INTO pvz_assignment (userId, pvzId)
+		VALUES ($1, $2)
+		ON CONFLICT (userId, pvzId) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userId, pvzId)
+	if err != nil {
+		r.logger.Errorw("Failed to assign pvz", "userId", userId, "pvzId", pvzId, "error", err)
+		return false, fmt.Errorf("failed to assign pvz: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *AssignmentPostgres) UnassignPvz(ctx context.Context, userId, pvzId uuid.UUID) error {
+	query := `DELETE FROM pvz_assignment WHERE userId = $1 AND pvzId = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userId, pvzId)
+	if err != nil {
+		r.logger.Errorw("Failed to unassign pvz", "userId", userId, "pvzId", pvzId, "error", err)
+		return fmt.Errorf("failed to unassign pvz: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to unassign pvz: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("pvz %s of user %s: %w", pvzId, userId, ErrNotFound)
+	}
+	return nil
+}
+
+// GetAssignedPvzIds возвращает ПВЗ сотрудника в порядке назначения
+func (r *AssignmentPostgres) GetAssignedPvzIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT pvzId FROM pvz_assignment WHERE userId = $1 ORDER BY createdAt, pvzId`
+
+	var pvzIds []uuid.UUID
+	if err := r.db.SelectContext(ctx, &pvzIds, query, userId); err != nil {
+		r.logger.Errorw("Failed to get assigned pvz", "userId", userId, "error", err)
+		return nil, fmt.Errorf("failed to get assigned pvz: %w", err)
+	}
+	return pvzIds, nil
+}
+
+func (r *AssignmentPostgres) IsPvzAssigned(ctx context.Context, userId, pvzId uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM pvz_assignment WHERE userId = $1 AND pvzId = $2)`
+
+	var assigned bool
+	if err := r.db.GetContext(ctx, &assigned, query, userId, pvzId); err != nil {
+		r.logger.Errorw("Failed to check pvz assignment", "userId", userId, "pvzId", pvzId, "error", err)
+		return false, fmt.Errorf("failed to check pvz assignment: %w", err)
+	}
+	return assigned, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+type AuditPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewAuditPostgres(db DB, log logger.Logger) *AuditPostgres {
+	return &AuditPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+// CreateAuditEntry добавляет запись в журнал. Вызывается через репозитории
+// транзакции, чтобы запись откатывалась вместе с изменением.
+func (r *AuditPostgres) CreateAuditEntry(ctx context.Context, entry model.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actorId, actorRole, action, entityType, entityId, before, after, requestId)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, entry.ActorId, entry.ActorRole, entry.Action, entry.EntityType,
+		entry.EntityId, jsonOrNull(entry.Before), jsonOrNull(entry.After), entry.RequestId)
+	if err != nil {
+		r.logger.Errorw("Failed to write audit entry", "action", entry.Action, "entityId", entry.EntityId, "error", err)
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// GetAuditLog возвращает записи журнала от новых к старым
+func (r *AuditPostgres) GetAuditLog(ctx context.Context, limit, offset int, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	query := `
+		SELECT id, createdAt, actorId, actorRole, action, entityType, entityId,
+		       COALESCE(before, 'null') AS before, COALESCE(after, 'null') AS after, requestId
+		FROM audit_log
+		WHERE ($1::uuid IS NULL OR actorId = $1)
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR entityType = $3)
+		  AND ($4 = '' OR entityId = $4)
+		  AND ($5::timestamp IS NULL OR createdAt >= $5)
+		  AND ($6::timestamp IS NULL OR createdAt <= $6)
+		ORDER BY createdAt DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	var entries []model.AuditEntry
+	err := r.db.SelectContext(ctx, &entries, query, filter.ActorId, filter.Action, filter.EntityType, filter.EntityId,
+		filter.StartDate, filter.EndDate, limit, offset)
+	if err != nil {
+		r.logger.Errorw("Failed to get audit log", "error", err)
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	r.logger.Infow("Fetched audit log", "count", len(entries), "limit", limit, "offset", offset)
+	return entries, nil
+}
+
+// jsonOrNull передаёт пустой снимок как NULL
+func jsonOrNull(value json.RawMessage) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+// Имена таблиц справочников. Подставляются в запрос, поэтому берутся
+// только из этого списка, а не из пользовательского ввода.
+var catalogTables = map[model.CatalogKind]string{
+	model.CatalogCity:        "city",
+	model.CatalogProductType: "product_type",
+}
+
+type CatalogPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewCatalogPostgres(db DB, log logger.Logger) *CatalogPostgres {
+	return &CatalogPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+func (r *CatalogPostgres) ListCatalog(ctx context.Context, kind model.CatalogKind) ([]model.CatalogItem, error) {
+	table, err := catalogTable(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT name, active FROM ` + table + ` ORDER BY name`
+
+	var items []model.CatalogItem
+	if err := r.db.SelectContext(ctx, &items, query); err != nil {
+		r.logger.Errorw("Failed to list catalog", "catalog", kind, "error", err)
+		return nil, fmt.Errorf("failed to list %s catalog: %w", kind, err)
+	}
+
+	return items, nil
+}
+
+func (r *CatalogPostgres) AddCatalogItem(ctx context.Context, kind model.CatalogKind, name string) (model.CatalogItem, error) {
+	table, err := catalogTable(kind)
+	if err != nil {
+		return model.CatalogItem{}, err
+	}
+
+	query := `
+		INSERT INTO ` + table + ` (name)
+		VALUES ($1)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING name, active
+	`
+
+	var item model.CatalogItem
+	err = r.db.QueryRowxContext(ctx, query, name).StructScan(&item)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CatalogItem{}, fmt.Errorf("%s %q: %w", kind, name, ErrAlreadyExists)
+		}
+		r.logger.Errorw("Failed to add catalog item", "catalog", kind, "name", name, "error", err)
+		return model.CatalogItem{}, fmt.Errorf("failed to add %s: %w", kind, err)
+	}
+
+	r.logger.Infow("Catalog item added", "catalog", kind, "name", name)
+	return item, nil
+}
+
+func (r *CatalogPostgres) SetCatalogItemActive(ctx context.Context, kind model.CatalogKind, name string, active bool) (model.CatalogItem, error) {
+	table, err := catalogTable(kind)
+	if err != nil {
+		return model.CatalogItem{}, err
+	}
+
+	query := `
+		UPDATE ` + table + `
+		SET active = $2
+		WHERE name = $1
+		RETURNING name, active
+	`
+
+	var item model.CatalogItem
+	err = r.db.QueryRowxContext(ctx, query, name, active).StructScan(&item)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CatalogItem{}, fmt.Errorf("%s %q: %w", kind, name, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to update catalog item", "catalog", kind, "name", name, "error", err)
+		return model.CatalogItem{}, fmt.Errorf("failed to update %s: %w", kind, err)
+	}
+
+	r.logger.Infow("Catalog item updated", "catalog", kind, "name", name, "active", active)
+	return item, nil
+}
+
+func catalogTable(kind model.CatalogKind) (string, error) {
+	table, ok := catalogTables[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown catalog %q", kind)
+	}
+	return table, nil
+}
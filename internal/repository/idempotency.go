@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+type IdempotencyPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewIdempotencyPostgres(db DB, log logger.Logger) *IdempotencyPostgres {
+	return &IdempotencyPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+// AcquireIdempotencyKey занимает ключ на ttl по часам базы. Просроченный,
+// но ещё не удалённый ключ занимается заново. Возвращает false, если ключ
+// уже занят.
+func (r *IdempotencyPostgres) AcquireIdempotencyKey(ctx context.Context, key model.IdempotencyKey, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO idempotency_key (userId, key, requestHash, expiresAt)
+		VALUES ($1, $2, $3, LOCALTIMESTAMP + make_interval(secs => $4))
+		ON CONFLICT (userId, key) DO UPDATE
+		SET requestHash = EXCLUDED.requestHash,
+			statusCode = NULL,
+			responseBody = NULL,
+			createdAt = CURRENT_TIMESTAMP,
+			expiresAt = EXCLUDED.expiresAt
+		WHERE idempotency_key.expiresAt <= LOCALTIMESTAMP
+	`
+
+	result, err := r.db.ExecContext(ctx, query, key.UserId, key.Key, key.RequestHash, ttl.Seconds())
+	if err != nil {
+		r.logger.Errorw("Failed to acquire idempotency key", "userId", key.UserId, "key", key.Key, "error", err)
+		return false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *IdempotencyPostgres) GetIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) (model.IdempotencyKey, error) {
+	query := `
+		SELECT userId, key, requestHash, statusCode, responseBody, expiresAt
+		FROM idempotency_key
+		WHERE userId = $1 AND key = $2
+	`
+
+	var stored model.IdempotencyKey
+	if err := r.db.GetContext(ctx, &stored, query, userId, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.IdempotencyKey{}, ErrNotFound
+		}
+		r.logger.Errorw("Failed to get idempotency key", "userId", userId, "key", key, "error", err)
+		return model.IdempotencyKey{}, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return stored, nil
+}
+
+func (r *IdempotencyPostgres) SaveIdempotentResponse(ctx context.Context, userId uuid.UUID, key string, statusCode int, body []byte) error {
+	query := `
+		UPDATE idempotency_key
+		SET statusCode = $3, responseBody = $4
+		WHERE userId = $1 AND key = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userId, key, statusCode, body); err != nil {
+		r.logger.Errorw("Failed to save idempotent response", "userId", userId, "key", key, "error", err)
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyPostgres) DeleteIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) error {
+	query := `DELETE FROM idempotency_key WHERE userId = $1 AND key = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, userId, key); err != nil {
+		r.logger.Errorw("Failed to delete idempotency key", "userId", userId, "key", key, "error", err)
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys удаляет ключи, истёкшие по часам базы
+func (r *IdempotencyPostgres) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_key WHERE expiresAt <= LOCALTIMESTAMP`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Errorw("Failed to delete expired idempotency keys", "error", err)
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
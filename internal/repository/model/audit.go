@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Действия, которые записываются в журнал аудита
+const (
+	AuditPvzCreate          = "pvz.create"
+	AuditPvzUpdate          = "pvz.update"
+	AuditPvzDeactivate      = "pvz.deactivate"
+	AuditPvzActivate        = "pvz.activate"
+	AuditPvzDelete          = "pvz.delete"
+	AuditReceptionCreate    = "reception.create"
+	AuditReceptionClose     = "reception.close"
+	AuditReceptionAutoClose = "reception.auto_close"
+	AuditReceptionFlagStale = "reception.flag_stale"
+	AuditReceptionVerify    = "reception.verify"
+	AuditReceptionCancel    = "reception.cancel"
+	AuditReceptionReopen    = "reception.reopen"
+	AuditProductCreate      = "product.create"
+	AuditProductCreateBatch = "product.create_batch"
+	AuditProductUpdate      = "product.update"
+	AuditProductDelete      = "product.delete"
+	AuditCatalogCreate      = "catalog.create"
+	AuditCatalogUpdate      = "catalog.update"
+	AuditPvzAssign          = "user.assign_pvz"
+	AuditPvzUnassign        = "user.unassign_pvz"
+	AuditWebhookCreate      = "webhook.create"
+	AuditWebhookUpdate      = "webhook.update"
+	AuditWebhookDelete      = "webhook.delete"
+)
+
+// Типы сущностей журнала. Для значений справочников тип - CatalogKind.
+const (
+	AuditEntityPvz       = "pvz"
+	AuditEntityReception = "reception"
+	AuditEntityProduct   = "product"
+	AuditEntityUser      = "user"
+	AuditEntityWebhook   = "webhook"
+)
+
+// AuditEntry - запись журнала аудита. ActorId пуст, если изменение
+// сделано не от имени пользователя, например фоновой задачей.
+type AuditEntry struct {
+	Id         uuid.UUID       `db:"id"`
+	CreatedAt  time.Time       `db:"createdat"`
+	ActorId    *uuid.UUID      `db:"actorid"`
+	ActorRole  string          `db:"actorrole"`
+	Action     string          `db:"action"`
+	EntityType string          `db:"entitytype"`
+	EntityId   string          `db:"entityid"`
+	Before     json.RawMessage `db:"before"`
+	After      json.RawMessage `db:"after"`
+	RequestId  string          `db:"requestid"`
+}
+
+// AuditFilter - условия выборки журнала. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	ActorId    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityId   string
+	StartDate  *time.Time
+	EndDate    *time.Time
+}
@@ -0,0 +1,16 @@
+package model
+
+// CatalogKind - справочник допустимых значений
+type CatalogKind string
+
+const (
+	CatalogCity        CatalogKind = "city"
+	CatalogProductType CatalogKind = "product_type"
+)
+
+// CatalogItem - значение справочника. Отключённое значение остаётся
+// в старых данных, но для новых записей не принимается.
+type CatalogItem struct {
+	Name   string `db:"name"`
+	Active bool   `db:"active"`
+}
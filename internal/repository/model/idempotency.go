@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey - ключ Idempotency-Key пользователя и сохранённый ответ
+// на первый запрос с ним. StatusCode равен nil, пока запрос выполняется.
+type IdempotencyKey struct {
+	UserId       uuid.UUID `db:"userid"`
+	Key          string    `db:"key"`
+	RequestHash  string    `db:"requesthash"`
+	StatusCode   *int      `db:"statuscode"`
+	ResponseBody []byte    `db:"responsebody"`
+	ExpiresAt    time.Time `db:"expiresat"`
+}
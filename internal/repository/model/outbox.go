@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы доменных событий, которые публикуются для внешних систем
+const (
+	EventPvzCreated         = "PvzCreated"
+	EventReceptionOpened    = "ReceptionOpened"
+	EventReceptionClosed    = "ReceptionClosed"
+	EventReceptionVerified  = "ReceptionVerified"
+	EventReceptionCancelled = "ReceptionCancelled"
+	EventReceptionReopened  = "ReceptionReopened"
+	EventProductAdded       = "ProductAdded"
+	EventProductRemoved     = "ProductRemoved"
+)
+
+// EventTypes - все типы доменных событий
+var EventTypes = []string{
+	EventPvzCreated, EventReceptionOpened, EventReceptionClosed, EventReceptionVerified,
+	EventReceptionCancelled, EventReceptionReopened, EventProductAdded, EventProductRemoved,
+}
+
+// Event - доменное событие. Доставка не реже одного раза: при повторной
+// публикации Id не меняется, по нему получатель отбрасывает дубликаты.
+type Event struct {
+	Id         uuid.UUID       `db:"eventid" json:"id"`
+	Type       string          `db:"eventtype" json:"type"`
+	PvzId      uuid.UUID       `db:"pvzid" json:"pvzId"`
+	OccurredAt time.Time       `db:"createdat" json:"occurredAt"`
+	Payload    json.RawMessage `db:"payload" json:"payload"`
+}
+
+// PvzPayload - payload события PvzCreated
+type PvzPayload struct {
+	Id               uuid.UUID  `json:"id"`
+	RegistrationDate time.Time  `json:"registrationDate"`
+	City             string     `json:"city"`
+	Name             string     `json:"name"`
+	Address          string     `json:"address"`
+	Status           string     `json:"status"`
+	DeactivatedAt    *time.Time `json:"deactivatedAt,omitempty"`
+}
+
+// ReceptionPayload - payload событий Reception*: приёмка после перехода
+type ReceptionPayload struct {
+	Id          uuid.UUID  `json:"id"`
+	DateTime    time.Time  `json:"dateTime"`
+	PvzId       uuid.UUID  `json:"pvzId"`
+	Status      string     `json:"status"`
+	StaleAt     *time.Time `json:"staleAt,omitempty"`
+	StaleReason *string    `json:"staleReason,omitempty"`
+	ClosedAt    *time.Time `json:"closedAt,omitempty"`
+	ClosedBy    *uuid.UUID `json:"closedBy,omitempty"`
+	VerifiedAt  *time.Time `json:"verifiedAt,omitempty"`
+	VerifiedBy  *uuid.UUID `json:"verifiedBy,omitempty"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+	CancelledBy *uuid.UUID `json:"cancelledBy,omitempty"`
+	ReopenedAt  *time.Time `json:"reopenedAt,omitempty"`
+	ReopenedBy  *uuid.UUID `json:"reopenedBy,omitempty"`
+}
+
+// ProductPayload - payload событий ProductAdded и ProductRemoved
+type ProductPayload struct {
+	Id          uuid.UUID       `json:"id"`
+	DateTime    time.Time       `json:"dateTime"`
+	Type        string          `json:"type"`
+	ReceptionId uuid.UUID       `json:"receptionId"`
+	Barcode     *string         `json:"barcode,omitempty"`
+	OrderId     *string         `json:"orderId,omitempty"`
+	Weight      *int            `json:"weight,omitempty"`
+	Attributes  json.RawMessage `json:"attributes,omitempty"`
+}
+
+// OutboxEvent - событие в очереди на публикацию. События одного ПВЗ
+// публикуются по возрастанию Seq.
+type OutboxEvent struct {
+	Event
+	Seq      int64 `db:"id"`
+	Attempts int   `db:"attempts"`
+}
@@ -1,14 +1,30 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Product struct {
-	Id          uuid.UUID `db:"id"`
-	DateTime    time.Time `db:"datetime"`
-	Type        string    `db:"type"`
-	ReceptionId uuid.UUID `db:"receptionid"`
+	Id          uuid.UUID       `db:"id"`
+	DateTime    time.Time       `db:"datetime"`
+	Type        string          `db:"type"`
+	ReceptionId uuid.UUID       `db:"receptionid"`
+	Barcode     *string         `db:"barcode"`
+	OrderId     *string         `db:"orderid"`
+	Weight      *int            `db:"weight"` // в граммах
+	Attributes  json.RawMessage `db:"attributes"`
+}
+
+// ProductWithPvz - товар вместе с ПВЗ, в приёмку которого он попал
+type ProductWithPvz struct {
+	Product
+	PvzId uuid.UUID `db:"pvzid"`
+}
+
+// ProductUpdate - изменяемые поля товара; nil означает "не менять"
+type ProductUpdate struct {
+	Type *string
 }
@@ -6,8 +6,63 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	PvzStatusActive   = "active"
+	PvzStatusInactive = "inactive"
+)
+
 type Pvz struct {
-	Id               uuid.UUID `db:"id"`
-	RegistrationDate time.Time `db:"registrationdate"`
-	City             string    `db:"city"`
+	Id               uuid.UUID  `db:"id"`
+	RegistrationDate time.Time  `db:"registrationdate"`
+	City             string     `db:"city"`
+	Name             string     `db:"name"`
+	Address          string     `db:"address"`
+	Status           string     `db:"status"`
+	DeactivatedAt    *time.Time `db:"deactivatedat"`
+}
+
+// PvzUpdate - изменяемые поля ПВЗ; nil означает "не менять"
+type PvzUpdate struct {
+	City    *string
+	Name    *string
+	Address *string
+}
+
+// PvzFilter - условия выборки списка ПВЗ. Пустые поля не ограничивают выборку.
+//
+// StartDate, EndDate, ReceptionStatus и ProductType относятся к приёмкам: ПВЗ
+// попадает в список, если у него есть подходящая приёмка, и в ответ вкладываются
+// только подходящие приёмки. С IncludeEmpty в список попадают и ПВЗ без таких
+// приёмок, в том числе только что созданные.
+//
+// After ограничивает выборку ПВЗ, идущими после курсора.
+type PvzFilter struct {
+	StartDate       *time.Time
+	EndDate         *time.Time
+	ReceptionStatus string
+	ProductType     string
+	IncludeEmpty    bool
+
+	Status         string
+	City           string
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time
+
+	After *PvzCursor
+}
+
+// PvzCursor - позиция в списке ПВЗ, упорядоченном по (RegistrationDate, Id)
+type PvzCursor struct {
+	RegistrationDate time.Time
+	Id               uuid.UUID
+}
+
+type PvzWithReceptions struct {
+	Pvz        Pvz
+	Receptions []ReceptionWithProducts
+}
+
+type ReceptionWithProducts struct {
+	Reception Reception
+	Products  []Product
 }
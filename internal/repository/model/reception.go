@@ -6,9 +6,65 @@ import (
 	"github.com/google/uuid"
 )
 
+// Статусы приёмки. Допустимые переходы между ними задаёт сервис.
+const (
+	ReceptionStatusInProgress = "in_progress"
+	ReceptionStatusClose      = "close"
+	ReceptionStatusVerified   = "verified"
+	ReceptionStatusCancelled  = "cancelled"
+)
+
+// ReceptionStatuses - все статусы приёмки
+var ReceptionStatuses = []string{
+	ReceptionStatusInProgress, ReceptionStatusClose, ReceptionStatusVerified, ReceptionStatusCancelled,
+}
+
+// Что фоновая задача делает с приёмкой, в которой долго не было активности
+const (
+	StaleActionClose = "close"
+	StaleActionFlag  = "flag"
+)
+
 type Reception struct {
 	Id       uuid.UUID `db:"id"`
 	DateTime time.Time `db:"datetime"`
 	PvzId    uuid.UUID `db:"pvzid"`
 	Status   string    `db:"status"`
+	// StaleAt и StaleReason заданы, если приёмку закрыла или пометила
+	// фоновая задача из-за отсутствия активности
+	StaleAt     *time.Time `db:"staleat"`
+	StaleReason *string    `db:"stalereason"`
+	// Время и автор переходов. Автор пуст, если переход сделала фоновая
+	// задача. При повторном открытии ClosedAt и ClosedBy сбрасываются.
+	ClosedAt    *time.Time `db:"closedat"`
+	ClosedBy    *uuid.UUID `db:"closedby"`
+	VerifiedAt  *time.Time `db:"verifiedat"`
+	VerifiedBy  *uuid.UUID `db:"verifiedby"`
+	CancelledAt *time.Time `db:"cancelledat"`
+	CancelledBy *uuid.UUID `db:"cancelledby"`
+	ReopenedAt  *time.Time `db:"reopenedat"`
+	ReopenedBy  *uuid.UUID `db:"reopenedby"`
+}
+
+// ReceptionStatusChange - переход приёмки из статуса From в статус To,
+// сделанный пользователем By. Время перехода задаёт база.
+type ReceptionStatusChange struct {
+	ReceptionId uuid.UUID
+	From        string
+	To          string
+	By          *uuid.UUID
+}
+
+// StaleReception - незакрытая приёмка без активности. LastActivityAt -
+// время создания приёмки или последнего изменения её товаров.
+type StaleReception struct {
+	Reception
+	LastActivityAt time.Time `db:"lastactivityat"`
+}
+
+// ReceptionFilter - условия выборки истории приёмок ПВЗ. Пустые поля не ограничивают выборку.
+type ReceptionFilter struct {
+	Status    string
+	StartDate *time.Time
+	EndDate   *time.Time
 }
@@ -1,12 +1,38 @@
 package model
 
 import (
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 )
 
+// TokenClaims - содержимое access-токена. StandardClaims.Id хранит jti,
+// по которому токен можно отозвать. PvzScope задаётся только в токенах
+// /dummyLogin и заменяет для них назначения сотрудника на ПВЗ.
 type TokenClaims struct {
 	jwt.StandardClaims
-	UserId uuid.UUID
-	Role   string
+	UserId   uuid.UUID
+	Role     string
+	PvzScope []uuid.UUID `json:",omitempty"`
+	// Unscoped не входит в токен: его выставляет проверка токена, если
+	// роли разрешено работать с любым ПВЗ (rbac.PvzUnscoped)
+	Unscoped bool `json:"-"`
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// RefreshToken - сохранённый refresh-токен. Токены, выпущенные друг из друга
+// при обновлении, образуют семейство с общим FamilyId.
+type RefreshToken struct {
+	Id         uuid.UUID  `db:"id"`
+	UserId     uuid.UUID  `db:"userid"`
+	FamilyId   uuid.UUID  `db:"familyid"`
+	TokenHash  string     `db:"tokenhash"`
+	ExpiresAt  time.Time  `db:"expiresat"`
+	RevokedAt  *time.Time `db:"revokedat"`
+	ReplacedBy *uuid.UUID `db:"replacedby"`
 }
@@ -2,9 +2,28 @@ package model
 
 import "github.com/google/uuid"
 
+const (
+	RoleEmployee  = "employee"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	RoleAuditor   = "auditor"
+)
+
 type User struct {
 	Id       uuid.UUID `db:"id"`
 	Email    string    `db:"email"`
 	Role     string    `db:"role"`
 	Password string    `db:"password"`
 }
+
+// PvzAssignment - назначение сотрудника на ПВЗ
+type PvzAssignment struct {
+	UserId uuid.UUID `db:"userid"`
+	PvzId  uuid.UUID `db:"pvzid"`
+}
+
+// RolePermission - разрешение роли в политике RBAC
+type RolePermission struct {
+	Role       string `db:"role"`
+	Permission string `db:"permission"`
+}
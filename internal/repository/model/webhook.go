@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Статусы доставки вебхука. dead - попытки исчерпаны, доставка больше не повторяется.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription - подписка партнёра на события. Пустой EventTypes
+// означает все типы событий, пустой PvzId - все ПВЗ. Secret не попадает
+// в JSON-снимки, например в журнал аудита.
+type WebhookSubscription struct {
+	Id         uuid.UUID      `db:"id"`
+	Url        string         `db:"url"`
+	Secret     string         `db:"secret" json:"-"`
+	EventTypes pq.StringArray `db:"eventtypes"`
+	PvzId      *uuid.UUID     `db:"pvzid"`
+	Active     bool           `db:"active"`
+	CreatedAt  time.Time      `db:"createdat"`
+	UpdatedAt  time.Time      `db:"updatedat"`
+}
+
+// WebhookSubscriptionUpdate - изменяемые поля подписки; nil означает "не менять".
+// ClearPvzId снимает фильтр по ПВЗ.
+type WebhookSubscriptionUpdate struct {
+	Url        *string
+	EventTypes []string
+	PvzId      *uuid.UUID
+	ClearPvzId bool
+	Active     *bool
+}
+
+// WebhookDelivery - доставка одного события одному подписчику
+type WebhookDelivery struct {
+	Id             uuid.UUID  `db:"id"`
+	SubscriptionId uuid.UUID  `db:"subscriptionid"`
+	EventId        uuid.UUID  `db:"eventid"`
+	EventType      string     `db:"eventtype"`
+	Body           string     `db:"body"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  time.Time  `db:"nextattemptat"`
+	LastStatusCode *int       `db:"laststatuscode"`
+	LastError      *string    `db:"lasterror"`
+	CreatedAt      time.Time  `db:"createdat"`
+	DeliveredAt    *time.Time `db:"deliveredat"`
+}
+
+// PendingWebhookDelivery - доставка вместе с адресом и секретом подписки
+type PendingWebhookDelivery struct {
+	WebhookDelivery
+	Url    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// WebhookAttempt - результат попытки доставки. StatusCode равен 0,
+// если ответа не было. Следующая попытка - через RetryAfter по часам базы.
+type WebhookAttempt struct {
+	Status     string
+	StatusCode int
+	Error      string
+	RetryAfter time.Duration
+}
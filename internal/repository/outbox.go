@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+// Ключ pg_advisory_xact_lock: события выбирает и арендует одна реплика
+// за раз, иначе события одного ПВЗ могли бы уйти не по порядку
+const outboxLockKey = 0x6f7574626f78 // "outbox"
+
+type OutboxPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewOutboxPostgres(db DB, log logger.Logger) *OutboxPostgres {
+	return &OutboxPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+// CreateOutboxEvent ставит событие в очередь и возвращает его с присвоенным Id.
+// Вызывается через репозитории транзакции, чтобы событие откатывалось вместе с изменением.
+func (r *OutboxPostgres) CreateOutboxEvent(ctx context.Context, eventType string, pvzId uuid.UUID, payload json.RawMessage) (model.Event, error) {
+	query := `
+		INSERT INTO outbox (eventType, pvzId, payload)
+		VALUES ($1, $2, $3)
+		RETURNING eventId, eventType, pvzId, payload, createdAt
+	`
+
+	var event model.Event
+	if err := r.db.GetContext(ctx, &event, query, eventType, pvzId, string(payload)); err != nil {
+		r.logger.Errorw("Failed to write outbox event", "type", eventType, "pvzId", pvzId, "error", err)
+		return model.Event{}, fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return event, nil
+}
+
+// TryLockOutbox берёт блокировку публикации до конца транзакции.
+// Возвращает false, если её держит другая транзакция.
+func (r *OutboxPostgres) TryLockOutbox(ctx context.Context) (bool, error) {
+	var locked bool
+	if err := r.db.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey); err != nil {
+		r.logger.Errorw("Failed to lock outbox", "error", err)
+		return false, fmt.Errorf("failed to lock outbox: %w", err)
+	}
+	return locked, nil
+}
+
+// GetPendingOutboxEvents возвращает неопубликованные события по порядку.
+// ПВЗ, у которого есть событие с отложенной после ошибки попыткой,
+// пропускается целиком, чтобы следующие события не обогнали его.
+//
+// nextAttemptAt и createdAt проставляет база, поэтому все сроки очереди
+// считаются по её часам (LOCALTIMESTAMP), а не по часам приложения.
+func (r *OutboxPostgres) GetPendingOutboxEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	query := `
+		SELECT o.id, o.eventId, o.eventType, o.pvzId, o.payload, o.createdAt, o.attempts
+		FROM outbox o
+		WHERE o.publishedAt IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM outbox b
+		      WHERE b.pvzId = o.pvzId AND b.publishedAt IS NULL AND b.id <= o.id AND b.nextAttemptAt > LOCALTIMESTAMP
+		  )
+		ORDER BY o.id
+		LIMIT $1
+	`
+
+	var events []model.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, limit); err != nil {
+		r.logger.Errorw("Failed to get pending outbox events", "error", err)
+		return nil, fmt.Errorf("failed to get pending outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// DelayOutboxEvents откладывает следующую попытку публикации событий seqs на
+// delay. Так публикующая реплика арендует пачку, а затем с нулевым delay
+// возвращает в очередь события, до которых не дошла.
+func (r *OutboxPostgres) DelayOutboxEvents(ctx context.Context, seqs []int64, delay time.Duration) error {
+	query := `
+		UPDATE outbox
+		SET nextAttemptAt = LOCALTIMESTAMP + make_interval(secs => $2)
+		WHERE id = ANY($1) AND publishedAt IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, pq.Array(seqs), delay.Seconds()); err != nil {
+		r.logger.Errorw("Failed to delay outbox events", "count", len(seqs), "error", err)
+		return fmt.Errorf("failed to delay outbox events: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxPostgres) MarkOutboxEventPublished(ctx context.Context, seq int64) error {
+	query := `
+		UPDATE outbox
+		SET publishedAt = CURRENT_TIMESTAMP, attempts = attempts + 1, lastError = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, seq); err != nil {
+		r.logger.Errorw("Failed to mark outbox event published", "seq", seq, "error", err)
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxEventFailed откладывает следующую попытку публикации на retryAfter
+func (r *OutboxPostgres) MarkOutboxEventFailed(ctx context.Context, seq int64, lastError string, retryAfter time.Duration) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, lastError = $2, nextAttemptAt = LOCALTIMESTAMP + make_interval(secs => $3)
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, seq, lastError, retryAfter.Seconds()); err != nil {
+		r.logger.Errorw("Failed to mark outbox event failed", "seq", seq, "error", err)
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// DeletePublishedOutboxEvents удаляет события, опубликованные больше retention назад
+func (r *OutboxPostgres) DeletePublishedOutboxEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `DELETE FROM outbox WHERE publishedAt < LOCALTIMESTAMP - make_interval(secs => $1)`
+
+	result, err := r.db.ExecContext(ctx, query, retention.Seconds())
+	if err != nil {
+		r.logger.Errorw("Failed to delete published outbox events", "error", err)
+		return 0, fmt.Errorf("failed to delete published outbox events: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+type PermissionPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewPermissionPostgres(db DB, log logger.Logger) *PermissionPostgres {
+	return &PermissionPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+// GetRolePermissions возвращает разрешения всех ролей из таблицы role_permission
+func (r *PermissionPostgres) GetRolePermissions(ctx context.Context) (map[string][]string, error) {
+	query := `SELECT role, permission FROM role_permission ORDER BY role, permission`
+
+	var rows []model.RolePermission
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		r.logger.Errorw("Failed to get role permissions", "error", err)
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	roles := make(map[string][]string)
+	for _, row := range rows {
+		roles[row.Role] = append(roles[row.Role], row.Permission)
+	}
+	return roles, nil
+}
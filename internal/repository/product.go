@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -10,30 +11,39 @@ import (
 	"pvz/internal/repository/model"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+const productColumns = `id, datetime, type, receptionid, barcode, orderid, weight, attributes`
+
 type ProductPostgres struct {
-	db     *sqlx.DB
+	db     DB
 	logger logger.Logger
 }
 
-func NewProductPostgres(db *sqlx.DB, log logger.Logger) *ProductPostgres {
+func NewProductPostgres(db DB, log logger.Logger) *ProductPostgres {
 	return &ProductPostgres{
 		db:     db,
 		logger: log,
 	}
 }
 
+// CreateProduct возвращает ErrAlreadyExists, если в приёмке уже есть товар
+// с таким же штрихкодом
 func (r *ProductPostgres) CreateProduct(ctx context.Context, product model.Product) (model.Product, error) {
 	query := `
-	INSERT INTO product (type, receptionid)
-	VALUES ($1, $2)
-	RETURNING id, datetime, type, receptionid;
+	INSERT INTO product (type, receptionid, barcode, orderid, weight, attributes)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (receptionid, barcode) WHERE barcode IS NOT NULL AND deletedAt IS NULL DO NOTHING
+	RETURNING ` + productColumns + `;
 	`
 	var created model.Product
-	err := r.db.QueryRowxContext(ctx, query, product.Type, product.ReceptionId).StructScan(&created)
+	err := r.db.QueryRowxContext(ctx, query, product.Type, product.ReceptionId,
+		product.Barcode, product.OrderId, product.Weight, attributesOrEmpty(product.Attributes)).StructScan(&created)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Product{}, fmt.Errorf("barcode %q: %w", *product.Barcode, ErrAlreadyExists)
+		}
 		r.logger.Errorw("Failed to create product", "product", product, "error", err)
 		return model.Product{}, fmt.Errorf("error inserting product: %w", err)
 	}
@@ -42,11 +52,55 @@ func (r *ProductPostgres) CreateProduct(ctx context.Context, product model.Produ
 	return created, nil
 }
 
+// CreateProducts вставляет товары одним запросом. Id товаров задаются
+// вызывающим; строки с уже занятым id или штрихкодом пропускаются, поэтому
+// вернуться может меньше товаров, чем передано. Время товаров растёт на
+// микросекунду в порядке пакета, чтобы удаление последнего товара сохраняло порядок LIFO.
+func (r *ProductPostgres) CreateProducts(ctx context.Context, receptionId uuid.UUID, products []model.Product) ([]model.Product, error) {
+	query := `
+	INSERT INTO product (id, datetime, type, receptionid, barcode, orderid, weight, attributes)
+	SELECT i.id, now() + (i.n - 1) * interval '1 microsecond', i.type, $7, i.barcode, i.orderid, i.weight, i.attributes::jsonb
+	FROM unnest($1::uuid[], $2::text[], $3::text[], $4::text[], $5::int[], $6::text[])
+		WITH ORDINALITY AS i(id, type, barcode, orderid, weight, attributes, n)
+	ORDER BY i.n
+	ON CONFLICT DO NOTHING
+	RETURNING ` + productColumns + `;
+	`
+
+	ids := make([]string, len(products))
+	types := make([]string, len(products))
+	barcodes := make([]sql.NullString, len(products))
+	orderIds := make([]sql.NullString, len(products))
+	weights := make([]sql.NullInt64, len(products))
+	attributes := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.Id.String()
+		types[i] = product.Type
+		barcodes[i] = nullString(product.Barcode)
+		orderIds[i] = nullString(product.OrderId)
+		if product.Weight != nil {
+			weights[i] = sql.NullInt64{Int64: int64(*product.Weight), Valid: true}
+		}
+		attributes[i] = attributesOrEmpty(product.Attributes)
+	}
+
+	var created []model.Product
+	err := r.db.SelectContext(ctx, &created, query, pq.StringArray(ids), pq.StringArray(types),
+		pq.Array(barcodes), pq.Array(orderIds), pq.Array(weights), pq.StringArray(attributes), receptionId)
+	if err != nil {
+		r.logger.Errorw("Failed to create products", "receptionId", receptionId, "count", len(products), "error", err)
+		return nil, fmt.Errorf("error inserting products: %w", err)
+	}
+
+	r.logger.Infow("Successfully created products", "receptionId", receptionId, "count", len(created))
+	return created, nil
+}
+
 func (r *ProductPostgres) GetLastProductIdByReception(ctx context.Context, receptionId uuid.UUID) (uuid.UUID, error) {
 	query := `
 		SELECT id 
 		FROM product 
-		WHERE receptionId = $1 
+		WHERE receptionId = $1 AND deletedAt IS NULL
 		ORDER BY datetime DESC 
 		LIMIT 1;
 	`
@@ -66,21 +120,84 @@ func (r *ProductPostgres) GetLastProductIdByReception(ctx context.Context, recep
 	return id, nil
 }
 
-func (r *ProductPostgres) DeleteProductById(ctx context.Context, productId uuid.UUID) error {
-	query := `DELETE FROM product WHERE id = $1`
+// GetProductById возвращает неудалённый товар вместе с ПВЗ его приёмки
+func (r *ProductPostgres) GetProductById(ctx context.Context, productId uuid.UUID) (model.ProductWithPvz, error) {
+	query := `
+		SELECT p.id, p.datetime, p.type, p.receptionid, p.barcode, p.orderid, p.weight, p.attributes, r.pvzId
+		FROM product p
+		JOIN reception r ON r.id = p.receptionId
+		WHERE p.id = $1 AND p.deletedAt IS NULL
+	`
+
+	var product model.ProductWithPvz
+	err := r.db.GetContext(ctx, &product, query, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Product not found", "productId", productId)
+			return model.ProductWithPvz{}, fmt.Errorf("product %s: %w", productId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to get product", "productId", productId, "error", err)
+		return model.ProductWithPvz{}, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return product, nil
+}
+
+// UpdateProduct меняет поля неудалённого товара и запоминает, кто его исправил
+func (r *ProductPostgres) UpdateProduct(ctx context.Context, productId uuid.UUID, update model.ProductUpdate, updatedBy uuid.UUID) (model.Product, error) {
+	query := `
+		UPDATE product
+		SET type = COALESCE($2, type),
+		    updatedAt = now(),
+		    updatedBy = $3
+		WHERE id = $1 AND deletedAt IS NULL
+		RETURNING ` + productColumns
+
+	var product model.Product
+	err := r.db.QueryRowxContext(ctx, query, productId, update.Type, updatedBy).StructScan(&product)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Product not found for update", "productId", productId)
+			return model.Product{}, fmt.Errorf("product %s: %w", productId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to update product", "productId", productId, "error", err)
+		return model.Product{}, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	r.logger.Infow("Product updated successfully", "productId", productId, "updatedBy", updatedBy)
+	return product, nil
+}
+
+// DeleteProductById помечает товар удалённым. Строка остаётся в таблице,
+// чтобы было видно, кто и когда удалил товар.
+func (r *ProductPostgres) DeleteProductById(ctx context.Context, productId uuid.UUID, deletedBy uuid.UUID) error {
+	query := `
+		UPDATE product
+		SET deletedAt = now(), deletedBy = $2
+		WHERE id = $1 AND deletedAt IS NULL
+	`
 
-	_, err := r.db.ExecContext(ctx, query, productId)
+	result, err := r.db.ExecContext(ctx, query, productId, deletedBy)
 	if err != nil {
 		r.logger.Errorw("Failed to delete product", "productId", productId, "error", err)
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
-	r.logger.Infow("Product deleted successfully", "productId", productId)
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	if rows == 0 {
+		r.logger.Warnw("Product not found for delete", "productId", productId)
+		return fmt.Errorf("product %s: %w", productId, ErrNotFound)
+	}
+
+	r.logger.Infow("Product deleted successfully", "productId", productId, "deletedBy", deletedBy)
 	return nil
 }
 
 func (r *ProductPostgres) GetProductsByReceptionID(ctx context.Context, receptionId uuid.UUID) ([]model.Product, error) {
-	query := `SELECT id, datetime, type, receptionId FROM product WHERE receptionId = $1`
+	query := `SELECT ` + productColumns + ` FROM product WHERE receptionId = $1 AND deletedAt IS NULL`
 
 	r.logger.Infow("Executing GetProductsByReceptionID query", "receptionId", receptionId)
 
@@ -94,3 +211,38 @@ func (r *ProductPostgres) GetProductsByReceptionID(ctx context.Context, receptio
 	r.logger.Infow("Successfully retrieved products", "count", len(result), "receptionId", receptionId)
 	return result, nil
 }
+
+// GetProductsByBarcode ищет товары со штрихкодом во всех ПВЗ, начиная с последних
+func (r *ProductPostgres) GetProductsByBarcode(ctx context.Context, barcode string) ([]model.ProductWithPvz, error) {
+	query := `
+		SELECT p.id, p.datetime, p.type, p.receptionid, p.barcode, p.orderid, p.weight, p.attributes, r.pvzId
+		FROM product p
+		JOIN reception r ON r.id = p.receptionId
+		WHERE p.barcode = $1 AND p.deletedAt IS NULL
+		ORDER BY p.datetime DESC, p.id DESC
+	`
+
+	var result []model.ProductWithPvz
+	if err := r.db.SelectContext(ctx, &result, query, barcode); err != nil {
+		r.logger.Errorw("Failed to fetch products by barcode", "barcode", barcode, "error", err)
+		return nil, fmt.Errorf("failed to fetch products by barcode: %w", err)
+	}
+
+	r.logger.Infow("Fetched products by barcode", "barcode", barcode, "count", len(result))
+	return result, nil
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// attributesOrEmpty подставляет пустой объект: колонка attributes не допускает NULL
+func attributesOrEmpty(attributes json.RawMessage) string {
+	if len(attributes) == 0 {
+		return "{}"
+	}
+	return string(attributes)
+}
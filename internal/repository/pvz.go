@@ -2,20 +2,25 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"pvz/internal/logger"
 	"pvz/internal/repository/model"
 )
 
+const pvzColumns = `id, registrationDate, city, name, address, status, deactivatedAt`
+
 type PvzPostgres struct {
-	db     *sqlx.DB
+	db     DB
 	logger logger.Logger
 }
 
-func NewPvzPostgres(db *sqlx.DB, log logger.Logger) *PvzPostgres {
+func NewPvzPostgres(db DB, log logger.Logger) *PvzPostgres {
 	return &PvzPostgres{
 		db:     db,
 		logger: log,
@@ -28,7 +33,7 @@ func (r *PvzPostgres) CreatePvz(ctx context.Context, city string) (model.Pvz, er
 	query := `
 		INSERT INTO pvz (city)
 		VALUES ($1)
-		RETURNING id, city, registrationDate
+		RETURNING ` + pvzColumns + `
 	`
 
 	r.logger.Infow("Inserting new PVZ into database", "city", city)
@@ -43,21 +48,44 @@ func (r *PvzPostgres) CreatePvz(ctx context.Context, city string) (model.Pvz, er
 	return pvz, nil
 }
 
-func (r *PvzPostgres) GetPvzListByReceptionDate(ctx context.Context, limit, offset int, startDate, endDate *time.Time) ([]model.Pvz, error) {
+// GetPvzListByReceptionDate возвращает ПВЗ от новых к старым. Порядок
+// (registrationDate, id) однозначен, поэтому filter.After задаёт стабильную
+// границу страницы даже при регистрации новых ПВЗ между запросами.
+func (r *PvzPostgres) GetPvzListByReceptionDate(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]model.Pvz, error) {
 	query := `
-		SELECT DISTINCT p.id, p.registrationDate, p.city
+		SELECT p.id, p.registrationDate, p.city, p.name, p.address, p.status, p.deactivatedAt
 		FROM pvz p
-		JOIN reception r ON r.pvzId = p.id
-		WHERE ($1::timestamp IS NULL OR r.dateTime >= $1)
-		  AND ($2::timestamp IS NULL OR r.dateTime <= $2)
-		ORDER BY p.registrationDate DESC
-		LIMIT $3 OFFSET $4
+		WHERE ($1 OR EXISTS (
+			SELECT 1 FROM reception r
+			WHERE r.pvzId = p.id
+			  AND ($2::timestamp IS NULL OR r.dateTime >= $2)
+			  AND ($3::timestamp IS NULL OR r.dateTime <= $3)
+			  AND ($4 = '' OR r.status = $4)
+			  AND ($5 = '' OR EXISTS (SELECT 1 FROM product pr WHERE pr.receptionId = r.id AND pr.type = $5 AND pr.deletedAt IS NULL))
+		))
+		  AND ($6 = '' OR p.status = $6)
+		  AND ($7 = '' OR p.city = $7)
+		  AND ($8::timestamp IS NULL OR p.registrationDate >= $8)
+		  AND ($9::timestamp IS NULL OR p.registrationDate <= $9)
+		  AND ($10::timestamp IS NULL OR (p.registrationDate, p.id) < ($10, $11::uuid))
+		ORDER BY p.registrationDate DESC, p.id DESC
+		LIMIT $12 OFFSET $13
 	`
 
-	r.logger.Infow("Executing GetPvzListByReceptionDate query", "startDate", startDate, "endDate", endDate, "limit", limit, "offset", offset)
+	var afterDate *time.Time
+	var afterId *uuid.UUID
+	if filter.After != nil {
+		afterDate, afterId = &filter.After.RegistrationDate, &filter.After.Id
+	}
+
+	r.logger.Infow("Executing GetPvzListByReceptionDate query",
+		"startDate", filter.StartDate, "endDate", filter.EndDate, "status", filter.Status, "limit", limit, "offset", offset)
 
 	var pvzList []model.Pvz
-	err := r.db.SelectContext(ctx, &pvzList, query, startDate, endDate, limit, offset)
+	err := r.db.SelectContext(ctx, &pvzList, query,
+		filter.IncludeEmpty, filter.StartDate, filter.EndDate, filter.ReceptionStatus, filter.ProductType,
+		filter.Status, filter.City, filter.RegisteredFrom, filter.RegisteredTo,
+		afterDate, afterId, limit, offset)
 	if err != nil {
 		r.logger.Errorw("Failed to fetch Pvz list", "error", err)
 		return nil, err
@@ -66,3 +94,194 @@ func (r *PvzPostgres) GetPvzListByReceptionDate(ctx context.Context, limit, offs
 	r.logger.Infow("Successfully retrieved Pvz list", "count", len(pvzList))
 	return pvzList, nil
 }
+
+// GetPvzListWithReceptions загружает страницу ПВЗ вместе с приёмками и товарами.
+// Количество запросов не зависит от объёма данных: один запрос на ПВЗ,
+// один на все их приёмки и один на все товары этих приёмок.
+func (r *PvzPostgres) GetPvzListWithReceptions(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]model.PvzWithReceptions, error) {
+	pvzList, err := r.GetPvzListByReceptionDate(ctx, limit, offset, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(pvzList) == 0 {
+		return nil, nil
+	}
+
+	pvzIds := make([]string, 0, len(pvzList))
+	for _, pvz := range pvzList {
+		pvzIds = append(pvzIds, pvz.Id.String())
+	}
+
+	// Вкладываются только приёмки, подходящие под фильтр
+	receptionsQuery := `
+		SELECT r.id, r.dateTime, r.pvzId, r.status
+		FROM reception r
+		WHERE r.pvzId = ANY($1::uuid[])
+		  AND ($2::timestamp IS NULL OR r.dateTime >= $2)
+		  AND ($3::timestamp IS NULL OR r.dateTime <= $3)
+		  AND ($4 = '' OR r.status = $4)
+		  AND ($5 = '' OR EXISTS (SELECT 1 FROM product pr WHERE pr.receptionId = r.id AND pr.type = $5 AND pr.deletedAt IS NULL))
+		ORDER BY r.dateTime
+	`
+
+	var receptions []model.Reception
+	err = r.db.SelectContext(ctx, &receptions, receptionsQuery,
+		pq.StringArray(pvzIds), filter.StartDate, filter.EndDate, filter.ReceptionStatus, filter.ProductType)
+	if err != nil {
+		r.logger.Errorw("Failed to fetch receptions for Pvz list", "error", err)
+		return nil, fmt.Errorf("failed to fetch receptions: %w", err)
+	}
+
+	var products []model.Product
+	if len(receptions) > 0 {
+		receptionIds := make([]string, 0, len(receptions))
+		for _, rec := range receptions {
+			receptionIds = append(receptionIds, rec.Id.String())
+		}
+
+		productsQuery := `
+		SELECT ` + productColumns + `
+		FROM product
+		WHERE receptionId = ANY($1::uuid[]) AND deletedAt IS NULL
+		ORDER BY dateTime
+	`
+
+		if err := r.db.SelectContext(ctx, &products, productsQuery, pq.StringArray(receptionIds)); err != nil {
+			r.logger.Errorw("Failed to fetch products for Pvz list", "error", err)
+			return nil, fmt.Errorf("failed to fetch products: %w", err)
+		}
+	}
+
+	productsByReception := make(map[uuid.UUID][]model.Product, len(receptions))
+	for _, p := range products {
+		productsByReception[p.ReceptionId] = append(productsByReception[p.ReceptionId], p)
+	}
+
+	receptionsByPvz := make(map[uuid.UUID][]model.ReceptionWithProducts, len(pvzList))
+	for _, rec := range receptions {
+		receptionsByPvz[rec.PvzId] = append(receptionsByPvz[rec.PvzId], model.ReceptionWithProducts{
+			Reception: rec,
+			Products:  productsByReception[rec.Id],
+		})
+	}
+
+	result := make([]model.PvzWithReceptions, 0, len(pvzList))
+	for _, pvz := range pvzList {
+		result = append(result, model.PvzWithReceptions{
+			Pvz:        pvz,
+			Receptions: receptionsByPvz[pvz.Id],
+		})
+	}
+
+	r.logger.Infow("Successfully assembled Pvz list with receptions",
+		"pvzCount", len(pvzList), "receptionCount", len(receptions), "productCount", len(products))
+	return result, nil
+}
+
+// LockPvz блокирует строку ПВЗ до конца текущей транзакции (SELECT ... FOR UPDATE).
+// Все операции с приёмками и товарами одного ПВЗ выполняются под этой блокировкой,
+// поэтому конкурентные запросы к одному ПВЗ сериализуются.
+func (r *PvzPostgres) LockPvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	query := `SELECT ` + pvzColumns + ` FROM pvz WHERE id = $1 FOR UPDATE`
+
+	var pvz model.Pvz
+	err := r.db.GetContext(ctx, &pvz, query, pvzId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Pvz not found for lock", "pvzId", pvzId)
+			return model.Pvz{}, fmt.Errorf("pvz %s: %w", pvzId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to lock Pvz", "pvzId", pvzId, "error", err)
+		return model.Pvz{}, fmt.Errorf("failed to lock pvz: %w", err)
+	}
+
+	return pvz, nil
+}
+
+func (r *PvzPostgres) GetPvzById(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	query := `SELECT ` + pvzColumns + ` FROM pvz WHERE id = $1`
+
+	var pvz model.Pvz
+	err := r.db.GetContext(ctx, &pvz, query, pvzId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Pvz not found", "pvzId", pvzId)
+			return model.Pvz{}, fmt.Errorf("pvz %s: %w", pvzId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to get Pvz", "pvzId", pvzId, "error", err)
+		return model.Pvz{}, fmt.Errorf("failed to get pvz: %w", err)
+	}
+
+	return pvz, nil
+}
+
+func (r *PvzPostgres) UpdatePvz(ctx context.Context, pvzId uuid.UUID, update model.PvzUpdate) (model.Pvz, error) {
+	query := `
+		UPDATE pvz
+		SET city = COALESCE($2, city),
+		    name = COALESCE($3, name),
+		    address = COALESCE($4, address)
+		WHERE id = $1
+		RETURNING ` + pvzColumns
+
+	var pvz model.Pvz
+	err := r.db.QueryRowxContext(ctx, query, pvzId, update.City, update.Name, update.Address).StructScan(&pvz)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Pvz not found for update", "pvzId", pvzId)
+			return model.Pvz{}, fmt.Errorf("pvz %s: %w", pvzId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to update Pvz", "pvzId", pvzId, "error", err)
+		return model.Pvz{}, fmt.Errorf("failed to update pvz: %w", err)
+	}
+
+	r.logger.Infow("Successfully updated PVZ", "pvz", pvz)
+	return pvz, nil
+}
+
+// SetPvzStatus переводит ПВЗ в статус status. Время деактивации
+// проставляется при переходе в inactive и сбрасывается при активации.
+func (r *PvzPostgres) SetPvzStatus(ctx context.Context, pvzId uuid.UUID, status string) (model.Pvz, error) {
+	query := `
+		UPDATE pvz
+		SET status = $2,
+		    deactivatedAt = CASE WHEN $2 = 'inactive' THEN now() END
+		WHERE id = $1
+		RETURNING ` + pvzColumns
+
+	var pvz model.Pvz
+	err := r.db.QueryRowxContext(ctx, query, pvzId, status).StructScan(&pvz)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Pvz not found for status change", "pvzId", pvzId)
+			return model.Pvz{}, fmt.Errorf("pvz %s: %w", pvzId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to change Pvz status", "pvzId", pvzId, "status", status, "error", err)
+		return model.Pvz{}, fmt.Errorf("failed to change pvz status: %w", err)
+	}
+
+	r.logger.Infow("Pvz status changed", "pvzId", pvzId, "status", status)
+	return pvz, nil
+}
+
+func (r *PvzPostgres) DeletePvz(ctx context.Context, pvzId uuid.UUID) error {
+	query := `DELETE FROM pvz WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, pvzId)
+	if err != nil {
+		r.logger.Errorw("Failed to delete Pvz", "pvzId", pvzId, "error", err)
+		return fmt.Errorf("failed to delete pvz: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete pvz: %w", err)
+	}
+	if rows == 0 {
+		r.logger.Warnw("Pvz not found for delete", "pvzId", pvzId)
+		return fmt.Errorf("pvz %s: %w", pvzId, ErrNotFound)
+	}
+
+	r.logger.Infow("Pvz deleted", "pvzId", pvzId)
+	return nil
+}
@@ -5,19 +5,46 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"pvz/internal/logger"
 	"pvz/internal/repository/model"
 )
 
+const receptionColumns = `id, dateTime, pvzId, status, staleAt, staleReason,
+	closedAt, closedBy, verifiedAt, verifiedBy, cancelledAt, cancelledBy, reopenedAt, reopenedBy`
+
+// receptionTransitionSet - что меняет переход в статус кроме самого статуса:
+// время перехода и $4 - его автор. Время берётся из часов базы, как и в
+// MarkReceptionStale, чтобы окно повторного открытия и простой приёмки
+// считались по одним часам.
+var receptionTransitionSet = map[string]string{
+	model.ReceptionStatusClose:     `closedAt = LOCALTIMESTAMP, closedBy = $4`,
+	model.ReceptionStatusVerified:  `verifiedAt = LOCALTIMESTAMP, verifiedBy = $4`,
+	model.ReceptionStatusCancelled: `cancelledAt = LOCALTIMESTAMP, cancelledBy = $4`,
+	// Повторно открытая приёмка снова может стать забытой
+	model.ReceptionStatusInProgress: `reopenedAt = LOCALTIMESTAMP, reopenedBy = $4,
+		closedAt = NULL, closedBy = NULL, staleAt = NULL, staleReason = NULL`,
+}
+
+// Ключ pg_advisory_xact_lock: забытые приёмки обрабатывает одна реплика за раз
+const staleReceptionsLockKey = 0x7374616c65 // "stale"
+
+// receptionLastActivity - время последней активности приёмки r: её создание,
+// повторное открытие или последнее добавление, исправление или удаление товара
+const receptionLastActivity = `(
+	SELECT GREATEST(r.dateTime, r.reopenedAt, MAX(p.dateTime), MAX(p.updatedAt), MAX(p.deletedAt))
+	FROM product p
+	WHERE p.receptionId = r.id
+)`
+
 type ReceptionPostgres struct {
-	db     *sqlx.DB
+	db     DB
 	logger logger.Logger
 }
 
-func NewReceptionPostgres(db *sqlx.DB, log logger.Logger) *ReceptionPostgres {
+func NewReceptionPostgres(db DB, log logger.Logger) *ReceptionPostgres {
 	return &ReceptionPostgres{
 		db:     db,
 		logger: log,
@@ -66,25 +93,103 @@ func (r *ReceptionPostgres) GetInProgressReception(ctx context.Context, pvzId uu
 	return receptionId, nil
 }
 
-func (r *ReceptionPostgres) CloseReception(ctx context.Context, pvzId uuid.UUID) error {
+// UpdateReceptionStatus переводит приёмку из статуса change.From в change.To и
+// записывает время и автора перехода. Допустимость перехода проверяет сервис.
+// Если приёмка уже не в статусе change.From, возвращается ErrNotFound.
+func (r *ReceptionPostgres) UpdateReceptionStatus(ctx context.Context, change model.ReceptionStatusChange) (model.Reception, error) {
+	set, ok := receptionTransitionSet[change.To]
+	if !ok {
+		return model.Reception{}, fmt.Errorf("unknown reception status %q", change.To)
+	}
+
 	query := `
 		UPDATE reception
-		SET status = 'close'
-		WHERE pvzId = $1 AND status = 'in_progress'
+		SET status = $3, ` + set + `
+		WHERE id = $1 AND status = $2
+		RETURNING ` + receptionColumns
+
+	var reception model.Reception
+	err := r.db.GetContext(ctx, &reception, query, change.ReceptionId, change.From, change.To, change.By)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Reception not found for status change", "receptionId", change.ReceptionId, "status", change.From)
+			return model.Reception{}, fmt.Errorf("reception %s in status %s: %w", change.ReceptionId, change.From, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to change reception status", "receptionId", change.ReceptionId, "status", change.To, "error", err)
+		return model.Reception{}, fmt.Errorf("failed to change reception status: %w", err)
+	}
+
+	r.logger.Infow("Reception status changed", "receptionId", change.ReceptionId, "from", change.From, "to", change.To)
+	return reception, nil
+}
+
+// ReceptionClosedWithin сообщает, закрыта ли приёмка не раньше window назад
+// по часам базы. У приёмок, закрытых до появления closedAt, время закрытия
+// неизвестно: для них возвращается false.
+func (r *ReceptionPostgres) ReceptionClosedWithin(ctx context.Context, receptionId uuid.UUID, window time.Duration) (bool, error) {
+	query := `
+		SELECT COALESCE(closedAt >= LOCALTIMESTAMP - make_interval(secs => $2), false)
+		FROM reception
+		WHERE id = $1
+	`
+
+	var within bool
+	err := r.db.GetContext(ctx, &within, query, receptionId, window.Seconds())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("reception %s: %w", receptionId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to check reception close time", "receptionId", receptionId, "error", err)
+		return false, fmt.Errorf("failed to check reception close time: %w", err)
+	}
+	return within, nil
+}
+
+func (r *ReceptionPostgres) GetReceptionById(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	query := `SELECT ` + receptionColumns + ` FROM reception WHERE id = $1`
+
+	var reception model.Reception
+	err := r.db.GetContext(ctx, &reception, query, receptionId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnw("Reception not found", "receptionId", receptionId)
+			return model.Reception{}, fmt.Errorf("reception %s: %w", receptionId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to get reception", "receptionId", receptionId, "error", err)
+		return model.Reception{}, fmt.Errorf("failed to get reception: %w", err)
+	}
+
+	return reception, nil
+}
+
+// GetReceptionList возвращает приёмки ПВЗ от новых к старым
+func (r *ReceptionPostgres) GetReceptionList(ctx context.Context, pvzId uuid.UUID, limit, offset int, filter model.ReceptionFilter) ([]model.Reception, error) {
+	query := `
+		SELECT ` + receptionColumns + `
+		FROM reception
+		WHERE pvzId = $1
+		  AND ($2 = '' OR status = $2)
+		  AND ($3::timestamp IS NULL OR dateTime >= $3)
+		  AND ($4::timestamp IS NULL OR dateTime <= $4)
+		ORDER BY dateTime DESC, id DESC
+		LIMIT $5 OFFSET $6
 	`
 
-	_, err := r.db.ExecContext(ctx, query, pvzId)
+	r.logger.Infow("Executing GetReceptionList query", "pvzId", pvzId, "status", filter.Status,
+		"startDate", filter.StartDate, "endDate", filter.EndDate, "limit", limit, "offset", offset)
+
+	var receptions []model.Reception
+	err := r.db.SelectContext(ctx, &receptions, query, pvzId, filter.Status, filter.StartDate, filter.EndDate, limit, offset)
 	if err != nil {
-		r.logger.Errorw("Failed to close reception", "pvzId", pvzId, "error", err)
-		return fmt.Errorf("failed to close reception: %w", err)
+		r.logger.Errorw("Failed to fetch reception list", "pvzId", pvzId, "error", err)
+		return nil, fmt.Errorf("failed to fetch receptions: %w", err)
 	}
 
-	r.logger.Infow("Successfully closed reception(s)", "pvzId", pvzId)
-	return nil
+	return receptions, nil
 }
 
 func (r *ReceptionPostgres) GetReceptionsByPvzID(ctx context.Context, pvzId uuid.UUID) ([]model.Reception, error) {
-	query := `SELECT id, dateTime, pvzId, status FROM reception WHERE pvzId = $1`
+	query := `SELECT ` + receptionColumns + ` FROM reception WHERE pvzId = $1`
 
 	r.logger.Infow("Executing GetReceptionsByPvzID query", "pvzId", pvzId)
 
@@ -98,3 +203,77 @@ func (r *ReceptionPostgres) GetReceptionsByPvzID(ctx context.Context, pvzId uuid
 	r.logger.Infow("Successfully retrieved receptions list", "count", len(receptions), "pvzId", pvzId)
 	return receptions, nil
 }
+
+func (r *ReceptionPostgres) HasReceptions(ctx context.Context, pvzId uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM reception WHERE pvzId = $1)`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, pvzId); err != nil {
+		r.logger.Errorw("Failed to check receptions", "pvzId", pvzId, "error", err)
+		return false, fmt.Errorf("failed to check receptions: %w", err)
+	}
+
+	return exists, nil
+}
+
+// TryLockStaleReceptions берёт блокировку обработки забытых приёмок до конца
+// транзакции. Возвращает false, если её держит другая транзакция.
+func (r *ReceptionPostgres) TryLockStaleReceptions(ctx context.Context) (bool, error) {
+	var locked bool
+	if err := r.db.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock($1)`, staleReceptionsLockKey); err != nil {
+		r.logger.Errorw("Failed to lock stale receptions", "error", err)
+		return false, fmt.Errorf("failed to lock stale receptions: %w", err)
+	}
+	return locked, nil
+}
+
+// GetStaleReceptions возвращает до limit незакрытых приёмок без активности
+// дольше idleFor, начиная с самых давних. Уже помеченные приёмки пропускаются.
+func (r *ReceptionPostgres) GetStaleReceptions(ctx context.Context, idleFor time.Duration, limit int) ([]model.StaleReception, error) {
+	query := `
+		SELECT ` + receptionColumns + `, lastActivityAt
+		FROM (
+			SELECT r.*, ` + receptionLastActivity + ` AS lastActivityAt
+			FROM reception r
+			WHERE r.status = 'in_progress' AND r.staleAt IS NULL
+		) r
+		WHERE lastActivityAt < LOCALTIMESTAMP - make_interval(secs => $1)
+		ORDER BY lastActivityAt
+		LIMIT $2
+	`
+
+	var receptions []model.StaleReception
+	if err := r.db.SelectContext(ctx, &receptions, query, idleFor.Seconds(), limit); err != nil {
+		r.logger.Errorw("Failed to get stale receptions", "error", err)
+		return nil, fmt.Errorf("failed to get stale receptions: %w", err)
+	}
+	return receptions, nil
+}
+
+// MarkReceptionStale проставляет приёмке staleAt и reason, а с closeReception ещё и
+// закрывает её без автора. Активность проверяется заново: если после выборки в приёмку
+// добавили товар или её закрыли, возвращается ErrNotFound.
+func (r *ReceptionPostgres) MarkReceptionStale(ctx context.Context, receptionId uuid.UUID, idleFor time.Duration, reason string, closeReception bool) (model.Reception, error) {
+	query := `
+		UPDATE reception r
+		SET status = CASE WHEN $3 THEN 'close' ELSE r.status END,
+		    closedAt = CASE WHEN $3 THEN LOCALTIMESTAMP ELSE r.closedAt END,
+		    staleAt = LOCALTIMESTAMP,
+		    staleReason = $2
+		WHERE r.id = $1
+		  AND r.status = 'in_progress'
+		  AND r.staleAt IS NULL
+		  AND ` + receptionLastActivity + ` < LOCALTIMESTAMP - make_interval(secs => $4)
+		RETURNING ` + receptionColumns
+
+	var reception model.Reception
+	err := r.db.GetContext(ctx, &reception, query, receptionId, reason, closeReception, idleFor.Seconds())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Reception{}, fmt.Errorf("stale reception %s: %w", receptionId, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to mark stale reception", "receptionId", receptionId, "error", err)
+		return model.Reception{}, fmt.Errorf("failed to mark stale reception: %w", err)
+	}
+	return reception, nil
+}
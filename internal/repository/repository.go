@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -11,42 +13,151 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+)
+
 type User interface {
 	CreateUser(ctx context.Context, user model.User) (uuid.UUID, error)
 	GetUserByEmail(ctx context.Context, email string) (model.User, error)
+	GetUserById(ctx context.Context, id uuid.UUID) (model.User, error)
+}
+
+type Token interface {
+	CreateRefreshToken(ctx context.Context, token model.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) error
+	RevokeTokenFamily(ctx context.Context, familyId uuid.UUID) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, before time.Time) (int64, error)
 }
 
 type Pvz interface {
 	CreatePvz(ctx context.Context, city string) (model.Pvz, error)
-	GetPvzListByReceptionDate(ctx context.Context, limit, offset int, startDate, endDate *time.Time) ([]model.Pvz, error)
+	GetPvzById(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error)
+	GetPvzListByReceptionDate(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]model.Pvz, error)
+	GetPvzListWithReceptions(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]model.PvzWithReceptions, error)
+	UpdatePvz(ctx context.Context, pvzId uuid.UUID, update model.PvzUpdate) (model.Pvz, error)
+	SetPvzStatus(ctx context.Context, pvzId uuid.UUID, status string) (model.Pvz, error)
+	DeletePvz(ctx context.Context, pvzId uuid.UUID) error
+	LockPvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error)
 }
 
 type Reception interface {
 	CreateReception(ctx context.Context, pvzId uuid.UUID) (model.Reception, error)
 	GetInProgressReception(ctx context.Context, pvzId uuid.UUID) (uuid.UUID, error)
-	CloseReception(ctx context.Context, pvzId uuid.UUID) error
+	UpdateReceptionStatus(ctx context.Context, change model.ReceptionStatusChange) (model.Reception, error)
+	ReceptionClosedWithin(ctx context.Context, receptionId uuid.UUID, window time.Duration) (bool, error)
+	GetReceptionById(ctx context.Context, receptionId uuid.UUID) (model.Reception, error)
+	GetReceptionList(ctx context.Context, pvzId uuid.UUID, limit, offset int, filter model.ReceptionFilter) ([]model.Reception, error)
 	GetReceptionsByPvzID(ctx context.Context, pvzId uuid.UUID) ([]model.Reception, error)
+	HasReceptions(ctx context.Context, pvzId uuid.UUID) (bool, error)
+	TryLockStaleReceptions(ctx context.Context) (bool, error)
+	GetStaleReceptions(ctx context.Context, idleFor time.Duration, limit int) ([]model.StaleReception, error)
+	MarkReceptionStale(ctx context.Context, receptionId uuid.UUID, idleFor time.Duration, reason string, closeReception bool) (model.Reception, error)
 }
 
 type Product interface {
 	CreateProduct(ctx context.Context, product model.Product) (model.Product, error)
+	CreateProducts(ctx context.Context, receptionId uuid.UUID, products []model.Product) ([]model.Product, error)
 	GetLastProductIdByReception(ctx context.Context, receptionId uuid.UUID) (uuid.UUID, error)
-	DeleteProductById(ctx context.Context, productId uuid.UUID) error
+	GetProductById(ctx context.Context, productId uuid.UUID) (model.ProductWithPvz, error)
+	UpdateProduct(ctx context.Context, productId uuid.UUID, update model.ProductUpdate, updatedBy uuid.UUID) (model.Product, error)
+	DeleteProductById(ctx context.Context, productId uuid.UUID, deletedBy uuid.UUID) error
 	GetProductsByReceptionID(ctx context.Context, receptionId uuid.UUID) ([]model.Product, error)
+	GetProductsByBarcode(ctx context.Context, barcode string) ([]model.ProductWithPvz, error)
+}
+
+type Catalog interface {
+	ListCatalog(ctx context.Context, kind model.CatalogKind) ([]model.CatalogItem, error)
+	AddCatalogItem(ctx context.Context, kind model.CatalogKind, name string) (model.CatalogItem, error)
+	SetCatalogItemActive(ctx context.Context, kind model.CatalogKind, name string, active bool) (model.CatalogItem, error)
+}
+
+type Idempotency interface {
+	AcquireIdempotencyKey(ctx context.Context, key model.IdempotencyKey, ttl time.Duration) (bool, error)
+	GetIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) (model.IdempotencyKey, error)
+	SaveIdempotentResponse(ctx context.Context, userId uuid.UUID, key string, statusCode int, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+type Audit interface {
+	CreateAuditEntry(ctx context.Context, entry model.AuditEntry) error
+	GetAuditLog(ctx context.Context, limit, offset int, filter model.AuditFilter) ([]model.AuditEntry, error)
+}
+
+type Assignment interface {
+	AssignPvz(ctx context.Context, userId, pvzId uuid.UUID) (bool, error)
+	UnassignPvz(ctx context.Context, userId, pvzId uuid.UUID) error
+	GetAssignedPvzIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error)
+	IsPvzAssigned(ctx context.Context, userId, pvzId uuid.UUID) (bool, error)
+}
+
+type Outbox interface {
+	CreateOutboxEvent(ctx context.Context, eventType string, pvzId uuid.UUID, payload json.RawMessage) (model.Event, error)
+	TryLockOutbox(ctx context.Context) (bool, error)
+	GetPendingOutboxEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error)
+	DelayOutboxEvents(ctx context.Context, seqs []int64, delay time.Duration) error
+	MarkOutboxEventPublished(ctx context.Context, seq int64) error
+	MarkOutboxEventFailed(ctx context.Context, seq int64, lastError string, retryAfter time.Duration) error
+	DeletePublishedOutboxEvents(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type Webhook interface {
+	CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetWebhookSubscriptionById(ctx context.Context, id uuid.UUID) (model.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, id uuid.UUID, update model.WebhookSubscriptionUpdate) (model.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error
+	CreateWebhookDeliveries(ctx context.Context, event model.Event, body string) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, lease time.Duration, limit int) ([]model.PendingWebhookDelivery, error)
+	SaveWebhookAttempt(ctx context.Context, id uuid.UUID, attempt model.WebhookAttempt) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit, offset int) ([]model.WebhookDelivery, error)
+}
+
+type Permission interface {
+	GetRolePermissions(ctx context.Context) (map[string][]string, error)
 }
 
 type Repository struct {
 	User
+	Token
 	Pvz
 	Reception
 	Product
+	Catalog
+	Idempotency
+	Audit
+	Assignment
+	Outbox
+	Webhook
+	Permission
+	UnitOfWork
 }
 
 func NewRepository(db *sqlx.DB, log logger.Logger) *Repository {
+	repos := newRepository(db, log)
+	repos.UnitOfWork = NewPostgresUnitOfWork(db, log)
+	return repos
+}
+
+func newRepository(db DB, log logger.Logger) *Repository {
 	return &Repository{
-		User:      NewUserPostgres(db, log),
-		Pvz:       NewPvzPostgres(db, log),
-		Reception: NewReceptionPostgres(db, log),
-		Product:   NewProductPostgres(db, log),
+		User:        NewUserPostgres(db, log),
+		Token:       NewTokenPostgres(db, log),
+		Pvz:         NewPvzPostgres(db, log),
+		Reception:   NewReceptionPostgres(db, log),
+		Product:     NewProductPostgres(db, log),
+		Catalog:     NewCatalogPostgres(db, log),
+		Idempotency: NewIdempotencyPostgres(db, log),
+		Audit:       NewAuditPostgres(db, log),
+		Assignment:  NewAssignmentPostgres(db, log),
+		Outbox:      NewOutboxPostgres(db, log),
+		Webhook:     NewWebhookPostgres(db, log),
+		Permission:  NewPermissionPostgres(db, log),
 	}
 }
@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/mocks"
+)
+
+func TestAssignPvz(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAssignmentPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+	userId, pvzId := uuid.New(), uuid.New()
+
+	query := `INSERT INTO pvz_assignment \(userId, pvzId\)\s+VALUES \(\$1, \$2\)\s+ON CONFLICT \(userId, pvzId\) DO NOTHING`
+	mockDB.ExpectExec(query).WithArgs(userId, pvzId).WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectExec(query).WithArgs(userId, pvzId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assigned, err := repo.AssignPvz(context.Background(), userId, pvzId)
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+
+	// Повторное назначение не создаёт записи
+	assigned, err = repo.AssignPvz(context.Background(), userId, pvzId)
+	assert.NoError(t, err)
+	assert.False(t, assigned)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUnassignPvz_NotFound(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAssignmentPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+	userId, pvzId := uuid.New(), uuid.New()
+
+	mockDB.ExpectExec(`DELETE FROM pvz_assignment WHERE userId = \$1 AND pvzId = \$2`).
+		WithArgs(userId, pvzId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.UnassignPvz(context.Background(), userId, pvzId)
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetAssignedPvzIds(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAssignmentPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+	userId := uuid.New()
+	pvzIds := []uuid.UUID{uuid.New(), uuid.New()}
+
+	mockDB.ExpectQuery(`SELECT pvzId FROM pvz_assignment WHERE userId = \$1 ORDER BY createdAt, pvzId`).
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"pvzid"}).AddRow(pvzIds[0]).AddRow(pvzIds[1]))
+	mockDB.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pvz_assignment WHERE userId = \$1 AND pvzId = \$2\)`).
+		WithArgs(userId, pvzIds[0]).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	result, err := repo.GetAssignedPvzIds(context.Background(), userId)
+	assert.NoError(t, err)
+	assert.Equal(t, pvzIds, result)
+
+	assigned, err := repo.IsPvzAssigned(context.Background(), userId, pvzIds[0])
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+func TestCreateAuditEntry(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAuditPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	actorId := uuid.New()
+	entry := model.AuditEntry{
+		ActorId:    &actorId,
+		ActorRole:  "employee",
+		Action:     model.AuditProductDelete,
+		EntityType: model.AuditEntityProduct,
+		EntityId:   uuid.NewString(),
+		Before:     json.RawMessage(`{"Type":"обувь"}`),
+		RequestId:  "req-1",
+	}
+
+	// Пустой снимок after пишется как NULL
+	mockDB.ExpectExec(`INSERT INTO audit_log \(actorId, actorRole, action, entityType, entityId, before, after, requestId\)`).
+		WithArgs(actorId, "employee", model.AuditProductDelete, model.AuditEntityProduct, entry.EntityId, `{"Type":"обувь"}`, nil, "req-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CreateAuditEntry(context.Background(), entry)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetAuditLog(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAuditPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	entryId := uuid.New()
+	createdAt := time.Now()
+	startDate := createdAt.Add(-time.Hour)
+	filter := model.AuditFilter{EntityType: model.AuditEntityPvz, StartDate: &startDate}
+
+	mockDB.ExpectQuery(`SELECT id, createdAt, actorId, actorRole, action, entityType, entityId,.+FROM audit_log.+ORDER BY createdAt DESC, id DESC\s+LIMIT \$7 OFFSET \$8`).
+		WithArgs(nil, "", model.AuditEntityPvz, "", startDate, nil, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "createdat", "actorid", "actorrole", "action", "entitytype", "entityid", "before", "after", "requestid"}).
+			AddRow(entryId, createdAt, nil, "", model.AuditPvzCreate, model.AuditEntityPvz, "pvz-1", []byte("null"), []byte(`{"City":"Москва"}`), "req-1"))
+	mockLogger.On("Infow", "Fetched audit log", "count", 1, "limit", 10, "offset", 0).Return()
+
+	entries, err := repo.GetAuditLog(context.Background(), 10, 0, filter)
+
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, entryId, entries[0].Id)
+		assert.Nil(t, entries[0].ActorId)
+		assert.JSONEq(t, `{"City":"Москва"}`, string(entries[0].After))
+		assert.Equal(t, "req-1", entries[0].RequestId)
+	}
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+func TestListCatalog(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCatalogPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	mockDB.ExpectQuery(`SELECT name, active FROM product_type ORDER BY name`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "active"}).
+			AddRow("обувь", true).
+			AddRow("электроника", false))
+
+	items, err := repo.ListCatalog(context.Background(), model.CatalogProductType)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CatalogItem{{Name: "обувь", Active: true}, {Name: "электроника", Active: false}}, items)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestListCatalog_UnknownKind(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCatalogPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	_, err = repo.ListCatalog(context.Background(), model.CatalogKind("pvz; DROP TABLE pvz"))
+
+	assert.Error(t, err)
+}
+
+func TestAddCatalogItem_AlreadyExists(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCatalogPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	mockDB.ExpectQuery(`INSERT INTO city \(name\)\s+VALUES \(\$1\)\s+ON CONFLICT \(name\) DO NOTHING\s+RETURNING name, active`).
+		WithArgs("Москва").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "active"}))
+
+	_, err = repo.AddCatalogItem(context.Background(), model.CatalogCity, "Москва")
+
+	assert.True(t, errors.Is(err, repository.ErrAlreadyExists))
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSetCatalogItemActive(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCatalogPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	mockDB.ExpectQuery(`UPDATE city\s+SET active = \$2\s+WHERE name = \$1\s+RETURNING name, active`).
+		WithArgs("Казань", false).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "active"}).AddRow("Казань", false))
+	mockLogger.On("Infow", "Catalog item updated", "catalog", model.CatalogCity, "name", "Казань", "active", false).Return()
+
+	item, err := repo.SetCatalogItemActive(context.Background(), model.CatalogCity, "Казань", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.CatalogItem{Name: "Казань", Active: false}, item)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+
+	mockDB.ExpectQuery(`UPDATE city`).
+		WithArgs("Omsk", true).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "active"}))
+
+	_, err = repo.SetCatalogItemActive(context.Background(), model.CatalogCity, "Omsk", true)
+
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+}
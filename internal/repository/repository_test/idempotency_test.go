@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+func TestAcquireIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		acquired bool
+	}{
+		{name: "new key", affected: 1, acquired: true},
+		{name: "key in use", affected: 0, acquired: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+			key := model.IdempotencyKey{UserId: uuid.New(), Key: "key-1", RequestHash: "hash"}
+
+			// Срок считается по часам базы; просроченный ключ перезанимается, действующий - нет
+			mockDB.ExpectExec(`INSERT INTO idempotency_key .+ VALUES \(\$1, \$2, \$3, LOCALTIMESTAMP \+ make_interval\(secs => \$4\)\)\s+ON CONFLICT \(userId, key\) DO UPDATE .+ WHERE idempotency_key.expiresAt <= LOCALTIMESTAMP`).
+				WithArgs(key.UserId, key.Key, key.RequestHash, float64(3600)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			acquired, err := repo.AcquireIdempotencyKey(context.Background(), key, time.Hour)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.acquired, acquired)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetIdempotencyKey_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	status := 201
+	expected := model.IdempotencyKey{
+		UserId:       uuid.New(),
+		Key:          "key-1",
+		RequestHash:  "hash",
+		StatusCode:   &status,
+		ResponseBody: []byte(`{"id":"1"}`),
+		ExpiresAt:    time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+
+	mockDB.ExpectQuery(`SELECT userId, key, requestHash, statusCode, responseBody, expiresAt\s+FROM idempotency_key\s+WHERE userId = \$1 AND key = \$2`).
+		WithArgs(expected.UserId, expected.Key).
+		WillReturnRows(sqlmock.NewRows([]string{"userid", "key", "requesthash", "statuscode", "responsebody", "expiresat"}).
+			AddRow(expected.UserId, expected.Key, expected.RequestHash, status, expected.ResponseBody, expected.ExpiresAt))
+
+	stored, err := repo.GetIdempotencyKey(context.Background(), expected.UserId, expected.Key)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, stored)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetIdempotencyKey_NotFound(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectQuery(`FROM idempotency_key`).WillReturnRows(sqlmock.NewRows([]string{"userid"}))
+
+	_, err = repo.GetIdempotencyKey(context.Background(), uuid.New(), "unknown")
+
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectExec(`DELETE FROM idempotency_key WHERE expiresAt <= LOCALTIMESTAMP`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteExpiredIdempotencyKeys(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
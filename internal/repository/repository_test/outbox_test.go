@@ -0,0 +1,140 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+func TestCreateOutboxEvent(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	pvzId, eventId := uuid.New(), uuid.New()
+	createdAt := time.Now()
+	mockDB.ExpectQuery(`INSERT INTO outbox \(eventType, pvzId, payload\)\s+VALUES \(\$1, \$2, \$3\)\s+RETURNING eventId`).
+		WithArgs(model.EventPvzCreated, pvzId, `{"city":"Москва"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"eventid", "eventtype", "pvzid", "payload", "createdat"}).
+			AddRow(eventId, model.EventPvzCreated, pvzId, []byte(`{"city":"Москва"}`), createdAt))
+
+	event, err := repo.CreateOutboxEvent(context.Background(), model.EventPvzCreated, pvzId, json.RawMessage(`{"city":"Москва"}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, eventId, event.Id)
+	assert.Equal(t, model.EventPvzCreated, event.Type)
+	assert.Equal(t, createdAt, event.OccurredAt)
+	assert.JSONEq(t, `{"city":"Москва"}`, string(event.Payload))
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTryLockOutbox(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectQuery(`SELECT pg_try_advisory_xact_lock\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+
+	locked, err := repo.TryLockOutbox(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetPendingOutboxEvents(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	now := time.Now()
+	eventId, pvzId := uuid.New(), uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "eventid", "eventtype", "pvzid", "payload", "createdat", "attempts"}).
+		AddRow(7, eventId, model.EventReceptionOpened, pvzId, []byte(`{"id":"1"}`), now, 2)
+
+	mockDB.ExpectQuery(`SELECT o.id, o.eventId, .+FROM outbox o.+NOT EXISTS.+b.nextAttemptAt > LOCALTIMESTAMP.+ORDER BY o.id\s+LIMIT \$1`).
+		WithArgs(50).
+		WillReturnRows(rows)
+
+	events, err := repo.GetPendingOutboxEvents(context.Background(), 50)
+
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(7), events[0].Seq)
+	assert.Equal(t, eventId, events[0].Id)
+	assert.Equal(t, model.EventReceptionOpened, events[0].Type)
+	assert.Equal(t, pvzId, events[0].PvzId)
+	assert.JSONEq(t, `{"id":"1"}`, string(events[0].Payload))
+	assert.Equal(t, 2, events[0].Attempts)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMarkOutboxEventFailed(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectExec(`UPDATE outbox\s+SET attempts = attempts \+ 1, lastError = \$2, nextAttemptAt = LOCALTIMESTAMP \+ make_interval\(secs => \$3\)\s+WHERE id = \$1`).
+		WithArgs(int64(7), "timeout", float64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.MarkOutboxEventFailed(context.Background(), 7, "timeout", 4*time.Second)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestDelayOutboxEvents(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectExec(`UPDATE outbox\s+SET nextAttemptAt = LOCALTIMESTAMP \+ make_interval\(secs => \$2\)\s+WHERE id = ANY\(\$1\) AND publishedAt IS NULL`).
+		WithArgs(pq.Array([]int64{3, 5}), float64(60)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.DelayOutboxEvents(context.Background(), []int64{3, 5}, time.Minute)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestDeletePublishedOutboxEvents(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectExec(`DELETE FROM outbox WHERE publishedAt < LOCALTIMESTAMP - make_interval\(secs => \$1\)`).
+		WithArgs(float64(60 * 60)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := repo.DeletePublishedOutboxEvents(context.Background(), time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
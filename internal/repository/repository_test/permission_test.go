@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/mocks"
+)
+
+func TestGetRolePermissions(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPermissionPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectQuery(`SELECT role, permission FROM role_permission ORDER BY role, permission`).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission"}).
+			AddRow("admin", "*").
+			AddRow("auditor", "audit:read").
+			AddRow("auditor", "pvz:read"))
+
+	roles, err := repo.GetRolePermissions(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"admin":   {"*"},
+		"auditor": {"audit:read", "pvz:read"},
+	}, roles)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
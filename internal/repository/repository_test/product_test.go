@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"pvz/internal/repository"
@@ -38,7 +39,7 @@ func TestCreateProduct_Success(t *testing.T) {
 		AddRow(uuid.New(), time.Now(), product.Type, product.ReceptionId)
 
 	mockDB.ExpectQuery(`INSERT INTO product`).
-		WithArgs(product.Type, product.ReceptionId).
+		WithArgs(product.Type, product.ReceptionId, nil, nil, nil, "{}").
 		WillReturnRows(rows)
 
 	result, err := repo.CreateProduct(context.Background(), product)
@@ -64,9 +65,10 @@ func TestCreateProduct_Error(t *testing.T) {
 	productType := "TestType"
 
 	exactQuery := `
-	INSERT INTO product (type, receptionid)
-	VALUES ($1, $2)
-	RETURNING id, datetime, type, receptionid;
+	INSERT INTO product (type, receptionid, barcode, orderid, weight, attributes)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (receptionid, barcode) WHERE barcode IS NOT NULL AND deletedAt IS NULL DO NOTHING
+	RETURNING id, datetime, type, receptionid, barcode, orderid, weight, attributes;
 	`
 
 	mockLogger.On("Errorw",
@@ -75,7 +77,7 @@ func TestCreateProduct_Error(t *testing.T) {
 		"error", mock.Anything).Return()
 
 	mockDB.ExpectQuery(exactQuery).
-		WithArgs(productType, testUUID).
+		WithArgs(productType, testUUID, nil, nil, nil, "{}").
 		WillReturnError(errors.New("database error"))
 
 	_, err = repo.CreateProduct(context.Background(), model.Product{
@@ -89,6 +91,34 @@ func TestCreateProduct_Error(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestCreateProduct_DuplicateBarcode(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	barcode := "4600000000017"
+	weight := 1200
+	product := model.Product{
+		Type:        "обувь",
+		ReceptionId: uuid.New(),
+		Barcode:     &barcode,
+		Weight:      &weight,
+		Attributes:  []byte(`{"size":42}`),
+	}
+
+	// ON CONFLICT DO NOTHING не возвращает строку
+	mockDB.ExpectQuery(`INSERT INTO product .+ ON CONFLICT \(receptionid, barcode\) WHERE barcode IS NOT NULL AND deletedAt IS NULL DO NOTHING`).
+		WithArgs(product.Type, product.ReceptionId, barcode, nil, weight, `{"size":42}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.CreateProduct(context.Background(), product)
+
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
 func TestGetLastProductIdByReception_Success(t *testing.T) {
 	mockLogger := new(mocks.MockLogger)
 	db, mockDB, err := sqlmock.New()
@@ -107,7 +137,7 @@ func TestGetLastProductIdByReception_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(expectedId)
 
-	mockDB.ExpectQuery(`SELECT id FROM product WHERE receptionId = \$1 ORDER BY datetime DESC LIMIT 1`).
+	mockDB.ExpectQuery(`SELECT id FROM product WHERE receptionId = \$1 AND deletedAt IS NULL ORDER BY datetime DESC LIMIT 1`).
 		WithArgs(receptionId).
 		WillReturnRows(rows)
 
@@ -133,7 +163,7 @@ func TestGetLastProductIdByReception_NoRows(t *testing.T) {
 		"No products found for reception",
 		"receptionId", receptionId).Return()
 
-	mockDB.ExpectQuery(`SELECT id FROM product WHERE receptionId = \$1 ORDER BY datetime DESC LIMIT 1`).
+	mockDB.ExpectQuery(`SELECT id FROM product WHERE receptionId = \$1 AND deletedAt IS NULL ORDER BY datetime DESC LIMIT 1`).
 		WithArgs(receptionId).
 		WillReturnError(sql.ErrNoRows)
 
@@ -161,7 +191,7 @@ func TestGetLastProductIdByReception_DBError(t *testing.T) {
 		"receptionId", receptionId,
 		"error", dbError).Return()
 
-	mockDB.ExpectQuery(`SELECT id FROM product WHERE receptionId = \$1 ORDER BY datetime DESC LIMIT 1`).
+	mockDB.ExpectQuery(`SELECT id FROM product WHERE receptionId = \$1 AND deletedAt IS NULL ORDER BY datetime DESC LIMIT 1`).
 		WithArgs(receptionId).
 		WillReturnError(dbError)
 
@@ -183,16 +213,18 @@ func TestDeleteProductById_Success(t *testing.T) {
 	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
 
 	productId := uuid.New()
+	userId := uuid.New()
 
 	mockLogger.On("Infow",
 		"Product deleted successfully",
-		"productId", productId).Return()
+		"productId", productId,
+		"deletedBy", userId).Return()
 
-	mockDB.ExpectExec(`DELETE FROM product WHERE id = \$1`).
-		WithArgs(productId).
+	mockDB.ExpectExec(`UPDATE product\s+SET deletedAt = now\(\), deletedBy = \$2\s+WHERE id = \$1 AND deletedAt IS NULL`).
+		WithArgs(productId, userId).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.DeleteProductById(context.Background(), productId)
+	err = repo.DeleteProductById(context.Background(), productId, userId)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mockDB.ExpectationsWereMet())
@@ -208,6 +240,7 @@ func TestDeleteProductById_DBError(t *testing.T) {
 	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
 
 	productId := uuid.New()
+	userId := uuid.New()
 	dbError := errors.New("database error")
 
 	mockLogger.On("Errorw",
@@ -215,11 +248,11 @@ func TestDeleteProductById_DBError(t *testing.T) {
 		"productId", productId,
 		"error", dbError).Return()
 
-	mockDB.ExpectExec(`DELETE FROM product WHERE id = \$1`).
-		WithArgs(productId).
+	mockDB.ExpectExec(`UPDATE product`).
+		WithArgs(productId, userId).
 		WillReturnError(dbError)
 
-	err = repo.DeleteProductById(context.Background(), productId)
+	err = repo.DeleteProductById(context.Background(), productId, userId)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to delete product")
@@ -236,18 +269,87 @@ func TestDeleteProductById_NoRowsAffected(t *testing.T) {
 	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
 
 	productId := uuid.New()
+	userId := uuid.New()
 
-	mockLogger.On("Infow",
-		"Product deleted successfully",
-		"productId", productId).Return()
+	// Товар не существует или уже удалён
+	mockLogger.On("Warnw", "Product not found for delete", "productId", productId).Return()
 
-	mockDB.ExpectExec(`DELETE FROM product WHERE id = \$1`).
-		WithArgs(productId).
+	mockDB.ExpectExec(`UPDATE product`).
+		WithArgs(productId, userId).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
-	err = repo.DeleteProductById(context.Background(), productId)
+	err = repo.DeleteProductById(context.Background(), productId, userId)
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGetProductById(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	productId := uuid.New()
+	receptionId := uuid.New()
+	pvzId := uuid.New()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	mockDB.ExpectQuery(`WHERE p.id = \$1 AND p.deletedAt IS NULL`).
+		WithArgs(productId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid", "barcode", "orderid", "weight", "attributes", "pvzid"}).
+			AddRow(productId, now, "обувь", receptionId, nil, nil, nil, []byte("{}"), pvzId))
+
+	product, err := repo.GetProductById(context.Background(), productId)
+	assert.NoError(t, err)
+	assert.Equal(t, pvzId, product.PvzId)
+	assert.Equal(t, receptionId, product.ReceptionId)
+
+	missingId := uuid.New()
+	mockLogger.On("Warnw", "Product not found", "productId", missingId).Return()
+	mockDB.ExpectQuery(`WHERE p.id = \$1 AND p.deletedAt IS NULL`).
+		WithArgs(missingId).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetProductById(context.Background(), missingId)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestUpdateProduct(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	productId := uuid.New()
+	userId := uuid.New()
+	productType := "одежда"
+	update := model.ProductUpdate{Type: &productType}
 
+	mockLogger.On("Infow", "Product updated successfully", "productId", productId, "updatedBy", userId).Return()
+	mockDB.ExpectQuery(`UPDATE product\s+SET type = COALESCE\(\$2, type\),\s+updatedAt = now\(\),\s+updatedBy = \$3\s+WHERE id = \$1 AND deletedAt IS NULL`).
+		WithArgs(productId, productType, userId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid"}).
+			AddRow(productId, time.Now(), productType, uuid.New()))
+
+	product, err := repo.UpdateProduct(context.Background(), productId, update, userId)
 	assert.NoError(t, err)
+	assert.Equal(t, productType, product.Type)
+
+	mockLogger.On("Warnw", "Product not found for update", "productId", productId).Return()
+	mockDB.ExpectQuery(`UPDATE product`).
+		WithArgs(productId, productType, userId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.UpdateProduct(context.Background(), productId, update, userId)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 	mockLogger.AssertExpectations(t)
 }
@@ -290,7 +392,7 @@ func TestGetProductsByReceptionID_Success(t *testing.T) {
 		rows.AddRow(p.Id, p.DateTime, p.Type, p.ReceptionId)
 	}
 
-	mockDB.ExpectQuery(`SELECT id, datetime, type, receptionId FROM product WHERE receptionId = \$1`).
+	mockDB.ExpectQuery(`SELECT id, datetime, type, receptionid, barcode, orderid, weight, attributes FROM product WHERE receptionId = \$1 AND deletedAt IS NULL`).
 		WithArgs(receptionId).
 		WillReturnRows(rows)
 
@@ -323,7 +425,7 @@ func TestGetProductsByReceptionID_EmptyResult(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid"})
 
-	mockDB.ExpectQuery(`SELECT id, datetime, type, receptionId FROM product WHERE receptionId = \$1`).
+	mockDB.ExpectQuery(`SELECT id, datetime, type, receptionid, barcode, orderid, weight, attributes FROM product WHERE receptionId = \$1 AND deletedAt IS NULL`).
 		WithArgs(receptionId).
 		WillReturnRows(rows)
 
@@ -355,7 +457,7 @@ func TestGetProductsByReceptionID_DBError(t *testing.T) {
 		"error", dbError,
 		"receptionId", receptionId).Return()
 
-	mockDB.ExpectQuery(`SELECT id, datetime, type, receptionId FROM product WHERE receptionId = \$1`).
+	mockDB.ExpectQuery(`SELECT id, datetime, type, receptionid, barcode, orderid, weight, attributes FROM product WHERE receptionId = \$1 AND deletedAt IS NULL`).
 		WithArgs(receptionId).
 		WillReturnError(dbError)
 
@@ -367,3 +469,105 @@ func TestGetProductsByReceptionID_DBError(t *testing.T) {
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 	mockLogger.AssertExpectations(t)
 }
+
+func TestCreateProducts_Success(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	receptionId := uuid.New()
+	barcode := "4600000000017"
+	products := []model.Product{
+		{Id: uuid.New(), Type: "обувь", Barcode: &barcode, Attributes: []byte(`{"size":42}`)},
+		{Id: uuid.New(), Type: "одежда"},
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid"})
+	for _, p := range products {
+		rows.AddRow(p.Id, time.Now(), p.Type, receptionId)
+	}
+
+	mockDB.ExpectQuery(`INSERT INTO product \(id, datetime, type, receptionid, barcode, orderid, weight, attributes\)\s+SELECT .+ FROM unnest\(.+\)\s+WITH ORDINALITY .+ ON CONFLICT DO NOTHING`).
+		WithArgs(
+			pq.StringArray{products[0].Id.String(), products[1].Id.String()},
+			pq.StringArray{"обувь", "одежда"},
+			pq.Array([]sql.NullString{{String: "4600000000017", Valid: true}, {}}),
+			pq.Array([]sql.NullString{{}, {}}),
+			pq.Array([]sql.NullInt64{{}, {}}),
+			pq.StringArray{`{"size":42}`, "{}"},
+			receptionId).
+		WillReturnRows(rows)
+	mockLogger.On("Infow", "Successfully created products", "receptionId", receptionId, "count", 2).Return()
+
+	result, err := repo.CreateProducts(context.Background(), receptionId, products)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, products[1].Id, result[1].Id)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestCreateProducts_DBError(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	receptionId := uuid.New()
+	dbErr := errors.New("database error")
+
+	mockDB.ExpectQuery(`INSERT INTO product`).WillReturnError(dbErr)
+	mockLogger.On("Errorw", "Failed to create products", "receptionId", receptionId, "count", 1, "error", dbErr).Return()
+
+	result, err := repo.CreateProducts(context.Background(), receptionId, []model.Product{{Id: uuid.New(), Type: "обувь"}})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, dbErr)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGetProductsByBarcode(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	barcode := "4600000000017"
+	orderId := "order-1"
+	weight := 350
+	expected := model.ProductWithPvz{
+		Product: model.Product{
+			Id:          uuid.New(),
+			DateTime:    time.Now().UTC().Truncate(time.Microsecond),
+			Type:        "обувь",
+			ReceptionId: uuid.New(),
+			Barcode:     &barcode,
+			OrderId:     &orderId,
+			Weight:      &weight,
+			Attributes:  []byte(`{"size":42}`),
+		},
+		PvzId: uuid.New(),
+	}
+
+	mockDB.ExpectQuery(`FROM product p\s+JOIN reception r ON r.id = p.receptionId\s+WHERE p.barcode = \$1`).
+		WithArgs(barcode).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid", "barcode", "orderid", "weight", "attributes", "pvzid"}).
+			AddRow(expected.Id, expected.DateTime, expected.Type, expected.ReceptionId, barcode, orderId, weight, []byte(`{"size":42}`), expected.PvzId))
+	mockLogger.On("Infow", "Fetched products by barcode", "barcode", barcode, "count", 1).Return()
+
+	result, err := repo.GetProductsByBarcode(context.Background(), barcode)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ProductWithPvz{expected}, result)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
@@ -0,0 +1,295 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+type pvzListFixture struct {
+	pvz        []model.Pvz
+	receptions []model.Reception
+	products   []model.Product
+}
+
+func newPvzListFixture(pvzCount, receptionsPerPvz, productsPerReception int) pvzListFixture {
+	var f pvzListFixture
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	for i := 0; i < pvzCount; i++ {
+		pvz := model.Pvz{Id: uuid.New(), City: "Москва", RegistrationDate: now.Add(-time.Duration(i) * time.Hour)}
+		f.pvz = append(f.pvz, pvz)
+
+		for j := 0; j < receptionsPerPvz; j++ {
+			rec := model.Reception{Id: uuid.New(), DateTime: now.Add(time.Duration(j) * time.Minute), PvzId: pvz.Id, Status: "close"}
+			f.receptions = append(f.receptions, rec)
+
+			for k := 0; k < productsPerReception; k++ {
+				f.products = append(f.products, model.Product{
+					Id: uuid.New(), DateTime: now.Add(time.Duration(k) * time.Second), Type: "обувь", ReceptionId: rec.Id,
+				})
+			}
+		}
+	}
+
+	return f
+}
+
+// expect регистрирует ожидаемые запросы и возвращает их количество
+func (f pvzListFixture) expect(mockDB sqlmock.Sqlmock) int {
+	pvzRows := sqlmock.NewRows([]string{"id", "registrationdate", "city"})
+	pvzIds := make([]string, 0, len(f.pvz))
+	for _, p := range f.pvz {
+		pvzRows.AddRow(p.Id, p.RegistrationDate, p.City)
+		pvzIds = append(pvzIds, p.Id.String())
+	}
+	mockDB.ExpectQuery("FROM pvz p").WillReturnRows(pvzRows)
+
+	if len(f.pvz) == 0 {
+		return 1
+	}
+
+	receptionRows := sqlmock.NewRows([]string{"id", "datetime", "pvzid", "status"})
+	receptionIds := make([]string, 0, len(f.receptions))
+	for _, r := range f.receptions {
+		receptionRows.AddRow(r.Id, r.DateTime, r.PvzId, r.Status)
+		receptionIds = append(receptionIds, r.Id.String())
+	}
+	mockDB.ExpectQuery("FROM reception r").WithArgs(pq.StringArray(pvzIds), nil, nil, "", "").WillReturnRows(receptionRows)
+
+	if len(f.receptions) == 0 {
+		return 2
+	}
+
+	productRows := sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid"})
+	for _, p := range f.products {
+		productRows.AddRow(p.Id, p.DateTime, p.Type, p.ReceptionId)
+	}
+	mockDB.ExpectQuery("FROM product").WithArgs(pq.StringArray(receptionIds)).WillReturnRows(productRows)
+	return 3
+}
+
+func (f pvzListFixture) expectLogs(mockLogger *mocks.MockLogger, limit, offset int) {
+	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
+		"startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", "", "limit", limit, "offset", offset).Return()
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", len(f.pvz)).Return()
+	mockLogger.On("Infow", "Successfully assembled Pvz list with receptions",
+		"pvzCount", len(f.pvz), "receptionCount", len(f.receptions), "productCount", len(f.products)).Return()
+}
+
+func TestGetPvzListWithReceptions_Success(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	fixture := newPvzListFixture(2, 2, 3)
+	fixture.expectLogs(mockLogger, 10, 0)
+	fixture.expect(mockDB)
+
+	result, err := repo.GetPvzListWithReceptions(context.Background(), 10, 0, model.PvzFilter{})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	for i, item := range result {
+		assert.Equal(t, fixture.pvz[i], item.Pvz)
+		assert.Len(t, item.Receptions, 2)
+		for _, rec := range item.Receptions {
+			assert.Equal(t, item.Pvz.Id, rec.Reception.PvzId)
+			assert.Len(t, rec.Products, 3)
+			for _, p := range rec.Products {
+				assert.Equal(t, rec.Reception.Id, p.ReceptionId)
+			}
+		}
+	}
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGetPvzListWithReceptions_EmptyPage(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	fixture := newPvzListFixture(0, 0, 0)
+	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
+		"startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", "", "limit", 10, "offset", 0).Return()
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 0).Return()
+	fixture.expect(mockDB)
+
+	result, err := repo.GetPvzListWithReceptions(context.Background(), 10, 0, model.PvzFilter{})
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGetPvzListWithReceptions_ReceptionsError(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	fixture := newPvzListFixture(1, 0, 0)
+	dbErr := errors.New("receptions failed")
+
+	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
+		"startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", "", "limit", 10, "offset", 0).Return()
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 1).Return()
+	mockLogger.On("Errorw", "Failed to fetch receptions for Pvz list", "error", dbErr).Return()
+
+	mockDB.ExpectQuery("FROM pvz p").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "registrationdate", "city"}).
+			AddRow(fixture.pvz[0].Id, fixture.pvz[0].RegistrationDate, fixture.pvz[0].City))
+	mockDB.ExpectQuery("FROM reception").WillReturnError(dbErr)
+
+	result, err := repo.GetPvzListWithReceptions(context.Background(), 10, 0, model.PvzFilter{})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, dbErr)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGetPvzListWithReceptions_Filter(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	startDate := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	registeredFrom := startDate.Add(-24 * time.Hour)
+	filter := model.PvzFilter{
+		StartDate:       &startDate,
+		ReceptionStatus: model.ReceptionStatusClose,
+		ProductType:     "обувь",
+		IncludeEmpty:    true,
+		City:            "Казань",
+		RegisteredFrom:  &registeredFrom,
+	}
+
+	// ПВЗ без приёмок в окне тоже попадает в список, но без вложенных приёмок
+	withReceptions, empty := uuid.New(), uuid.New()
+	receptionId := uuid.New()
+
+	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
+		"startDate", &startDate, "endDate", (*time.Time)(nil), "status", "", "limit", 10, "offset", 0).Return()
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 2).Return()
+	mockLogger.On("Infow", "Successfully assembled Pvz list with receptions",
+		"pvzCount", 2, "receptionCount", 1, "productCount", 0).Return()
+
+	mockDB.ExpectQuery("FROM pvz p").
+		WithArgs(true, &startDate, nil, "close", "обувь", "", "Казань", &registeredFrom, nil, nil, nil, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "city"}).AddRow(withReceptions, "Казань").AddRow(empty, "Казань"))
+	mockDB.ExpectQuery("FROM reception r").
+		WithArgs(pq.StringArray{withReceptions.String(), empty.String()}, &startDate, nil, "close", "обувь").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "pvzid", "status"}).
+			AddRow(receptionId, startDate.Add(time.Minute), withReceptions, "close"))
+	mockDB.ExpectQuery("FROM product").
+		WithArgs(pq.StringArray{receptionId.String()}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "type", "receptionid"}))
+
+	result, err := repo.GetPvzListWithReceptions(context.Background(), 10, 0, filter)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.Len(t, result[0].Receptions, 1)
+		assert.Empty(t, result[1].Receptions)
+	}
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+// pvzListSizes - размеры страницы ПВЗ: приёмок на ПВЗ и товаров на приёмку
+var pvzListSizes = []struct {
+	receptionsPerPvz     int
+	productsPerReception int
+}{
+	{1, 1},
+	{10, 10},
+	{50, 50},
+}
+
+// TestGetPvzListWithReceptions_QueryCount проверяет, что число запросов к БД
+// остаётся равным трём независимо от количества приёмок и товаров:
+// sqlmock падает на любом неожиданном запросе.
+func TestGetPvzListWithReceptions_QueryCount(t *testing.T) {
+	for _, size := range pvzListSizes {
+		t.Run(fmt.Sprintf("receptions=%d/products=%d", size.receptionsPerPvz, size.productsPerReception), func(t *testing.T) {
+			mockLogger := new(mocks.MockLogger)
+			db, mockDB, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+			fixture := newPvzListFixture(10, size.receptionsPerPvz, size.productsPerReception)
+			fixture.expectLogs(mockLogger, 10, 0)
+
+			assert.Equal(t, 3, fixture.expect(mockDB))
+			_, err = repo.GetPvzListWithReceptions(context.Background(), 10, 0, model.PvzFilter{})
+
+			assert.NoError(t, err)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+// BenchmarkGetPvzListWithReceptions измеряет сборку страницы ПВЗ разного размера
+func BenchmarkGetPvzListWithReceptions(b *testing.B) {
+	for _, size := range pvzListSizes {
+		b.Run(fmt.Sprintf("receptions=%d/products=%d", size.receptionsPerPvz, size.productsPerReception), func(b *testing.B) {
+			mockLogger := new(mocks.MockLogger)
+
+			db, mockDB, err := sqlmock.New()
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer db.Close()
+
+			repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+			fixture := newPvzListFixture(10, size.receptionsPerPvz, size.productsPerReception)
+			fixture.expectLogs(mockLogger, 10, 0)
+
+			queries := 0
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				queries += fixture.expect(mockDB)
+				b.StartTimer()
+
+				if _, err := repo.GetPvzListWithReceptions(context.Background(), 10, 0, model.PvzFilter{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+
+			if err := mockDB.ExpectationsWereMet(); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+		})
+	}
+}
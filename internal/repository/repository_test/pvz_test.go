@@ -41,7 +41,7 @@ func TestCreatePvz_Success(t *testing.T) {
 	exactQuery := `
 		INSERT INTO pvz (city)
 		VALUES ($1)
-		RETURNING id, city, registrationDate
+		RETURNING id, registrationDate, city, name, address, status, deactivatedAt
 	`
 
 	rows := sqlmock.NewRows([]string{"id", "city", "registrationdate"}).
@@ -82,7 +82,7 @@ func TestCreatePvz_DBError(t *testing.T) {
 	exactQuery := `
 		INSERT INTO pvz (city)
 		VALUES ($1)
-		RETURNING id, city, registrationDate
+		RETURNING id, registrationDate, city, name, address, status, deactivatedAt
 	`
 
 	mockDB.ExpectQuery(exactQuery).
@@ -130,6 +130,7 @@ func TestGetPvzListByReceptionDate_Success(t *testing.T) {
 	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
 		"startDate", &startDate,
 		"endDate", &endDate,
+		"status", "",
 		"limit", limit,
 		"offset", offset).Return()
 
@@ -137,13 +138,23 @@ func TestGetPvzListByReceptionDate_Success(t *testing.T) {
 
 	// SQL-запрос
 	query := `
-		SELECT DISTINCT p.id, p.registrationDate, p.city
+		SELECT p.id, p.registrationDate, p.city, p.name, p.address, p.status, p.deactivatedAt
 		FROM pvz p
-		JOIN reception r ON r.pvzId = p.id
-		WHERE ($1::timestamp IS NULL OR r.dateTime >= $1)
-		  AND ($2::timestamp IS NULL OR r.dateTime <= $2)
-		ORDER BY p.registrationDate DESC
-		LIMIT $3 OFFSET $4
+		WHERE ($1 OR EXISTS (
+			SELECT 1 FROM reception r
+			WHERE r.pvzId = p.id
+			  AND ($2::timestamp IS NULL OR r.dateTime >= $2)
+			  AND ($3::timestamp IS NULL OR r.dateTime <= $3)
+			  AND ($4 = '' OR r.status = $4)
+			  AND ($5 = '' OR EXISTS (SELECT 1 FROM product pr WHERE pr.receptionId = r.id AND pr.type = $5 AND pr.deletedAt IS NULL))
+		))
+		  AND ($6 = '' OR p.status = $6)
+		  AND ($7 = '' OR p.city = $7)
+		  AND ($8::timestamp IS NULL OR p.registrationDate >= $8)
+		  AND ($9::timestamp IS NULL OR p.registrationDate <= $9)
+		  AND ($10::timestamp IS NULL OR (p.registrationDate, p.id) < ($10, $11::uuid))
+		ORDER BY p.registrationDate DESC, p.id DESC
+		LIMIT $12 OFFSET $13
 	`
 
 	rows := sqlmock.NewRows([]string{"id", "registrationdate", "city"}).
@@ -151,11 +162,11 @@ func TestGetPvzListByReceptionDate_Success(t *testing.T) {
 		AddRow(expectedPvz[1].Id, expectedPvz[1].RegistrationDate, expectedPvz[1].City)
 
 	mockDB.ExpectQuery(query).
-		WithArgs(startDate, endDate, limit, offset).
+		WithArgs(false, startDate, endDate, "", "", "", "", nil, nil, nil, nil, limit, offset).
 		WillReturnRows(rows)
 
 	// Вызов метода
-	result, err := repo.GetPvzListByReceptionDate(context.Background(), limit, offset, &startDate, &endDate)
+	result, err := repo.GetPvzListByReceptionDate(context.Background(), limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedPvz, result)
@@ -182,6 +193,7 @@ func TestGetPvzListByReceptionDate_DBError(t *testing.T) {
 	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
 		"startDate", &startDate,
 		"endDate", &endDate,
+		"status", "",
 		"limit", limit,
 		"offset", offset).Return()
 
@@ -189,20 +201,30 @@ func TestGetPvzListByReceptionDate_DBError(t *testing.T) {
 
 	// SQL-запрос
 	query := `
-		SELECT DISTINCT p.id, p.registrationDate, p.city
+		SELECT p.id, p.registrationDate, p.city, p.name, p.address, p.status, p.deactivatedAt
 		FROM pvz p
-		JOIN reception r ON r.pvzId = p.id
-		WHERE ($1::timestamp IS NULL OR r.dateTime >= $1)
-		  AND ($2::timestamp IS NULL OR r.dateTime <= $2)
-		ORDER BY p.registrationDate DESC
-		LIMIT $3 OFFSET $4
+		WHERE ($1 OR EXISTS (
+			SELECT 1 FROM reception r
+			WHERE r.pvzId = p.id
+			  AND ($2::timestamp IS NULL OR r.dateTime >= $2)
+			  AND ($3::timestamp IS NULL OR r.dateTime <= $3)
+			  AND ($4 = '' OR r.status = $4)
+			  AND ($5 = '' OR EXISTS (SELECT 1 FROM product pr WHERE pr.receptionId = r.id AND pr.type = $5 AND pr.deletedAt IS NULL))
+		))
+		  AND ($6 = '' OR p.status = $6)
+		  AND ($7 = '' OR p.city = $7)
+		  AND ($8::timestamp IS NULL OR p.registrationDate >= $8)
+		  AND ($9::timestamp IS NULL OR p.registrationDate <= $9)
+		  AND ($10::timestamp IS NULL OR (p.registrationDate, p.id) < ($10, $11::uuid))
+		ORDER BY p.registrationDate DESC, p.id DESC
+		LIMIT $12 OFFSET $13
 	`
 
 	mockDB.ExpectQuery(query).
-		WithArgs(startDate, endDate, limit, offset).
+		WithArgs(false, startDate, endDate, "", "", "", "", nil, nil, nil, nil, limit, offset).
 		WillReturnError(dbError)
 
-	result, err := repo.GetPvzListByReceptionDate(context.Background(), limit, offset, &startDate, &endDate)
+	result, err := repo.GetPvzListByReceptionDate(context.Background(), limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -210,3 +232,99 @@ func TestGetPvzListByReceptionDate_DBError(t *testing.T) {
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 	mockLogger.AssertExpectations(t)
 }
+
+func TestGetPvzListByReceptionDate_AfterCursor(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	cursor := model.PvzCursor{RegistrationDate: time.Now().UTC().Truncate(time.Microsecond), Id: uuid.New()}
+
+	mockLogger.On("Infow", "Executing GetPvzListByReceptionDate query",
+		"startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", "", "limit", 3, "offset", 0).Return()
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 0).Return()
+
+	// Курсор передаётся в запрос парой (registrationDate, id)
+	mockDB.ExpectQuery(`\(p\.registrationDate, p\.id\) < \(\$10, \$11::uuid\)\)\s+ORDER BY p\.registrationDate DESC, p\.id DESC`).
+		WithArgs(false, nil, nil, "", "", "", "", nil, nil, cursor.RegistrationDate, cursor.Id, 3, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := repo.GetPvzListByReceptionDate(context.Background(), 3, 0, model.PvzFilter{After: &cursor})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestSetPvzStatus_Deactivate(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	pvzId := uuid.New()
+	deactivatedAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	mockDB.ExpectQuery(`UPDATE pvz\s+SET status = \$2,\s+deactivatedAt = CASE WHEN \$2 = 'inactive' THEN now\(\) END\s+WHERE id = \$1\s+RETURNING`).
+		WithArgs(pvzId, model.PvzStatusInactive).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "registrationdate", "city", "name", "address", "status", "deactivatedat"}).
+			AddRow(pvzId, deactivatedAt, "Москва", "", "", model.PvzStatusInactive, deactivatedAt))
+	mockLogger.On("Infow", "Pvz status changed", "pvzId", pvzId, "status", model.PvzStatusInactive).Return()
+
+	pvz, err := repo.SetPvzStatus(context.Background(), pvzId, model.PvzStatusInactive)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PvzStatusInactive, pvz.Status)
+	assert.Equal(t, &deactivatedAt, pvz.DeactivatedAt)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestUpdatePvz_NotFound(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	pvzId := uuid.New()
+	name := "ПВЗ"
+
+	mockDB.ExpectQuery(`UPDATE pvz`).
+		WithArgs(pvzId, nil, &name, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mockLogger.On("Warnw", "Pvz not found for update", "pvzId", pvzId).Return()
+
+	_, err = repo.UpdatePvz(context.Background(), pvzId, model.PvzUpdate{Name: &name})
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestDeletePvz_NotFound(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPvzPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+	pvzId := uuid.New()
+
+	mockDB.ExpectExec(`DELETE FROM pvz WHERE id = \$1`).
+		WithArgs(pvzId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mockLogger.On("Warnw", "Pvz not found for delete", "pvzId", pvzId).Return()
+
+	err = repo.DeletePvz(context.Background(), pvzId)
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 	"pvz/mocks"
@@ -188,60 +189,123 @@ func TestGetInProgressReception_DBError(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
-func TestCloseReception_Success(t *testing.T) {
+func TestUpdateReceptionStatus(t *testing.T) {
 	mockLogger := new(mocks.MockLogger)
-	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	db, mockDB, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
 	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
-	pvzId := uuid.New()
 
-	mockLogger.On("Infow", "Successfully closed reception(s)", "pvzId", pvzId).Return()
+	actorId := uuid.New()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	change := model.ReceptionStatusChange{
+		ReceptionId: uuid.New(),
+		From:        model.ReceptionStatusClose,
+		To:          model.ReceptionStatusVerified,
+		By:          &actorId,
+	}
 
-	query := `
-		UPDATE reception
-		SET status = 'close'
-		WHERE pvzId = $1 AND status = 'in_progress'
-	`
+	mockLogger.On("Infow", "Reception status changed", "receptionId", change.ReceptionId,
+		"from", change.From, "to", change.To).Return()
 
-	mockDB.ExpectExec(query).
-		WithArgs(pvzId).
-		WillReturnResult(sqlmock.NewResult(0, 1)) // 1 row affected
+	// Время перехода задаёт база, как и для забытых приёмок
+	mockDB.ExpectQuery(`UPDATE reception\s+SET status = \$3, verifiedAt = LOCALTIMESTAMP, verifiedBy = \$4\s+WHERE id = \$1 AND status = \$2\s+RETURNING id,`).
+		WithArgs(change.ReceptionId, change.From, change.To, change.By).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "verifiedat", "verifiedby"}).
+			AddRow(change.ReceptionId, change.To, now, actorId))
 
-	err = repo.CloseReception(context.Background(), pvzId)
+	reception, err := repo.UpdateReceptionStatus(context.Background(), change)
 
 	assert.NoError(t, err)
+	assert.Equal(t, model.ReceptionStatusVerified, reception.Status)
+	assert.Equal(t, &now, reception.VerifiedAt)
+	assert.Equal(t, &actorId, reception.VerifiedBy)
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 	mockLogger.AssertExpectations(t)
 }
 
-func TestCloseReception_DBError(t *testing.T) {
+func TestUpdateReceptionStatus_Reopen(t *testing.T) {
 	mockLogger := new(mocks.MockLogger)
-	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	db, mockDB, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
 	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
-	pvzId := uuid.New()
-	dbErr := errors.New("connection lost")
 
-	mockLogger.On("Errorw", "Failed to close reception", "pvzId", pvzId, "error", dbErr).Return()
+	change := model.ReceptionStatusChange{
+		ReceptionId: uuid.New(),
+		From:        model.ReceptionStatusClose,
+		To:          model.ReceptionStatusInProgress,
+	}
 
-	query := `
-		UPDATE reception
-		SET status = 'close'
-		WHERE pvzId = $1 AND status = 'in_progress'
-	`
+	// Повторное открытие сбрасывает закрытие и пометку забытой приёмки
+	mockDB.ExpectQuery(`SET status = \$3, reopenedAt = LOCALTIMESTAMP, reopenedBy = \$4,\s+closedAt = NULL, closedBy = NULL, staleAt = NULL, staleReason = NULL\s+WHERE`).
+		WithArgs(change.ReceptionId, change.From, change.To, change.By).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(change.ReceptionId, change.To))
 
-	mockDB.ExpectExec(query).
-		WithArgs(pvzId).
-		WillReturnError(dbErr)
+	reception, err := repo.UpdateReceptionStatus(context.Background(), change)
 
-	err = repo.CloseReception(context.Background(), pvzId)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReceptionStatusInProgress, reception.Status)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReceptionClosedWithin(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	receptionId := uuid.New()
+	query := `SELECT COALESCE\(closedAt >= LOCALTIMESTAMP - make_interval\(secs => \$2\), false\)\s+FROM reception\s+WHERE id = \$1`
+	mockDB.ExpectQuery(query).
+		WithArgs(receptionId, float64(60*60)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(true))
+	mockDB.ExpectQuery(query).
+		WithArgs(receptionId, float64(60*60)).
+		WillReturnError(sql.ErrNoRows)
+
+	within, err := repo.ReceptionClosedWithin(context.Background(), receptionId, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, within)
+
+	_, err = repo.ReceptionClosedWithin(context.Background(), receptionId, time.Hour)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateReceptionStatus_Errors(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	// Статус успел измениться
+	change := model.ReceptionStatusChange{ReceptionId: uuid.New(), From: model.ReceptionStatusInProgress, To: model.ReceptionStatusCancelled}
+	mockLogger.On("Warnw", "Reception not found for status change", "receptionId", change.ReceptionId, "status", change.From).Return()
+	mockDB.ExpectQuery(`UPDATE reception`).WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.UpdateReceptionStatus(context.Background(), change)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	dbErr := errors.New("connection lost")
+	mockLogger.On("Errorw", "Failed to change reception status", "receptionId", change.ReceptionId, "status", change.To, "error", dbErr).Return()
+	mockDB.ExpectQuery(`UPDATE reception`).WillReturnError(dbErr)
+
+	_, err = repo.UpdateReceptionStatus(context.Background(), change)
+	assert.ErrorIs(t, err, dbErr)
+
+	// Неизвестный статус не доходит до базы
+	change.To = "archived"
+	_, err = repo.UpdateReceptionStatus(context.Background(), change)
+	assert.ErrorContains(t, err, `unknown reception status "archived"`)
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to close reception")
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 	mockLogger.AssertExpectations(t)
 }
@@ -260,7 +324,7 @@ func TestGetReceptionsByPvzID_Success(t *testing.T) {
 		AddRow(uuid.New(), time.Now(), pvzId, "in_progress").
 		AddRow(uuid.New(), time.Now(), pvzId, "close")
 
-	query := `SELECT id, dateTime, pvzId, status FROM reception WHERE pvzId = \$1`
+	query := `SELECT id, dateTime, pvzId, status, .+, reopenedBy FROM reception WHERE pvzId = \$1`
 
 	mockLogger.On("Infow", "Executing GetReceptionsByPvzID query", "pvzId", pvzId).Return()
 	mockLogger.On("Infow", "Successfully retrieved receptions list", "count", 2, "pvzId", pvzId).Return()
@@ -289,7 +353,7 @@ func TestGetReceptionsByPvzID_Error(t *testing.T) {
 	pvzId := uuid.New()
 	dbErr := errors.New("query failed")
 
-	query := `SELECT id, dateTime, pvzId, status FROM reception WHERE pvzId = \$1`
+	query := `SELECT id, dateTime, pvzId, status, .+, reopenedBy FROM reception WHERE pvzId = \$1`
 
 	mockLogger.On("Infow", "Executing GetReceptionsByPvzID query", "pvzId", pvzId).Return()
 	mockLogger.On("Errorw", "Failed to execute query in GetReceptionsByPvzID", "error", dbErr, "pvzId", pvzId).Return()
@@ -305,3 +369,185 @@ func TestGetReceptionsByPvzID_Error(t *testing.T) {
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 	mockLogger.AssertExpectations(t)
 }
+
+func TestGetReceptionById(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	expected := model.Reception{Id: uuid.New(), DateTime: time.Now().UTC().Truncate(time.Microsecond), PvzId: uuid.New(), Status: "close"}
+
+	mockDB.ExpectQuery(`SELECT id, dateTime, pvzId, status, staleAt, staleReason,\s+closedAt, closedBy, .+, reopenedBy FROM reception WHERE id = \$1`).
+		WithArgs(expected.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "pvzid", "status"}).
+			AddRow(expected.Id, expected.DateTime, expected.PvzId, expected.Status))
+
+	result, err := repo.GetReceptionById(context.Background(), expected.Id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	missing := uuid.New()
+	mockDB.ExpectQuery(`FROM reception WHERE id = \$1`).
+		WithArgs(missing).
+		WillReturnError(sql.ErrNoRows)
+	mockLogger.On("Warnw", "Reception not found", "receptionId", missing).Return()
+
+	_, err = repo.GetReceptionById(context.Background(), missing)
+
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestGetReceptionList(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	pvzId := uuid.New()
+	startDate := time.Now().Add(-time.Hour)
+	filter := model.ReceptionFilter{Status: "close", StartDate: &startDate}
+
+	mockLogger.On("Infow", "Executing GetReceptionList query", "pvzId", pvzId, "status", "close",
+		"startDate", &startDate, "endDate", (*time.Time)(nil), "limit", 10, "offset", 20).Return()
+
+	mockDB.ExpectQuery(`FROM reception\s+WHERE pvzId = \$1\s+AND \(\$2 = '' OR status = \$2\)\s+AND \(\$3::timestamp IS NULL OR dateTime >= \$3\)\s+AND \(\$4::timestamp IS NULL OR dateTime <= \$4\)\s+ORDER BY dateTime DESC, id DESC\s+LIMIT \$5 OFFSET \$6`).
+		WithArgs(pvzId, "close", &startDate, nil, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "pvzid", "status"}).
+			AddRow(uuid.New(), time.Now(), pvzId, "close"))
+
+	result, err := repo.GetReceptionList(context.Background(), pvzId, 10, 20, filter)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestTryLockStaleReceptions(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectQuery(`SELECT pg_try_advisory_xact_lock\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+
+	locked, err := repo.TryLockStaleReceptions(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetStaleReceptions(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	receptionId, pvzId := uuid.New(), uuid.New()
+	lastActivity := time.Now().Add(-20 * time.Hour).UTC().Truncate(time.Microsecond)
+
+	// Активность - по товарам приёмки, уже помеченные приёмки не выбираются
+	mockDB.ExpectQuery(`GREATEST\(r.dateTime, r.reopenedAt, MAX\(p.dateTime\), MAX\(p.updatedAt\), MAX\(p.deletedAt\)\).+`+
+		`WHERE r.status = 'in_progress' AND r.staleAt IS NULL.+`+
+		`WHERE lastActivityAt < LOCALTIMESTAMP - make_interval\(secs => \$1\)\s+ORDER BY lastActivityAt\s+LIMIT \$2`).
+		WithArgs(float64(12*60*60), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "pvzid", "status", "staleat", "stalereason", "lastactivityat"}).
+			AddRow(receptionId, lastActivity, pvzId, model.ReceptionStatusInProgress, nil, nil, lastActivity))
+
+	receptions, err := repo.GetStaleReceptions(context.Background(), 12*time.Hour, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.StaleReception{{
+		Reception:      model.Reception{Id: receptionId, DateTime: lastActivity, PvzId: pvzId, Status: model.ReceptionStatusInProgress},
+		LastActivityAt: lastActivity,
+	}}, receptions)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMarkReceptionStale(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	receptionId, pvzId := uuid.New(), uuid.New()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	reason := "no activity for 12h0m0s"
+	query := `UPDATE reception r\s+SET status = CASE WHEN \$3 THEN 'close' ELSE r.status END,\s+closedAt = CASE WHEN \$3 THEN LOCALTIMESTAMP ELSE r.closedAt END,\s+staleAt = LOCALTIMESTAMP,\s+staleReason = \$2\s+` +
+		`WHERE r.id = \$1\s+AND r.status = 'in_progress'\s+AND r.staleAt IS NULL.+< LOCALTIMESTAMP - make_interval\(secs => \$4\)`
+
+	mockDB.ExpectQuery(query).
+		WithArgs(receptionId, reason, true, float64(12*60*60)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "pvzid", "status", "staleat", "stalereason"}).
+			AddRow(receptionId, now, pvzId, model.ReceptionStatusClose, now, reason))
+
+	reception, err := repo.MarkReceptionStale(context.Background(), receptionId, 12*time.Hour, reason, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReceptionStatusClose, reception.Status)
+	assert.Equal(t, &now, reception.StaleAt)
+	assert.Equal(t, &reason, reception.StaleReason)
+
+	// Приёмку закрыли или в ней появилась активность после выборки
+	mockDB.ExpectQuery(query).
+		WithArgs(receptionId, reason, false, float64(12*60*60)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.MarkReceptionStale(context.Background(), receptionId, 12*time.Hour, reason, false)
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMarkReceptionStale_Reopened(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	// Модератор открывает закрытую как забытую приёмку: пометка сбрасывается
+	receptionId := uuid.New()
+	mockDB.ExpectQuery(`SET status = \$3, reopenedAt = .+ staleAt = NULL, staleReason = NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(receptionId, model.ReceptionStatusInProgress))
+
+	_, err = repo.UpdateReceptionStatus(context.Background(), model.ReceptionStatusChange{
+		ReceptionId: receptionId,
+		From:        model.ReceptionStatusClose,
+		To:          model.ReceptionStatusInProgress,
+	})
+	assert.NoError(t, err)
+
+	// Следующий запуск задачи считает повторное открытие активностью и не
+	// закрывает приёмку снова, пока с него не пройдёт idleFor
+	activity := `GREATEST\(r.dateTime, r.reopenedAt, MAX\(p.dateTime\), MAX\(p.updatedAt\), MAX\(p.deletedAt\)\)`
+	mockDB.ExpectQuery(activity+`.+WHERE lastActivityAt < LOCALTIMESTAMP - make_interval\(secs => \$1\)`).
+		WithArgs(float64(12*60*60), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lastactivityat"}))
+	mockDB.ExpectQuery(`UPDATE reception r.+AND \( SELECT `+activity+`.+< LOCALTIMESTAMP - make_interval\(secs => \$4\)`).
+		WithArgs(receptionId, "no activity for 12h0m0s", true, float64(12*60*60)).
+		WillReturnError(sql.ErrNoRows)
+
+	stale, err := repo.GetStaleReceptions(context.Background(), 12*time.Hour, 50)
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+
+	_, err = repo.MarkReceptionStale(context.Background(), receptionId, 12*time.Hour, "no activity for 12h0m0s", true)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
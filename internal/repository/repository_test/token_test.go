@@ -0,0 +1,128 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+func TestGetRefreshTokenForUpdate_Success(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTokenPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	expected := model.RefreshToken{
+		Id:        uuid.New(),
+		UserId:    uuid.New(),
+		FamilyId:  uuid.New(),
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+
+	mockDB.ExpectQuery(`SELECT id, userId, familyId, tokenHash, expiresAt, revokedAt, replacedBy\s+FROM refresh_token\s+WHERE tokenHash = \$1\s+FOR UPDATE`).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "userid", "familyid", "tokenhash", "expiresat", "revokedat", "replacedby"}).
+			AddRow(expected.Id, expected.UserId, expected.FamilyId, expected.TokenHash, expected.ExpiresAt, nil, nil))
+
+	token, err := repo.GetRefreshTokenForUpdate(context.Background(), "hash")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, token)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetRefreshTokenForUpdate_NotFound(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTokenPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	mockDB.ExpectQuery(`SELECT .+ FROM refresh_token`).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.GetRefreshTokenForUpdate(context.Background(), "unknown")
+
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestRevokeTokenFamily(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTokenPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+	familyId := uuid.New()
+
+	mockDB.ExpectExec(`UPDATE refresh_token\s+SET revokedAt = now\(\)\s+WHERE familyId = \$1 AND revokedAt IS NULL`).
+		WithArgs(familyId).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mockLogger.On("Infow", "Refresh token family revoked", "familyId", familyId).Return()
+
+	err = repo.RevokeTokenFamily(context.Background(), familyId)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestIsAccessTokenRevoked(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTokenPostgres(sqlx.NewDb(db, "sqlmock"), mockLogger)
+	jti := uuid.NewString()
+
+	mockDB.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM revoked_token WHERE jti = \$1\)`).
+		WithArgs(jti).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	revoked, err := repo.IsAccessTokenRevoked(context.Background(), jti)
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestDeleteExpiredTokens(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTokenPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+	now := time.Now()
+
+	mockDB.ExpectExec(`DELETE FROM refresh_token WHERE expiresAt <= \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mockDB.ExpectExec(`DELETE FROM revoked_token WHERE expiresAt <= \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	refresh, err := repo.DeleteExpiredRefreshTokens(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), refresh)
+
+	revoked, err := repo.DeleteExpiredRevokedTokens(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), revoked)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
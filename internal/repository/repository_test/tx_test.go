@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"pvz/internal/repository"
+	"pvz/mocks"
+)
+
+func TestUnitOfWork_Commit(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewPostgresUnitOfWork(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	pvzId := uuid.New()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectQuery(`SELECT id, registrationDate, city, name, address, status, deactivatedAt FROM pvz WHERE id = $1 FOR UPDATE`).
+		WithArgs(pvzId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzId))
+	mockDB.ExpectCommit()
+
+	err = uow.Do(context.Background(), func(repos *repository.Repository) error {
+		_, err := repos.Pvz.LockPvz(context.Background(), pvzId)
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestUnitOfWork_RollbackOnError(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewPostgresUnitOfWork(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	pvzId := uuid.New()
+
+	mockLogger.On("Warnw", "Pvz not found for lock", "pvzId", pvzId).Return()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectQuery(`SELECT id, registrationDate, city, name, address, status, deactivatedAt FROM pvz WHERE id = $1 FOR UPDATE`).
+		WithArgs(pvzId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mockDB.ExpectRollback()
+
+	err = uow.Do(context.Background(), func(repos *repository.Repository) error {
+		_, err := repos.Pvz.LockPvz(context.Background(), pvzId)
+		return err
+	})
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestUnitOfWork_BeginError(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewPostgresUnitOfWork(sqlx.NewDb(db, "sqlmock"), mockLogger)
+
+	beginErr := errors.New("connection refused")
+
+	mockLogger.On("Errorw", "Failed to begin transaction", "error", beginErr).Return()
+	mockDB.ExpectBegin().WillReturnError(beginErr)
+
+	called := false
+	err = uow.Do(context.Background(), func(repos *repository.Repository) error {
+		called = true
+		return nil
+	})
+
+	assert.ErrorIs(t, err, beginErr)
+	assert.False(t, called)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+	mockLogger.AssertExpectations(t)
+}
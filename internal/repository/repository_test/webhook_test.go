@@ -0,0 +1,208 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/mocks"
+)
+
+var webhookSubscriptionColumns = []string{"id", "url", "secret", "eventtypes", "pvzid", "active", "createdat", "updatedat"}
+
+func TestCreateWebhookSubscription_AllEventTypes(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	id := uuid.New()
+	now := time.Now()
+	// Без типов событий пишется пустой массив, а не NULL
+	mockDB.ExpectQuery(`INSERT INTO webhook_subscription \(url, secret, eventTypes, pvzId, active\)`).
+		WithArgs("https://partner.example/hook", "0123456789abcdef", pq.StringArray{}, nil, true).
+		WillReturnRows(sqlmock.NewRows(webhookSubscriptionColumns).
+			AddRow(id, "https://partner.example/hook", "0123456789abcdef", "{}", nil, true, now, now))
+
+	created, err := repo.CreateWebhookSubscription(context.Background(), model.WebhookSubscription{
+		Url:    "https://partner.example/hook",
+		Secret: "0123456789abcdef",
+		Active: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, id, created.Id)
+	assert.Empty(t, created.EventTypes)
+	assert.Nil(t, created.PvzId)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateWebhookSubscription(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	id := uuid.New()
+	active := false
+	now := time.Now()
+	mockDB.ExpectQuery(`UPDATE webhook_subscription\s+SET url = COALESCE\(\$2, url\).+pvzId = CASE WHEN \$5 THEN NULL`).
+		WithArgs(id, nil, pq.StringArray{model.EventReceptionClosed}, nil, true, &active).
+		WillReturnRows(sqlmock.NewRows(webhookSubscriptionColumns).
+			AddRow(id, "https://partner.example/hook", "secret", "{ReceptionClosed}", nil, false, now, now))
+
+	updated, err := repo.UpdateWebhookSubscription(context.Background(), id, model.WebhookSubscriptionUpdate{
+		EventTypes: []string{model.EventReceptionClosed},
+		ClearPvzId: true,
+		Active:     &active,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, pq.StringArray{model.EventReceptionClosed}, updated.EventTypes)
+	assert.False(t, updated.Active)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestUpdateWebhookSubscription_NotFound(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	mockDB.ExpectQuery(`UPDATE webhook_subscription`).WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.UpdateWebhookSubscription(context.Background(), uuid.New(), model.WebhookSubscriptionUpdate{})
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestDeleteWebhookSubscription_NotFound(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	id := uuid.New()
+	mockDB.ExpectExec(`DELETE FROM webhook_subscription WHERE id = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteWebhookSubscription(context.Background(), id)
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestCreateWebhookDeliveries(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	event := model.Event{Id: uuid.New(), Type: model.EventReceptionClosed, PvzId: uuid.New()}
+	mockDB.ExpectExec(`INSERT INTO webhook_delivery \(subscriptionId, eventId, eventType, body\)\s+SELECT id, \$1, \$2, \$4\s+FROM webhook_subscription\s+WHERE active.+\$2 = ANY\(eventTypes\).+pvzId = \$3.+ON CONFLICT \(subscriptionId, eventId\) DO NOTHING`).
+		WithArgs(event.Id, event.Type, event.PvzId, `{"type":"ReceptionClosed"}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	created, err := repo.CreateWebhookDeliveries(context.Background(), event, `{"type":"ReceptionClosed"}`)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), created)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	now := time.Now()
+	deliveryId, subscriptionId := uuid.New(), uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "subscriptionid", "eventid", "eventtype", "body", "status", "attempts",
+		"nextattemptat", "laststatuscode", "lasterror", "createdat", "deliveredat", "url", "secret"}).
+		AddRow(deliveryId, subscriptionId, uuid.New(), model.EventReceptionClosed, `{}`, model.WebhookDeliveryPending, 1,
+			now, 503, "unavailable", now, nil, "https://partner.example/hook", "secret")
+
+	// Пачка арендуется одним запросом, без транзакции на время отправки
+	// Сроки считаются по часам базы, которыми проставлен nextAttemptAt
+	mockDB.ExpectQuery(`UPDATE webhook_delivery d\s+SET nextAttemptAt = LOCALTIMESTAMP \+ make_interval\(secs => \$1\).+FROM webhook_delivery d\s+JOIN webhook_subscription s.+d.status = 'pending' AND d.nextAttemptAt <= LOCALTIMESTAMP AND s.active.+LIMIT \$2\s+FOR UPDATE OF d SKIP LOCKED\s+\)\s+RETURNING`).
+		WithArgs(float64(60), 20).
+		WillReturnRows(rows)
+
+	deliveries, err := repo.ClaimWebhookDeliveries(context.Background(), time.Minute, 20)
+
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, deliveryId, deliveries[0].Id)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, 503, *deliveries[0].LastStatusCode)
+	assert.Equal(t, "https://partner.example/hook", deliveries[0].Url)
+	assert.Equal(t, "secret", deliveries[0].Secret)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaveWebhookAttempt(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	id := uuid.New()
+	mockDB.ExpectExec(`UPDATE webhook_delivery\s+SET status = \$2,\s+attempts = attempts \+ 1,\s+nextAttemptAt = LOCALTIMESTAMP \+ make_interval\(secs => \$3\)`).
+		WithArgs(id, model.WebhookDeliveryDead, float64(60), 500, "boom").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.SaveWebhookAttempt(context.Background(), id, model.WebhookAttempt{
+		Status:     model.WebhookDeliveryDead,
+		StatusCode: 500,
+		Error:      "boom",
+		RetryAfter: time.Minute,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"), new(mocks.MockLogger))
+
+	subscriptionId := uuid.New()
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "subscriptionid", "eventid", "eventtype", "body", "status", "attempts",
+		"nextattemptat", "laststatuscode", "lasterror", "createdat", "deliveredat"}).
+		AddRow(uuid.New(), subscriptionId, uuid.New(), model.EventReceptionClosed, `{}`, model.WebhookDeliveryDelivered, 1,
+			now, 200, nil, now, now)
+
+	mockDB.ExpectQuery(`FROM webhook_delivery\s+WHERE subscriptionId = \$1 AND \(\$2 = '' OR status = \$2\)\s+ORDER BY createdAt DESC, id DESC\s+LIMIT \$3 OFFSET \$4`).
+		WithArgs(subscriptionId, model.WebhookDeliveryDelivered, 10, 0).
+		WillReturnRows(rows)
+
+	deliveries, err := repo.GetWebhookDeliveries(context.Background(), subscriptionId, model.WebhookDeliveryDelivered, 10, 0)
+
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, model.WebhookDeliveryDelivered, deliveries[0].Status)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+	assert.Nil(t, deliveries[0].LastError)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
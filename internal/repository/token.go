@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+type TokenPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewTokenPostgres(db DB, log logger.Logger) *TokenPostgres {
+	return &TokenPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+func (r *TokenPostgres) CreateRefreshToken(ctx context.Context, token model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_token (id, userId, familyId, tokenHash, expiresAt)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, token.Id, token.UserId, token.FamilyId, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		r.logger.Errorw("Failed to create refresh token", "userId", token.UserId, "error", err)
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefreshTokenForUpdate находит токен по хэшу и блокирует строку до конца
+// транзакции, чтобы один токен нельзя было обменять дважды параллельно.
+func (r *TokenPostgres) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	query := `
+		SELECT id, userId, familyId, tokenHash, expiresAt, revokedAt, replacedBy
+		FROM refresh_token
+		WHERE tokenHash = $1
+		FOR UPDATE
+	`
+
+	var token model.RefreshToken
+	if err := r.db.GetContext(ctx, &token, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.RefreshToken{}, ErrNotFound
+		}
+		r.logger.Errorw("Failed to get refresh token", "error", err)
+		return model.RefreshToken{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *TokenPostgres) RevokeRefreshToken(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) error {
+	query := `
+		UPDATE refresh_token
+		SET revokedAt = now(), replacedBy = $2
+		WHERE id = $1 AND revokedAt IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, replacedBy); err != nil {
+		r.logger.Errorw("Failed to revoke refresh token", "id", id, "error", err)
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TokenPostgres) RevokeTokenFamily(ctx context.Context, familyId uuid.UUID) error {
+	query := `
+		UPDATE refresh_token
+		SET revokedAt = now()
+		WHERE familyId = $1 AND revokedAt IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, familyId); err != nil {
+		r.logger.Errorw("Failed to revoke refresh token family", "familyId", familyId, "error", err)
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	r.logger.Infow("Refresh token family revoked", "familyId", familyId)
+	return nil
+}
+
+func (r *TokenPostgres) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_token (jti, expiresAt)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, jti, expiresAt); err != nil {
+		r.logger.Errorw("Failed to revoke access token", "jti", jti, "error", err)
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TokenPostgres) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_token WHERE jti = $1)`
+
+	var revoked bool
+	if err := r.db.GetContext(ctx, &revoked, query, jti); err != nil {
+		r.logger.Errorw("Failed to check access token revocation", "jti", jti, "error", err)
+		return false, fmt.Errorf("failed to check access token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpiredRefreshTokens удаляет refresh-токены, истёкшие к моменту before.
+// Обменять их уже нельзя, поэтому удаление не мешает найти утечку: повтор
+// истёкшего токена отклоняется и без отзыва семейства.
+func (r *TokenPostgres) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM refresh_token WHERE expiresAt <= $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		r.logger.Errorw("Failed to delete expired refresh tokens", "error", err)
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
+
+// DeleteExpiredRevokedTokens удаляет записи об отзыве access-токенов, истёкших
+// к моменту before: истёкший токен отклоняется и без них
+func (r *TokenPostgres) DeleteExpiredRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM revoked_token WHERE expiresAt <= $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		r.logger.Errorw("Failed to delete expired revoked tokens", "error", err)
+		return 0, fmt.Errorf("failed to delete expired revoked tokens: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
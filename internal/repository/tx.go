@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"pvz/internal/logger"
+)
+
+// DB - общие методы *sqlx.DB и *sqlx.Tx, которыми пользуются репозитории.
+// Благодаря ему одни и те же репозитории работают как с пулом соединений,
+// так и внутри транзакции.
+type DB interface {
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// UnitOfWork выполняет fn в одной транзакции. Репозитории, переданные в fn,
+// привязаны к этой транзакции; ошибка из fn откатывает все изменения.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos *Repository) error) error
+}
+
+type PostgresUnitOfWork struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewPostgresUnitOfWork(db *sqlx.DB, log logger.Logger) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{
+		db:     db,
+		logger: log,
+	}
+}
+
+func (u *PostgresUnitOfWork) Do(ctx context.Context, fn func(repos *Repository) error) error {
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		u.logger.Errorw("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(newRepository(tx, u.logger)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			u.logger.Errorw("Failed to rollback transaction", "error", rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		u.logger.Errorw("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -2,20 +2,21 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx" // Используем sqlx вместо pgx
 	"pvz/internal/logger"
 	"pvz/internal/repository/model"
 )
 
 type UserPostgres struct {
-	db     *sqlx.DB
+	db     DB
 	logger logger.Logger
 }
 
-func NewUserPostgres(db *sqlx.DB, log logger.Logger) *UserPostgres {
+func NewUserPostgres(db DB, log logger.Logger) *UserPostgres {
 	return &UserPostgres{
 		db:     db,
 		logger: log,
@@ -53,3 +54,19 @@ func (r *UserPostgres) GetUserByEmail(ctx context.Context, email string) (model.
 
 	return user, nil
 }
+
+func (r *UserPostgres) GetUserById(ctx context.Context, id uuid.UUID) (model.User, error) {
+	var user model.User
+
+	query := `SELECT id, email, role, password FROM users WHERE id = $1`
+	err := r.db.GetContext(ctx, &user, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, ErrNotFound
+		}
+		r.logger.Errorw("Failed to get user", "userID", id, "error", err)
+		return user, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"pvz/internal/logger"
+	"pvz/internal/repository/model"
+)
+
+const webhookSubscriptionColumns = `id, url, secret, eventTypes, pvzId, active, createdAt, updatedAt`
+
+const webhookDeliveryColumns = `id, subscriptionId, eventId, eventType, body, status, attempts, nextAttemptAt,
+	lastStatusCode, lastError, createdAt, deliveredAt`
+
+type WebhookPostgres struct {
+	db     DB
+	logger logger.Logger
+}
+
+func NewWebhookPostgres(db DB, log logger.Logger) *WebhookPostgres {
+	return &WebhookPostgres{
+		db:     db,
+		logger: log,
+	}
+}
+
+func (r *WebhookPostgres) CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscription (url, secret, eventTypes, pvzId, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookSubscriptionColumns
+
+	// Пустой список - подписка на все типы; NULL колонка не принимает
+	eventTypes := subscription.EventTypes
+	if eventTypes == nil {
+		eventTypes = pq.StringArray{}
+	}
+
+	var created model.WebhookSubscription
+	err := r.db.QueryRowxContext(ctx, query, subscription.Url, subscription.Secret, eventTypes,
+		subscription.PvzId, subscription.Active).StructScan(&created)
+	if err != nil {
+		r.logger.Errorw("Failed to create webhook subscription", "url", subscription.Url, "error", err)
+		return model.WebhookSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return created, nil
+}
+
+func (r *WebhookPostgres) GetWebhookSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscription ORDER BY createdAt, id`
+
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.SelectContext(ctx, &subscriptions, query); err != nil {
+		r.logger.Errorw("Failed to get webhook subscriptions", "error", err)
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookPostgres) GetWebhookSubscriptionById(ctx context.Context, id uuid.UUID) (model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscription WHERE id = $1`
+
+	var subscription model.WebhookSubscription
+	if err := r.db.GetContext(ctx, &subscription, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WebhookSubscription{}, fmt.Errorf("webhook subscription %s: %w", id, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to get webhook subscription", "id", id, "error", err)
+		return model.WebhookSubscription{}, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *WebhookPostgres) UpdateWebhookSubscription(ctx context.Context, id uuid.UUID, update model.WebhookSubscriptionUpdate) (model.WebhookSubscription, error) {
+	query := `
+		UPDATE webhook_subscription
+		SET url = COALESCE($2, url),
+		    eventTypes = COALESCE($3, eventTypes),
+		    pvzId = CASE WHEN $5 THEN NULL ELSE COALESCE($4, pvzId) END,
+		    active = COALESCE($6, active),
+		    updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + webhookSubscriptionColumns
+
+	var subscription model.WebhookSubscription
+	err := r.db.QueryRowxContext(ctx, query, id, update.Url, pq.StringArray(update.EventTypes), update.PvzId,
+		update.ClearPvzId, update.Active).StructScan(&subscription)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WebhookSubscription{}, fmt.Errorf("webhook subscription %s: %w", id, ErrNotFound)
+		}
+		r.logger.Errorw("Failed to update webhook subscription", "id", id, "error", err)
+		return model.WebhookSubscription{}, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// DeleteWebhookSubscription удаляет подписку вместе с журналом её доставок
+func (r *WebhookPostgres) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscription WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Errorw("Failed to delete webhook subscription", "id", id, "error", err)
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook subscription %s: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// CreateWebhookDeliveries ставит событие в очередь доставки всем активным
+// подпискам, которые на него подходят. Вызывается через репозитории транзакции,
+// в которой записано событие. Возвращает число созданных доставок.
+func (r *WebhookPostgres) CreateWebhookDeliveries(ctx context.Context, event model.Event, body string) (int64, error) {
+	query := `
+		INSERT INTO webhook_delivery (subscriptionId, eventId, eventType, body)
+		SELECT id, $1, $2, $4
+		FROM webhook_subscription
+		WHERE active
+		  AND (cardinality(eventTypes) = 0 OR $2 = ANY(eventTypes))
+		  AND (pvzId IS NULL OR pvzId = $3)
+		ON CONFLICT (subscriptionId, eventId) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, event.Id, event.Type, event.PvzId, body)
+	if err != nil {
+		r.logger.Errorw("Failed to create webhook deliveries", "eventId", event.Id, "error", err)
+		return 0, fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return created, nil
+}
+
+// ClaimWebhookDeliveries арендует на lease доставки, чья попытка уже
+// наступила: переносит их nextAttemptAt, чтобы другие реплики их не взяли.
+// Сроки считаются по часам базы, которыми проставлен nextAttemptAt. Строки,
+// занятые другой репликой, пропускаются. Доставки отключённых подписок ждут,
+// пока подписку не включат.
+func (r *WebhookPostgres) ClaimWebhookDeliveries(ctx context.Context, lease time.Duration, limit int) ([]model.PendingWebhookDelivery, error) {
+	query := `
+		UPDATE webhook_delivery d
+		SET nextAttemptAt = LOCALTIMESTAMP + make_interval(secs => $1)
+		FROM webhook_subscription s
+		WHERE s.id = d.subscriptionId
+		  AND d.id IN (
+			SELECT d.id
+			FROM webhook_delivery d
+			JOIN webhook_subscription s ON s.id = d.subscriptionId
+			WHERE d.status = 'pending' AND d.nextAttemptAt <= LOCALTIMESTAMP AND s.active
+			ORDER BY d.nextAttemptAt, d.createdAt
+			LIMIT $2
+			FOR UPDATE OF d SKIP LOCKED
+		  )
+		RETURNING d.id, d.subscriptionId, d.eventId, d.eventType, d.body, d.status, d.attempts, d.nextAttemptAt,
+		          d.lastStatusCode, d.lastError, d.createdAt, d.deliveredAt, s.url, s.secret
+	`
+
+	var deliveries []model.PendingWebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, lease.Seconds(), limit); err != nil {
+		r.logger.Errorw("Failed to claim webhook deliveries", "error", err)
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// SaveWebhookAttempt записывает результат попытки доставки. Уже доставленную
+// или мёртвую доставку не меняет: её могла завершить другая реплика после
+// истечения аренды.
+func (r *WebhookPostgres) SaveWebhookAttempt(ctx context.Context, id uuid.UUID, attempt model.WebhookAttempt) error {
+	query := `
+		UPDATE webhook_delivery
+		SET status = $2,
+		    attempts = attempts + 1,
+		    nextAttemptAt = LOCALTIMESTAMP + make_interval(secs => $3),
+		    lastStatusCode = NULLIF($4, 0),
+		    lastError = NULLIF($5, ''),
+		    deliveredAt = CASE WHEN $2 = 'delivered' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1 AND status = 'pending'
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, attempt.Status, attempt.RetryAfter.Seconds(), attempt.StatusCode, attempt.Error)
+	if err != nil {
+		r.logger.Errorw("Failed to save webhook attempt", "deliveryId", id, "error", err)
+		return fmt.Errorf("failed to save webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries возвращает журнал доставок подписки от новых к старым.
+// Пустой status - доставки в любом статусе.
+func (r *WebhookPostgres) GetWebhookDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit, offset int) ([]model.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_delivery
+		WHERE subscriptionId = $1 AND ($2 = '' OR status = $2)
+		ORDER BY createdAt DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	var deliveries []model.WebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, subscriptionId, status, limit, offset); err != nil {
+		r.logger.Errorw("Failed to get webhook deliveries", "subscriptionId", subscriptionId, "error", err)
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
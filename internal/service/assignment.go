@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/logger"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/rbac"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+)
+
+// AssignmentService ведёт назначения сотрудников на ПВЗ
+type AssignmentService struct {
+	repoUser       repository.User
+	repoAssignment repository.Assignment
+	uow            repository.UnitOfWork
+	policy         *rbac.Policy
+	logger         logger.Logger
+}
+
+func NewAssignmentService(repos *repository.Repository, policy *rbac.Policy, log logger.Logger) *AssignmentService {
+	return &AssignmentService{
+		repoUser:       repos.User,
+		repoAssignment: repos.Assignment,
+		uow:            repos.UnitOfWork,
+		policy:         policy,
+		logger:         log,
+	}
+}
+
+// AssignPvz назначает сотрудника на ПВЗ; повторное назначение ничего не меняет
+func (s *AssignmentService) AssignPvz(ctx context.Context, userId, pvzId uuid.UUID) error {
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		if err := s.checkAssignable(ctx, repos, userId); err != nil {
+			return err
+		}
+		if _, err := lockPvz(ctx, repos, pvzId); err != nil {
+			return err
+		}
+
+		assigned, err := repos.Assignment.AssignPvz(ctx, userId, pvzId)
+		if err != nil {
+			return err
+		}
+		if !assigned {
+			return nil
+		}
+
+		assignment := model.PvzAssignment{UserId: userId, PvzId: pvzId}
+		return recordAudit(ctx, repos, model.AuditPvzAssign, model.AuditEntityUser, userId.String(), nil, assignment)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to assign pvz", "userId", userId, "pvzId", pvzId, "error", err)
+		return err
+	}
+
+	s.logger.Infow("Pvz assigned", "userId", userId, "pvzId", pvzId)
+	return nil
+}
+
+func (s *AssignmentService) UnassignPvz(ctx context.Context, userId, pvzId uuid.UUID) error {
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		err := repos.Assignment.UnassignPvz(ctx, userId, pvzId)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.Wrap(apperror.ErrNotFound, err, "pvz %s is not assigned to user %s", pvzId, userId)
+		}
+		if err != nil {
+			return err
+		}
+
+		assignment := model.PvzAssignment{UserId: userId, PvzId: pvzId}
+		return recordAudit(ctx, repos, model.AuditPvzUnassign, model.AuditEntityUser, userId.String(), assignment, nil)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to unassign pvz", "userId", userId, "pvzId", pvzId, "error", err)
+		return err
+	}
+
+	s.logger.Infow("Pvz unassigned", "userId", userId, "pvzId", pvzId)
+	return nil
+}
+
+// GetAssignedPvzIds возвращает ПВЗ, на которые назначен сотрудник
+func (s *AssignmentService) GetAssignedPvzIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	if _, err := s.repoUser.GetUserById(ctx, userId); err != nil {
+		return nil, userNotFound(err, userId)
+	}
+
+	pvzIds, err := s.repoAssignment.GetAssignedPvzIds(ctx, userId)
+	if err != nil {
+		s.logger.Errorw("Failed to get assigned pvz", "userId", userId, "error", err)
+		return nil, err
+	}
+	return pvzIds, nil
+}
+
+// checkAssignable отклоняет назначение пользователя, роль которого и так
+// работает с любым ПВЗ
+func (s *AssignmentService) checkAssignable(ctx context.Context, repos *repository.Repository, userId uuid.UUID) error {
+	user, err := repos.User.GetUserById(ctx, userId)
+	if err != nil {
+		return userNotFound(err, userId)
+	}
+	if s.policy.Allowed(user.Role, rbac.PvzUnscoped) {
+		return apperror.Validation("role %s is not limited to assigned pvz", user.Role)
+	}
+	return nil
+}
+
+func userNotFound(err error, userId uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(apperror.ErrNotFound, err, "user %s not found", userId)
+	}
+	return err
+}
+
+// authorizePvz проверяет, что пользователь из ctx может менять данные ПВЗ.
+// Роли с правом pvz:unscoped и вызовы без пользователя не ограничены.
+// Остальные работают только с назначенными ПВЗ, а токен /dummyLogin -
+// с ПВЗ из PvzScope.
+func authorizePvz(ctx context.Context, repos *repository.Repository, pvzId uuid.UUID) error {
+	claims, ok := jwt.ClaimsFromContext(ctx)
+	if !ok || claims.Unscoped {
+		return nil
+	}
+
+	var allowed bool
+	if claims.UserId == uuid.Nil {
+		allowed = len(claims.PvzScope) == 0 || slices.Contains(claims.PvzScope, pvzId)
+	} else {
+		var err error
+		if allowed, err = repos.Assignment.IsPvzAssigned(ctx, claims.UserId, pvzId); err != nil {
+			return err
+		}
+	}
+
+	if !allowed {
+		return apperror.Forbidden("pvz %s is not assigned to user", pvzId)
+	}
+	return nil
+}
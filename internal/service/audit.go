@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pvz/internal/logger"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/middleware/requestid"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+)
+
+// AuditService отдаёт журнал аудита. Записи в журнал добавляют сами
+// сервисы через recordAudit в транзакции изменения.
+type AuditService struct {
+	repo   repository.Audit
+	logger logger.Logger
+}
+
+func NewAuditService(repo repository.Audit, log logger.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// GetAuditLog возвращает записи журнала от новых к старым
+func (s *AuditService) GetAuditLog(ctx context.Context, limit, offset int, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	limit = min(limit, maxPageLimit)
+
+	entries, err := s.repo.GetAuditLog(ctx, limit, offset, filter)
+	if err != nil {
+		s.logger.Errorw("Failed to get audit log", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// recordAudit записывает изменение сущности через репозитории транзакции,
+// поэтому запись откатывается вместе с изменением. Пользователь и
+// идентификатор запроса берутся из ctx; before и after сохраняются как JSON,
+// nil означает отсутствие снимка.
+func recordAudit(ctx context.Context, repos *repository.Repository, action, entityType, entityId string, before, after any) error {
+	entry := model.AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		RequestId:  requestid.FromContext(ctx),
+	}
+	if claims, ok := jwt.ClaimsFromContext(ctx); ok {
+		entry.ActorId = &claims.UserId
+		entry.ActorRole = claims.Role
+	}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return err
+	}
+
+	return repos.Audit.CreateAuditEntry(ctx, entry)
+}
+
+func auditSnapshot(value any) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	snapshot, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit snapshot: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"pvz/internal/apperror"
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+)
+
+// Как долго справочник в памяти считается актуальным. Ограничивает задержку,
+// с которой изменение, сделанное через другой инстанс, начинает действовать здесь.
+const catalogCacheTTL = time.Minute
+
+type catalogSnapshot struct {
+	items    map[string]bool // значение -> активно
+	loadedAt time.Time
+}
+
+// CatalogService ведёт справочники городов и типов товаров и проверяет
+// по ним входные данные. Справочники кэшируются на catalogCacheTTL.
+type CatalogService struct {
+	repo   repository.Catalog
+	uow    repository.UnitOfWork
+	logger logger.Logger
+
+	mu        sync.Mutex
+	snapshots map[model.CatalogKind]catalogSnapshot
+}
+
+func NewCatalogService(repos *repository.Repository, log logger.Logger) *CatalogService {
+	return &CatalogService{
+		repo:      repos.Catalog,
+		uow:       repos.UnitOfWork,
+		logger:    log,
+		snapshots: make(map[model.CatalogKind]catalogSnapshot),
+	}
+}
+
+func (s *CatalogService) ListCatalog(ctx context.Context, kind model.CatalogKind) ([]model.CatalogItem, error) {
+	items, err := s.repo.ListCatalog(ctx, kind)
+	if err != nil {
+		s.logger.Errorw("Failed to list catalog", "catalog", kind, "error", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *CatalogService) AddCatalogItem(ctx context.Context, kind model.CatalogKind, name string) (model.CatalogItem, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.CatalogItem{}, apperror.Validation("name is required")
+	}
+
+	var item model.CatalogItem
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		if item, err = repos.Catalog.AddCatalogItem(ctx, kind, name); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, model.AuditCatalogCreate, string(kind), name, nil, item)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return model.CatalogItem{}, apperror.Wrap(apperror.ErrConflict, err, "%s %q already exists", kind, name)
+		}
+		s.logger.Errorw("Failed to add catalog item", "catalog", kind, "name", name, "error", err)
+		return model.CatalogItem{}, err
+	}
+
+	s.invalidate(kind)
+	s.logger.Infow("Catalog item added", "catalog", kind, "name", name)
+	return item, nil
+}
+
+// SetCatalogItemActive включает или отключает значение. Записи, которые уже
+// ссылаются на отключённое значение, не меняются.
+func (s *CatalogService) SetCatalogItemActive(ctx context.Context, kind model.CatalogKind, name string, active bool) (model.CatalogItem, error) {
+	var item model.CatalogItem
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		if item, err = repos.Catalog.SetCatalogItemActive(ctx, kind, name, active); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, model.AuditCatalogUpdate, string(kind), name, nil, item)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.CatalogItem{}, apperror.Wrap(apperror.ErrNotFound, err, "%s %q not found", kind, name)
+		}
+		s.logger.Errorw("Failed to update catalog item", "catalog", kind, "name", name, "error", err)
+		return model.CatalogItem{}, err
+	}
+
+	s.invalidate(kind)
+	s.logger.Infow("Catalog item updated", "catalog", kind, "name", name, "active", active)
+	return item, nil
+}
+
+// Validate проверяет, что value есть в справочнике kind и не отключено
+func (s *CatalogService) Validate(ctx context.Context, kind model.CatalogKind, value string) error {
+	items, err := s.snapshot(ctx, kind)
+	if err != nil {
+		return err
+	}
+
+	active, ok := items[value]
+	switch {
+	case !ok:
+		return apperror.Validation("unknown %s %q", kind, value)
+	case !active:
+		return apperror.Validation("%s %q is disabled", kind, value)
+	}
+	return nil
+}
+
+func (s *CatalogService) snapshot(ctx context.Context, kind model.CatalogKind) (map[string]bool, error) {
+	s.mu.Lock()
+	snapshot, ok := s.snapshots[kind]
+	s.mu.Unlock()
+	if ok && time.Since(snapshot.loadedAt) < catalogCacheTTL {
+		return snapshot.items, nil
+	}
+
+	list, err := s.repo.ListCatalog(ctx, kind)
+	if err != nil {
+		s.logger.Errorw("Failed to load catalog", "catalog", kind, "error", err)
+		return nil, err
+	}
+
+	items := make(map[string]bool, len(list))
+	for _, item := range list {
+		items[item.Name] = item.Active
+	}
+
+	s.mu.Lock()
+	s.snapshots[kind] = catalogSnapshot{items: items, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return items, nil
+}
+
+func (s *CatalogService) invalidate(kind model.CatalogKind) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.snapshots, kind)
+}
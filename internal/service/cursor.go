@@ -0,0 +1,41 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/repository/model"
+)
+
+// Курсор для клиента непрозрачен: это base64 от JSON с позицией последнего
+// ПВЗ на странице. Формат можно менять, не ломая клиентов, которые только
+// передают полученный nextCursor обратно.
+type pvzCursorPayload struct {
+	RegistrationDate time.Time `json:"d"`
+	Id               uuid.UUID `json:"id"`
+}
+
+func encodePvzCursor(cursor model.PvzCursor) string {
+	data, _ := json.Marshal(pvzCursorPayload{RegistrationDate: cursor.RegistrationDate, Id: cursor.Id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePvzCursor(cursor string) (model.PvzCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return model.PvzCursor{}, err
+	}
+
+	var payload pvzCursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return model.PvzCursor{}, err
+	}
+	if payload.Id == uuid.Nil || payload.RegistrationDate.IsZero() {
+		return model.PvzCursor{}, errors.New("incomplete cursor")
+	}
+
+	return model.PvzCursor{RegistrationDate: payload.RegistrationDate, Id: payload.Id}, nil
+}
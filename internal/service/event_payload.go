@@ -0,0 +1,54 @@
+package service
+
+import "pvz/internal/repository/model"
+
+// eventPayload - допустимые payload доменных событий. Payload - внешний
+// контракт, поэтому модели репозитория в события не попадают: поля payload
+// не меняются вместе с колонками таблиц.
+type eventPayload interface {
+	model.PvzPayload | model.ReceptionPayload | model.ProductPayload
+}
+
+func pvzPayload(pvz model.Pvz) model.PvzPayload {
+	return model.PvzPayload{
+		Id:               pvz.Id,
+		RegistrationDate: pvz.RegistrationDate,
+		City:             pvz.City,
+		Name:             pvz.Name,
+		Address:          pvz.Address,
+		Status:           pvz.Status,
+		DeactivatedAt:    pvz.DeactivatedAt,
+	}
+}
+
+func receptionPayload(reception model.Reception) model.ReceptionPayload {
+	return model.ReceptionPayload{
+		Id:          reception.Id,
+		DateTime:    reception.DateTime,
+		PvzId:       reception.PvzId,
+		Status:      reception.Status,
+		StaleAt:     reception.StaleAt,
+		StaleReason: reception.StaleReason,
+		ClosedAt:    reception.ClosedAt,
+		ClosedBy:    reception.ClosedBy,
+		VerifiedAt:  reception.VerifiedAt,
+		VerifiedBy:  reception.VerifiedBy,
+		CancelledAt: reception.CancelledAt,
+		CancelledBy: reception.CancelledBy,
+		ReopenedAt:  reception.ReopenedAt,
+		ReopenedBy:  reception.ReopenedBy,
+	}
+}
+
+func productPayload(product model.Product) model.ProductPayload {
+	return model.ProductPayload{
+		Id:          product.Id,
+		DateTime:    product.DateTime,
+		Type:        product.Type,
+		ReceptionId: product.ReceptionId,
+		Barcode:     product.Barcode,
+		OrderId:     product.OrderId,
+		Weight:      product.Weight,
+		Attributes:  product.Attributes,
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+)
+
+// IdempotentResponse - сохранённый ответ, который повторяется на ретраи
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// IdempotencyService хранит ответы на запросы с заголовком Idempotency-Key.
+// Ключи принадлежат пользователю (для токенов /dummyLogin - токену) и живут ttl, после чего их удаляет
+// DeleteExpiredIdempotencyKeys.
+type IdempotencyService struct {
+	repo   repository.Idempotency
+	ttl    time.Duration
+	logger logger.Logger
+}
+
+func NewIdempotencyService(repo repository.Idempotency, ttl time.Duration, log logger.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		repo:   repo,
+		ttl:    ttl,
+		logger: log,
+	}
+}
+
+// AcquireIdempotencyKey занимает ключ под запрос с хэшем requestHash.
+// Если ключ уже использован тем же запросом, возвращает сохранённый ответ;
+// nil означает, что запрос нужно выполнить и затем вызвать SaveIdempotentResponse.
+func (s *IdempotencyService) AcquireIdempotencyKey(ctx context.Context, userId uuid.UUID, key, requestHash string) (*IdempotentResponse, error) {
+	acquired, err := s.repo.AcquireIdempotencyKey(ctx, model.IdempotencyKey{
+		UserId:      userId,
+		Key:         key,
+		RequestHash: requestHash,
+	}, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		return nil, nil
+	}
+
+	stored, err := s.repo.GetIdempotencyKey(ctx, userId, key)
+	if err != nil {
+		// Ключ удалили между вставкой и чтением: первый запрос завершился ошибкой
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.Wrap(apperror.ErrConflict, err, "request with this idempotency key is in progress, retry later")
+		}
+		return nil, err
+	}
+
+	switch {
+	case stored.RequestHash != requestHash:
+		s.logger.Warnw("Idempotency key reused with a different request", "userId", userId, "key", key)
+		return nil, apperror.Conflict("idempotency key is already used for a different request")
+	case stored.StatusCode == nil:
+		return nil, apperror.Conflict("request with this idempotency key is in progress, retry later")
+	}
+
+	s.logger.Infow("Replaying idempotent response", "userId", userId, "key", key, "status", *stored.StatusCode)
+	return &IdempotentResponse{StatusCode: *stored.StatusCode, Body: stored.ResponseBody}, nil
+}
+
+// SaveIdempotentResponse сохраняет ответ на запрос. После внутренней ошибки
+// ключ освобождается: повтор с ним выполнит запрос заново.
+func (s *IdempotencyService) SaveIdempotentResponse(ctx context.Context, userId uuid.UUID, key string, statusCode int, body []byte) error {
+	if statusCode >= http.StatusInternalServerError {
+		return s.ReleaseIdempotencyKey(ctx, userId, key)
+	}
+	return s.repo.SaveIdempotentResponse(ctx, userId, key, statusCode, body)
+}
+
+// ReleaseIdempotencyKey освобождает ключ запроса, который не получил ответа:
+// повтор с ним выполнит запрос заново
+func (s *IdempotencyService) ReleaseIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) error {
+	return s.repo.DeleteIdempotencyKey(ctx, userId, key)
+}
+
+func (s *IdempotencyService) DeleteExpiredIdempotencyKeys(ctx context.Context) error {
+	deleted, err := s.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.logger.Infow("Expired idempotency keys deleted", "count", deleted)
+	}
+	return nil
+}
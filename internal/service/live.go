@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"pvz/internal/live"
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+)
+
+// LiveService подписывает клиентов на поток событий ПВЗ. События в хаб
+// публикуют ReceptionService и ProductService после коммита изменения.
+type LiveService struct {
+	repoPvz repository.Pvz
+	hub     *live.Hub
+	logger  logger.Logger
+}
+
+func NewLiveService(repos *repository.Repository, hub *live.Hub, log logger.Logger) *LiveService {
+	return &LiveService{
+		repoPvz: repos.Pvz,
+		hub:     hub,
+		logger:  log,
+	}
+}
+
+// SubscribePvzEvents подписывает на события существующего ПВЗ, продолжая
+// поток после lastEventId, если он задан. Подписку нужно закрыть.
+func (s *LiveService) SubscribePvzEvents(ctx context.Context, pvzId uuid.UUID, lastEventId string) (*live.Subscription, error) {
+	if _, err := s.repoPvz.GetPvzById(ctx, pvzId); err != nil {
+		return nil, pvzNotFound(err, pvzId)
+	}
+
+	sub := s.hub.Subscribe(pvzId, lastEventId)
+	s.logger.Infow("Live subscriber connected", "pvzId", pvzId, "replayed", len(sub.Replay), "resync", sub.Resync)
+	return sub, nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/logger"
+	"pvz/internal/outbox"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/metrics"
+)
+
+// Первая повторная попытка публикации; дальше интервал удваивается до MaxBackoff
+const outboxInitialBackoff = time.Second
+
+// На сколько реплика арендует пачку событий. Срок аренды в базе считается
+// по её часам, а Publish получает контекст с тем же сроком по часам
+// приложения, отсчитанным до начала аренды: после него события могут быть
+// опубликованы другой репликой.
+const outboxLease = time.Minute
+
+// OutboxConfig - параметры публикации доменных событий
+type OutboxConfig struct {
+	Publisher outbox.Publisher
+	// BatchSize - сколько событий публикуется за один запуск
+	BatchSize  int
+	MaxBackoff time.Duration
+	// Retention - сколько хранятся уже опубликованные события
+	Retention time.Duration
+}
+
+// OutboxService публикует события, которые сервисы записали через
+// recordEvent в транзакции изменения. Доставка не реже одного раза: событие
+// помечается опубликованным только после успешного Publish.
+type OutboxService struct {
+	repo   repository.Outbox
+	uow    repository.UnitOfWork
+	cfg    OutboxConfig
+	logger logger.Logger
+}
+
+func NewOutboxService(repos *repository.Repository, cfg OutboxConfig, log logger.Logger) *OutboxService {
+	return &OutboxService{
+		repo:   repos.Outbox,
+		uow:    repos.UnitOfWork,
+		cfg:    cfg,
+		logger: log,
+	}
+}
+
+// PublishOutboxEvents публикует очередную пачку событий. События одного ПВЗ
+// уходят по порядку: после ошибки остальные события этого ПВЗ ждут, пока
+// не будет доставлено неудавшееся.
+//
+// Пачка выбирается и арендуется в короткой транзакции под блокировкой, а
+// Publish вызывается уже вне её, каждый результат записывается отдельно.
+// Пока аренда не истекла, другие реплики не берут ни события пачки, ни
+// следующие события тех же ПВЗ.
+func (s *OutboxService) PublishOutboxEvents(ctx context.Context) error {
+	leaseUntil := time.Now().Add(outboxLease)
+	events, err := s.claimOutboxEvents(ctx)
+	if err != nil {
+		s.logger.Errorw("Failed to publish outbox events", "published", 0, "error", err)
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	publishCtx, cancel := context.WithDeadline(ctx, leaseUntil)
+	defer cancel()
+
+	var published, failed int
+	var errs []error
+	var skipped []int64
+	blocked := make(map[uuid.UUID]bool)
+	for _, event := range events {
+		if blocked[event.PvzId] {
+			skipped = append(skipped, event.Seq)
+			continue
+		}
+
+		if err := s.cfg.Publisher.Publish(publishCtx, event.Event); err != nil {
+			blocked[event.PvzId] = true
+			failed++
+			metrics.OutboxPublishErrors.Inc()
+
+			attempt := event.Attempts + 1
+			s.logger.Warnw("Failed to publish event", "eventId", event.Id, "type", event.Type,
+				"pvzId", event.PvzId, "attempt", attempt, "error", err)
+			retryAfter := retryBackoff(outboxInitialBackoff, attempt, s.cfg.MaxBackoff)
+			if err := s.repo.MarkOutboxEventFailed(ctx, event.Seq, err.Error(), retryAfter); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		// Не отмеченное событие опубликуют повторно после аренды, поэтому
+		// следующие события его ПВЗ в этой пачке не публикуются
+		if err := s.repo.MarkOutboxEventPublished(ctx, event.Seq); err != nil {
+			blocked[event.PvzId] = true
+			errs = append(errs, err)
+			continue
+		}
+		published++
+		metrics.OutboxEventsPublished.WithLabelValues(event.Type).Inc()
+	}
+
+	// Пропущенные события ждут своей очереди за неудавшимся, а не конца аренды
+	if len(skipped) > 0 {
+		if err := s.repo.DelayOutboxEvents(ctx, skipped, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		s.logger.Errorw("Failed to publish outbox events", "published", published, "error", err)
+		return err
+	}
+
+	if published > 0 || failed > 0 {
+		s.logger.Infow("Outbox events published", "published", published, "failed", failed)
+	}
+	return nil
+}
+
+// claimOutboxEvents выбирает очередную пачку и арендует её на outboxLease.
+// Если блокировку держит другая реплика, пачка пуста.
+func (s *OutboxService) claimOutboxEvents(ctx context.Context) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		locked, err := repos.Outbox.TryLockOutbox(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err = repos.Outbox.GetPendingOutboxEvents(ctx, s.cfg.BatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		seqs := make([]int64, 0, len(events))
+		for _, event := range events {
+			seqs = append(seqs, event.Seq)
+		}
+		return repos.Outbox.DelayOutboxEvents(ctx, seqs, outboxLease)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// DeletePublishedOutboxEvents удаляет опубликованные события старше Retention
+func (s *OutboxService) DeletePublishedOutboxEvents(ctx context.Context) error {
+	deleted, err := s.repo.DeletePublishedOutboxEvents(ctx, s.cfg.Retention)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.logger.Infow("Published outbox events deleted", "count", deleted)
+	}
+	return nil
+}
+
+// retryBackoff возвращает задержку перед попыткой attempt+1: initial,
+// удваиваемый с каждой попыткой, но не больше maxBackoff
+func retryBackoff(initial time.Duration, attempt int, maxBackoff time.Duration) time.Duration {
+	backoff := initial
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// recordEvent ставит доменное событие в очередь через репозитории транзакции,
+// поэтому событие откатывается вместе с изменением. payload сохраняется как JSON.
+// Подходящим подпискам на вебхуки событие ставится в очередь доставки там же.
+// Возвращённое событие сервис рассылает в live-поток после коммита.
+func recordEvent[P eventPayload](ctx context.Context, repos *repository.Repository, eventType string, pvzId uuid.UUID, payload P) (model.Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return model.Event{}, fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	event, err := repos.Outbox.CreateOutboxEvent(ctx, eventType, pvzId, data)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return model.Event{}, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if _, err := repos.Webhook.CreateWebhookDeliveries(ctx, event, string(body)); err != nil {
+		return model.Event{}, err
+	}
+	return event, nil
+}
@@ -1,84 +1,481 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/live"
 	"pvz/internal/logger"
 	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 	"pvz/metrics"
 )
 
+// Ограничения полей товара, совпадают с размерами колонок
+const (
+	maxBarcodeLength = 64
+	maxOrderIdLength = 64
+)
+
 type ProductService struct {
-	repoProduct   repository.Product
-	repoReception repository.Reception
-	logger        logger.Logger
+	repoProduct repository.Product
+	uow         repository.UnitOfWork
+	catalog     Catalog
+	hub         *live.Hub
+	logger      logger.Logger
 }
 
-func NewProductService(repoProduct repository.Product, repoReception repository.Reception, log logger.Logger) *ProductService {
+func NewProductService(repos *repository.Repository, catalog Catalog, hub *live.Hub, log logger.Logger) *ProductService {
 	return &ProductService{
-		repoProduct:   repoProduct,
-		repoReception: repoReception,
-		logger:        log,
+		repoProduct: repos.Product,
+		uow:         repos.UnitOfWork,
+		catalog:     catalog,
+		hub:         hub,
+		logger:      log,
 	}
 }
 
-func (s *ProductService) AddProduct(ctx context.Context, pvzId uuid.UUID, productType string) (model.Product, error) {
-	s.logger.Infow("Adding product", "pvzId", pvzId, "type", productType)
+// AddProduct добавляет товар в открытую приёмку ПВЗ. Штрихкод, если задан,
+// должен быть уникален в пределах приёмки.
+func (s *ProductService) AddProduct(ctx context.Context, pvzId uuid.UUID, product model.Product) (model.Product, error) {
+	s.logger.Infow("Adding product", "pvzId", pvzId, "type", product.Type)
 
-	receptionId, err := s.repoReception.GetInProgressReception(ctx, pvzId)
-	if err != nil {
-		s.logger.Warnw("Cannot add product, no open reception", "pvzId", pvzId, "error", err)
-		return model.Product{}, fmt.Errorf("no open reception for pvz %s: %w", pvzId, err)
+	if err := s.catalog.Validate(ctx, model.CatalogProductType, product.Type); err != nil {
+		s.logger.Warnw("Invalid product type", "type", product.Type, "error", err)
+		return model.Product{}, err
 	}
 
-	product := model.Product{
-		Type:        productType,
-		ReceptionId: receptionId,
+	product, err := normalizeProduct(product)
+	if err != nil {
+		s.logger.Warnw("Invalid product", "pvzId", pvzId, "error", err)
+		return model.Product{}, err
 	}
 
-	created, err := s.repoProduct.CreateProduct(ctx, product)
+	var created model.Product
+	var event model.Event
+
+	err = s.uow.Do(ctx, func(repos *repository.Repository) error {
+		if _, err := lockPvz(ctx, repos, pvzId); err != nil {
+			s.logger.Errorw("Failed to lock PVZ", "pvzId", pvzId, "error", err)
+			return err
+		}
+		if err := authorizePvz(ctx, repos, pvzId); err != nil {
+			return err
+		}
+
+		receptionId, err := repos.Reception.GetInProgressReception(ctx, pvzId)
+		if err != nil {
+			s.logger.Warnw("Cannot add product, no open reception", "pvzId", pvzId, "error", err)
+			return fmt.Errorf("no open reception for pvz %s: %w", pvzId, err)
+		}
+		if receptionId == uuid.Nil {
+			s.logger.Warnw("Cannot add product, no open reception", "pvzId", pvzId)
+			return apperror.Conflict("no open reception for pvz %s", pvzId)
+		}
+
+		product.ReceptionId = receptionId
+
+		created, err = repos.Product.CreateProduct(ctx, product)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			s.logger.Warnw("Duplicate barcode in reception", "receptionId", receptionId, "barcode", *product.Barcode)
+			return apperror.Wrap(apperror.ErrConflict, err, "barcode %q already exists in the current reception", *product.Barcode)
+		}
+		if err != nil {
+			s.logger.Errorw("Failed to create product", "product", product, "error", err)
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		if err := recordAudit(ctx, repos, model.AuditProductCreate, model.AuditEntityProduct, created.Id.String(), nil, created); err != nil {
+			return err
+		}
+		event, err = recordEvent(ctx, repos, model.EventProductAdded, pvzId, productPayload(created))
+		return err
+	})
 	if err != nil {
-		s.logger.Errorw("Failed to create product", "product", product, "error", err)
-		return model.Product{}, fmt.Errorf("failed to create product: %w", err)
+		return model.Product{}, err
 	}
+	s.hub.Publish(event)
 	metrics.ProductsAdded.Inc()
 
 	s.logger.Infow("Product created successfully", "productId", created.Id, "receptionId", created.ReceptionId)
 	return created, nil
 }
 
-func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzId uuid.UUID) error {
-	s.logger.Infow("Attempting to delete last product", "pvzId", pvzId)
+// Максимальное число товаров в одном пакетном запросе
+const maxProductBatchSize = 500
 
-	receptionId, err := s.repoReception.GetInProgressReception(ctx, pvzId)
-	if err != nil {
-		s.logger.Errorw("Failed to get in-progress reception", "pvzId", pvzId, "error", err)
-		return fmt.Errorf("cannot delete product: reception lookup failed: %w", err)
+// AddProducts добавляет пакет товаров в открытую приёмку одной транзакцией.
+// Пакет принимается целиком или отклоняется целиком: ошибки отдельных товаров
+// возвращаются через apperror.WithItems с индексами в исходном пакете.
+// Товары без Id получают сгенерированный.
+func (s *ProductService) AddProducts(ctx context.Context, pvzId uuid.UUID, products []model.Product) ([]model.Product, error) {
+	s.logger.Infow("Adding product batch", "pvzId", pvzId, "count", len(products))
+
+	switch {
+	case len(products) == 0:
+		return nil, apperror.Validation("products must not be empty")
+	case len(products) > maxProductBatchSize:
+		return nil, apperror.Validation("too many products in batch: max %d", maxProductBatchSize)
 	}
-	if receptionId == uuid.Nil {
-		s.logger.Warnw("No active reception found", "pvzId", pvzId)
-		return fmt.Errorf("no active reception found for pvz %s", pvzId)
+
+	batch, err := s.prepareBatch(ctx, products)
+	if err != nil {
+		s.logger.Warnw("Invalid product batch", "pvzId", pvzId, "error", err)
+		return nil, err
 	}
 
-	lastProductId, err := s.repoProduct.GetLastProductIdByReception(ctx, receptionId)
+	var created []model.Product
+	var events []model.Event
+
+	err = s.uow.Do(ctx, func(repos *repository.Repository) error {
+		if _, err := lockPvz(ctx, repos, pvzId); err != nil {
+			s.logger.Errorw("Failed to lock PVZ", "pvzId", pvzId, "error", err)
+			return err
+		}
+		if err := authorizePvz(ctx, repos, pvzId); err != nil {
+			return err
+		}
+
+		receptionId, err := repos.Reception.GetInProgressReception(ctx, pvzId)
+		if err != nil {
+			s.logger.Warnw("Cannot add products, no open reception", "pvzId", pvzId, "error", err)
+			return fmt.Errorf("no open reception for pvz %s: %w", pvzId, err)
+		}
+		if receptionId == uuid.Nil {
+			s.logger.Warnw("Cannot add products, no open reception", "pvzId", pvzId)
+			return apperror.Conflict("no open reception for pvz %s", pvzId)
+		}
+
+		created, err = repos.Product.CreateProducts(ctx, receptionId, batch)
+		if err != nil {
+			s.logger.Errorw("Failed to create products", "receptionId", receptionId, "error", err)
+			return fmt.Errorf("failed to create products: %w", err)
+		}
+
+		// Товары с уже занятым id не вставлены, откатываем весь пакет
+		if len(created) != len(batch) {
+			return existingProductsError(batch, created)
+		}
+		if err := recordAudit(ctx, repos, model.AuditProductCreateBatch, model.AuditEntityReception, receptionId.String(), nil, created); err != nil {
+			return err
+		}
+		// Событие на каждый товар: получателям не нужно отличать пакетную приёмку
+		events = make([]model.Event, 0, len(created))
+		for _, product := range created {
+			event, err := recordEvent(ctx, repos, model.EventProductAdded, pvzId, productPayload(product))
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Errorw("Failed to get last product ID", "receptionId", receptionId, "error", err)
-		return fmt.Errorf("cannot delete product: failed to get last product: %w", err)
+		return nil, err
+	}
+	for _, event := range events {
+		s.hub.Publish(event)
+	}
+	metrics.ProductsAdded.Add(float64(len(created)))
+
+	s.logger.Infow("Product batch created successfully", "pvzId", pvzId, "count", len(created))
+	return created, nil
+}
+
+// prepareBatch проверяет товары пакета и уникальность переданных id и
+// штрихкодов, а товарам без id проставляет сгенерированный
+func (s *ProductService) prepareBatch(ctx context.Context, products []model.Product) ([]model.Product, error) {
+	batch := make([]model.Product, len(products))
+	seenIds := make(map[uuid.UUID]int, len(products))
+	seenBarcodes := make(map[string]int, len(products))
+	var items []apperror.ItemError
+
+	for i, item := range products {
+		product, err := s.validateBatchItem(ctx, item)
+		if err != nil {
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) {
+				return nil, err
+			}
+			items = append(items, apperror.ItemError{Index: i, Message: appErr.Message()})
+			continue
+		}
+
+		if product.Id == uuid.Nil {
+			product.Id = uuid.New()
+		} else if first, ok := seenIds[product.Id]; ok {
+			items = append(items, apperror.ItemError{
+				Index:   i,
+				Message: fmt.Sprintf("duplicate id %s, already used by item %d", product.Id, first),
+			})
+			continue
+		}
+
+		if product.Barcode != nil {
+			if first, ok := seenBarcodes[*product.Barcode]; ok {
+				items = append(items, apperror.ItemError{
+					Index:   i,
+					Message: fmt.Sprintf("duplicate barcode %q, already used by item %d", *product.Barcode, first),
+				})
+				continue
+			}
+			seenBarcodes[*product.Barcode] = i
+		}
+		seenIds[product.Id] = i
+
+		batch[i] = product
+	}
+
+	if len(items) > 0 {
+		return nil, apperror.WithItems(apperror.ErrValidation, items, "product batch contains invalid items")
+	}
+	return batch, nil
+}
+
+func (s *ProductService) validateBatchItem(ctx context.Context, product model.Product) (model.Product, error) {
+	if err := s.catalog.Validate(ctx, model.CatalogProductType, product.Type); err != nil {
+		return model.Product{}, err
+	}
+	return normalizeProduct(product)
+}
+
+func existingProductsError(batch, created []model.Product) error {
+	inserted := make(map[uuid.UUID]bool, len(created))
+	for _, product := range created {
+		inserted[product.Id] = true
 	}
-	if lastProductId == uuid.Nil {
-		s.logger.Warnw("No products found in current reception", "receptionId", receptionId)
-		return fmt.Errorf("no products found for current reception")
+
+	var items []apperror.ItemError
+	for i, product := range batch {
+		if inserted[product.Id] {
+			continue
+		}
+		message := fmt.Sprintf("product %s already exists", product.Id)
+		if product.Barcode != nil {
+			message = fmt.Sprintf("product %s or barcode %q already exists", product.Id, *product.Barcode)
+		}
+		items = append(items, apperror.ItemError{Index: i, Message: message})
 	}
+	return apperror.WithItems(apperror.ErrConflict, items, "product batch contains existing products")
+}
+
+// DeleteLastProduct удаляет последний добавленный товар открытой приёмки ПВЗ
+func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzId uuid.UUID, userId uuid.UUID) error {
+	s.logger.Infow("Attempting to delete last product", "pvzId", pvzId)
+
+	var receptionId, lastProductId uuid.UUID
+	var event model.Event
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		if _, err := lockPvz(ctx, repos, pvzId); err != nil {
+			s.logger.Errorw("Failed to lock PVZ", "pvzId", pvzId, "error", err)
+			return err
+		}
+		if err := authorizePvz(ctx, repos, pvzId); err != nil {
+			return err
+		}
+
+		var err error
+		receptionId, err = repos.Reception.GetInProgressReception(ctx, pvzId)
+		if err != nil {
+			s.logger.Errorw("Failed to get in-progress reception", "pvzId", pvzId, "error", err)
+			return fmt.Errorf("cannot delete product: reception lookup failed: %w", err)
+		}
+		if receptionId == uuid.Nil {
+			s.logger.Warnw("No active reception found", "pvzId", pvzId)
+			return apperror.Conflict("no active reception found for pvz %s", pvzId)
+		}
 
-	err = s.repoProduct.DeleteProductById(ctx, lastProductId)
+		lastProductId, err = repos.Product.GetLastProductIdByReception(ctx, receptionId)
+		if err != nil {
+			s.logger.Errorw("Failed to get last product ID", "receptionId", receptionId, "error", err)
+			return fmt.Errorf("cannot delete product: failed to get last product: %w", err)
+		}
+		if lastProductId == uuid.Nil {
+			s.logger.Warnw("No products found in current reception", "receptionId", receptionId)
+			return apperror.Conflict("no products found for current reception")
+		}
+
+		before, err := repos.Product.GetProductById(ctx, lastProductId)
+		if err != nil {
+			s.logger.Errorw("Failed to get last product", "productId", lastProductId, "error", err)
+			return fmt.Errorf("cannot delete product: failed to get last product: %w", err)
+		}
+
+		if err := repos.Product.DeleteProductById(ctx, lastProductId, userId); err != nil {
+			s.logger.Errorw("Failed to delete product", "productId", lastProductId, "error", err)
+			return fmt.Errorf("failed to delete last product: %w", err)
+		}
+		if err := recordAudit(ctx, repos, model.AuditProductDelete, model.AuditEntityProduct, lastProductId.String(), before.Product, nil); err != nil {
+			return err
+		}
+		event, err = recordEvent(ctx, repos, model.EventProductRemoved, pvzId, productPayload(before.Product))
+		return err
+	})
 	if err != nil {
-		s.logger.Errorw("Failed to delete product", "productId", lastProductId, "error", err)
-		return fmt.Errorf("failed to delete last product: %w", err)
+		return err
 	}
+	s.hub.Publish(event)
 
 	s.logger.Infow("Product deleted successfully", "productId", lastProductId, "receptionId", receptionId)
 	return nil
 }
+
+// DeleteProduct удаляет произвольный товар, пока его приёмка не закрыта
+func (s *ProductService) DeleteProduct(ctx context.Context, productId uuid.UUID, userId uuid.UUID) error {
+	s.logger.Infow("Attempting to delete product", "productId", productId, "userId", userId)
+
+	var event model.Event
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		before, err := s.lockEditableProduct(ctx, repos, productId)
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Product.DeleteProductById(ctx, productId, userId); err != nil {
+			s.logger.Errorw("Failed to delete product", "productId", productId, "error", err)
+			return productNotFound(err, productId)
+		}
+		if err := recordAudit(ctx, repos, model.AuditProductDelete, model.AuditEntityProduct, productId.String(), before.Product, nil); err != nil {
+			return err
+		}
+		event, err = recordEvent(ctx, repos, model.EventProductRemoved, before.PvzId, productPayload(before.Product))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.hub.Publish(event)
+
+	s.logger.Infow("Product deleted successfully", "productId", productId, "userId", userId)
+	return nil
+}
+
+// UpdateProduct исправляет товар, пока его приёмка не закрыта
+func (s *ProductService) UpdateProduct(ctx context.Context, productId uuid.UUID, update model.ProductUpdate, userId uuid.UUID) (model.Product, error) {
+	if update.Type == nil {
+		return model.Product{}, apperror.Validation("nothing to update")
+	}
+	if err := s.catalog.Validate(ctx, model.CatalogProductType, *update.Type); err != nil {
+		s.logger.Warnw("Invalid product type", "type", *update.Type, "error", err)
+		return model.Product{}, err
+	}
+
+	var updated model.Product
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		before, err := s.lockEditableProduct(ctx, repos, productId)
+		if err != nil {
+			return err
+		}
+
+		updated, err = repos.Product.UpdateProduct(ctx, productId, update, userId)
+		if err != nil {
+			s.logger.Errorw("Failed to update product", "productId", productId, "error", err)
+			return productNotFound(err, productId)
+		}
+		return recordAudit(ctx, repos, model.AuditProductUpdate, model.AuditEntityProduct, productId.String(), before.Product, updated)
+	})
+	if err != nil {
+		return model.Product{}, err
+	}
+
+	s.logger.Infow("Product updated successfully", "productId", productId, "userId", userId)
+	return updated, nil
+}
+
+// lockEditableProduct блокирует ПВЗ товара и проверяет доступ к нему и то,
+// что приёмка товара ещё открыта. Закрытие приёмки тоже блокирует ПВЗ, поэтому до конца
+// транзакции она не закроется.
+func (s *ProductService) lockEditableProduct(ctx context.Context, repos *repository.Repository, productId uuid.UUID) (model.ProductWithPvz, error) {
+	product, err := repos.Product.GetProductById(ctx, productId)
+	if err != nil {
+		return model.ProductWithPvz{}, productNotFound(err, productId)
+	}
+
+	if _, err := lockPvz(ctx, repos, product.PvzId); err != nil {
+		s.logger.Errorw("Failed to lock PVZ", "pvzId", product.PvzId, "error", err)
+		return model.ProductWithPvz{}, err
+	}
+	if err := authorizePvz(ctx, repos, product.PvzId); err != nil {
+		return model.ProductWithPvz{}, err
+	}
+
+	reception, err := repos.Reception.GetReceptionById(ctx, product.ReceptionId)
+	if err != nil {
+		s.logger.Errorw("Failed to get product reception", "receptionId", product.ReceptionId, "error", err)
+		return model.ProductWithPvz{}, err
+	}
+	if reception.Status != model.ReceptionStatusInProgress {
+		s.logger.Warnw("Product reception is closed", "productId", productId, "receptionId", reception.Id)
+		return model.ProductWithPvz{}, apperror.Conflict("reception %s of product %s is closed", reception.Id, productId)
+	}
+
+	return product, nil
+}
+
+// productNotFound переводит repository.ErrNotFound в ошибку NotFound для клиента
+func productNotFound(err error, productId uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(apperror.ErrNotFound, err, "product %s not found", productId)
+	}
+	return err
+}
+
+// FindProductsByBarcode ищет товары со штрихкодом во всех ПВЗ
+func (s *ProductService) FindProductsByBarcode(ctx context.Context, barcode string) ([]model.ProductWithPvz, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, apperror.Validation("barcode is required")
+	}
+
+	products, err := s.repoProduct.GetProductsByBarcode(ctx, barcode)
+	if err != nil {
+		s.logger.Errorw("Failed to find products by barcode", "barcode", barcode, "error", err)
+		return nil, err
+	}
+	return products, nil
+}
+
+// normalizeProduct обрезает пробелы в штрихкоде и номере заказа, заменяя
+// пустые значения на nil, и проверяет поля товара
+func normalizeProduct(product model.Product) (model.Product, error) {
+	product.Barcode = trimOptional(product.Barcode)
+	product.OrderId = trimOptional(product.OrderId)
+
+	switch {
+	case product.Barcode != nil && len(*product.Barcode) > maxBarcodeLength:
+		return model.Product{}, apperror.Validation("barcode must be at most %d characters", maxBarcodeLength)
+	case product.OrderId != nil && len(*product.OrderId) > maxOrderIdLength:
+		return model.Product{}, apperror.Validation("orderId must be at most %d characters", maxOrderIdLength)
+	case product.Weight != nil && *product.Weight <= 0:
+		return model.Product{}, apperror.Validation("weight must be positive")
+	}
+
+	attributes := bytes.TrimSpace(product.Attributes)
+	switch {
+	case len(attributes) == 0 || bytes.Equal(attributes, []byte("null")):
+		product.Attributes = nil
+	case attributes[0] != '{' || !json.Valid(attributes):
+		return model.Product{}, apperror.Validation("attributes must be a JSON object")
+	}
+
+	return product, nil
+}
+
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
-	"time"
 
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+
+	"pvz/internal/api/mapper"
 	"pvz/internal/api/response"
 	"pvz/internal/logger"
 	"pvz/internal/repository"
@@ -13,26 +16,40 @@ import (
 )
 
 type PvzService struct {
-	repoPvz       repository.Pvz
-	repoReception repository.Reception
-	repoProduct   repository.Product
-	logger        logger.Logger
+	repoPvz repository.Pvz
+	uow     repository.UnitOfWork
+	catalog Catalog
+	logger  logger.Logger
 }
 
-func NewPvzService(repoPvz repository.Pvz, repoReception repository.Reception,
-	repoProduct repository.Product, log logger.Logger) *PvzService {
+func NewPvzService(repos *repository.Repository, catalog Catalog, log logger.Logger) *PvzService {
 	return &PvzService{
-		repoPvz:       repoPvz,
-		repoReception: repoReception,
-		repoProduct:   repoProduct,
-		logger:        log,
+		repoPvz: repos.Pvz,
+		uow:     repos.UnitOfWork,
+		catalog: catalog,
+		logger:  log,
 	}
 }
 
 func (s *PvzService) CreatePvz(ctx context.Context, pvz model.Pvz) (model.Pvz, error) {
+	if err := s.catalog.Validate(ctx, model.CatalogCity, pvz.City); err != nil {
+		s.logger.Warnw("Invalid city", "city", pvz.City, "error", err)
+		return model.Pvz{}, err
+	}
+
 	s.logger.Infow("Calling repository to create PVZ", "city", pvz.City)
 
-	pvz, err := s.repoPvz.CreatePvz(ctx, pvz.City)
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		if pvz, err = repos.Pvz.CreatePvz(ctx, pvz.City); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, repos, model.AuditPvzCreate, model.AuditEntityPvz, pvz.Id.String(), nil, pvz); err != nil {
+			return err
+		}
+		_, err = recordEvent(ctx, repos, model.EventPvzCreated, pvz.Id, pvzPayload(pvz))
+		return err
+	})
 	if err != nil {
 		s.logger.Errorw("Service failed to create PVZ", "city", pvz.City, "error", err)
 		return model.Pvz{}, fmt.Errorf("error creating PVZ: %w", err)
@@ -42,70 +59,203 @@ func (s *PvzService) CreatePvz(ctx context.Context, pvz model.Pvz) (model.Pvz, e
 	return pvz, nil
 }
 
-func (s *PvzService) GetPvzList(ctx context.Context, limit, offset int, startDate, endDate *time.Time) ([]response.PvzFullResponse, error) {
-	s.logger.Infow("Getting Pvz list by reception date", "limit", limit, "offset", offset, "startDate", startDate, "endDate", endDate)
+// Максимальный размер страницы списков; больший limit урезается
+const maxPageLimit = 30
+
+func (s *PvzService) GetPvzList(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]response.PvzFullResponse, error) {
+	limit = min(limit, maxPageLimit)
 
-	pvzList, err := s.repoPvz.GetPvzListByReceptionDate(ctx, limit, offset, startDate, endDate)
+	s.logger.Infow("Getting Pvz list by reception date", "limit", limit, "offset", offset,
+		"startDate", filter.StartDate, "endDate", filter.EndDate, "status", filter.Status)
+
+	pvzList, err := s.repoPvz.GetPvzListWithReceptions(ctx, limit, offset, filter)
 	if err != nil {
 		s.logger.Errorw("Failed to get Pvz list", "error", err)
 		return nil, err
 	}
 
 	var fullResponse []response.PvzFullResponse
+	for _, pvz := range pvzList {
+		fullResponse = append(fullResponse, mapper.ToPvzFullResponse(pvz))
+	}
+
+	s.logger.Infow("Successfully retrieved Pvz list", "count", len(fullResponse))
+	return fullResponse, nil
+}
+
+// GetPvzPage возвращает страницу ПВЗ после курсора. Пустой cursor - первая страница.
+// NextCursor пуст, если следующей страницы нет.
+func (s *PvzService) GetPvzPage(ctx context.Context, limit int, cursor string, filter model.PvzFilter) (response.PvzPageResponse, error) {
+	limit = min(limit, maxPageLimit)
+
+	if cursor != "" {
+		after, err := decodePvzCursor(cursor)
+		if err != nil {
+			s.logger.Warnw("Invalid cursor", "cursor", cursor, "error", err)
+			return response.PvzPageResponse{}, apperror.Validation("invalid cursor")
+		}
+		filter.After = &after
+	}
+
+	s.logger.Infow("Getting Pvz page", "limit", limit, "cursor", cursor,
+		"startDate", filter.StartDate, "endDate", filter.EndDate, "status", filter.Status)
+
+	// Лишний ПВЗ показывает, есть ли следующая страница, без отдельного COUNT
+	pvzList, err := s.repoPvz.GetPvzListWithReceptions(ctx, limit+1, 0, filter)
+	if err != nil {
+		s.logger.Errorw("Failed to get Pvz page", "error", err)
+		return response.PvzPageResponse{}, err
+	}
 
+	page := response.PvzPageResponse{Items: make([]response.PvzFullResponse, 0, limit)}
+	if len(pvzList) > limit {
+		pvzList = pvzList[:limit]
+		last := pvzList[limit-1].Pvz
+		next := encodePvzCursor(model.PvzCursor{RegistrationDate: last.RegistrationDate, Id: last.Id})
+		page.NextCursor = &next
+	}
 	for _, pvz := range pvzList {
-		s.logger.Infow("Processing Pvz", "pvzId", pvz.Id)
+		page.Items = append(page.Items, mapper.ToPvzFullResponse(pvz))
+	}
 
-		receptions, err := s.repoReception.GetReceptionsByPvzID(ctx, pvz.Id)
+	s.logger.Infow("Successfully retrieved Pvz page", "count", len(page.Items), "hasNext", page.NextCursor != nil)
+	return page, nil
+}
+
+func (s *PvzService) GetPvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	pvz, err := s.repoPvz.GetPvzById(ctx, pvzId)
+	if err != nil {
+		return model.Pvz{}, pvzNotFound(err, pvzId)
+	}
+	return pvz, nil
+}
+
+func (s *PvzService) UpdatePvz(ctx context.Context, pvzId uuid.UUID, update model.PvzUpdate) (model.Pvz, error) {
+	if update.City == nil && update.Name == nil && update.Address == nil {
+		return model.Pvz{}, apperror.Validation("nothing to update")
+	}
+	if update.City != nil {
+		if err := s.catalog.Validate(ctx, model.CatalogCity, *update.City); err != nil {
+			return model.Pvz{}, err
+		}
+	}
+
+	var pvz model.Pvz
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		before, err := lockPvz(ctx, repos, pvzId)
 		if err != nil {
-			s.logger.Errorw("Failed to get receptions for Pvz", "pvzId", pvz.Id, "error", err)
-			return nil, err
-		}
-
-		var receptionWrappers []response.ReceptionWrapper
-
-		for _, rec := range receptions {
-			s.logger.Infow("Processing Reception", "receptionId", rec.Id)
-
-			products, err := s.repoProduct.GetProductsByReceptionID(ctx, rec.Id)
-			if err != nil {
-				s.logger.Errorw("Failed to get products for Reception", "receptionId", rec.Id, "error", err)
-				return nil, err
-			}
-
-			var productResponses []response.ProductResponse
-			for _, p := range products {
-				productResponses = append(productResponses, response.ProductResponse{
-					Id:          p.Id.String(),
-					DateTime:    p.DateTime.Format("2006-01-02 15:04:05"),
-					Type:        p.Type,
-					ReceptionId: p.ReceptionId.String(),
-				})
-			}
-
-			receptionWrappers = append(receptionWrappers, response.ReceptionWrapper{
-				Reception: response.ReceptionResponse{
-					Id:       rec.Id.String(),
-					DateTime: rec.DateTime.Format("2006-01-02 15:04:05"),
-					PvzId:    rec.PvzId.String(),
-					Status:   rec.Status,
-				},
-				Products: productResponses,
-			})
-		}
-
-		fullResponse = append(fullResponse, response.PvzFullResponse{
-			Pvz: response.PvzResponse{
-				Id:               pvz.Id.String(),
-				RegistrationDate: pvz.RegistrationDate.Format("2006-01-02 15:04:05"),
-				City:             pvz.City,
-			},
-			Receptions: receptionWrappers,
-		})
-
-		s.logger.Infow("Completed processing Pvz", "pvzId", pvz.Id)
+			return err
+		}
+
+		if pvz, err = repos.Pvz.UpdatePvz(ctx, pvzId, update); err != nil {
+			return pvzNotFound(err, pvzId)
+		}
+		return recordAudit(ctx, repos, model.AuditPvzUpdate, model.AuditEntityPvz, pvzId.String(), before, pvz)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to update PVZ", "pvzId", pvzId, "error", err)
+		return model.Pvz{}, err
 	}
 
-	s.logger.Infow("Successfully retrieved Pvz list", "count", len(fullResponse))
-	return fullResponse, nil
+	s.logger.Infow("Service successfully updated PVZ", "pvz", pvz)
+	return pvz, nil
+}
+
+// DeactivatePvz закрывает ПВЗ для новых приёмок, сохраняя историю.
+// Незакрытую приёмку нужно завершить до деактивации.
+func (s *PvzService) DeactivatePvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	var pvz model.Pvz
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		pvz, err = lockPvz(ctx, repos, pvzId)
+		if err != nil {
+			return err
+		}
+		if pvz.Status == model.PvzStatusInactive {
+			return nil
+		}
+
+		receptionId, err := repos.Reception.GetInProgressReception(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if receptionId != uuid.Nil {
+			s.logger.Warnw("Cannot deactivate PVZ with reception in progress", "pvzId", pvzId, "receptionId", receptionId)
+			return apperror.Conflict("pvz %s has a reception in progress", pvzId)
+		}
+
+		before := pvz
+		if pvz, err = repos.Pvz.SetPvzStatus(ctx, pvzId, model.PvzStatusInactive); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, model.AuditPvzDeactivate, model.AuditEntityPvz, pvzId.String(), before, pvz)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to deactivate PVZ", "pvzId", pvzId, "error", err)
+		return model.Pvz{}, err
+	}
+
+	s.logger.Infow("PVZ deactivated", "pvzId", pvzId)
+	return pvz, nil
+}
+
+func (s *PvzService) ActivatePvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	var pvz model.Pvz
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		pvz, err = lockPvz(ctx, repos, pvzId)
+		if err != nil {
+			return err
+		}
+		if pvz.Status == model.PvzStatusActive {
+			return nil
+		}
+
+		before := pvz
+		if pvz, err = repos.Pvz.SetPvzStatus(ctx, pvzId, model.PvzStatusActive); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, model.AuditPvzActivate, model.AuditEntityPvz, pvzId.String(), before, pvz)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to activate PVZ", "pvzId", pvzId, "error", err)
+		return model.Pvz{}, err
+	}
+
+	s.logger.Infow("PVZ activated", "pvzId", pvzId)
+	return pvz, nil
+}
+
+// DeletePvz удаляет ПВЗ без истории. ПВЗ с приёмками можно только деактивировать.
+func (s *PvzService) DeletePvz(ctx context.Context, pvzId uuid.UUID) error {
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		pvz, err := lockPvz(ctx, repos, pvzId)
+		if err != nil {
+			return err
+		}
+
+		hasReceptions, err := repos.Reception.HasReceptions(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if hasReceptions {
+			s.logger.Warnw("Cannot delete PVZ with receptions", "pvzId", pvzId)
+			return apperror.Conflict("pvz %s has receptions, deactivate it instead", pvzId)
+		}
+
+		if err := repos.Pvz.DeletePvz(ctx, pvzId); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, model.AuditPvzDelete, model.AuditEntityPvz, pvzId.String(), pvz, nil)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to delete PVZ", "pvzId", pvzId, "error", err)
+		return err
+	}
+
+	s.logger.Infow("PVZ deleted", "pvzId", pvzId)
+	return nil
 }
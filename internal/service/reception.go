@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/live"
 	"pvz/internal/logger"
 	"pvz/internal/repository"
 	"pvz/internal/repository/model"
@@ -12,62 +16,198 @@ import (
 )
 
 type ReceptionService struct {
+	repoPvz       repository.Pvz
 	repoReception repository.Reception
-	logger        logger.Logger
+	repoProduct   repository.Product
+	uow           repository.UnitOfWork
+	hub           *live.Hub
+	// reopenWindow - сколько после закрытия приёмку можно открыть снова
+	reopenWindow time.Duration
+	logger       logger.Logger
 }
 
-func NewReceptionService(repoReception repository.Reception, log logger.Logger) *ReceptionService {
+func NewReceptionService(repos *repository.Repository, hub *live.Hub, reopenWindow time.Duration, log logger.Logger) *ReceptionService {
 	return &ReceptionService{
-		repoReception: repoReception,
+		repoPvz:       repos.Pvz,
+		repoReception: repos.Reception,
+		repoProduct:   repos.Product,
+		uow:           repos.UnitOfWork,
+		hub:           hub,
+		reopenWindow:  reopenWindow,
 		logger:        log,
 	}
 }
 
 func (s *ReceptionService) CreateReception(ctx context.Context, pvzId uuid.UUID) (model.Reception, error) {
-	s.logger.Infow("Checking for existing in-progress reception", "pvzId", pvzId)
+	var reception model.Reception
+	var event model.Event
 
-	receptionId, err := s.repoReception.GetInProgressReception(ctx, pvzId)
-	if err != nil {
-		return model.Reception{}, err
-	}
-	if receptionId != uuid.Nil {
-		s.logger.Warnw("Reception already in progress for PVZ", "pvzId", pvzId)
-		return model.Reception{}, fmt.Errorf("an in-progress reception already exists for PVZ %s", pvzId)
-	}
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		pvz, err := lockPvz(ctx, repos, pvzId)
+		if err != nil {
+			s.logger.Errorw("Failed to lock PVZ", "pvzId", pvzId, "error", err)
+			return err
+		}
+		if err := authorizePvz(ctx, repos, pvzId); err != nil {
+			return err
+		}
+		if pvz.Status == model.PvzStatusInactive {
+			s.logger.Warnw("Reception rejected for inactive PVZ", "pvzId", pvzId)
+			return apperror.Conflict("pvz %s is deactivated", pvzId)
+		}
+
+		s.logger.Infow("Checking for existing in-progress reception", "pvzId", pvzId)
 
-	s.logger.Infow("Calling repo to create reception", "pvzId", pvzId)
+		receptionId, err := repos.Reception.GetInProgressReception(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if receptionId != uuid.Nil {
+			s.logger.Warnw("Reception already in progress for PVZ", "pvzId", pvzId)
+			return apperror.Conflict("an in-progress reception already exists for PVZ %s", pvzId)
+		}
 
-	reception, err := s.repoReception.CreateReception(ctx, pvzId)
+		s.logger.Infow("Calling repo to create reception", "pvzId", pvzId)
+
+		reception, err = repos.Reception.CreateReception(ctx, pvzId)
+		if err != nil {
+			s.logger.Errorw("Failed to create reception in service", "pvzId", pvzId, "error", err)
+			return err
+		}
+		if err := recordAudit(ctx, repos, model.AuditReceptionCreate, model.AuditEntityReception, reception.Id.String(), nil, reception); err != nil {
+			return err
+		}
+		event, err = recordEvent(ctx, repos, model.EventReceptionOpened, pvzId, receptionPayload(reception))
+		return err
+	})
 	if err != nil {
-		s.logger.Errorw("Failed to create reception in service", "pvzId", pvzId, "error", err)
 		return model.Reception{}, err
 	}
+	s.hub.Publish(event)
+
 	metrics.CreatedReceptions.Inc()
 	s.logger.Infow("Successfully created reception", "receptionId", reception.Id)
 	return reception, nil
 }
 
+// CloseReception закрывает незакрытую приёмку ПВЗ
 func (s *ReceptionService) CloseReception(ctx context.Context, pvzId uuid.UUID) error {
 	s.logger.Infow("Attempting to close reception", "pvzId", pvzId)
 
-	receptionId, err := s.repoReception.GetInProgressReception(ctx, pvzId)
+	var event model.Event
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		pvz, err := lockPvz(ctx, repos, pvzId)
+		if err != nil {
+			s.logger.Errorw("Failed to lock PVZ", "pvzId", pvzId, "error", err)
+			return err
+		}
+		if err := authorizePvz(ctx, repos, pvzId); err != nil {
+			return err
+		}
+
+		receptionId, err := repos.Reception.GetInProgressReception(ctx, pvzId)
+		if err != nil {
+			s.logger.Errorw("Failed to get in-progress reception", "pvzId", pvzId, "error", err)
+			return fmt.Errorf("cannot close reception: reception lookup failed: %w", err)
+		}
+
+		if receptionId == uuid.Nil {
+			s.logger.Warnw("No active reception found", "pvzId", pvzId)
+			return apperror.Conflict("no active reception found for pvz %s", pvzId)
+		}
+
+		before, err := repos.Reception.GetReceptionById(ctx, receptionId)
+		if err != nil {
+			return fmt.Errorf("cannot close reception: reception lookup failed: %w", err)
+		}
+
+		_, event, err = s.applyTransition(ctx, repos, pvz, before, receptionClose)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.hub.Publish(event)
+
+	s.logger.Infow("Reception closed successfully", "pvzId", pvzId)
+
+	return nil
+}
+
+// GetReception возвращает приёмку вместе с её товарами
+func (s *ReceptionService) GetReception(ctx context.Context, receptionId uuid.UUID) (model.ReceptionWithProducts, error) {
+	reception, err := s.repoReception.GetReceptionById(ctx, receptionId)
+	if err != nil {
+		return model.ReceptionWithProducts{}, receptionNotFound(err, receptionId)
+	}
+
+	return s.withProducts(ctx, reception)
+}
+
+// GetReceptionList возвращает историю приёмок ПВЗ от новых к старым
+func (s *ReceptionService) GetReceptionList(ctx context.Context, pvzId uuid.UUID, limit, offset int, filter model.ReceptionFilter) ([]model.Reception, error) {
+	limit = min(limit, maxPageLimit)
+
+	// Пустой список для несуществующего ПВЗ скрыл бы ошибку в pvzId
+	if _, err := s.repoPvz.GetPvzById(ctx, pvzId); err != nil {
+		return nil, pvzNotFound(err, pvzId)
+	}
+
+	receptions, err := s.repoReception.GetReceptionList(ctx, pvzId, limit, offset, filter)
 	if err != nil {
-		s.logger.Errorw("Failed to get in-progress reception", "pvzId", pvzId, "error", err)
-		return fmt.Errorf("cannot close reception: reception lookup failed: %w", err)
+		s.logger.Errorw("Failed to get reception list", "pvzId", pvzId, "error", err)
+		return nil, err
 	}
 
+	return receptions, nil
+}
+
+// GetCurrentReception возвращает незакрытую приёмку ПВЗ вместе с её товарами
+func (s *ReceptionService) GetCurrentReception(ctx context.Context, pvzId uuid.UUID) (model.ReceptionWithProducts, error) {
+	if _, err := s.repoPvz.GetPvzById(ctx, pvzId); err != nil {
+		return model.ReceptionWithProducts{}, pvzNotFound(err, pvzId)
+	}
+
+	receptionId, err := s.repoReception.GetInProgressReception(ctx, pvzId)
+	if err != nil {
+		return model.ReceptionWithProducts{}, err
+	}
 	if receptionId == uuid.Nil {
-		s.logger.Warnw("No active reception found", "pvzId", pvzId)
-		return fmt.Errorf("no active reception found for pvz %s", pvzId)
+		return model.ReceptionWithProducts{}, apperror.NotFound("no in-progress reception for pvz %s", pvzId)
 	}
 
-	err = s.repoReception.CloseReception(ctx, pvzId)
+	return s.GetReception(ctx, receptionId)
+}
+
+func (s *ReceptionService) withProducts(ctx context.Context, reception model.Reception) (model.ReceptionWithProducts, error) {
+	products, err := s.repoProduct.GetProductsByReceptionID(ctx, reception.Id)
 	if err != nil {
-		s.logger.Errorw("Failed to close reception", "pvzId", pvzId, "error", err)
-		return fmt.Errorf("failed to close reception: %w", err)
+		s.logger.Errorw("Failed to get reception products", "receptionId", reception.Id, "error", err)
+		return model.ReceptionWithProducts{}, err
 	}
 
-	s.logger.Infow("Reception closed successfully", "pvzId", pvzId)
+	return model.ReceptionWithProducts{Reception: reception, Products: products}, nil
+}
 
-	return nil
+// lockPvz блокирует ПВЗ в текущей транзакции и переводит отсутствие ПВЗ в ошибку NotFound
+func lockPvz(ctx context.Context, repos *repository.Repository, pvzId uuid.UUID) (model.Pvz, error) {
+	pvz, err := repos.Pvz.LockPvz(ctx, pvzId)
+	return pvz, pvzNotFound(err, pvzId)
+}
+
+// pvzNotFound переводит repository.ErrNotFound в ошибку NotFound для клиента
+func pvzNotFound(err error, pvzId uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(apperror.ErrNotFound, err, "pvz %s not found", pvzId)
+	}
+	return err
+}
+
+// receptionNotFound переводит repository.ErrNotFound в ошибку NotFound для клиента
+func receptionNotFound(err error, receptionId uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(apperror.ErrNotFound, err, "reception %s not found", receptionId)
+	}
+	return err
 }
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+)
+
+// receptionTransition - допустимый переход статуса приёмки. Приёмку в любом
+// другом статусе перевести этим действием нельзя.
+type receptionTransition struct {
+	// name - действие в сообщениях об ошибках и логах
+	name  string
+	from  string
+	to    string
+	audit string
+	event string
+}
+
+// Переходы приёмки:
+//
+//	in_progress -> close -> verified
+//	in_progress -> cancelled
+//	close -> in_progress (повторное открытие модератором в течение ReopenWindow)
+//
+// verified и cancelled - конечные статусы.
+var (
+	receptionClose = receptionTransition{
+		name: "close", from: model.ReceptionStatusInProgress, to: model.ReceptionStatusClose,
+		audit: model.AuditReceptionClose, event: model.EventReceptionClosed,
+	}
+	receptionVerify = receptionTransition{
+		name: "verify", from: model.ReceptionStatusClose, to: model.ReceptionStatusVerified,
+		audit: model.AuditReceptionVerify, event: model.EventReceptionVerified,
+	}
+	receptionCancel = receptionTransition{
+		name: "cancel", from: model.ReceptionStatusInProgress, to: model.ReceptionStatusCancelled,
+		audit: model.AuditReceptionCancel, event: model.EventReceptionCancelled,
+	}
+	receptionReopen = receptionTransition{
+		name: "reopen", from: model.ReceptionStatusClose, to: model.ReceptionStatusInProgress,
+		audit: model.AuditReceptionReopen, event: model.EventReceptionReopened,
+	}
+)
+
+// CloseReceptionById закрывает незакрытую приёмку
+func (s *ReceptionService) CloseReceptionById(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	return s.transition(ctx, receptionId, receptionClose)
+}
+
+// VerifyReception подтверждает закрытую приёмку. Подтверждённую приёмку
+// нельзя открыть повторно.
+func (s *ReceptionService) VerifyReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	return s.transition(ctx, receptionId, receptionVerify)
+}
+
+// CancelReception отменяет незакрытую приёмку, после чего в ПВЗ можно открыть новую
+func (s *ReceptionService) CancelReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	return s.transition(ctx, receptionId, receptionCancel)
+}
+
+// ReopenReception снова открывает закрытую приёмку, если с закрытия прошло
+// не больше ReopenWindow и в ПВЗ нет другой незакрытой приёмки
+func (s *ReceptionService) ReopenReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	return s.transition(ctx, receptionId, receptionReopen)
+}
+
+func (s *ReceptionService) transition(ctx context.Context, receptionId uuid.UUID, t receptionTransition) (model.Reception, error) {
+	var after model.Reception
+	var event model.Event
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		before, err := repos.Reception.GetReceptionById(ctx, receptionId)
+		if err != nil {
+			return receptionNotFound(err, receptionId)
+		}
+
+		pvz, err := lockPvz(ctx, repos, before.PvzId)
+		if err != nil {
+			s.logger.Errorw("Failed to lock PVZ", "pvzId", before.PvzId, "error", err)
+			return err
+		}
+		if err := authorizePvz(ctx, repos, before.PvzId); err != nil {
+			return err
+		}
+
+		after, event, err = s.applyTransition(ctx, repos, pvz, before, t)
+		return err
+	})
+	if err != nil {
+		return model.Reception{}, err
+	}
+	s.hub.Publish(event)
+
+	s.logger.Infow("Reception status changed", "receptionId", receptionId, "from", t.from, "to", t.to)
+	return after, nil
+}
+
+// applyTransition проверяет и выполняет переход в транзакции repos. ПВЗ
+// приёмки уже заблокирован: переходы одного ПВЗ выполняются по очереди.
+func (s *ReceptionService) applyTransition(ctx context.Context, repos *repository.Repository, pvz model.Pvz, before model.Reception, t receptionTransition) (model.Reception, model.Event, error) {
+	if before.Status != t.from {
+		s.logger.Warnw("Invalid reception transition", "receptionId", before.Id, "transition", t.name, "status", before.Status)
+		return model.Reception{}, model.Event{}, apperror.Conflict("cannot %s reception %s in status %s", t.name, before.Id, before.Status)
+	}
+
+	if t == receptionReopen {
+		if err := s.checkReopen(ctx, repos, pvz, before); err != nil {
+			return model.Reception{}, model.Event{}, err
+		}
+	}
+
+	after, err := repos.Reception.UpdateReceptionStatus(ctx, model.ReceptionStatusChange{
+		ReceptionId: before.Id,
+		From:        t.from,
+		To:          t.to,
+		By:          actorId(ctx),
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Reception{}, model.Event{}, apperror.Wrap(apperror.ErrConflict, err, "reception %s was changed concurrently", before.Id)
+	}
+	if err != nil {
+		s.logger.Errorw("Failed to change reception status", "receptionId", before.Id, "transition", t.name, "error", err)
+		return model.Reception{}, model.Event{}, err
+	}
+
+	if err := recordAudit(ctx, repos, t.audit, model.AuditEntityReception, before.Id.String(), before, after); err != nil {
+		return model.Reception{}, model.Event{}, err
+	}
+	event, err := recordEvent(ctx, repos, t.event, after.PvzId, receptionPayload(after))
+	if err != nil {
+		return model.Reception{}, model.Event{}, err
+	}
+	return after, event, nil
+}
+
+// checkReopen проверяет, что закрытую приёмку ещё можно открыть снова.
+// Окно считается по часам базы, которыми проставлен closedAt.
+func (s *ReceptionService) checkReopen(ctx context.Context, repos *repository.Repository, pvz model.Pvz, reception model.Reception) error {
+	within, err := repos.Reception.ReceptionClosedWithin(ctx, reception.Id, s.reopenWindow)
+	if err != nil {
+		return receptionNotFound(err, reception.Id)
+	}
+	if !within {
+		return apperror.Conflict("reopen window of reception %s has expired", reception.Id)
+	}
+	if pvz.Status == model.PvzStatusInactive {
+		return apperror.Conflict("pvz %s is deactivated", pvz.Id)
+	}
+
+	inProgress, err := repos.Reception.GetInProgressReception(ctx, pvz.Id)
+	if err != nil {
+		return err
+	}
+	if inProgress != uuid.Nil {
+		return apperror.Conflict("an in-progress reception already exists for PVZ %s", pvz.Id)
+	}
+	return nil
+}
+
+// actorId возвращает пользователя запроса для closedBy и других авторов
+// переходов или nil, если пользователь неизвестен
+func actorId(ctx context.Context) *uuid.UUID {
+	claims, ok := jwt.ClaimsFromContext(ctx)
+	if !ok || claims.UserId == uuid.Nil {
+		return nil
+	}
+	return &claims.UserId
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+)
+
+// Сколько доверяем ответу «токен не отозван», прежде чем снова спросить БД.
+// Ограничивает задержку, с которой отзыв на другом инстансе начинает действовать здесь.
+const revocationCheckTTL = 30 * time.Second
+
+// RevocationCache проверяет отзыв access-токенов по jti. Отозванные токены
+// хранятся в памяти до истечения их срока, положительные проверки - revocationCheckTTL.
+type RevocationCache struct {
+	repo   repository.Token
+	logger logger.Logger
+
+	mu      sync.Mutex
+	revoked map[string]time.Time // jti -> истечение токена
+	checked map[string]time.Time // jti -> до какого момента доверяем проверке
+	swept   time.Time
+}
+
+func NewRevocationCache(repo repository.Token, log logger.Logger) *RevocationCache {
+	return &RevocationCache{
+		repo:    repo,
+		logger:  log,
+		revoked: make(map[string]time.Time),
+		checked: make(map[string]time.Time),
+	}
+}
+
+func (c *RevocationCache) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	c.sweep(now)
+	if _, ok := c.revoked[jti]; ok {
+		c.mu.Unlock()
+		return true, nil
+	}
+	if until, ok := c.checked[jti]; ok && now.Before(until) {
+		c.mu.Unlock()
+		return false, nil
+	}
+	c.mu.Unlock()
+
+	revoked, err := c.repo.IsAccessTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if revoked {
+		// Срок токена здесь неизвестен: держим запись не дольше максимального TTL проверки
+		c.revoked[jti] = now.Add(revocationCheckTTL)
+	} else {
+		c.checked[jti] = now.Add(revocationCheckTTL)
+	}
+
+	return revoked, nil
+}
+
+// Revoke сохраняет отзыв в БД и сразу учитывает его в кэше
+func (c *RevocationCache) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if err := c.repo.RevokeAccessToken(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revoked[jti] = expiresAt
+	delete(c.checked, jti)
+
+	c.logger.Infow("Access token revoked", "jti", jti)
+	return nil
+}
+
+// sweep удаляет устаревшие записи не чаще раза в revocationCheckTTL. Вызывается под mu.
+func (c *RevocationCache) sweep(now time.Time) {
+	if now.Sub(c.swept) < revocationCheckTTL {
+		return
+	}
+	c.swept = now
+
+	for jti, until := range c.revoked {
+		if now.After(until) {
+			delete(c.revoked, jti)
+		}
+	}
+	for jti, until := range c.checked {
+		if now.After(until) {
+			delete(c.checked, jti)
+		}
+	}
+}
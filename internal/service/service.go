@@ -6,44 +6,155 @@ import (
 
 	"github.com/google/uuid"
 	"pvz/internal/api/response"
+	"pvz/internal/live"
 	"pvz/internal/logger"
+	"pvz/internal/rbac"
 	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 )
 
 type User interface {
 	CreateUser(ctx context.Context, user model.User) (model.User, error)
-	LoginUser(ctx context.Context, email, password string) (string, error)
-	DummyLogin(ctx context.Context, role string) (string, error)
+	CreateUserWithRole(ctx context.Context, user model.User) (model.User, error)
+	LoginUser(ctx context.Context, email, password string) (model.TokenPair, error)
+	DummyLogin(ctx context.Context, role string, pvzScope []uuid.UUID) (string, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (model.TokenPair, error)
+	Logout(ctx context.Context, claims *model.TokenClaims, refreshToken string) error
+	DeleteExpiredTokens(ctx context.Context) error
+}
+
+type Revocation interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
 }
 
 type Pvz interface {
 	CreatePvz(ctx context.Context, pvz model.Pvz) (model.Pvz, error)
-	GetPvzList(ctx context.Context, limit, offset int, startDate, endDate *time.Time) ([]response.PvzFullResponse, error)
+	GetPvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error)
+	GetPvzList(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]response.PvzFullResponse, error)
+	GetPvzPage(ctx context.Context, limit int, cursor string, filter model.PvzFilter) (response.PvzPageResponse, error)
+	UpdatePvz(ctx context.Context, pvzId uuid.UUID, update model.PvzUpdate) (model.Pvz, error)
+	DeactivatePvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error)
+	ActivatePvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error)
+	DeletePvz(ctx context.Context, pvzId uuid.UUID) error
 }
 
 type Reception interface {
 	CreateReception(ctx context.Context, pvzId uuid.UUID) (model.Reception, error)
 	CloseReception(ctx context.Context, pvzId uuid.UUID) error
+	CloseReceptionById(ctx context.Context, receptionId uuid.UUID) (model.Reception, error)
+	VerifyReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error)
+	CancelReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error)
+	ReopenReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error)
+	GetReception(ctx context.Context, receptionId uuid.UUID) (model.ReceptionWithProducts, error)
+	GetReceptionList(ctx context.Context, pvzId uuid.UUID, limit, offset int, filter model.ReceptionFilter) ([]model.Reception, error)
+	GetCurrentReception(ctx context.Context, pvzId uuid.UUID) (model.ReceptionWithProducts, error)
 }
 
 type Product interface {
-	AddProduct(ctx context.Context, pvzId uuid.UUID, productType string) (model.Product, error)
-	DeleteLastProduct(ctx context.Context, pvzId uuid.UUID) error
+	AddProduct(ctx context.Context, pvzId uuid.UUID, product model.Product) (model.Product, error)
+	AddProducts(ctx context.Context, pvzId uuid.UUID, products []model.Product) ([]model.Product, error)
+	DeleteLastProduct(ctx context.Context, pvzId uuid.UUID, userId uuid.UUID) error
+	DeleteProduct(ctx context.Context, productId uuid.UUID, userId uuid.UUID) error
+	UpdateProduct(ctx context.Context, productId uuid.UUID, update model.ProductUpdate, userId uuid.UUID) (model.Product, error)
+	FindProductsByBarcode(ctx context.Context, barcode string) ([]model.ProductWithPvz, error)
+}
+
+type Catalog interface {
+	ListCatalog(ctx context.Context, kind model.CatalogKind) ([]model.CatalogItem, error)
+	AddCatalogItem(ctx context.Context, kind model.CatalogKind, name string) (model.CatalogItem, error)
+	SetCatalogItemActive(ctx context.Context, kind model.CatalogKind, name string, active bool) (model.CatalogItem, error)
+	Validate(ctx context.Context, kind model.CatalogKind, value string) error
+}
+
+type Idempotency interface {
+	AcquireIdempotencyKey(ctx context.Context, userId uuid.UUID, key, requestHash string) (*IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, userId uuid.UUID, key string, statusCode int, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) error
+}
+
+type Audit interface {
+	GetAuditLog(ctx context.Context, limit, offset int, filter model.AuditFilter) ([]model.AuditEntry, error)
+}
+
+type Assignment interface {
+	AssignPvz(ctx context.Context, userId, pvzId uuid.UUID) error
+	UnassignPvz(ctx context.Context, userId, pvzId uuid.UUID) error
+	GetAssignedPvzIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error)
+}
+
+type StaleReception interface {
+	ProcessStaleReceptions(ctx context.Context) error
+}
+
+type Outbox interface {
+	PublishOutboxEvents(ctx context.Context) error
+	DeletePublishedOutboxEvents(ctx context.Context) error
+}
+
+type Webhook interface {
+	CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (model.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, id uuid.UUID, update model.WebhookSubscriptionUpdate) (model.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit, offset int) ([]model.WebhookDelivery, error)
+	DeliverWebhooks(ctx context.Context) error
+}
+
+type Live interface {
+	SubscribePvzEvents(ctx context.Context, pvzId uuid.UUID, lastEventId string) (*live.Subscription, error)
 }
 
 type Service struct {
 	User
+	Revocation
 	Pvz
 	Reception
+	StaleReception
 	Product
+	Catalog
+	Idempotency
+	Audit
+	Assignment
+	Outbox
+	Webhook
+	Live
 }
 
-func NewService(repos *repository.Repository, log logger.Logger) *Service {
+// Config - параметры сервисного слоя
+type Config struct {
+	Tokens         TokenConfig
+	IdempotencyTTL time.Duration
+	// ReceptionReopenWindow - сколько после закрытия модератор может открыть приёмку снова
+	ReceptionReopenWindow time.Duration
+	// StaleReceptions - что делать с приёмками, которые забыли закрыть
+	StaleReceptions StaleReceptionConfig
+	Outbox          OutboxConfig
+	Webhooks        WebhookConfig
+	// Hub рассылает события ПВЗ подписчикам live-потока
+	Hub *live.Hub
+	// Policy - политика RBAC; пользователь может получить только роль из неё
+	Policy *rbac.Policy
+}
+
+func NewService(repos *repository.Repository, cfg Config, log logger.Logger) *Service {
+	revocations := NewRevocationCache(repos.Token, log)
+	catalog := NewCatalogService(repos, log)
+
 	return &Service{
-		User:      NewUserService(repos.User, log),
-		Pvz:       NewPvzService(repos.Pvz, repos.Reception, repos.Product, log),
-		Reception: NewReceptionService(repos.Reception, log),
-		Product:   NewProductService(repos.Product, repos.Reception, log),
+		User:           NewUserService(repos, revocations, cfg.Tokens, cfg.Policy, log),
+		Revocation:     revocations,
+		Pvz:            NewPvzService(repos, catalog, log),
+		Reception:      NewReceptionService(repos, cfg.Hub, cfg.ReceptionReopenWindow, log),
+		StaleReception: NewStaleReceptionService(repos, cfg.StaleReceptions, cfg.Hub, log),
+		Product:        NewProductService(repos, catalog, cfg.Hub, log),
+		Catalog:        catalog,
+		Idempotency:    NewIdempotencyService(repos.Idempotency, cfg.IdempotencyTTL, log),
+		Audit:          NewAuditService(repos.Audit, log),
+		Assignment:     NewAssignmentService(repos, cfg.Policy, log),
+		Outbox:         NewOutboxService(repos, cfg.Outbox, log),
+		Webhook:        NewWebhookService(repos, cfg.Webhooks, log),
+		Live:           NewLiveService(repos, cfg.Hub, log),
 	}
 }
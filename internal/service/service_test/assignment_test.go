@@ -0,0 +1,241 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/apperror"
+	"pvz/internal/jwtkeys"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/rbac"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func TestCreateReception_PvzScope(t *testing.T) {
+	pvzID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		claims    *model.TokenClaims
+		assigned  bool
+		forbidden bool
+	}{
+		{name: "unscoped role is global", claims: &model.TokenClaims{UserId: userID, Role: model.RoleModerator, Unscoped: true}},
+		{name: "custom role without assignment", claims: &model.TokenClaims{UserId: userID, Role: "packer"}, forbidden: true},
+		{name: "assigned employee", claims: &model.TokenClaims{UserId: userID, Role: model.RoleEmployee}, assigned: true},
+		{name: "unassigned employee", claims: &model.TokenClaims{UserId: userID, Role: model.RoleEmployee}, forbidden: true},
+		{name: "dummy token without scope", claims: &model.TokenClaims{Role: model.RoleEmployee}},
+		{name: "dummy token in scope", claims: &model.TokenClaims{Role: model.RoleEmployee, PvzScope: []uuid.UUID{uuid.New(), pvzID}}},
+		{name: "dummy token out of scope", claims: &model.TokenClaims{Role: model.RoleEmployee, PvzScope: []uuid.UUID{uuid.New()}}, forbidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockReceptionRepository)
+			mockPvzRepo := new(mocks.MockPvzRepository)
+			mockAssignment := new(mocks.MockAssignmentRepository)
+			mockLogger := new(mocks.MockLogger)
+			uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo, Assignment: mockAssignment}}
+			receptionService := newReceptionService(uow, mockLogger)
+
+			mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+			mockAssignment.On("IsPvzAssigned", mock.Anything, userID, pvzID).Return(tt.assigned, nil).Maybe()
+			mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil).Maybe()
+			mockRepo.On("CreateReception", mock.Anything, pvzID).Return(model.Reception{Id: uuid.New(), PvzId: pvzID}, nil).Maybe()
+			mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+			_, err := receptionService.CreateReception(jwt.ContextWithClaims(context.Background(), tt.claims), pvzID)
+
+			if tt.forbidden {
+				assert.ErrorIs(t, err, apperror.ErrForbidden)
+				mockRepo.AssertNotCalled(t, "CreateReception", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.claims.Unscoped || tt.claims.UserId == uuid.Nil {
+				mockAssignment.AssertNotCalled(t, "IsPvzAssigned", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDeleteProduct_UnassignedPvz(t *testing.T) {
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockAssignment := new(mocks.MockAssignmentRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Pvz: mockPvzRepo, Assignment: mockAssignment}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	productID := uuid.New()
+
+	mockProductRepo.On("GetProductById", mock.Anything, productID).
+		Return(model.ProductWithPvz{Product: model.Product{Id: productID}, PvzId: pvzID}, nil)
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	mockAssignment.On("IsPvzAssigned", mock.Anything, userID, pvzID).Return(false, nil)
+	mockLogger.On("Infow", "Attempting to delete product", "productId", productID, "userId", userID)
+
+	ctx := jwt.ContextWithClaims(context.Background(), &model.TokenClaims{UserId: userID, Role: model.RoleEmployee})
+	err := productService.DeleteProduct(ctx, productID, userID)
+
+	assert.ErrorIs(t, err, apperror.ErrForbidden)
+	mockProductRepo.AssertNotCalled(t, "DeleteProductById", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddProduct_CustomRolePvzScope(t *testing.T) {
+	// Права роли берутся из политики так же, как при проверке токена в API
+	policy, err := rbac.New(map[string][]string{
+		"packer":     {"product:create"},
+		"dispatcher": {"product:create", "pvz:unscoped"},
+	})
+	require.NoError(t, err)
+	keys := jwtkeys.NewHMAC([]byte("test-signing-key"))
+	revocations := mocks.NewMockRevocation(gomock.NewController(t))
+	revocations.EXPECT().IsRevoked(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+	auth := jwt.NewAuth(keys, revocations, policy)
+
+	tests := []struct {
+		role        string
+		expectedErr error
+	}{
+		// Без назначения и без pvz:unscoped роль не может менять данные ПВЗ
+		{role: "packer", expectedErr: apperror.ErrForbidden},
+		// Назначение не проверяется; дальше нет открытой приёмки
+		{role: "dispatcher", expectedErr: apperror.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			mockReceptionRepo := new(mocks.MockReceptionRepository)
+			mockProductRepo := new(mocks.MockProductRepository)
+			mockPvzRepo := new(mocks.MockPvzRepository)
+			mockAssignment := new(mocks.MockAssignmentRepository)
+			mockLogger := new(mocks.MockLogger)
+			mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+			mockLogger.On("Warnw", mock.Anything, mock.Anything, mock.Anything).Maybe()
+			uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{
+				Reception: mockReceptionRepo, Product: mockProductRepo, Pvz: mockPvzRepo, Assignment: mockAssignment,
+			}}
+			productService := newProductService(uow, allowAllCatalog(t), mockLogger)
+
+			userID, pvzID := uuid.New(), uuid.New()
+			token, err := keys.Sign(model.TokenClaims{
+				StandardClaims: jwtgo.StandardClaims{Id: uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour).Unix()},
+				UserId:         userID,
+				Role:           tt.role,
+			})
+			require.NoError(t, err)
+			claims, err := auth.Authenticate(context.Background(), "Bearer "+token)
+			require.NoError(t, err)
+
+			mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+			mockAssignment.On("IsPvzAssigned", mock.Anything, userID, pvzID).Return(false, nil).Maybe()
+			mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil).Maybe()
+
+			_, err = productService.AddProduct(jwt.ContextWithClaims(context.Background(), claims), pvzID, model.Product{Type: "одежда"})
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			mockProductRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func newAssignmentService(userRepo *mocks.MockUserPostgres, assignmentRepo *mocks.MockAssignmentRepository, pvzRepo *mocks.MockPvzRepository, audit *mocks.MockAuditRepository) *service.AssignmentService {
+	repos := &repository.Repository{User: userRepo, Assignment: assignmentRepo, Pvz: pvzRepo}
+	if audit != nil {
+		repos.Audit = audit
+	}
+	allowAudit(repos)
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+
+	log := new(mocks.MockLogger)
+	log.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	log.On("Errorw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	return service.NewAssignmentService(repos, rbac.Default(), log)
+}
+
+func TestAssignPvz(t *testing.T) {
+	mockUser := new(mocks.MockUserPostgres)
+	mockAssignment := new(mocks.MockAssignmentRepository)
+	mockPvz := new(mocks.MockPvzRepository)
+	mockAudit := new(mocks.MockAuditRepository)
+	assignmentService := newAssignmentService(mockUser, mockAssignment, mockPvz, mockAudit)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+
+	mockUser.On("GetUserById", mock.Anything, userID).Return(model.User{Id: userID, Role: model.RoleEmployee}, nil)
+	mockPvz.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	mockAssignment.On("AssignPvz", mock.Anything, userID, pvzID).Return(true, nil).Once()
+	mockAudit.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry model.AuditEntry) bool {
+		return entry.Action == model.AuditPvzAssign && entry.EntityId == userID.String()
+	})).Return(nil).Once()
+
+	require.NoError(t, assignmentService.AssignPvz(context.Background(), userID, pvzID))
+
+	// Повторное назначение ничего не меняет и не пишется в аудит
+	mockAssignment.On("AssignPvz", mock.Anything, userID, pvzID).Return(false, nil).Once()
+
+	require.NoError(t, assignmentService.AssignPvz(context.Background(), userID, pvzID))
+	mockAudit.AssertNumberOfCalls(t, "CreateAuditEntry", 1)
+}
+
+func TestAssignPvz_Errors(t *testing.T) {
+	userID := uuid.New()
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name        string
+		user        model.User
+		userErr     error
+		pvzErr      error
+		expectedErr error
+	}{
+		{name: "user not found", userErr: repository.ErrNotFound, expectedErr: apperror.ErrNotFound},
+		{name: "unscoped role", user: model.User{Id: userID, Role: model.RoleModerator}, expectedErr: apperror.ErrValidation},
+		{name: "pvz not found", user: model.User{Id: userID, Role: model.RoleEmployee}, pvzErr: repository.ErrNotFound, expectedErr: apperror.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUser := new(mocks.MockUserPostgres)
+			mockAssignment := new(mocks.MockAssignmentRepository)
+			mockPvz := new(mocks.MockPvzRepository)
+			assignmentService := newAssignmentService(mockUser, mockAssignment, mockPvz, nil)
+
+			mockUser.On("GetUserById", mock.Anything, userID).Return(tt.user, tt.userErr)
+			mockPvz.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{}, tt.pvzErr).Maybe()
+
+			err := assignmentService.AssignPvz(context.Background(), userID, pvzID)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			mockAssignment.AssertNotCalled(t, "AssignPvz", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUnassignPvz_NotAssigned(t *testing.T) {
+	mockAssignment := new(mocks.MockAssignmentRepository)
+	assignmentService := newAssignmentService(new(mocks.MockUserPostgres), mockAssignment, new(mocks.MockPvzRepository), nil)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	mockAssignment.On("UnassignPvz", mock.Anything, userID, pvzID).Return(repository.ErrNotFound)
+
+	err := assignmentService.UnassignPvz(context.Background(), userID, pvzID)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
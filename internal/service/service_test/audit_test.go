@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/middleware/requestid"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+// allowAudit принимает любые записи аудита, если тест не задал свой мок
+func allowAudit(repos *repository.Repository) {
+	if repos.Audit != nil {
+		return
+	}
+	audit := new(mocks.MockAuditRepository)
+	audit.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(nil)
+	repos.Audit = audit
+}
+
+// requestContext возвращает контекст запроса, прошедшего requestid.Middleware
+func requestContext(t *testing.T, requestId string) context.Context {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var ctx context.Context
+	router := gin.New()
+	router.Use(requestid.Middleware())
+	router.GET("/", func(c *gin.Context) { ctx = c.Request.Context() })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestid.Header, requestId)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.NotNil(t, ctx)
+	return ctx
+}
+
+func TestCreatePvz_WritesAudit(t *testing.T) {
+	mockRepo := new(mocks.MockPvzRepository)
+	mockAudit := new(mocks.MockAuditRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Pvz: mockRepo, Reception: new(mocks.MockReceptionRepository), Audit: mockAudit}
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	allowOutbox(repos)
+	pvzService := service.NewPvzService(repos, allowAllCatalog(t), mockLogger)
+
+	created := model.Pvz{Id: uuid.New(), City: "Москва", Status: model.PvzStatusActive}
+	mockRepo.On("CreatePvz", mock.Anything, "Москва").Return(created, nil)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything)
+
+	var entry model.AuditEntry
+	mockAudit.On("CreateAuditEntry", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { entry = args.Get(1).(model.AuditEntry) }).
+		Return(nil).Once()
+
+	_, err := pvzService.CreatePvz(requestContext(t, "req-1"), model.Pvz{City: "Москва"})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.AuditPvzCreate, entry.Action)
+	assert.Equal(t, model.AuditEntityPvz, entry.EntityType)
+	assert.Equal(t, created.Id.String(), entry.EntityId)
+	assert.Equal(t, "req-1", entry.RequestId)
+	assert.Nil(t, entry.Before)
+	assert.Contains(t, string(entry.After), created.Id.String())
+	// Без токена в контексте действие считается системным
+	assert.Nil(t, entry.ActorId)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestCreatePvz_AuditErrorRollsBack(t *testing.T) {
+	mockRepo := new(mocks.MockPvzRepository)
+	mockAudit := new(mocks.MockAuditRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Pvz: mockRepo, Reception: new(mocks.MockReceptionRepository), Audit: mockAudit}
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	allowOutbox(repos)
+	pvzService := service.NewPvzService(repos, allowAllCatalog(t), mockLogger)
+
+	auditErr := assert.AnError
+	mockRepo.On("CreatePvz", mock.Anything, "Москва").Return(model.Pvz{Id: uuid.New(), City: "Москва"}, nil)
+	mockAudit.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(auditErr)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything)
+	mockLogger.On("Errorw", "Service failed to create PVZ", "city", "Москва", "error", auditErr)
+
+	_, err := pvzService.CreatePvz(context.Background(), model.Pvz{City: "Москва"})
+
+	assert.ErrorIs(t, err, auditErr)
+}
+
+func TestGetAuditLog_ClampsLimit(t *testing.T) {
+	mockAudit := new(mocks.MockAuditRepository)
+	auditService := service.NewAuditService(mockAudit, new(mocks.MockLogger))
+
+	actorId := uuid.New()
+	filter := model.AuditFilter{ActorId: &actorId, Action: model.AuditProductDelete}
+	entries := []model.AuditEntry{{Id: uuid.New(), Action: model.AuditProductDelete}}
+	mockAudit.On("GetAuditLog", mock.Anything, 30, 5, filter).Return(entries, nil)
+
+	result, err := auditService.GetAuditLog(context.Background(), 100, 5, filter)
+
+	require.NoError(t, err)
+	assert.Equal(t, entries, result)
+	mockAudit.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvz/internal/apperror"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func newCatalogService(repo *mocks.MockCatalogRepository, log *mocks.MockLogger) *service.CatalogService {
+	repos := &repository.Repository{Catalog: repo}
+	allowAudit(repos)
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	return service.NewCatalogService(repos, log)
+}
+
+func TestCatalogValidate(t *testing.T) {
+	mockRepo := new(mocks.MockCatalogRepository)
+	mockLogger := new(mocks.MockLogger)
+	catalogService := newCatalogService(mockRepo, mockLogger)
+
+	mockRepo.On("ListCatalog", mock.Anything, model.CatalogCity).Return([]model.CatalogItem{
+		{Name: "Москва", Active: true},
+		{Name: "Тверь", Active: false},
+	}, nil).Once()
+
+	ctx := context.Background()
+	assert.NoError(t, catalogService.Validate(ctx, model.CatalogCity, "Москва"))
+	assert.ErrorIs(t, catalogService.Validate(ctx, model.CatalogCity, "Тверь"), apperror.ErrValidation)
+	assert.ErrorIs(t, catalogService.Validate(ctx, model.CatalogCity, "Omsk"), apperror.ErrValidation)
+
+	// Справочник загружается один раз и дальше берётся из кэша
+	mockRepo.AssertNumberOfCalls(t, "ListCatalog", 1)
+}
+
+func TestCatalogValidate_RepoError(t *testing.T) {
+	mockRepo := new(mocks.MockCatalogRepository)
+	mockLogger := new(mocks.MockLogger)
+	catalogService := newCatalogService(mockRepo, mockLogger)
+
+	repoErr := errors.New("db error")
+	mockRepo.On("ListCatalog", mock.Anything, model.CatalogProductType).Return([]model.CatalogItem(nil), repoErr)
+	mockLogger.On("Errorw", "Failed to load catalog", "catalog", model.CatalogProductType, "error", repoErr)
+
+	err := catalogService.Validate(context.Background(), model.CatalogProductType, "обувь")
+
+	assert.ErrorIs(t, err, repoErr)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddCatalogItem_InvalidatesCache(t *testing.T) {
+	mockRepo := new(mocks.MockCatalogRepository)
+	mockLogger := new(mocks.MockLogger)
+	catalogService := newCatalogService(mockRepo, mockLogger)
+	ctx := context.Background()
+
+	mockRepo.On("ListCatalog", mock.Anything, model.CatalogCity).
+		Return([]model.CatalogItem{{Name: "Москва", Active: true}}, nil).Once()
+	assert.ErrorIs(t, catalogService.Validate(ctx, model.CatalogCity, "Казань"), apperror.ErrValidation)
+
+	mockRepo.On("AddCatalogItem", mock.Anything, model.CatalogCity, "Казань").
+		Return(model.CatalogItem{Name: "Казань", Active: true}, nil)
+	mockLogger.On("Infow", "Catalog item added", "catalog", model.CatalogCity, "name", "Казань")
+	mockRepo.On("ListCatalog", mock.Anything, model.CatalogCity).
+		Return([]model.CatalogItem{{Name: "Казань", Active: true}, {Name: "Москва", Active: true}}, nil).Once()
+
+	item, err := catalogService.AddCatalogItem(ctx, model.CatalogCity, "  Казань ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Казань", item.Name)
+
+	assert.NoError(t, catalogService.Validate(ctx, model.CatalogCity, "Казань"))
+	mockRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddCatalogItem_Errors(t *testing.T) {
+	mockRepo := new(mocks.MockCatalogRepository)
+	mockLogger := new(mocks.MockLogger)
+	catalogService := newCatalogService(mockRepo, mockLogger)
+
+	_, err := catalogService.AddCatalogItem(context.Background(), model.CatalogCity, "  ")
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+
+	mockRepo.On("AddCatalogItem", mock.Anything, model.CatalogCity, "Москва").
+		Return(model.CatalogItem{}, repository.ErrAlreadyExists)
+
+	_, err = catalogService.AddCatalogItem(context.Background(), model.CatalogCity, "Москва")
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+}
+
+func TestSetCatalogItemActive_NotFound(t *testing.T) {
+	mockRepo := new(mocks.MockCatalogRepository)
+	mockLogger := new(mocks.MockLogger)
+	catalogService := newCatalogService(mockRepo, mockLogger)
+
+	mockRepo.On("SetCatalogItemActive", mock.Anything, model.CatalogProductType, "мебель", false).
+		Return(model.CatalogItem{}, repository.ErrNotFound)
+
+	_, err := catalogService.SetCatalogItemActive(context.Background(), model.CatalogProductType, "мебель", false)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvz/internal/apperror"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func TestAcquireIdempotencyKey_NewKey(t *testing.T) {
+	mockRepo := new(mocks.MockIdempotencyRepository)
+	svc := service.NewIdempotencyService(mockRepo, time.Hour, new(mocks.MockLogger))
+	userId := uuid.New()
+
+	mockRepo.On("AcquireIdempotencyKey", mock.Anything, mock.MatchedBy(func(key model.IdempotencyKey) bool {
+		return key.UserId == userId && key.Key == "key-1" && key.RequestHash == "hash"
+	}), time.Hour).Return(true, nil).Once()
+
+	stored, err := svc.AcquireIdempotencyKey(context.Background(), userId, "key-1", "hash")
+
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAcquireIdempotencyKey_UsedKey(t *testing.T) {
+	created := http.StatusCreated
+	userId := uuid.New()
+
+	tests := []struct {
+		name     string
+		stored   model.IdempotencyKey
+		expected *service.IdempotentResponse
+		errKind  error
+	}{
+		{
+			name:     "replay",
+			stored:   model.IdempotencyKey{RequestHash: "hash", StatusCode: &created, ResponseBody: []byte(`{}`)},
+			expected: &service.IdempotentResponse{StatusCode: created, Body: []byte(`{}`)},
+		},
+		{
+			name:    "different request",
+			stored:  model.IdempotencyKey{RequestHash: "other", StatusCode: &created},
+			errKind: apperror.ErrConflict,
+		},
+		{
+			name:    "in progress",
+			stored:  model.IdempotencyKey{RequestHash: "hash"},
+			errKind: apperror.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockIdempotencyRepository)
+			mockLogger := new(mocks.MockLogger)
+			svc := service.NewIdempotencyService(mockRepo, time.Hour, mockLogger)
+
+			mockRepo.On("AcquireIdempotencyKey", mock.Anything, mock.Anything, time.Hour).Return(false, nil).Once()
+			mockRepo.On("GetIdempotencyKey", mock.Anything, userId, "key-1").Return(tt.stored, nil).Once()
+			mockLogger.On("Infow", "Replaying idempotent response", "userId", userId, "key", "key-1", "status", created).Maybe()
+			mockLogger.On("Warnw", "Idempotency key reused with a different request", "userId", userId, "key", "key-1").Maybe()
+
+			stored, err := svc.AcquireIdempotencyKey(context.Background(), userId, "key-1", "hash")
+
+			if tt.errKind != nil {
+				assert.ErrorIs(t, err, tt.errKind)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, stored)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSaveIdempotentResponse(t *testing.T) {
+	mockRepo := new(mocks.MockIdempotencyRepository)
+	svc := service.NewIdempotencyService(mockRepo, time.Hour, new(mocks.MockLogger))
+	userId := uuid.New()
+
+	mockRepo.On("SaveIdempotentResponse", mock.Anything, userId, "key-1", http.StatusConflict, []byte(`{}`)).Return(nil).Once()
+	// После внутренней ошибки ключ освобождается для повтора
+	mockRepo.On("DeleteIdempotencyKey", mock.Anything, userId, "key-2").Return(nil).Once()
+
+	assert.NoError(t, svc.SaveIdempotentResponse(context.Background(), userId, "key-1", http.StatusConflict, []byte(`{}`)))
+	assert.NoError(t, svc.SaveIdempotentResponse(context.Background(), userId, "key-2", http.StatusInternalServerError, nil))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	mockRepo := new(mocks.MockIdempotencyRepository)
+	mockLogger := new(mocks.MockLogger)
+	svc := service.NewIdempotencyService(mockRepo, time.Hour, mockLogger)
+
+	mockRepo.On("DeleteExpiredIdempotencyKeys", mock.Anything).Return(int64(2), nil).Once()
+	mockLogger.On("Infow", "Expired idempotency keys deleted", "count", int64(2)).Once()
+
+	assert.NoError(t, svc.DeleteExpiredIdempotencyKeys(context.Background()))
+	mockRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
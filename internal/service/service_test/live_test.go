@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/apperror"
+	"pvz/internal/live"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func newHub() *live.Hub {
+	return live.NewHub(live.Config{ReplayBuffer: 16, ClientBuffer: 16, HeartbeatInterval: time.Second}, new(mocks.MockLogger))
+}
+
+// receive возвращает следующее событие подписки или проваливает тест
+func receive(t *testing.T, sub *live.Subscription) live.Message {
+	t.Helper()
+	select {
+	case msg := <-sub.Messages():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no live event received")
+		return live.Message{}
+	}
+}
+
+func assertNoMessage(t *testing.T, sub *live.Subscription) {
+	t.Helper()
+	select {
+	case msg := <-sub.Messages():
+		t.Fatalf("unexpected live event %s", msg.Event.Type)
+	default:
+	}
+}
+
+func TestReceptionService_PublishesLiveEvents(t *testing.T) {
+	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+	repos := &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}
+	allowAudit(repos)
+	allowOutbox(repos)
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	hub := newHub()
+	receptionService := service.NewReceptionService(repos, hub, time.Hour, mockLogger)
+
+	pvzID := uuid.New()
+	reception := model.Reception{Id: uuid.New(), PvzId: pvzID, Status: model.ReceptionStatusInProgress}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil).Once()
+	mockRepo.On("CreateReception", mock.Anything, pvzID).Return(reception, nil)
+	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(reception.Id, nil)
+	mockRepo.On("GetReceptionById", mock.Anything, reception.Id).Return(reception, nil)
+	mockRepo.On("UpdateReceptionStatus", mock.Anything, mock.Anything).
+		Return(model.Reception{Id: reception.Id, PvzId: pvzID, Status: model.ReceptionStatusClose}, nil)
+
+	sub := hub.Subscribe(pvzID, "")
+	defer sub.Close()
+	other := hub.Subscribe(uuid.New(), "")
+	defer other.Close()
+
+	_, err := receptionService.CreateReception(context.Background(), pvzID)
+	require.NoError(t, err)
+	require.NoError(t, receptionService.CloseReception(context.Background(), pvzID))
+
+	opened := receive(t, sub)
+	assert.Equal(t, model.EventReceptionOpened, opened.Event.Type)
+	assert.Equal(t, pvzID, opened.Event.PvzId)
+	var payload model.ReceptionPayload
+	require.NoError(t, json.Unmarshal(opened.Event.Payload, &payload))
+	assert.Equal(t, reception.Id, payload.Id)
+
+	closed := receive(t, sub)
+	assert.Equal(t, model.EventReceptionClosed, closed.Event.Type)
+	assert.NotEqual(t, opened.Id, closed.Id)
+
+	// События другого ПВЗ не приходят
+	assertNoMessage(t, other)
+}
+
+func TestProductService_PublishesLiveEventsAfterCommit(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Errorw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+	repos := &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}
+	allowAudit(repos)
+	allowOutbox(repos)
+	uow := &mocks.MockUnitOfWork{Repos: repos}
+	repos.UnitOfWork = uow
+	hub := newHub()
+	productService := service.NewProductService(repos, allowAllCatalog(t), hub, mockLogger)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	product := model.Product{Id: uuid.New(), Type: "электроника", ReceptionId: receptionID}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(product, nil).Once()
+	mockProductRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(model.Product{}, errors.New("db down")).Once()
+
+	sub := hub.Subscribe(pvzID, "")
+	defer sub.Close()
+
+	_, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: "электроника"})
+	require.NoError(t, err)
+
+	added := receive(t, sub)
+	assert.Equal(t, model.EventProductAdded, added.Event.Type)
+	assert.Equal(t, pvzID, added.Event.PvzId)
+
+	// Откаченное изменение не попадает в поток
+	_, err = productService.AddProduct(context.Background(), pvzID, model.Product{Type: "электроника"})
+	require.Error(t, err)
+	assertNoMessage(t, sub)
+}
+
+func TestSubscribePvzEvents(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	hub := newHub()
+	liveService := service.NewLiveService(&repository.Repository{Pvz: mockPvzRepo}, hub, mockLogger)
+
+	pvzID := uuid.New()
+	mockPvzRepo.On("GetPvzById", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	mockLogger.On("Infow", "Live subscriber connected", "pvzId", pvzID, "replayed", 0, "resync", false)
+
+	sub, err := liveService.SubscribePvzEvents(context.Background(), pvzID, "")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	hub.Publish(model.Event{Id: uuid.New(), Type: model.EventProductAdded, PvzId: pvzID})
+	assert.Equal(t, model.EventProductAdded, receive(t, sub).Event.Type)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestSubscribePvzEvents_PvzNotFound(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	liveService := service.NewLiveService(&repository.Repository{Pvz: mockPvzRepo}, newHub(), new(mocks.MockLogger))
+
+	pvzID := uuid.New()
+	mockPvzRepo.On("GetPvzById", mock.Anything, pvzID).Return(model.Pvz{}, repository.ErrNotFound)
+
+	_, err := liveService.SubscribePvzEvents(context.Background(), pvzID, "")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
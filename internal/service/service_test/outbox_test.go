@@ -0,0 +1,385 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/outbox"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+// allowOutbox принимает любые события и доставки вебхуков, если тест не задал свои моки
+func allowOutbox(repos *repository.Repository) {
+	if repos.Outbox == nil {
+		events := new(mocks.MockOutboxRepository)
+		call := events.On("CreateOutboxEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		call.Run(func(args mock.Arguments) {
+			call.Return(model.Event{
+				Id:      uuid.New(),
+				Type:    args.String(1),
+				PvzId:   args.Get(2).(uuid.UUID),
+				Payload: args.Get(3).(json.RawMessage),
+			}, nil)
+		})
+		repos.Outbox = events
+	}
+	if repos.Webhook == nil {
+		webhooks := new(mocks.MockWebhookRepository)
+		webhooks.On("CreateWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), nil)
+		repos.Webhook = webhooks
+	}
+}
+
+func newOutboxService(repo *mocks.MockOutboxRepository, publisher outbox.Publisher, log *mocks.MockLogger) *service.OutboxService {
+	repos := &repository.Repository{Outbox: repo}
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	return service.NewOutboxService(repos, service.OutboxConfig{
+		Publisher:  publisher,
+		BatchSize:  10,
+		MaxBackoff: time.Minute,
+		Retention:  time.Hour,
+	}, log)
+}
+
+func outboxEvent(seq int64, pvzId uuid.UUID, attempts int) model.OutboxEvent {
+	return model.OutboxEvent{
+		Event:    model.Event{Id: uuid.New(), Type: model.EventProductAdded, PvzId: pvzId, Payload: json.RawMessage(`{}`)},
+		Seq:      seq,
+		Attempts: attempts,
+	}
+}
+
+// failingPublisher не доставляет события ПВЗ pvzId
+type failingPublisher struct {
+	*outbox.MemoryPublisher
+	pvzId uuid.UUID
+}
+
+func (p failingPublisher) Publish(ctx context.Context, event model.Event) error {
+	if event.PvzId == p.pvzId {
+		return errors.New("partner unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestPublishOutboxEvents_OrderPerPvz(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	log := new(mocks.MockLogger)
+	publisher := failingPublisher{MemoryPublisher: outbox.NewMemoryPublisher(), pvzId: uuid.New()}
+	svc := newOutboxService(repo, publisher, log)
+
+	healthyPvz := uuid.New()
+	events := []model.OutboxEvent{
+		outboxEvent(1, publisher.pvzId, 2),
+		outboxEvent(2, healthyPvz, 0),
+		outboxEvent(3, publisher.pvzId, 0),
+		outboxEvent(4, healthyPvz, 0),
+	}
+
+	repo.On("TryLockOutbox", mock.Anything).Return(true, nil)
+	repo.On("GetPendingOutboxEvents", mock.Anything, 10).Return(events, nil)
+	// Пачка арендуется на минуту, а пропущенное событие 3 сразу возвращается в очередь
+	repo.On("DelayOutboxEvents", mock.Anything, []int64{1, 2, 3, 4}, time.Minute).Return(nil).Once()
+	repo.On("DelayOutboxEvents", mock.Anything, []int64{3}, time.Duration(0)).Return(nil).Once()
+	repo.On("MarkOutboxEventPublished", mock.Anything, int64(2)).Return(nil).Once()
+	repo.On("MarkOutboxEventPublished", mock.Anything, int64(4)).Return(nil).Once()
+
+	// Третья попытка откладывается на 4 секунды, событие 3 ждёт события 1
+	repo.On("MarkOutboxEventFailed", mock.Anything, int64(1), "partner unavailable", 4*time.Second).Return(nil).Once()
+	log.On("Warnw", "Failed to publish event", "eventId", events[0].Id, "type", model.EventProductAdded,
+		"pvzId", publisher.pvzId, "attempt", 3, "error", mock.Anything).Once()
+	log.On("Infow", "Outbox events published", "published", 2, "failed", 1).Once()
+
+	err := svc.PublishOutboxEvents(context.Background())
+
+	require.NoError(t, err)
+	// События healthyPvz опубликованы по порядку
+	published := publisher.Events()
+	require.Len(t, published, 2)
+	assert.Equal(t, events[1].Id, published[0].Id)
+	assert.Equal(t, events[3].Id, published[1].Id)
+	repo.AssertNotCalled(t, "MarkOutboxEventFailed", mock.Anything, int64(3), mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+	log.AssertExpectations(t)
+}
+
+func TestPublishOutboxEvents_BackoffCapped(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	log := new(mocks.MockLogger)
+	publisher := outbox.NewMemoryPublisher()
+	publisher.SetError(errors.New("down"))
+	svc := newOutboxService(repo, publisher, log)
+
+	event := outboxEvent(1, uuid.New(), 40)
+	repo.On("TryLockOutbox", mock.Anything).Return(true, nil)
+	repo.On("GetPendingOutboxEvents", mock.Anything, 10).Return([]model.OutboxEvent{event}, nil)
+	repo.On("DelayOutboxEvents", mock.Anything, []int64{1}, mock.Anything).Return(nil)
+
+	repo.On("MarkOutboxEventFailed", mock.Anything, int64(1), "down", time.Minute).Return(nil).Once()
+	log.On("Warnw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	log.On("Infow", "Outbox events published", "published", 0, "failed", 1)
+
+	require.NoError(t, svc.PublishOutboxEvents(context.Background()))
+
+	repo.AssertExpectations(t)
+}
+
+func TestPublishOutboxEvents_LockedByAnotherReplica(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	publisher := outbox.NewMemoryPublisher()
+	svc := newOutboxService(repo, publisher, new(mocks.MockLogger))
+
+	repo.On("TryLockOutbox", mock.Anything).Return(false, nil)
+
+	require.NoError(t, svc.PublishOutboxEvents(context.Background()))
+
+	assert.Empty(t, publisher.Events())
+	repo.AssertNotCalled(t, "GetPendingOutboxEvents", mock.Anything, mock.Anything)
+}
+
+func TestPublishOutboxEvents_MarkError(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	log := new(mocks.MockLogger)
+	publisher := outbox.NewMemoryPublisher()
+	svc := newOutboxService(repo, publisher, log)
+
+	event := outboxEvent(1, uuid.New(), 0)
+	markErr := errors.New("connection reset")
+	repo.On("TryLockOutbox", mock.Anything).Return(true, nil)
+	repo.On("GetPendingOutboxEvents", mock.Anything, 10).Return([]model.OutboxEvent{event}, nil)
+	repo.On("DelayOutboxEvents", mock.Anything, []int64{1}, mock.Anything).Return(nil)
+	repo.On("MarkOutboxEventPublished", mock.Anything, int64(1)).Return(markErr)
+	log.On("Errorw", "Failed to publish outbox events", "published", 0, "error", mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, markErr)
+	})).Once()
+
+	err := svc.PublishOutboxEvents(context.Background())
+
+	// Событие не отмечено и будет опубликовано повторно после аренды
+	assert.ErrorIs(t, err, markErr)
+	assert.Len(t, publisher.Events(), 1)
+	log.AssertExpectations(t)
+}
+
+// trackingUnitOfWork отмечает, выполняется ли сейчас транзакция
+type trackingUnitOfWork struct {
+	repos *repository.Repository
+	inTx  bool
+}
+
+func (u *trackingUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repository) error) error {
+	u.inTx = true
+	defer func() { u.inTx = false }()
+	return fn(u.repos)
+}
+
+// txCheckingPublisher запоминает, был ли Publish вызван внутри транзакции
+type txCheckingPublisher struct {
+	uow         *trackingUnitOfWork
+	inTx        bool
+	hasDeadline bool
+}
+
+func (p *txCheckingPublisher) Publish(ctx context.Context, event model.Event) error {
+	p.inTx = p.inTx || p.uow.inTx
+	_, p.hasDeadline = ctx.Deadline()
+	return nil
+}
+
+func TestPublishOutboxEvents_OutsideTransaction(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	log := new(mocks.MockLogger)
+	repos := &repository.Repository{Outbox: repo}
+	uow := &trackingUnitOfWork{repos: repos}
+	repos.UnitOfWork = uow
+	publisher := &txCheckingPublisher{uow: uow}
+	svc := service.NewOutboxService(repos, service.OutboxConfig{
+		Publisher:  publisher,
+		BatchSize:  10,
+		MaxBackoff: time.Minute,
+		Retention:  time.Hour,
+	}, log)
+
+	event := outboxEvent(1, uuid.New(), 0)
+	repo.On("TryLockOutbox", mock.Anything).Return(true, nil)
+	repo.On("GetPendingOutboxEvents", mock.Anything, 10).Return([]model.OutboxEvent{event}, nil)
+	repo.On("DelayOutboxEvents", mock.Anything, []int64{1}, mock.Anything).Return(nil)
+	repo.On("MarkOutboxEventPublished", mock.Anything, int64(1)).
+		Run(func(mock.Arguments) { assert.False(t, uow.inTx) }).
+		Return(nil).Once()
+	log.On("Infow", "Outbox events published", "published", 1, "failed", 0).Once()
+
+	require.NoError(t, svc.PublishOutboxEvents(context.Background()))
+
+	// Блокировка отпущена до публикации, а Publish ограничен сроком аренды
+	assert.False(t, publisher.inTx)
+	assert.True(t, publisher.hasDeadline)
+	repo.AssertExpectations(t)
+}
+
+func TestDeletePublishedOutboxEvents(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	log := new(mocks.MockLogger)
+	svc := newOutboxService(repo, outbox.NewMemoryPublisher(), log)
+
+	repo.On("DeletePublishedOutboxEvents", mock.Anything, time.Hour).Return(int64(3), nil).Once()
+	log.On("Infow", "Published outbox events deleted", "count", int64(3)).Once()
+
+	require.NoError(t, svc.DeletePublishedOutboxEvents(context.Background()))
+
+	repo.AssertExpectations(t)
+	log.AssertExpectations(t)
+}
+
+func TestCloseReception_WritesEvent(t *testing.T) {
+	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockOutbox := new(mocks.MockOutboxRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo, Outbox: mockOutbox}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID := uuid.MustParse("3fa85f64-5717-4562-b3fc-2c963f66afa6")
+	receptionID := uuid.MustParse("9b2f6c1e-0d4a-4c6b-8f3e-1a2b3c4d5e6f")
+	openedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	closedAt := openedAt.Add(2 * time.Hour)
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, DateTime: openedAt, PvzId: pvzID, Status: model.ReceptionStatusInProgress}, nil)
+	mockRepo.On("UpdateReceptionStatus", mock.Anything, mock.Anything).
+		Return(model.Reception{Id: receptionID, DateTime: openedAt, PvzId: pvzID, Status: model.ReceptionStatusClose, ClosedAt: &closedAt}, nil)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything)
+
+	var payload json.RawMessage
+	mockOutbox.On("CreateOutboxEvent", mock.Anything, model.EventReceptionClosed, pvzID, mock.Anything).
+		Run(func(args mock.Arguments) { payload = args.Get(3).(json.RawMessage) }).
+		Return(model.Event{Id: uuid.New()}, nil).Once()
+
+	err := receptionService.CloseReception(context.Background(), pvzID)
+
+	require.NoError(t, err)
+	// Payload - внешний контракт: поля в camelCase, пустые необязательные поля опущены
+	assert.JSONEq(t, `{
+		"id": "9b2f6c1e-0d4a-4c6b-8f3e-1a2b3c4d5e6f",
+		"dateTime": "2024-05-01T10:00:00Z",
+		"pvzId": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"status": "close",
+		"closedAt": "2024-05-01T12:00:00Z"
+	}`, string(payload))
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestAddProduct_EventPayload(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockOutbox := new(mocks.MockOutboxRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{
+		Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo, Outbox: mockOutbox,
+	}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
+
+	pvzID := uuid.MustParse("3fa85f64-5717-4562-b3fc-2c963f66afa6")
+	receptionID := uuid.MustParse("9b2f6c1e-0d4a-4c6b-8f3e-1a2b3c4d5e6f")
+	barcode, weight := "4600000000017", 1200
+	created := model.Product{
+		Id:          uuid.MustParse("1c0e7a52-8d3b-4f7e-9a61-5b2d4e8f0a13"),
+		DateTime:    time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+		Type:        "обувь",
+		ReceptionId: receptionID,
+		Barcode:     &barcode,
+		Weight:      &weight,
+		Attributes:  json.RawMessage(`{"size":42}`),
+	}
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(created, nil)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	var payload json.RawMessage
+	mockOutbox.On("CreateOutboxEvent", mock.Anything, model.EventProductAdded, pvzID, mock.Anything).
+		Run(func(args mock.Arguments) { payload = args.Get(3).(json.RawMessage) }).
+		Return(model.Event{Id: uuid.New()}, nil).Once()
+
+	_, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: "обувь"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "1c0e7a52-8d3b-4f7e-9a61-5b2d4e8f0a13",
+		"dateTime": "2024-05-01T10:30:00Z",
+		"type": "обувь",
+		"receptionId": "9b2f6c1e-0d4a-4c6b-8f3e-1a2b3c4d5e6f",
+		"barcode": "4600000000017",
+		"weight": 1200,
+		"attributes": {"size": 42}
+	}`, string(payload))
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestAddProducts_WritesEventPerProduct(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockOutbox := new(mocks.MockOutboxRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{
+		Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo, Outbox: mockOutbox,
+	}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
+
+	pvzID, receptionID := uuid.New(), uuid.New()
+	created := []model.Product{{Id: uuid.New(), Type: "обувь"}, {Id: uuid.New(), Type: "одежда"}}
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProducts", mock.Anything, receptionID, mock.Anything).Return(created, nil)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	var productIds []uuid.UUID
+	mockOutbox.On("CreateOutboxEvent", mock.Anything, model.EventProductAdded, pvzID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			var product model.ProductPayload
+			require.NoError(t, json.Unmarshal(args.Get(3).(json.RawMessage), &product))
+			productIds = append(productIds, product.Id)
+		}).
+		Return(model.Event{Id: uuid.New()}, nil).Twice()
+
+	_, err := productService.AddProducts(context.Background(), pvzID, []model.Product{{Type: "обувь"}, {Type: "одежда"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{created[0].Id, created[1].Id}, productIds)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestCreateReception_EventErrorRollsBack(t *testing.T) {
+	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockOutbox := new(mocks.MockOutboxRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo, Outbox: mockOutbox}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID := uuid.New()
+	eventErr := errors.New("outbox unavailable")
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
+	mockRepo.On("CreateReception", mock.Anything, pvzID).Return(model.Reception{Id: uuid.New(), PvzId: pvzID}, nil)
+	mockOutbox.On("CreateOutboxEvent", mock.Anything, model.EventReceptionOpened, pvzID, mock.Anything).Return(model.Event{}, eventErr)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err := receptionService.CreateReception(context.Background(), pvzID)
+
+	assert.ErrorIs(t, err, eventErr)
+}
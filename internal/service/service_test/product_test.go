@@ -3,23 +3,38 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/apperror"
+	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
 )
 
+func newProductService(uow *mocks.MockUnitOfWork, catalog service.Catalog, log *mocks.MockLogger) *service.ProductService {
+	allowAudit(uow.Repos)
+	allowOutbox(uow.Repos)
+	repos := *uow.Repos
+	repos.UnitOfWork = uow
+	return service.NewProductService(&repos, catalog, newHub(), log)
+}
+
 func TestAddProduct_Success(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
@@ -31,18 +46,20 @@ func TestAddProduct_Success(t *testing.T) {
 		DateTime:    time.Now(),
 	}
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
 	mockProductRepo.On("CreateProduct", mock.Anything, mock.AnythingOfType("model.Product")).Return(expectedProduct, nil)
 	mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", productType)
 	mockLogger.On("Infow", "Product created successfully", "productId", expectedProduct.Id, "receptionId", receptionID)
 
 	// Act
-	result, err := productService.AddProduct(context.Background(), pvzID, productType)
+	result, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: productType})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProduct, result)
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -51,25 +68,29 @@ func TestAddProduct_NoOpenReception(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
 	productType := "package"
 	expectedError := errors.New("no reception found")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, expectedError)
 	mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", productType)
 	mockLogger.On("Warnw", "Cannot add product, no open reception", "pvzId", pvzID, "error", expectedError)
 
 	// Act
-	result, err := productService.AddProduct(context.Background(), pvzID, productType)
+	result, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: productType})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, model.Product{}, result)
 	assert.Contains(t, err.Error(), "no open reception for pvz")
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -78,27 +99,31 @@ func TestAddProduct_CreateProductError(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	productType := "package"
 	expectedError := errors.New("create error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
 	mockProductRepo.On("CreateProduct", mock.Anything, mock.AnythingOfType("model.Product")).Return(model.Product{}, expectedError)
 	mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", productType)
 	mockLogger.On("Errorw", "Failed to create product", "product", mock.Anything, "error", expectedError)
 
 	// Act
-	result, err := productService.AddProduct(context.Background(), pvzID, productType)
+	result, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: productType})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, model.Product{}, result)
 	assert.Contains(t, err.Error(), "failed to create product")
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -107,25 +132,32 @@ func TestDeleteLastProduct_Success(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 	receptionID := uuid.New()
 	lastProductID := uuid.New()
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
 	mockProductRepo.On("GetLastProductIdByReception", mock.Anything, receptionID).Return(lastProductID, nil)
-	mockProductRepo.On("DeleteProductById", mock.Anything, lastProductID).Return(nil)
+	mockProductRepo.On("GetProductById", mock.Anything, lastProductID).
+		Return(model.ProductWithPvz{Product: model.Product{Id: lastProductID, ReceptionId: receptionID}, PvzId: pvzID}, nil)
+	mockProductRepo.On("DeleteProductById", mock.Anything, lastProductID, userID).Return(nil)
 	mockLogger.On("Infow", "Attempting to delete last product", "pvzId", pvzID)
 	mockLogger.On("Infow", "Product deleted successfully", "productId", lastProductID, "receptionId", receptionID)
 
 	// Act
-	err := productService.DeleteLastProduct(context.Background(), pvzID)
+	err := productService.DeleteLastProduct(context.Background(), pvzID, userID)
 
 	// Assert
 	assert.NoError(t, err)
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -134,22 +166,27 @@ func TestDeleteLastProduct_NoActiveReception(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
 	mockLogger.On("Infow", "Attempting to delete last product", "pvzId", pvzID)
 	mockLogger.On("Warnw", "No active reception found", "pvzId", pvzID)
 
 	// Act
-	err := productService.DeleteLastProduct(context.Background(), pvzID)
+	err := productService.DeleteLastProduct(context.Background(), pvzID, userID)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no active reception found")
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -158,23 +195,28 @@ func TestDeleteLastProduct_ReceptionLookupError(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 	expectedError := errors.New("lookup error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, expectedError)
 	mockLogger.On("Infow", "Attempting to delete last product", "pvzId", pvzID)
 	mockLogger.On("Errorw", "Failed to get in-progress reception", "pvzId", pvzID, "error", expectedError)
 
 	// Act
-	err := productService.DeleteLastProduct(context.Background(), pvzID)
+	err := productService.DeleteLastProduct(context.Background(), pvzID, userID)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "reception lookup failed")
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -183,24 +225,29 @@ func TestDeleteLastProduct_NoProductsFound(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 	receptionID := uuid.New()
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
 	mockProductRepo.On("GetLastProductIdByReception", mock.Anything, receptionID).Return(uuid.Nil, nil)
 	mockLogger.On("Infow", "Attempting to delete last product", "pvzId", pvzID)
 	mockLogger.On("Warnw", "No products found in current reception", "receptionId", receptionID)
 
 	// Act
-	err := productService.DeleteLastProduct(context.Background(), pvzID)
+	err := productService.DeleteLastProduct(context.Background(), pvzID, userID)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no products found for current reception")
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
@@ -209,27 +256,428 @@ func TestDeleteLastProduct_DeleteError(t *testing.T) {
 	// Arrange
 	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
 
 	pvzID := uuid.New()
+	userID := uuid.New()
 	receptionID := uuid.New()
 	lastProductID := uuid.New()
 	expectedError := errors.New("delete error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
 	mockProductRepo.On("GetLastProductIdByReception", mock.Anything, receptionID).Return(lastProductID, nil)
-	mockProductRepo.On("DeleteProductById", mock.Anything, lastProductID).Return(expectedError)
+	mockProductRepo.On("GetProductById", mock.Anything, lastProductID).
+		Return(model.ProductWithPvz{Product: model.Product{Id: lastProductID, ReceptionId: receptionID}, PvzId: pvzID}, nil)
+	mockProductRepo.On("DeleteProductById", mock.Anything, lastProductID, userID).Return(expectedError)
 	mockLogger.On("Infow", "Attempting to delete last product", "pvzId", pvzID)
 	mockLogger.On("Errorw", "Failed to delete product", "productId", lastProductID, "error", expectedError)
 
 	// Act
-	err := productService.DeleteLastProduct(context.Background(), pvzID)
+	err := productService.DeleteLastProduct(context.Background(), pvzID, userID)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to delete last product")
 	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddProduct_ReceptionNotOpen(t *testing.T) {
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	productService := newProductService(uow, allowAllCatalog(t), mockLogger)
+
+	pvzID := uuid.New()
+	productType := "обувь"
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
+	mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", productType)
+	mockLogger.On("Warnw", "Cannot add product, no open reception", "pvzId", pvzID)
+
+	result, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: productType})
+
+	assert.Error(t, err)
+	assert.Equal(t, model.Product{}, result)
+	assert.Contains(t, err.Error(), "no open reception")
+	mockProductRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	mockReceptionRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddProduct_DisabledType(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Pvz: mockPvzRepo}}
+	catalog := mocks.NewMockCatalog(gomock.NewController(t))
+	productService := newProductService(uow, catalog, mockLogger)
+
+	pvzID := uuid.New()
+	productType := "мебель"
+	validationErr := apperror.Validation("product_type %q is disabled", productType)
+
+	catalog.EXPECT().Validate(gomock.Any(), model.CatalogProductType, productType).Return(validationErr)
+	mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", productType)
+	mockLogger.On("Warnw", "Invalid product type", "type", productType, "error", validationErr)
+
+	_, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: productType})
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	mockPvzRepo.AssertNotCalled(t, "LockPvz", mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
+func newBatchService(t *testing.T, catalog service.Catalog) (*service.ProductService, *mocks.MockPvzRepository, *mocks.MockReceptionRepository, *mocks.MockProductRepository, *mocks.MockLogger) {
+	t.Helper()
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Product: mockProductRepo, Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	return newProductService(uow, catalog, mockLogger), mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger
+}
+
+func TestAddProducts_Success(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	clientID := uuid.New()
+	products := []model.Product{{Type: "обувь"}, {Id: clientID, Type: "одежда"}}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProducts", mock.Anything, receptionID, mock.MatchedBy(func(batch []model.Product) bool {
+		return len(batch) == 2 && batch[0].Id != uuid.Nil && batch[1].Id == clientID
+	})).Return([]model.Product{
+		{Id: uuid.New(), Type: "обувь", ReceptionId: receptionID, DateTime: time.Now()},
+		{Id: clientID, Type: "одежда", ReceptionId: receptionID, DateTime: time.Now()},
+	}, nil)
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 2)
+	mockLogger.On("Infow", "Product batch created successfully", "pvzId", pvzID, "count", 2)
+
+	result, err := productService.AddProducts(context.Background(), pvzID, products)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, clientID, result[1].Id)
+	mockProductRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddProducts_InvalidItems(t *testing.T) {
+	catalog := mocks.NewMockCatalog(gomock.NewController(t))
+	productService, mockPvzRepo, _, _, mockLogger := newBatchService(t, catalog)
+
+	pvzID := uuid.New()
+	duplicate := uuid.New()
+	products := []model.Product{
+		{Id: duplicate, Type: "обувь"},
+		{Type: "мебель"},
+		{Id: duplicate, Type: "одежда"},
+	}
+
+	catalog.EXPECT().Validate(gomock.Any(), model.CatalogProductType, "мебель").Return(apperror.Validation("unknown product_type %q", "мебель"))
+	catalog.EXPECT().Validate(gomock.Any(), model.CatalogProductType, gomock.Any()).Return(nil).Times(2)
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 3)
+	mockLogger.On("Warnw", "Invalid product batch", "pvzId", pvzID, "error", mock.Anything)
+
+	_, err := productService.AddProducts(context.Background(), pvzID, products)
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []apperror.ItemError{
+		{Index: 1, Message: `unknown product_type "мебель"`},
+		{Index: 2, Message: "duplicate id " + duplicate.String() + ", already used by item 0"},
+	}, appErr.Items())
+	mockPvzRepo.AssertNotCalled(t, "LockPvz", mock.Anything, mock.Anything)
+}
+
+func TestAddProducts_BatchSize(t *testing.T) {
+	productService, _, _, _, mockLogger := newBatchService(t, allowAllCatalog(t))
+	pvzID := uuid.New()
+
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 0)
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 501)
+
+	_, err := productService.AddProducts(context.Background(), pvzID, nil)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+
+	_, err = productService.AddProducts(context.Background(), pvzID, make([]model.Product, 501))
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+}
+
+func TestAddProducts_ExistingIds(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	fresh, existing := uuid.New(), uuid.New()
+	products := []model.Product{{Id: fresh, Type: "обувь"}, {Id: existing, Type: "обувь"}}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProducts", mock.Anything, receptionID, mock.Anything).
+		Return([]model.Product{{Id: fresh, Type: "обувь", ReceptionId: receptionID}}, nil)
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 2)
+
+	result, err := productService.AddProducts(context.Background(), pvzID, products)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []apperror.ItemError{{Index: 1, Message: "product " + existing.String() + " already exists"}}, appErr.Items())
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddProducts_NoOpenReception(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	pvzID := uuid.New()
+	products := []model.Product{{Type: "обувь"}}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 1)
+	mockLogger.On("Warnw", "Cannot add products, no open reception", "pvzId", pvzID)
+
+	_, err := productService.AddProducts(context.Background(), pvzID, products)
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	mockProductRepo.AssertNotCalled(t, "CreateProducts", mock.Anything, mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddProduct_InvalidDetails(t *testing.T) {
+	long := strings.Repeat("1", 65)
+	blank := "  "
+	zero := 0
+
+	tests := []struct {
+		name    string
+		product model.Product
+	}{
+		{name: "long barcode", product: model.Product{Type: "обувь", Barcode: &long}},
+		{name: "long order id", product: model.Product{Type: "обувь", OrderId: &long}},
+		{name: "zero weight", product: model.Product{Type: "обувь", Barcode: &blank, Weight: &zero}},
+		{name: "attributes array", product: model.Product{Type: "обувь", Attributes: []byte(`[1, 2]`)}},
+		{name: "malformed attributes", product: model.Product{Type: "обувь", Attributes: []byte(`{"size":`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productService, mockPvzRepo, _, _, mockLogger := newBatchService(t, allowAllCatalog(t))
+			pvzID := uuid.New()
+
+			mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", "обувь")
+			mockLogger.On("Warnw", "Invalid product", "pvzId", pvzID, "error", mock.Anything)
+
+			_, err := productService.AddProduct(context.Background(), pvzID, tt.product)
+
+			assert.ErrorIs(t, err, apperror.ErrValidation)
+			mockPvzRepo.AssertNotCalled(t, "LockPvz", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAddProduct_NormalizesDetails(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	barcode, orderId := " 4600000000017 ", " "
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProduct", mock.Anything, mock.MatchedBy(func(p model.Product) bool {
+		return p.Barcode != nil && *p.Barcode == "4600000000017" && p.OrderId == nil && p.Attributes == nil
+	})).Return(model.Product{Id: uuid.New(), ReceptionId: receptionID}, nil)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	_, err := productService.AddProduct(context.Background(), pvzID, model.Product{
+		Type:       "обувь",
+		Barcode:    &barcode,
+		OrderId:    &orderId,
+		Attributes: []byte("null"),
+	})
+
+	assert.NoError(t, err)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestAddProduct_DuplicateBarcode(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	barcode := "4600000000017"
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockProductRepo.On("CreateProduct", mock.Anything, mock.Anything).
+		Return(model.Product{}, fmt.Errorf("barcode %q: %w", barcode, repository.ErrAlreadyExists))
+	mockLogger.On("Infow", "Adding product", "pvzId", pvzID, "type", "обувь")
+	mockLogger.On("Warnw", "Duplicate barcode in reception", "receptionId", receptionID, "barcode", barcode)
+
+	_, err := productService.AddProduct(context.Background(), pvzID, model.Product{Type: "обувь", Barcode: &barcode})
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestAddProducts_DuplicateBarcodes(t *testing.T) {
+	productService, mockPvzRepo, _, _, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	pvzID := uuid.New()
+	barcode := "4600000000017"
+	weight := -1
+	products := []model.Product{
+		{Type: "обувь", Barcode: &barcode},
+		{Type: "обувь", Weight: &weight},
+		{Type: "одежда", Barcode: &barcode},
+	}
+
+	mockLogger.On("Infow", "Adding product batch", "pvzId", pvzID, "count", 3)
+	mockLogger.On("Warnw", "Invalid product batch", "pvzId", pvzID, "error", mock.Anything)
+
+	_, err := productService.AddProducts(context.Background(), pvzID, products)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []apperror.ItemError{
+		{Index: 1, Message: "weight must be positive"},
+		{Index: 2, Message: `duplicate barcode "4600000000017", already used by item 0`},
+	}, appErr.Items())
+	mockPvzRepo.AssertNotCalled(t, "LockPvz", mock.Anything, mock.Anything)
+}
+
+func TestFindProductsByBarcode(t *testing.T) {
+	productService, _, _, mockProductRepo, _ := newBatchService(t, allowAllCatalog(t))
+
+	barcode := "4600000000017"
+	expected := []model.ProductWithPvz{{Product: model.Product{Id: uuid.New(), Barcode: &barcode}, PvzId: uuid.New()}}
+	mockProductRepo.On("GetProductsByBarcode", mock.Anything, barcode).Return(expected, nil)
+
+	result, err := productService.FindProductsByBarcode(context.Background(), " "+barcode+" ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = productService.FindProductsByBarcode(context.Background(), " ")
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	mockProductRepo.AssertNumberOfCalls(t, "GetProductsByBarcode", 1)
+}
+
+func TestDeleteProduct_Success(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	productID, receptionID, pvzID, userID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mockProductRepo.On("GetProductById", mock.Anything, productID).
+		Return(model.ProductWithPvz{Product: model.Product{Id: productID, ReceptionId: receptionID}, PvzId: pvzID}, nil)
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusInProgress}, nil)
+	mockProductRepo.On("DeleteProductById", mock.Anything, productID, userID).Return(nil)
+	mockLogger.On("Infow", "Attempting to delete product", "productId", productID, "userId", userID)
+	mockLogger.On("Infow", "Product deleted successfully", "productId", productID, "userId", userID)
+
+	err := productService.DeleteProduct(context.Background(), productID, userID)
+
+	assert.NoError(t, err)
+	mockPvzRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestDeleteProduct_ClosedReception(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	productID, receptionID, pvzID, userID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mockProductRepo.On("GetProductById", mock.Anything, productID).
+		Return(model.ProductWithPvz{Product: model.Product{Id: productID, ReceptionId: receptionID}, PvzId: pvzID}, nil)
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusClose}, nil)
+	mockLogger.On("Infow", "Attempting to delete product", "productId", productID, "userId", userID)
+	mockLogger.On("Warnw", "Product reception is closed", "productId", productID, "receptionId", receptionID)
+
+	err := productService.DeleteProduct(context.Background(), productID, userID)
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	mockProductRepo.AssertNotCalled(t, "DeleteProductById", mock.Anything, mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestDeleteProduct_NotFound(t *testing.T) {
+	productService, mockPvzRepo, _, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	productID, userID := uuid.New(), uuid.New()
+
+	mockProductRepo.On("GetProductById", mock.Anything, productID).
+		Return(model.ProductWithPvz{}, fmt.Errorf("product %s: %w", productID, repository.ErrNotFound))
+	mockLogger.On("Infow", "Attempting to delete product", "productId", productID, "userId", userID)
+
+	err := productService.DeleteProduct(context.Background(), productID, userID)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	mockPvzRepo.AssertNotCalled(t, "LockPvz", mock.Anything, mock.Anything)
+}
+
+func TestUpdateProduct_Success(t *testing.T) {
+	productService, mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger := newBatchService(t, allowAllCatalog(t))
+
+	productID, receptionID, pvzID, userID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	productType := "одежда"
+	update := model.ProductUpdate{Type: &productType}
+	expected := model.Product{Id: productID, Type: productType, ReceptionId: receptionID}
+
+	mockProductRepo.On("GetProductById", mock.Anything, productID).
+		Return(model.ProductWithPvz{Product: model.Product{Id: productID, Type: "обувь", ReceptionId: receptionID}, PvzId: pvzID}, nil)
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusInProgress}, nil)
+	mockProductRepo.On("UpdateProduct", mock.Anything, productID, update, userID).Return(expected, nil)
+	mockLogger.On("Infow", "Product updated successfully", "productId", productID, "userId", userID)
+
+	result, err := productService.UpdateProduct(context.Background(), productID, update, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockProductRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestUpdateProduct_Invalid(t *testing.T) {
+	catalog := mocks.NewMockCatalog(gomock.NewController(t))
+	productService, mockPvzRepo, _, mockProductRepo, mockLogger := newBatchService(t, catalog)
+
+	productID, userID := uuid.New(), uuid.New()
+
+	_, err := productService.UpdateProduct(context.Background(), productID, model.ProductUpdate{}, userID)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+
+	productType := "мебель"
+	validationErr := apperror.Validation("product_type %q is disabled", productType)
+	catalog.EXPECT().Validate(gomock.Any(), model.CatalogProductType, productType).Return(validationErr)
+	mockLogger.On("Warnw", "Invalid product type", "type", productType, "error", validationErr)
+
+	_, err = productService.UpdateProduct(context.Background(), productID, model.ProductUpdate{Type: &productType}, userID)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+
+	mockProductRepo.AssertNotCalled(t, "GetProductById", mock.Anything, mock.Anything)
+	mockPvzRepo.AssertNotCalled(t, "LockPvz", mock.Anything, mock.Anything)
+}
@@ -9,16 +9,35 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+	"pvz/internal/apperror"
+	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
 )
 
+// newPvzService собирает PvzService поверх моков; транзакции выполняются без БД
+func newPvzService(t *testing.T, repoPvz *mocks.MockPvzRepository, repoReception *mocks.MockReceptionRepository, log *mocks.MockLogger) *service.PvzService {
+	repos := &repository.Repository{Pvz: repoPvz, Reception: repoReception}
+	allowAudit(repos)
+	allowOutbox(repos)
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	return service.NewPvzService(repos, allowAllCatalog(t), log)
+}
+
+// allowAllCatalog принимает любые значения справочников
+func allowAllCatalog(t *testing.T) *mocks.MockCatalog {
+	catalog := mocks.NewMockCatalog(gomock.NewController(t))
+	catalog.EXPECT().Validate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return catalog
+}
+
 func TestCreatePvz_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	pvzService := service.NewPvzService(mockRepo, nil, nil, mockLogger)
+	pvzService := newPvzService(t, mockRepo, new(mocks.MockReceptionRepository), mockLogger)
 
 	expectedPvz := model.Pvz{
 		Id:               uuid.New(),
@@ -44,7 +63,7 @@ func TestCreatePvz_Error(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	pvzService := service.NewPvzService(mockRepo, nil, nil, mockLogger)
+	pvzService := newPvzService(t, mockRepo, new(mocks.MockReceptionRepository), mockLogger)
 
 	testPvz := model.Pvz{
 		City: "Moscow", // Make sure this matches the mock expectation
@@ -66,13 +85,29 @@ func TestCreatePvz_Error(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestCreatePvz_UnknownCity(t *testing.T) {
+	mockRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Pvz: mockRepo}
+	catalog := mocks.NewMockCatalog(gomock.NewController(t))
+	pvzService := service.NewPvzService(repos, catalog, mockLogger)
+
+	validationErr := apperror.Validation("unknown city %q", "Omsk")
+	catalog.EXPECT().Validate(gomock.Any(), model.CatalogCity, "Omsk").Return(validationErr)
+	mockLogger.On("Warnw", "Invalid city", "city", "Omsk", "error", validationErr)
+
+	_, err := pvzService.CreatePvz(context.Background(), model.Pvz{City: "Omsk"})
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	mockRepo.AssertNotCalled(t, "CreatePvz", mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
 func TestGetPvzList_Success(t *testing.T) {
 	// Arrange
 	mockPvzRepo := new(mocks.MockPvzRepository)
-	mockReceptionRepo := new(mocks.MockReceptionRepository)
-	mockProductRepo := new(mocks.MockProductRepository)
 	mockLogger := new(mocks.MockLogger)
-	pvzService := service.NewPvzService(mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
 
 	limit := 10
 	offset := 0
@@ -82,185 +117,310 @@ func TestGetPvzList_Success(t *testing.T) {
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 
-	pvzList := []model.Pvz{
-		{
-			Id:               pvzID,
-			City:             "Moscow",
-			RegistrationDate: time.Now(),
-		},
-	}
-
-	receptions := []model.Reception{
+	pvzList := []model.PvzWithReceptions{
 		{
-			Id:       receptionID,
-			DateTime: time.Now(),
-			PvzId:    pvzID,
-			Status:   "received",
+			Pvz: model.Pvz{
+				Id:               pvzID,
+				City:             "Moscow",
+				RegistrationDate: time.Now(),
+			},
+			Receptions: []model.ReceptionWithProducts{
+				{
+					Reception: model.Reception{
+						Id:       receptionID,
+						DateTime: time.Now(),
+						PvzId:    pvzID,
+						Status:   "received",
+					},
+					Products: []model.Product{
+						{
+							Id:          uuid.New(),
+							DateTime:    time.Now(),
+							Type:        "package",
+							ReceptionId: receptionID,
+						},
+					},
+				},
+			},
 		},
 	}
 
-	products := []model.Product{
-		{
-			Id:          uuid.New(),
-			DateTime:    time.Now(),
-			Type:        "package",
-			ReceptionId: receptionID,
-		},
-	}
-
-	mockPvzRepo.On("GetPvzListByReceptionDate", mock.Anything, limit, offset, &startDate, &endDate).Return(pvzList, nil)
-	mockReceptionRepo.On("GetReceptionsByPvzID", mock.Anything, pvzID).Return(receptions, nil)
-	mockProductRepo.On("GetProductsByReceptionID", mock.Anything, receptionID).Return(products, nil)
+	mockPvzRepo.On("GetPvzListWithReceptions", mock.Anything, limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate}).Return(pvzList, nil)
 
 	// Logger expectations
 	mockLogger.On("Infow", "Getting Pvz list by reception date",
-		"limit", limit, "offset", offset, "startDate", &startDate, "endDate", &endDate)
-	mockLogger.On("Infow", "Processing Pvz", "pvzId", pvzID)
-	mockLogger.On("Infow", "Processing Reception", "receptionId", receptionID)
-	mockLogger.On("Infow", "Completed processing Pvz", "pvzId", pvzID)
+		"limit", limit, "offset", offset, "startDate", &startDate, "endDate", &endDate, "status", "")
 	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 1)
 
 	// Act
-	result, err := pvzService.GetPvzList(context.Background(), limit, offset, &startDate, &endDate)
+	result, err := pvzService.GetPvzList(context.Background(), limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
 	assert.Equal(t, pvzID.String(), result[0].Pvz.Id)
 	assert.Len(t, result[0].Receptions, 1)
+	assert.Equal(t, receptionID.String(), result[0].Receptions[0].Reception.Id)
 	assert.Len(t, result[0].Receptions[0].Products, 1)
+	assert.Equal(t, "package", result[0].Receptions[0].Products[0].Type)
 
 	mockPvzRepo.AssertExpectations(t)
-	mockReceptionRepo.AssertExpectations(t)
-	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
-func TestGetPvzList_ErrorGettingPvzList(t *testing.T) {
+func TestGetPvzList_PvzWithoutReceptions(t *testing.T) {
 	// Arrange
 	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	pvzService := service.NewPvzService(mockPvzRepo, nil, nil, mockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
 
 	limit := 10
 	offset := 0
-	startDate := time.Now().Add(-24 * time.Hour)
-	endDate := time.Now()
-	expectedError := errors.New("database error")
 
-	mockPvzRepo.On("GetPvzListByReceptionDate", mock.Anything, limit, offset, &startDate, &endDate).Return(nil, expectedError)
+	pvzList := []model.PvzWithReceptions{
+		{Pvz: model.Pvz{Id: uuid.New(), City: "Kazan", RegistrationDate: time.Now()}},
+	}
+
+	mockPvzRepo.On("GetPvzListWithReceptions", mock.Anything, limit, offset, model.PvzFilter{}).Return(pvzList, nil)
 	mockLogger.On("Infow", "Getting Pvz list by reception date",
-		"limit", limit, "offset", offset, "startDate", &startDate, "endDate", &endDate)
-	mockLogger.On("Errorw", "Failed to get Pvz list", "error", expectedError)
+		"limit", limit, "offset", offset, "startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", "")
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 1)
 
 	// Act
-	result, err := pvzService.GetPvzList(context.Background(), limit, offset, &startDate, &endDate)
+	result, err := pvzService.GetPvzList(context.Background(), limit, offset, model.PvzFilter{})
 
 	// Assert
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Equal(t, expectedError, err)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Nil(t, result[0].Receptions)
 	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
-func TestGetPvzList_ErrorGettingReceptions(t *testing.T) {
+func TestGetPvzList_ErrorGettingPvzList(t *testing.T) {
 	// Arrange
 	mockPvzRepo := new(mocks.MockPvzRepository)
-	mockReceptionRepo := new(mocks.MockReceptionRepository)
 	mockLogger := new(mocks.MockLogger)
-	pvzService := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil, mockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
 
 	limit := 10
 	offset := 0
 	startDate := time.Now().Add(-24 * time.Hour)
 	endDate := time.Now()
-	expectedError := errors.New("reception error")
-
-	pvzID := uuid.New()
-	pvzList := []model.Pvz{
-		{
-			Id:               pvzID,
-			City:             "Moscow",
-			RegistrationDate: time.Now(),
-		},
-	}
-
-	mockPvzRepo.On("GetPvzListByReceptionDate", mock.Anything, limit, offset, &startDate, &endDate).Return(pvzList, nil)
-	mockReceptionRepo.On("GetReceptionsByPvzID", mock.Anything, pvzID).Return(nil, expectedError)
+	expectedError := errors.New("database error")
 
-	// Logger expectations
+	mockPvzRepo.On("GetPvzListWithReceptions", mock.Anything, limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate}).Return(nil, expectedError)
 	mockLogger.On("Infow", "Getting Pvz list by reception date",
-		"limit", limit, "offset", offset, "startDate", &startDate, "endDate", &endDate)
-	mockLogger.On("Infow", "Processing Pvz", "pvzId", pvzID)
-	mockLogger.On("Errorw", "Failed to get receptions for Pvz", "pvzId", pvzID, "error", expectedError)
+		"limit", limit, "offset", offset, "startDate", &startDate, "endDate", &endDate, "status", "")
+	mockLogger.On("Errorw", "Failed to get Pvz list", "error", expectedError)
 
 	// Act
-	result, err := pvzService.GetPvzList(context.Background(), limit, offset, &startDate, &endDate)
+	result, err := pvzService.GetPvzList(context.Background(), limit, offset, model.PvzFilter{StartDate: &startDate, EndDate: &endDate})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, expectedError, err)
 	mockPvzRepo.AssertExpectations(t)
-	mockReceptionRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
-func TestGetPvzList_ErrorGettingProducts(t *testing.T) {
-	// Arrange
+func TestGetPvzList_LimitCapped(t *testing.T) {
 	mockPvzRepo := new(mocks.MockPvzRepository)
-	mockReceptionRepo := new(mocks.MockReceptionRepository)
-	mockProductRepo := new(mocks.MockProductRepository)
 	mockLogger := new(mocks.MockLogger)
-	pvzService := service.NewPvzService(mockPvzRepo, mockReceptionRepo, mockProductRepo, mockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
 
-	limit := 10
-	offset := 0
-	startDate := time.Now().Add(-24 * time.Hour)
-	endDate := time.Now()
-	expectedError := errors.New("product error")
+	mockPvzRepo.On("GetPvzListWithReceptions", mock.Anything, 30, 0, model.PvzFilter{}).Return([]model.PvzWithReceptions(nil), nil)
+	mockLogger.On("Infow", "Getting Pvz list by reception date",
+		"limit", 30, "offset", 0, "startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", "")
+	mockLogger.On("Infow", "Successfully retrieved Pvz list", "count", 0)
 
-	pvzID := uuid.New()
-	receptionID := uuid.New()
+	_, err := pvzService.GetPvzList(context.Background(), 1000, 0, model.PvzFilter{})
 
-	pvzList := []model.Pvz{
-		{
-			Id:               pvzID,
-			City:             "Moscow",
-			RegistrationDate: time.Now(),
-		},
+	assert.NoError(t, err)
+	mockPvzRepo.AssertExpectations(t)
+}
+
+func TestGetPvzPage_Cursor(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
+	mockLogger.On("Infow", "Getting Pvz page", "limit", 2, "cursor", mock.Anything,
+		"startDate", (*time.Time)(nil), "endDate", (*time.Time)(nil), "status", model.PvzStatusActive)
+	mockLogger.On("Infow", "Successfully retrieved Pvz page", "count", mock.Anything, "hasNext", mock.Anything)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	pvzList := make([]model.PvzWithReceptions, 3)
+	for i := range pvzList {
+		pvzList[i] = model.PvzWithReceptions{Pvz: model.Pvz{Id: uuid.New(), RegistrationDate: now.Add(-time.Duration(i) * time.Hour)}}
 	}
 
-	receptions := []model.Reception{
-		{
-			Id:       receptionID,
-			DateTime: time.Now(),
-			PvzId:    pvzID,
-			Status:   "received",
-		},
+	// Первая страница: репозиторий отдаёт на один ПВЗ больше, чем limit
+	mockPvzRepo.On("GetPvzListWithReceptions", mock.Anything, 3, 0, model.PvzFilter{Status: model.PvzStatusActive}).Return(pvzList, nil).Once()
+
+	first, err := pvzService.GetPvzPage(context.Background(), 2, "", model.PvzFilter{Status: model.PvzStatusActive})
+
+	assert.NoError(t, err)
+	assert.Len(t, first.Items, 2)
+	if assert.NotNil(t, first.NextCursor) {
+		assert.NotContains(t, *first.NextCursor, pvzList[1].Pvz.Id.String())
 	}
 
-	mockPvzRepo.On("GetPvzListByReceptionDate", mock.Anything, limit, offset, &startDate, &endDate).Return(pvzList, nil)
-	mockReceptionRepo.On("GetReceptionsByPvzID", mock.Anything, pvzID).Return(receptions, nil)
-	mockProductRepo.On("GetProductsByReceptionID", mock.Anything, receptionID).Return(nil, expectedError)
+	// Курсор указывает на последний ПВЗ первой страницы
+	after := &model.PvzCursor{RegistrationDate: pvzList[1].Pvz.RegistrationDate, Id: pvzList[1].Pvz.Id}
+	mockPvzRepo.On("GetPvzListWithReceptions", mock.Anything, 3, 0, model.PvzFilter{Status: model.PvzStatusActive, After: after}).Return(pvzList[2:], nil).Once()
 
-	// Logger expectations
-	mockLogger.On("Infow", "Getting Pvz list by reception date",
-		"limit", limit, "offset", offset, "startDate", &startDate, "endDate", &endDate)
-	mockLogger.On("Infow", "Processing Pvz", "pvzId", pvzID)
-	mockLogger.On("Infow", "Processing Reception", "receptionId", receptionID)
-	mockLogger.On("Errorw", "Failed to get products for Reception", "receptionId", receptionID, "error", expectedError)
+	second, err := pvzService.GetPvzPage(context.Background(), 2, *first.NextCursor, model.PvzFilter{Status: model.PvzStatusActive})
 
-	// Act
-	result, err := pvzService.GetPvzList(context.Background(), limit, offset, &startDate, &endDate)
+	assert.NoError(t, err)
+	assert.Len(t, second.Items, 1)
+	assert.Equal(t, pvzList[2].Pvz.Id.String(), second.Items[0].Pvz.Id)
+	assert.Nil(t, second.NextCursor)
+	mockPvzRepo.AssertExpectations(t)
+}
 
-	// Assert
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Equal(t, expectedError, err)
+func TestGetPvzPage_InvalidCursor(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
+	mockLogger.On("Warnw", "Invalid cursor", "cursor", mock.Anything, "error", mock.Anything)
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := pvzService.GetPvzPage(context.Background(), 10, cursor, model.PvzFilter{})
+		assert.ErrorIs(t, err, apperror.ErrValidation, cursor)
+	}
+	mockPvzRepo.AssertNotCalled(t, "GetPvzListWithReceptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdatePvz_NothingToUpdate(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), new(mocks.MockLogger))
+
+	_, err := pvzService.UpdatePvz(context.Background(), uuid.New(), model.PvzUpdate{})
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	mockPvzRepo.AssertNotCalled(t, "UpdatePvz", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdatePvz_NotFound(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
+
+	pvzID := uuid.New()
+	name := "ПВЗ на Тверской"
+	update := model.PvzUpdate{Name: &name}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	mockPvzRepo.On("UpdatePvz", mock.Anything, pvzID, update).Return(model.Pvz{}, repository.ErrNotFound)
+	mockLogger.On("Errorw", "Failed to update PVZ", "pvzId", pvzID, "error", mock.Anything)
+
+	_, err := pvzService.UpdatePvz(context.Background(), pvzID, update)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	mockPvzRepo.AssertExpectations(t)
+}
+
+func TestDeactivatePvz_Success(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockLogger := new(mocks.MockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, mockReceptionRepo, mockLogger)
+
+	pvzID := uuid.New()
+	deactivatedAt := time.Now()
+	deactivated := model.Pvz{Id: pvzID, Status: model.PvzStatusInactive, DeactivatedAt: &deactivatedAt}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
+	mockPvzRepo.On("SetPvzStatus", mock.Anything, pvzID, model.PvzStatusInactive).Return(deactivated, nil)
+	mockLogger.On("Infow", "PVZ deactivated", "pvzId", pvzID)
+
+	result, err := pvzService.DeactivatePvz(context.Background(), pvzID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, deactivated, result)
 	mockPvzRepo.AssertExpectations(t)
 	mockReceptionRepo.AssertExpectations(t)
-	mockProductRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestDeactivatePvz_ReceptionInProgress(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockLogger := new(mocks.MockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, mockReceptionRepo, mockLogger)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockLogger.On("Warnw", "Cannot deactivate PVZ with reception in progress", "pvzId", pvzID, "receptionId", receptionID)
+	mockLogger.On("Errorw", "Failed to deactivate PVZ", "pvzId", pvzID, "error", mock.Anything)
+
+	_, err := pvzService.DeactivatePvz(context.Background(), pvzID)
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	mockPvzRepo.AssertNotCalled(t, "SetPvzStatus", mock.Anything, mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestDeactivatePvz_AlreadyInactive(t *testing.T) {
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	pvzService := newPvzService(t, mockPvzRepo, new(mocks.MockReceptionRepository), mockLogger)
+
+	pvzID := uuid.New()
+	inactive := model.Pvz{Id: pvzID, Status: model.PvzStatusInactive}
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(inactive, nil)
+	mockLogger.On("Infow", "PVZ deactivated", "pvzId", pvzID)
+
+	result, err := pvzService.DeactivatePvz(context.Background(), pvzID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, inactive, result)
+	mockPvzRepo.AssertNotCalled(t, "SetPvzStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeletePvz(t *testing.T) {
+	tests := []struct {
+		name          string
+		lockErr       error
+		hasReceptions bool
+		expectedErr   error
+	}{
+		{name: "success"},
+		{name: "not found", lockErr: repository.ErrNotFound, expectedErr: apperror.ErrNotFound},
+		{name: "has receptions", hasReceptions: true, expectedErr: apperror.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPvzRepo := new(mocks.MockPvzRepository)
+			mockReceptionRepo := new(mocks.MockReceptionRepository)
+			mockLogger := new(mocks.MockLogger)
+			pvzService := newPvzService(t, mockPvzRepo, mockReceptionRepo, mockLogger)
+
+			pvzID := uuid.New()
+
+			mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, tt.lockErr)
+			mockReceptionRepo.On("HasReceptions", mock.Anything, pvzID).Return(tt.hasReceptions, nil).Maybe()
+			mockPvzRepo.On("DeletePvz", mock.Anything, pvzID).Return(nil).Maybe()
+			mockLogger.On("Warnw", "Cannot delete PVZ with receptions", "pvzId", pvzID).Maybe()
+			mockLogger.On("Errorw", "Failed to delete PVZ", "pvzId", pvzID, "error", mock.Anything).Maybe()
+			mockLogger.On("Infow", "PVZ deleted", "pvzId", pvzID).Maybe()
+
+			err := pvzService.DeletePvz(context.Background(), pvzID)
+
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				mockPvzRepo.AssertCalled(t, "DeletePvz", mock.Anything, pvzID)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+			mockPvzRepo.AssertNotCalled(t, "DeletePvz", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -9,16 +9,28 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"pvz/internal/apperror"
+	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
 )
 
+func newReceptionService(uow *mocks.MockUnitOfWork, log *mocks.MockLogger) *service.ReceptionService {
+	allowAudit(uow.Repos)
+	allowOutbox(uow.Repos)
+	repos := *uow.Repos
+	repos.UnitOfWork = uow
+	return service.NewReceptionService(&repos, newHub(), time.Hour, log)
+}
+
 func TestCreateReception_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	expectedReception := model.Reception{
@@ -28,6 +40,7 @@ func TestCreateReception_Success(t *testing.T) {
 		Status:   "in_progress",
 	}
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
 	mockRepo.On("CreateReception", mock.Anything, pvzID).Return(expectedReception, nil)
 	mockLogger.On("Infow", "Checking for existing in-progress reception", "pvzId", pvzID)
@@ -41,18 +54,22 @@ func TestCreateReception_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedReception, result)
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCreateReception_ExistingReception(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	existingReceptionID := uuid.New()
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(existingReceptionID, nil)
 	mockLogger.On("Infow", "Checking for existing in-progress reception", "pvzId", pvzID)
 	mockLogger.On("Warnw", "Reception already in progress for PVZ", "pvzId", pvzID)
@@ -63,20 +80,44 @@ func TestCreateReception_ExistingReception(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, model.Reception{}, result)
+	assert.ErrorIs(t, err, apperror.ErrConflict)
 	assert.Contains(t, err.Error(), "an in-progress reception already exists")
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestCreateReception_InactivePvz(t *testing.T) {
+	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID := uuid.New()
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusInactive}, nil)
+	mockLogger.On("Warnw", "Reception rejected for inactive PVZ", "pvzId", pvzID)
+
+	_, err := receptionService.CreateReception(context.Background(), pvzID)
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	mockRepo.AssertNotCalled(t, "CreateReception", mock.Anything, mock.Anything)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCreateReception_GetInProgressError(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	expectedError := errors.New("database error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, expectedError)
 	mockLogger.On("Infow", "Checking for existing in-progress reception", "pvzId", pvzID)
 
@@ -88,18 +129,22 @@ func TestCreateReception_GetInProgressError(t *testing.T) {
 	assert.Equal(t, model.Reception{}, result)
 	assert.Equal(t, expectedError, err)
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCreateReception_CreateError(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	expectedError := errors.New("create error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
 	mockRepo.On("CreateReception", mock.Anything, pvzID).Return(model.Reception{}, expectedError)
 	mockLogger.On("Infow", "Checking for existing in-progress reception", "pvzId", pvzID)
@@ -114,20 +159,28 @@ func TestCreateReception_CreateError(t *testing.T) {
 	assert.Equal(t, model.Reception{}, result)
 	assert.Equal(t, expectedError, err)
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCloseReception_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
-	mockRepo.On("CloseReception", mock.Anything, pvzID).Return(nil)
+	mockRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusInProgress}, nil)
+	mockRepo.On("UpdateReceptionStatus", mock.Anything, mock.MatchedBy(func(change model.ReceptionStatusChange) bool {
+		return change.ReceptionId == receptionID && change.From == model.ReceptionStatusInProgress && change.To == model.ReceptionStatusClose
+	})).Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusClose}, nil)
 	mockLogger.On("Infow", "Attempting to close reception", "pvzId", pvzID)
 	mockLogger.On("Infow", "Reception closed successfully", "pvzId", pvzID)
 
@@ -137,18 +190,22 @@ func TestCloseReception_Success(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCloseReception_GetInProgressError(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	expectedError := errors.New("database error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, expectedError)
 	mockLogger.On("Infow", "Attempting to close reception", "pvzId", pvzID)
 	mockLogger.On("Errorw", "Failed to get in-progress reception", "pvzId", pvzID, "error", expectedError)
@@ -160,17 +217,21 @@ func TestCloseReception_GetInProgressError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "reception lookup failed")
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCloseReception_NoActiveReception(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil)
 	mockLogger.On("Infow", "Attempting to close reception", "pvzId", pvzID)
 	mockLogger.On("Warnw", "No active reception found", "pvzId", pvzID)
@@ -180,32 +241,159 @@ func TestCloseReception_NoActiveReception(t *testing.T) {
 
 	// Assert
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, apperror.ErrConflict)
 	assert.Contains(t, err.Error(), "no active reception found")
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
 func TestCloseReception_CloseError(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
 	mockLogger := new(mocks.MockLogger)
-	receptionService := service.NewReceptionService(mockRepo, mockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	expectedError := errors.New("close error")
 
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
 	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
-	mockRepo.On("CloseReception", mock.Anything, pvzID).Return(expectedError)
+	mockRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusInProgress}, nil)
+	mockRepo.On("UpdateReceptionStatus", mock.Anything, mock.Anything).Return(model.Reception{}, expectedError)
 	mockLogger.On("Infow", "Attempting to close reception", "pvzId", pvzID)
-	mockLogger.On("Errorw", "Failed to close reception", "pvzId", pvzID, "error", expectedError)
+	mockLogger.On("Errorw", "Failed to change reception status", "receptionId", receptionID, "transition", "close", "error", expectedError)
 
 	// Act
 	err := receptionService.CloseReception(context.Background(), pvzID)
 
 	// Assert
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to close reception")
+	assert.ErrorIs(t, err, expectedError)
+	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestCreateReception_PvzNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID := uuid.New()
+
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{}, repository.ErrNotFound)
+	mockLogger.On("Errorw", "Failed to lock PVZ", "pvzId", pvzID, "error", mock.Anything)
+
+	// Act
+	result, err := receptionService.CreateReception(context.Background(), pvzID)
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.Equal(t, model.Reception{}, result)
+	mockRepo.AssertNotCalled(t, "GetInProgressReception", mock.Anything, pvzID)
 	mockRepo.AssertExpectations(t)
+	mockPvzRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestGetReception_Success(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockReceptionRepo, Product: mockProductRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	reception := model.Reception{Id: uuid.New(), PvzId: uuid.New(), Status: model.ReceptionStatusClose}
+	products := []model.Product{{Id: uuid.New(), Type: "обувь", ReceptionId: reception.Id}}
+
+	mockReceptionRepo.On("GetReceptionById", mock.Anything, reception.Id).Return(reception, nil)
+	mockProductRepo.On("GetProductsByReceptionID", mock.Anything, reception.Id).Return(products, nil)
+
+	result, err := receptionService.GetReception(context.Background(), reception.Id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReceptionWithProducts{Reception: reception, Products: products}, result)
+	mockReceptionRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestGetReception_NotFound(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockReceptionRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	receptionId := uuid.New()
+	mockReceptionRepo.On("GetReceptionById", mock.Anything, receptionId).Return(model.Reception{}, repository.ErrNotFound)
+
+	_, err := receptionService.GetReception(context.Background(), receptionId)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestGetReceptionList(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockReceptionRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID := uuid.New()
+	filter := model.ReceptionFilter{Status: model.ReceptionStatusClose}
+	receptions := []model.Reception{{Id: uuid.New(), PvzId: pvzID, Status: model.ReceptionStatusClose}}
+
+	mockPvzRepo.On("GetPvzById", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	// limit урезается до максимального размера страницы
+	mockReceptionRepo.On("GetReceptionList", mock.Anything, pvzID, 30, 5, filter).Return(receptions, nil)
+
+	result, err := receptionService.GetReceptionList(context.Background(), pvzID, 100, 5, filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, receptions, result)
+
+	unknown := uuid.New()
+	mockPvzRepo.On("GetPvzById", mock.Anything, unknown).Return(model.Pvz{}, repository.ErrNotFound)
+
+	_, err = receptionService.GetReceptionList(context.Background(), unknown, 10, 0, filter)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	mockReceptionRepo.AssertNotCalled(t, "GetReceptionList", mock.Anything, unknown, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetCurrentReception(t *testing.T) {
+	mockReceptionRepo := new(mocks.MockReceptionRepository)
+	mockProductRepo := new(mocks.MockProductRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{Reception: mockReceptionRepo, Product: mockProductRepo, Pvz: mockPvzRepo}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID := uuid.New()
+	reception := model.Reception{Id: uuid.New(), PvzId: pvzID, Status: model.ReceptionStatusInProgress}
+
+	mockPvzRepo.On("GetPvzById", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID}, nil)
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(reception.Id, nil).Once()
+	mockReceptionRepo.On("GetReceptionById", mock.Anything, reception.Id).Return(reception, nil)
+	mockProductRepo.On("GetProductsByReceptionID", mock.Anything, reception.Id).Return([]model.Product{}, nil)
+
+	result, err := receptionService.GetCurrentReception(context.Background(), pvzID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reception, result.Reception)
+
+	// Приёмка закрыта - текущей нет
+	mockReceptionRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil).Once()
+
+	_, err = receptionService.GetCurrentReception(context.Background(), pvzID)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	mockReceptionRepo.AssertExpectations(t)
+}
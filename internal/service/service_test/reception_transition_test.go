@@ -0,0 +1,210 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/apperror"
+	"pvz/internal/live"
+	"pvz/internal/middleware/jwt"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+type transitionFixture struct {
+	service   *service.ReceptionService
+	reception *mocks.MockReceptionRepository
+	pvz       *mocks.MockPvzRepository
+	audit     *mocks.MockAuditRepository
+	hub       *live.Hub
+}
+
+func newTransitionFixture() transitionFixture {
+	f := transitionFixture{
+		reception: new(mocks.MockReceptionRepository),
+		pvz:       new(mocks.MockPvzRepository),
+		audit:     new(mocks.MockAuditRepository),
+		hub:       newHub(),
+	}
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Warnw", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+	repos := &repository.Repository{Reception: f.reception, Pvz: f.pvz, Audit: f.audit}
+	allowOutbox(repos)
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	f.service = service.NewReceptionService(repos, f.hub, time.Hour, mockLogger)
+	return f
+}
+
+func moderatorContext(userId uuid.UUID) context.Context {
+	return jwt.ContextWithClaims(context.Background(), &model.TokenClaims{UserId: userId, Role: model.RoleModerator, Unscoped: true})
+}
+
+func TestReceptionTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		before model.Reception
+		to     string
+		audit  string
+		event  string
+		call   func(s *service.ReceptionService, ctx context.Context, id uuid.UUID) (model.Reception, error)
+	}{
+		{
+			name:   "close",
+			before: model.Reception{Status: model.ReceptionStatusInProgress},
+			to:     model.ReceptionStatusClose, audit: model.AuditReceptionClose, event: model.EventReceptionClosed,
+			call: (*service.ReceptionService).CloseReceptionById,
+		},
+		{
+			name:   "verify",
+			before: model.Reception{Status: model.ReceptionStatusClose},
+			to:     model.ReceptionStatusVerified, audit: model.AuditReceptionVerify, event: model.EventReceptionVerified,
+			call: (*service.ReceptionService).VerifyReception,
+		},
+		{
+			name:   "cancel",
+			before: model.Reception{Status: model.ReceptionStatusInProgress},
+			to:     model.ReceptionStatusCancelled, audit: model.AuditReceptionCancel, event: model.EventReceptionCancelled,
+			call: (*service.ReceptionService).CancelReception,
+		},
+		{
+			name:   "reopen",
+			before: model.Reception{Status: model.ReceptionStatusClose},
+			to:     model.ReceptionStatusInProgress, audit: model.AuditReceptionReopen, event: model.EventReceptionReopened,
+			call: (*service.ReceptionService).ReopenReception,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransitionFixture()
+			userID, pvzID := uuid.New(), uuid.New()
+			before := tt.before
+			before.Id, before.PvzId = uuid.New(), pvzID
+			after := before
+			after.Status = tt.to
+
+			f.reception.On("GetReceptionById", mock.Anything, before.Id).Return(before, nil)
+			f.pvz.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+			f.reception.On("GetInProgressReception", mock.Anything, pvzID).Return(uuid.Nil, nil).Maybe()
+			f.reception.On("ReceptionClosedWithin", mock.Anything, before.Id, time.Hour).Return(true, nil).Maybe()
+			f.reception.On("UpdateReceptionStatus", mock.Anything, mock.MatchedBy(func(change model.ReceptionStatusChange) bool {
+				return change.ReceptionId == before.Id && change.From == before.Status && change.To == tt.to &&
+					change.By != nil && *change.By == userID
+			})).Return(after, nil)
+			f.audit.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry model.AuditEntry) bool {
+				return entry.Action == tt.audit && entry.EntityId == before.Id.String()
+			})).Return(nil)
+
+			sub := f.hub.Subscribe(pvzID, "")
+			defer sub.Close()
+
+			result, err := tt.call(f.service, moderatorContext(userID), before.Id)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, result.Status)
+			assert.Equal(t, tt.event, receive(t, sub).Event.Type)
+			f.reception.AssertExpectations(t)
+			f.audit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionTransitions_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		call   func(s *service.ReceptionService, ctx context.Context, id uuid.UUID) (model.Reception, error)
+	}{
+		{name: "close closed", status: model.ReceptionStatusClose, call: (*service.ReceptionService).CloseReceptionById},
+		{name: "close cancelled", status: model.ReceptionStatusCancelled, call: (*service.ReceptionService).CloseReceptionById},
+		{name: "verify in progress", status: model.ReceptionStatusInProgress, call: (*service.ReceptionService).VerifyReception},
+		{name: "verify verified", status: model.ReceptionStatusVerified, call: (*service.ReceptionService).VerifyReception},
+		{name: "cancel closed", status: model.ReceptionStatusClose, call: (*service.ReceptionService).CancelReception},
+		{name: "reopen verified", status: model.ReceptionStatusVerified, call: (*service.ReceptionService).ReopenReception},
+		{name: "reopen cancelled", status: model.ReceptionStatusCancelled, call: (*service.ReceptionService).ReopenReception},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransitionFixture()
+			pvzID := uuid.New()
+			reception := model.Reception{Id: uuid.New(), PvzId: pvzID, Status: tt.status}
+
+			f.reception.On("GetReceptionById", mock.Anything, reception.Id).Return(reception, nil)
+			f.pvz.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+
+			_, err := tt.call(f.service, moderatorContext(uuid.New()), reception.Id)
+
+			assert.ErrorIs(t, err, apperror.ErrConflict)
+			f.reception.AssertNotCalled(t, "UpdateReceptionStatus", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReopenReception_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		within     bool
+		pvzStatus  string
+		inProgress uuid.UUID
+		message    string
+	}{
+		// Окно по часам базы истекло или closedAt неизвестен
+		{name: "window expired", pvzStatus: model.PvzStatusActive, message: "reopen window"},
+		{name: "inactive pvz", within: true, pvzStatus: model.PvzStatusInactive, message: "is deactivated"},
+		{name: "another reception open", within: true, pvzStatus: model.PvzStatusActive, inProgress: uuid.New(), message: "already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransitionFixture()
+			pvzID := uuid.New()
+			reception := model.Reception{Id: uuid.New(), PvzId: pvzID, Status: model.ReceptionStatusClose}
+
+			f.reception.On("GetReceptionById", mock.Anything, reception.Id).Return(reception, nil)
+			f.pvz.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: tt.pvzStatus}, nil)
+			f.reception.On("ReceptionClosedWithin", mock.Anything, reception.Id, time.Hour).Return(tt.within, nil)
+			f.reception.On("GetInProgressReception", mock.Anything, pvzID).Return(tt.inProgress, nil).Maybe()
+
+			_, err := f.service.ReopenReception(moderatorContext(uuid.New()), reception.Id)
+
+			assert.ErrorIs(t, err, apperror.ErrConflict)
+			assert.Contains(t, err.Error(), tt.message)
+			f.reception.AssertNotCalled(t, "UpdateReceptionStatus", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReceptionTransition_NotFound(t *testing.T) {
+	f := newTransitionFixture()
+	receptionID := uuid.New()
+	f.reception.On("GetReceptionById", mock.Anything, receptionID).Return(model.Reception{}, repository.ErrNotFound)
+
+	_, err := f.service.CancelReception(context.Background(), receptionID)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestReceptionTransition_ChangedConcurrently(t *testing.T) {
+	f := newTransitionFixture()
+	pvzID := uuid.New()
+	reception := model.Reception{Id: uuid.New(), PvzId: pvzID, Status: model.ReceptionStatusInProgress}
+
+	f.reception.On("GetReceptionById", mock.Anything, reception.Id).Return(reception, nil)
+	f.pvz.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	f.reception.On("UpdateReceptionStatus", mock.Anything, mock.Anything).Return(model.Reception{}, repository.ErrNotFound)
+
+	_, err := f.service.CancelReception(context.Background(), reception.Id)
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	f.audit.AssertNotCalled(t, "CreateAuditEntry", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/live"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+const staleReason = "no activity for 12h0m0s"
+
+func newStaleReceptionService(repos *repository.Repository, action string, hub *live.Hub, log *mocks.MockLogger) *service.StaleReceptionService {
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	return service.NewStaleReceptionService(repos, service.StaleReceptionConfig{
+		IdleFor:   12 * time.Hour,
+		Action:    action,
+		BatchSize: 10,
+	}, hub, log)
+}
+
+func staleCandidate(pvzId uuid.UUID) model.StaleReception {
+	return model.StaleReception{
+		Reception:      model.Reception{Id: uuid.New(), PvzId: pvzId, Status: model.ReceptionStatusInProgress},
+		LastActivityAt: time.Now().Add(-20 * time.Hour),
+	}
+}
+
+func TestProcessStaleReceptions_Close(t *testing.T) {
+	receptionRepo := new(mocks.MockReceptionRepository)
+	pvzRepo := new(mocks.MockPvzRepository)
+	auditRepo := new(mocks.MockAuditRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Reception: receptionRepo, Pvz: pvzRepo, Audit: auditRepo}
+	allowOutbox(repos)
+	hub := newHub()
+	staleService := newStaleReceptionService(repos, model.StaleActionClose, hub, mockLogger)
+
+	pvzId := uuid.New()
+	candidate := staleCandidate(pvzId)
+	now := time.Now()
+	reason := staleReason
+	closed := candidate.Reception
+	closed.Status = model.ReceptionStatusClose
+	closed.StaleAt = &now
+	closed.StaleReason = &reason
+
+	receptionRepo.On("TryLockStaleReceptions", mock.Anything).Return(true, nil)
+	receptionRepo.On("GetStaleReceptions", mock.Anything, 12*time.Hour, 10).Return([]model.StaleReception{candidate}, nil)
+	pvzRepo.On("LockPvz", mock.Anything, pvzId).Return(model.Pvz{Id: pvzId}, nil)
+	receptionRepo.On("MarkReceptionStale", mock.Anything, candidate.Id, 12*time.Hour, staleReason, true).Return(closed, nil)
+	auditRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry model.AuditEntry) bool {
+		return entry.Action == model.AuditReceptionAutoClose && entry.EntityId == candidate.Id.String() && entry.ActorId == nil
+	})).Return(nil)
+	mockLogger.On("Warnw", "Stale reception closed", "receptionId", candidate.Id, "pvzId", pvzId,
+		"lastActivityAt", candidate.LastActivityAt, "reason", staleReason).Once()
+
+	sub := hub.Subscribe(pvzId, "")
+	defer sub.Close()
+
+	require.NoError(t, staleService.ProcessStaleReceptions(context.Background()))
+
+	// Автоматическое закрытие видно получателям событий так же, как ручное
+	msg := receive(t, sub)
+	assert.Equal(t, model.EventReceptionClosed, msg.Event.Type)
+	repos.Outbox.(*mocks.MockOutboxRepository).AssertCalled(t, "CreateOutboxEvent", mock.Anything, model.EventReceptionClosed, pvzId, mock.Anything)
+	receptionRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestProcessStaleReceptions_Flag(t *testing.T) {
+	receptionRepo := new(mocks.MockReceptionRepository)
+	pvzRepo := new(mocks.MockPvzRepository)
+	auditRepo := new(mocks.MockAuditRepository)
+	outboxRepo := new(mocks.MockOutboxRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Reception: receptionRepo, Pvz: pvzRepo, Audit: auditRepo, Outbox: outboxRepo}
+	hub := newHub()
+	staleService := newStaleReceptionService(repos, model.StaleActionFlag, hub, mockLogger)
+
+	pvzId := uuid.New()
+	candidate := staleCandidate(pvzId)
+	flagged := candidate.Reception
+	reason := staleReason
+	flagged.StaleReason = &reason
+
+	receptionRepo.On("TryLockStaleReceptions", mock.Anything).Return(true, nil)
+	receptionRepo.On("GetStaleReceptions", mock.Anything, 12*time.Hour, 10).Return([]model.StaleReception{candidate}, nil)
+	pvzRepo.On("LockPvz", mock.Anything, pvzId).Return(model.Pvz{Id: pvzId}, nil)
+	receptionRepo.On("MarkReceptionStale", mock.Anything, candidate.Id, 12*time.Hour, staleReason, false).Return(flagged, nil)
+	auditRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry model.AuditEntry) bool {
+		return entry.Action == model.AuditReceptionFlagStale
+	})).Return(nil)
+	mockLogger.On("Warnw", "Stale reception flagged", "receptionId", candidate.Id, "pvzId", pvzId,
+		"lastActivityAt", candidate.LastActivityAt, "reason", staleReason).Once()
+
+	sub := hub.Subscribe(pvzId, "")
+	defer sub.Close()
+
+	require.NoError(t, staleService.ProcessStaleReceptions(context.Background()))
+
+	// Приёмка остаётся открытой, доменного события нет
+	outboxRepo.AssertNotCalled(t, "CreateOutboxEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assertNoMessage(t, sub)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestProcessStaleReceptions_ActivityResumed(t *testing.T) {
+	receptionRepo := new(mocks.MockReceptionRepository)
+	pvzRepo := new(mocks.MockPvzRepository)
+	auditRepo := new(mocks.MockAuditRepository)
+	repos := &repository.Repository{Reception: receptionRepo, Pvz: pvzRepo, Audit: auditRepo}
+	staleService := newStaleReceptionService(repos, model.StaleActionClose, newHub(), new(mocks.MockLogger))
+
+	pvzId := uuid.New()
+	candidate := staleCandidate(pvzId)
+
+	receptionRepo.On("TryLockStaleReceptions", mock.Anything).Return(true, nil)
+	receptionRepo.On("GetStaleReceptions", mock.Anything, 12*time.Hour, 10).Return([]model.StaleReception{candidate}, nil)
+	pvzRepo.On("LockPvz", mock.Anything, pvzId).Return(model.Pvz{Id: pvzId}, nil)
+	receptionRepo.On("MarkReceptionStale", mock.Anything, candidate.Id, 12*time.Hour, staleReason, true).
+		Return(model.Reception{}, repository.ErrNotFound)
+
+	require.NoError(t, staleService.ProcessStaleReceptions(context.Background()))
+
+	auditRepo.AssertNotCalled(t, "CreateAuditEntry", mock.Anything, mock.Anything)
+}
+
+func TestProcessStaleReceptions_LockedByAnotherReplica(t *testing.T) {
+	receptionRepo := new(mocks.MockReceptionRepository)
+	repos := &repository.Repository{Reception: receptionRepo}
+	staleService := newStaleReceptionService(repos, model.StaleActionClose, newHub(), new(mocks.MockLogger))
+
+	receptionRepo.On("TryLockStaleReceptions", mock.Anything).Return(false, nil)
+
+	require.NoError(t, staleService.ProcessStaleReceptions(context.Background()))
+
+	receptionRepo.AssertNotCalled(t, "GetStaleReceptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessStaleReceptions_Error(t *testing.T) {
+	receptionRepo := new(mocks.MockReceptionRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Reception: receptionRepo}
+	staleService := newStaleReceptionService(repos, model.StaleActionClose, newHub(), mockLogger)
+
+	dbErr := errors.New("db down")
+	receptionRepo.On("TryLockStaleReceptions", mock.Anything).Return(true, nil)
+	receptionRepo.On("GetStaleReceptions", mock.Anything, 12*time.Hour, 10).Return(nil, dbErr)
+	mockLogger.On("Errorw", "Failed to process stale receptions", "action", model.StaleActionClose, "error", dbErr).Once()
+
+	err := staleService.ProcessStaleReceptions(context.Background())
+
+	assert.ErrorIs(t, err, dbErr)
+	mockLogger.AssertExpectations(t)
+}
@@ -0,0 +1,260 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvz/internal/apperror"
+	"pvz/internal/rbac"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/mocks"
+)
+
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRefreshTokens_Success(t *testing.T) {
+	mockRepo := new(mocks.MockUserPostgres)
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	mockLogger := new(mocks.MockLogger)
+	svc := newUserService(mockRepo, mockTokenRepo, mockLogger)
+
+	user := model.User{Id: uuid.New(), Email: "test@example.com", Role: "employee"}
+	current := model.RefreshToken{
+		Id:        uuid.New(),
+		UserId:    user.Id,
+		FamilyId:  uuid.New(),
+		TokenHash: hash("old-refresh"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	var next model.RefreshToken
+	mockTokenRepo.On("GetRefreshTokenForUpdate", mock.Anything, current.TokenHash).Return(current, nil).Once()
+	mockRepo.On("GetUserById", mock.Anything, user.Id).Return(user, nil).Once()
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(token model.RefreshToken) bool {
+		next = token
+		return token.FamilyId == current.FamilyId && token.UserId == user.Id
+	})).Return(nil).Once()
+	mockTokenRepo.On("RevokeRefreshToken", mock.Anything, current.Id, mock.MatchedBy(func(replacedBy *uuid.UUID) bool {
+		return replacedBy != nil && *replacedBy == next.Id
+	})).Return(nil).Once()
+	mockLogger.On("Infow", "Tokens refreshed").Once()
+
+	pair, err := svc.RefreshTokens(context.Background(), "old-refresh")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEqual(t, "old-refresh", pair.RefreshToken)
+	assert.Equal(t, hash(pair.RefreshToken), next.TokenHash)
+
+	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestRefreshTokens_ReuseRevokesFamily(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	mockLogger := new(mocks.MockLogger)
+	svc := newUserService(new(mocks.MockUserPostgres), mockTokenRepo, mockLogger)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	current := model.RefreshToken{
+		Id:        uuid.New(),
+		UserId:    uuid.New(),
+		FamilyId:  uuid.New(),
+		TokenHash: hash("rotated-refresh"),
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}
+
+	mockTokenRepo.On("GetRefreshTokenForUpdate", mock.Anything, current.TokenHash).Return(current, nil).Once()
+	mockTokenRepo.On("RevokeTokenFamily", mock.Anything, current.FamilyId).Return(nil).Once()
+	mockLogger.On("Warnw", "Refresh token reuse detected, token family revoked",
+		"userID", current.UserId, "familyId", current.FamilyId).Once()
+
+	pair, err := svc.RefreshTokens(context.Background(), "rotated-refresh")
+
+	assert.ErrorIs(t, err, apperror.ErrUnauthorized)
+	assert.Empty(t, pair)
+	mockTokenRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestRefreshTokens_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   model.RefreshToken
+		err     error
+		message string
+	}{
+		{
+			name:    "unknown token",
+			err:     repository.ErrNotFound,
+			message: "invalid refresh token",
+		},
+		{
+			name:    "expired token",
+			token:   model.RefreshToken{Id: uuid.New(), ExpiresAt: time.Now().Add(-time.Second)},
+			message: "refresh token expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenRepo := new(mocks.MockTokenRepository)
+			mockLogger := new(mocks.MockLogger)
+			svc := newUserService(new(mocks.MockUserPostgres), mockTokenRepo, mockLogger)
+
+			mockTokenRepo.On("GetRefreshTokenForUpdate", mock.Anything, hash("refresh")).Return(tt.token, tt.err).Once()
+			mockLogger.On("Warnw", "Failed to refresh tokens", "error", mock.Anything).Once()
+
+			_, err := svc.RefreshTokens(context.Background(), "refresh")
+
+			assert.ErrorIs(t, err, apperror.ErrUnauthorized)
+			assert.EqualError(t, err, tt.message)
+			mockTokenRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogout_RevokesAccessTokenAndFamily(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	mockLogger := new(mocks.MockLogger)
+	revocations := service.NewRevocationCache(mockTokenRepo, mockLogger)
+
+	repos := &repository.Repository{Token: mockTokenRepo}
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	svc := service.NewUserService(repos, revocations, testTokens, rbac.Default(), mockLogger)
+
+	claims := &model.TokenClaims{UserId: uuid.New()}
+	claims.Id = uuid.NewString()
+	claims.ExpiresAt = time.Now().Add(time.Minute).Unix()
+	familyId := uuid.New()
+
+	mockTokenRepo.On("RevokeAccessToken", mock.Anything, claims.Id, time.Unix(claims.ExpiresAt, 0)).Return(nil).Once()
+	mockTokenRepo.On("GetRefreshTokenForUpdate", mock.Anything, hash("refresh")).
+		Return(model.RefreshToken{UserId: claims.UserId, FamilyId: familyId}, nil).Once()
+	mockTokenRepo.On("RevokeTokenFamily", mock.Anything, familyId).Return(nil).Once()
+	mockLogger.On("Infow", "Access token revoked", "jti", claims.Id).Once()
+	mockLogger.On("Infow", "User logged out", "userID", claims.UserId).Once()
+
+	err := svc.Logout(context.Background(), claims, "refresh")
+	assert.NoError(t, err)
+
+	// Отзыв сразу виден через кэш, без обращения к БД
+	revoked, err := revocations.IsRevoked(context.Background(), claims.Id)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	mockTokenRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestDeleteExpiredTokens(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Token: mockTokenRepo}
+	svc := service.NewUserService(repos, service.NewRevocationCache(mockTokenRepo, mockLogger), testTokens, rbac.Default(), mockLogger)
+
+	var before time.Time
+	mockTokenRepo.On("DeleteExpiredRefreshTokens", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { before = args.Get(1).(time.Time) }).
+		Return(int64(2), nil).Once()
+	mockTokenRepo.On("DeleteExpiredRevokedTokens", mock.Anything, mock.MatchedBy(func(t time.Time) bool { return t.Equal(before) })).
+		Return(int64(0), nil).Once()
+	mockLogger.On("Infow", "Expired tokens deleted", "refresh", int64(2), "revoked", int64(0)).Once()
+
+	assert.NoError(t, svc.DeleteExpiredTokens(context.Background()))
+
+	assert.WithinDuration(t, time.Now(), before, time.Second)
+	mockTokenRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestDeleteExpiredTokens_Error(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	mockLogger := new(mocks.MockLogger)
+	repos := &repository.Repository{Token: mockTokenRepo}
+	svc := service.NewUserService(repos, service.NewRevocationCache(mockTokenRepo, mockLogger), testTokens, rbac.Default(), mockLogger)
+
+	dbErr := errors.New("db down")
+	mockTokenRepo.On("DeleteExpiredRefreshTokens", mock.Anything, mock.Anything).Return(int64(0), dbErr).Once()
+
+	err := svc.DeleteExpiredTokens(context.Background())
+
+	assert.ErrorIs(t, err, dbErr)
+	mockTokenRepo.AssertNotCalled(t, "DeleteExpiredRevokedTokens", mock.Anything, mock.Anything)
+}
+
+func TestLogout_ForeignRefreshToken(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	mockLogger := new(mocks.MockLogger)
+	svc := newUserService(new(mocks.MockUserPostgres), mockTokenRepo, mockLogger)
+
+	claims := &model.TokenClaims{UserId: uuid.New()}
+	claims.Id = uuid.NewString()
+
+	mockTokenRepo.On("RevokeAccessToken", mock.Anything, claims.Id, mock.Anything).Return(nil).Once()
+	mockTokenRepo.On("GetRefreshTokenForUpdate", mock.Anything, hash("refresh")).
+		Return(model.RefreshToken{UserId: uuid.New(), FamilyId: uuid.New()}, nil).Once()
+	mockLogger.On("Infow", "Access token revoked", "jti", claims.Id).Once()
+	mockLogger.On("Warnw", "Failed to revoke refresh token on logout", "userID", claims.UserId, "error", mock.Anything).Once()
+
+	err := svc.Logout(context.Background(), claims, "refresh")
+
+	assert.ErrorIs(t, err, apperror.ErrUnauthorized)
+	mockTokenRepo.AssertNotCalled(t, "RevokeTokenFamily", mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestRevocationCache_CachesLookups(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	cache := service.NewRevocationCache(mockTokenRepo, new(mocks.MockLogger))
+
+	active, revoked := uuid.NewString(), uuid.NewString()
+	mockTokenRepo.On("IsAccessTokenRevoked", mock.Anything, active).Return(false, nil).Once()
+	mockTokenRepo.On("IsAccessTokenRevoked", mock.Anything, revoked).Return(true, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		isRevoked, err := cache.IsRevoked(context.Background(), active)
+		assert.NoError(t, err)
+		assert.False(t, isRevoked)
+
+		isRevoked, err = cache.IsRevoked(context.Background(), revoked)
+		assert.NoError(t, err)
+		assert.True(t, isRevoked)
+	}
+
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestRevocationCache_RepositoryError(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	cache := service.NewRevocationCache(mockTokenRepo, new(mocks.MockLogger))
+
+	jti := uuid.NewString()
+	expectedErr := errors.New("db error")
+	mockTokenRepo.On("IsAccessTokenRevoked", mock.Anything, jti).Return(false, expectedErr).Once()
+	mockTokenRepo.On("IsAccessTokenRevoked", mock.Anything, jti).Return(false, nil).Once()
+
+	_, err := cache.IsRevoked(context.Background(), jti)
+	assert.ErrorIs(t, err, expectedErr)
+
+	// Ошибка не кэшируется
+	isRevoked, err := cache.IsRevoked(context.Background(), jti)
+	assert.NoError(t, err)
+	assert.False(t, isRevoked)
+
+	mockTokenRepo.AssertExpectations(t)
+}
@@ -3,27 +3,47 @@ package service_test
 import (
 	"context"
 	"errors"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
+	"pvz/internal/apperror"
+	"pvz/internal/jwtkeys"
+	"pvz/internal/rbac"
+	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 	"pvz/internal/service"
 	"pvz/mocks"
 )
 
+var testKeys = jwtkeys.NewHMAC([]byte("test-signing-key"))
+
+var testTokens = service.TokenConfig{
+	Signer:     testKeys,
+	AccessTTL:  time.Minute,
+	RefreshTTL: time.Hour,
+}
+
+// newUserService собирает UserService поверх моков; транзакции выполняются без БД
+func newUserService(repoUser *mocks.MockUserPostgres, repoToken *mocks.MockTokenRepository, log *mocks.MockLogger) *service.UserService {
+	repos := &repository.Repository{User: repoUser, Token: repoToken}
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	return service.NewUserService(repos, service.NewRevocationCache(repoToken, log), testTokens, rbac.Default(), log)
+}
+
 func TestCreateUser_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockUserPostgres)
 	mockLogger := new(mocks.MockLogger)
 
-	service := service.NewUserService(mockRepo, mockLogger)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
 	testUser := model.User{
 		Email:    "test@example.com",
+		Role:     model.RoleEmployee,
 		Password: "password123",
 	}
 	expectedID := uuid.New()
@@ -55,12 +75,13 @@ func TestCreateUser_PasswordHashingError(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockUserPostgres)
 	mockLogger := new(mocks.MockLogger)
-	service := service.NewUserService(mockRepo, mockLogger)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
 
 	// Пароль, который вызовет ошибку хэширования (слишком длинный)
 	invalidPassword := string(make([]byte, 100))
 	testUser := model.User{
 		Email:    "test@example.com",
+		Role:     model.RoleEmployee,
 		Password: invalidPassword,
 	}
 
@@ -82,10 +103,11 @@ func TestCreateUser_PasswordHashingError(t *testing.T) {
 func TestCreateUser_RepositoryError(t *testing.T) {
 	mockRepo := new(mocks.MockUserPostgres)
 	mockLogger := new(mocks.MockLogger)
-	service := service.NewUserService(mockRepo, mockLogger)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
 
 	testUser := model.User{
 		Email:    "test@example.com",
+		Role:     model.RoleEmployee,
 		Password: "password123",
 	}
 	expectedError := errors.New("repository error")
@@ -104,11 +126,72 @@ func TestCreateUser_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateUser_UnknownRole(t *testing.T) {
+	mockRepo := new(mocks.MockUserPostgres)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), new(mocks.MockLogger))
+
+	_, err := service.CreateUser(context.Background(), model.User{
+		Email:    "test@example.com",
+		Role:     "superuser",
+		Password: "password123",
+	})
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestCreateUser_PrivilegedRole(t *testing.T) {
+	for _, role := range []string{model.RoleAdmin, model.RoleAuditor} {
+		t.Run(role, func(t *testing.T) {
+			mockRepo := new(mocks.MockUserPostgres)
+			mockLogger := new(mocks.MockLogger)
+			mockLogger.On("Warnw", "Self-service role rejected", "role", role).Once()
+			service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
+
+			_, err := service.CreateUser(context.Background(), model.User{
+				Email:    "test@example.com",
+				Role:     role,
+				Password: "password123",
+			})
+
+			assert.ErrorIs(t, err, apperror.ErrForbidden)
+			mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateUserWithRole(t *testing.T) {
+	mockRepo := new(mocks.MockUserPostgres)
+	mockLogger := new(mocks.MockLogger)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
+
+	userID := uuid.New()
+	mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user model.User) bool {
+		return user.Role == model.RoleAdmin
+	})).Return(userID, nil)
+	mockLogger.On("Infow", "User successfully created", "userID", userID, "email", "admin@example.com").Once()
+
+	// Администратор может создать пользователя с любой ролью политики
+	result, err := service.CreateUserWithRole(context.Background(), model.User{
+		Email:    "admin@example.com",
+		Role:     model.RoleAdmin,
+		Password: "password123",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, result.Id)
+
+	_, err = service.CreateUserWithRole(context.Background(), model.User{Email: "x@example.com", Role: "superuser", Password: "password123"})
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	mockRepo.AssertNumberOfCalls(t, "CreateUser", 1)
+}
+
 func TestLoginUser_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockUserPostgres)
+	mockTokenRepo := new(mocks.MockTokenRepository)
 	mockLogger := new(mocks.MockLogger)
-	service := service.NewUserService(mockRepo, mockLogger)
+	service := newUserService(mockRepo, mockTokenRepo, mockLogger)
 
 	password := "password123"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -121,20 +204,23 @@ func TestLoginUser_Success(t *testing.T) {
 	}
 
 	mockRepo.On("GetUserByEmail", mock.Anything, expectedUser.Email).Return(expectedUser, nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(token model.RefreshToken) bool {
+		return token.UserId == expectedUser.Id && token.FamilyId != uuid.Nil && len(token.TokenHash) == 64
+	})).Return(nil).Once()
 	mockLogger.On("Infow", "User authentication successful",
 		"userID", expectedUser.Id,
 		"email", expectedUser.Email).Once()
 
-	os.Setenv("SIGNING_KEY", "secret-key")
-
 	// Act
-	token, err := service.LoginUser(context.Background(), expectedUser.Email, password)
+	pair, err := service.LoginUser(context.Background(), expectedUser.Email, password)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
 
 	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
@@ -142,7 +228,7 @@ func TestLoginUser_UserNotFound(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockUserPostgres)
 	mockLogger := new(mocks.MockLogger)
-	service := service.NewUserService(mockRepo, mockLogger)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
 
 	email := "nonexistent@example.com"
 	mockRepo.On("GetUserByEmail", mock.Anything, email).Return(model.User{}, errors.New("user not found"))
@@ -162,7 +248,7 @@ func TestLoginUser_WrongPassword(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockUserPostgres)
 	mockLogger := new(mocks.MockLogger)
-	service := service.NewUserService(mockRepo, mockLogger)
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
 
 	correctPassword := "correct-password"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(correctPassword), bcrypt.DefaultCost)
@@ -192,53 +278,63 @@ func TestDummyLogin(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockUserPostgres)
 	mockLogger := new(mocks.MockLogger)
-	service := service.NewUserService(mockRepo, mockLogger)
-
-	// Set up environment variable for signing key
-	originalSigningKey := os.Getenv("SIGNING_KEY")
-	defer func() {
-		os.Setenv("SIGNING_KEY", originalSigningKey) // Restore original value
-	}()
-	os.Setenv("SIGNING_KEY", "test-signing-key")
+	service := newUserService(mockRepo, new(mocks.MockTokenRepository), mockLogger)
 
-	testRole := "admin"
+	testRole := model.RoleEmployee
+	pvzScope := []uuid.UUID{uuid.New()}
 
 	// Expected logger call
-	mockLogger.On("Infow", "Dummy token created", "role", testRole).Once()
+	mockLogger.On("Infow", "Dummy token created", "role", testRole, "pvzScope", pvzScope).Once()
 
 	// Act
-	token, err := service.DummyLogin(context.Background(), testRole)
+	token, err := service.DummyLogin(context.Background(), testRole, pvzScope)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
 	// Verify the token can be parsed and contains the correct claims
-	parsedToken, err := jwt.ParseWithClaims(token, &model.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte("test-signing-key"), nil
-	})
+	parsedToken, err := jwt.ParseWithClaims(token, &model.TokenClaims{}, testKeys.Keyfunc)
 	assert.NoError(t, err)
 	assert.True(t, parsedToken.Valid)
 
 	claims, ok := parsedToken.Claims.(*model.TokenClaims)
 	assert.True(t, ok)
 	assert.Equal(t, testRole, claims.Role)
+	assert.Equal(t, pvzScope, claims.PvzScope)
+	_, err = uuid.Parse(claims.Id)
+	assert.NoError(t, err, "token must carry a jti")
 
 	mockLogger.AssertExpectations(t)
 }
 
+func TestDummyLogin_UnknownRole(t *testing.T) {
+	service := newUserService(new(mocks.MockUserPostgres), new(mocks.MockTokenRepository), new(mocks.MockLogger))
+
+	token, err := service.DummyLogin(context.Background(), "superuser", nil)
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	assert.Empty(t, token)
+}
+
+func TestDummyLogin_PrivilegedRole(t *testing.T) {
+	mockLogger := new(mocks.MockLogger)
+	mockLogger.On("Warnw", "Self-service role rejected", "role", model.RoleAdmin).Once()
+	service := newUserService(new(mocks.MockUserPostgres), new(mocks.MockTokenRepository), mockLogger)
+
+	token, err := service.DummyLogin(context.Background(), model.RoleAdmin, nil)
+
+	assert.ErrorIs(t, err, apperror.ErrForbidden)
+	assert.Empty(t, token)
+}
+
 //func TestDummyLogin_SigningError(t *testing.T) {
 //	// Arrange
 //	mockRepo := new(mocks.MockUserPostgres)
 //	mockLogger := new(mocks.MockLogger)
-//	service := service.NewUserService(mockRepo, mockLogger)
-//
 //	// Set empty signing key to force an error
-//	originalSigningKey := os.Getenv("SIGNING_KEY")
-//	defer func() {
-//		os.Setenv("SIGNING_KEY", originalSigningKey) // Restore original value
-//	}()
-//	os.Setenv("SIGNING_KEY", "")
+//	repos := &repository.Repository{User: mockRepo}
+//	service := service.NewUserService(repos, nil, service.TokenConfig{AccessTTL: time.Minute}, mockLogger)
 //
 //	testRole := "admin"
 //
@@ -248,7 +344,7 @@ func TestDummyLogin(t *testing.T) {
 //		"error", mock.Anything).Once()
 //
 //	// Act
-//	token, err := service.DummyLogin(context.Background(), testRole)
+//	token, err := service.DummyLogin(context.Background(), testRole, nil)
 //
 //	// Assert
 //	assert.Error(t, err)
@@ -0,0 +1,339 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/apperror"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/service"
+	"pvz/internal/webhook"
+	"pvz/mocks"
+)
+
+const partnerSecret = "partner-secret-0123456789"
+
+func newWebhookService(repo *mocks.MockWebhookRepository, log *mocks.MockLogger) (*service.WebhookService, *repository.Repository) {
+	repos := &repository.Repository{Webhook: repo}
+	repos.UnitOfWork = &mocks.MockUnitOfWork{Repos: repos}
+	return service.NewWebhookService(repos, service.WebhookConfig{
+		Timeout:     time.Second,
+		BatchSize:   20,
+		MaxAttempts: 3,
+		MaxBackoff:  time.Minute,
+	}, log), repos
+}
+
+func pendingDelivery(url string, attempts int) model.PendingWebhookDelivery {
+	return model.PendingWebhookDelivery{
+		WebhookDelivery: model.WebhookDelivery{
+			Id:             uuid.New(),
+			SubscriptionId: uuid.New(),
+			EventId:        uuid.New(),
+			EventType:      model.EventReceptionClosed,
+			Body:           `{"type":"ReceptionClosed"}`,
+			Status:         model.WebhookDeliveryPending,
+			Attempts:       attempts,
+		},
+		Url:    url,
+		Secret: partnerSecret,
+	}
+}
+
+func TestDeliverWebhooks_SignedRequest(t *testing.T) {
+	// Партнёр проверяет подпись так же, как это сделал бы настоящий получатель
+	var received http.Header
+	var verifyErr error
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = r.Header.Clone()
+		verifyErr = webhook.Verify(partnerSecret, r.Header.Get(webhook.HeaderTimestamp),
+			r.Header.Get(webhook.HeaderSignature), body, time.Minute)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer partner.Close()
+
+	repo := new(mocks.MockWebhookRepository)
+	log := new(mocks.MockLogger)
+	svc, _ := newWebhookService(repo, log)
+
+	delivery := pendingDelivery(partner.URL, 0)
+	repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, 20).Return([]model.PendingWebhookDelivery{delivery}, nil)
+
+	var attempt model.WebhookAttempt
+	repo.On("SaveWebhookAttempt", mock.Anything, delivery.Id, mock.Anything).
+		Run(func(args mock.Arguments) { attempt = args.Get(2).(model.WebhookAttempt) }).
+		Return(nil).Once()
+	log.On("Infow", "Webhooks delivered", "delivered", 1, "failed", 0, "dead", 0).Once()
+
+	require.NoError(t, svc.DeliverWebhooks(context.Background()))
+
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, model.EventReceptionClosed, received.Get(webhook.HeaderEvent))
+	assert.Equal(t, delivery.Id.String(), received.Get(webhook.HeaderDelivery))
+	assert.Equal(t, "application/json", received.Get("Content-Type"))
+	assert.Equal(t, model.WebhookDeliveryDelivered, attempt.Status)
+	assert.Equal(t, http.StatusAccepted, attempt.StatusCode)
+	assert.Empty(t, attempt.Error)
+	log.AssertExpectations(t)
+}
+
+func TestDeliverWebhooks_RetryWithBackoff(t *testing.T) {
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer partner.Close()
+
+	repo := new(mocks.MockWebhookRepository)
+	log := new(mocks.MockLogger)
+	svc, _ := newWebhookService(repo, log)
+
+	// Вторая попытка из трёх: следующая через 20 секунд
+	delivery := pendingDelivery(partner.URL, 1)
+	repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, 20).Return([]model.PendingWebhookDelivery{delivery}, nil)
+
+	var attempt model.WebhookAttempt
+	repo.On("SaveWebhookAttempt", mock.Anything, delivery.Id, mock.Anything).
+		Run(func(args mock.Arguments) { attempt = args.Get(2).(model.WebhookAttempt) }).
+		Return(nil)
+	log.On("Warnw", "Failed to deliver webhook", "deliveryId", delivery.Id,
+		"subscriptionId", delivery.SubscriptionId, "attempt", 2, "error", mock.Anything).Once()
+	log.On("Infow", "Webhooks delivered", "delivered", 0, "failed", 1, "dead", 0).Once()
+
+	require.NoError(t, svc.DeliverWebhooks(context.Background()))
+
+	assert.Equal(t, model.WebhookDeliveryPending, attempt.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, attempt.StatusCode)
+	assert.Contains(t, attempt.Error, "503")
+	assert.Equal(t, 20*time.Second, attempt.RetryAfter)
+	log.AssertExpectations(t)
+}
+
+func TestDeliverWebhooks_DeadLetter(t *testing.T) {
+	// Получатель недоступен: ответа нет, код 0
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	partner.Close()
+
+	repo := new(mocks.MockWebhookRepository)
+	log := new(mocks.MockLogger)
+	svc, _ := newWebhookService(repo, log)
+
+	delivery := pendingDelivery(partner.URL, 2)
+	repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, 20).Return([]model.PendingWebhookDelivery{delivery}, nil)
+
+	var attempt model.WebhookAttempt
+	repo.On("SaveWebhookAttempt", mock.Anything, delivery.Id, mock.Anything).
+		Run(func(args mock.Arguments) { attempt = args.Get(2).(model.WebhookAttempt) }).
+		Return(nil)
+	log.On("Warnw", "Webhook delivery dead-lettered", "deliveryId", delivery.Id,
+		"subscriptionId", delivery.SubscriptionId, "attempts", 3, "error", mock.Anything).Once()
+	log.On("Infow", "Webhooks delivered", "delivered", 0, "failed", 0, "dead", 1).Once()
+
+	require.NoError(t, svc.DeliverWebhooks(context.Background()))
+
+	assert.Equal(t, model.WebhookDeliveryDead, attempt.Status)
+	assert.Zero(t, attempt.StatusCode)
+	assert.NotEmpty(t, attempt.Error)
+	log.AssertExpectations(t)
+}
+
+func TestDeliverWebhooks_OutsideTransaction(t *testing.T) {
+	var sent int
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer partner.Close()
+
+	// Без UnitOfWork: аренда, отправка и запись попыток не требуют общей транзакции
+	repo := new(mocks.MockWebhookRepository)
+	log := new(mocks.MockLogger)
+	svc := service.NewWebhookService(&repository.Repository{Webhook: repo}, service.WebhookConfig{
+		Timeout:     time.Second,
+		BatchSize:   20,
+		MaxAttempts: 3,
+		MaxBackoff:  time.Minute,
+	}, log)
+
+	first, second := pendingDelivery(partner.URL, 0), pendingDelivery(partner.URL, 0)
+	// Аренда переживает отправку всей пачки: таймаут на каждую доставку и запас
+	repo.On("ClaimWebhookDeliveries", mock.Anything, 20*time.Second+time.Minute, 20).
+		Return([]model.PendingWebhookDelivery{first, second}, nil)
+
+	// Ошибка записи одной попытки не мешает остальным
+	saveErr := errors.New("db down")
+	repo.On("SaveWebhookAttempt", mock.Anything, first.Id, mock.Anything).Return(saveErr).Once()
+	repo.On("SaveWebhookAttempt", mock.Anything, second.Id, mock.Anything).Return(nil).Once()
+	log.On("Errorw", "Failed to deliver webhooks", "error", mock.Anything).Once()
+
+	err := svc.DeliverWebhooks(context.Background())
+
+	assert.ErrorIs(t, err, saveErr)
+	assert.Equal(t, 2, sent)
+	repo.AssertExpectations(t)
+	log.AssertExpectations(t)
+}
+
+func TestDeliverWebhooks_Nothing(t *testing.T) {
+	repo := new(mocks.MockWebhookRepository)
+	svc, _ := newWebhookService(repo, new(mocks.MockLogger))
+
+	repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, 20).Return(nil, nil)
+
+	assert.NoError(t, svc.DeliverWebhooks(context.Background()))
+}
+
+func TestCreateWebhookSubscription_GeneratesSecret(t *testing.T) {
+	repo := new(mocks.MockWebhookRepository)
+	log := new(mocks.MockLogger)
+	svc, repos := newWebhookService(repo, log)
+	mockAudit := new(mocks.MockAuditRepository)
+	repos.Audit = mockAudit
+
+	var stored model.WebhookSubscription
+	call := repo.On("CreateWebhookSubscription", mock.Anything, mock.Anything)
+	call.Run(func(args mock.Arguments) {
+		stored = args.Get(1).(model.WebhookSubscription)
+		stored.Id = uuid.New()
+		call.Return(stored, nil)
+	})
+
+	var entry model.AuditEntry
+	mockAudit.On("CreateAuditEntry", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { entry = args.Get(1).(model.AuditEntry) }).
+		Return(nil)
+	log.On("Infow", "Webhook subscription created", "id", mock.Anything, "url", "https://partner.example/hook")
+
+	created, err := svc.CreateWebhookSubscription(context.Background(), model.WebhookSubscription{
+		Url:        "https://partner.example/hook",
+		EventTypes: []string{model.EventReceptionClosed},
+		Active:     true,
+	})
+
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(stored.Secret), 32)
+	assert.Equal(t, stored.Secret, created.Secret)
+	// Секрет не попадает в журнал аудита
+	assert.Equal(t, model.AuditWebhookCreate, entry.Action)
+	assert.NotContains(t, string(entry.After), stored.Secret)
+}
+
+func TestCreateWebhookSubscription_Validation(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription model.WebhookSubscription
+		message      string
+	}{
+		{
+			name:         "relative url",
+			subscription: model.WebhookSubscription{Url: "/hook"},
+			message:      "url must be an absolute http(s) URL",
+		},
+		{
+			name:         "unsupported scheme",
+			subscription: model.WebhookSubscription{Url: "ftp://partner.example/hook"},
+			message:      "url must be an absolute http(s) URL",
+		},
+		{
+			name:         "unknown event type",
+			subscription: model.WebhookSubscription{Url: "https://partner.example/hook", EventTypes: []string{"ReceptionLost"}},
+			message:      `unknown event type "ReceptionLost"`,
+		},
+		{
+			name:         "short secret",
+			subscription: model.WebhookSubscription{Url: "https://partner.example/hook", Secret: "short"},
+			message:      "secret must be at least 16 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newWebhookService(new(mocks.MockWebhookRepository), new(mocks.MockLogger))
+
+			_, err := svc.CreateWebhookSubscription(context.Background(), tt.subscription)
+
+			assert.ErrorIs(t, err, apperror.ErrValidation)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestCreateWebhookSubscription_UnknownPvz(t *testing.T) {
+	repo := new(mocks.MockWebhookRepository)
+	log := new(mocks.MockLogger)
+	svc, repos := newWebhookService(repo, log)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	repos.Pvz = mockPvzRepo
+
+	pvzId := uuid.New()
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzId).Return(model.Pvz{}, repository.ErrNotFound)
+	log.On("Errorw", "Failed to create webhook subscription", "url", "https://partner.example/hook", "error", mock.Anything)
+
+	_, err := svc.CreateWebhookSubscription(context.Background(), model.WebhookSubscription{
+		Url:   "https://partner.example/hook",
+		PvzId: &pvzId,
+	})
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	repo.AssertNotCalled(t, "CreateWebhookSubscription", mock.Anything, mock.Anything)
+}
+
+func TestGetWebhookDeliveries_UnknownSubscription(t *testing.T) {
+	repo := new(mocks.MockWebhookRepository)
+	svc, _ := newWebhookService(repo, new(mocks.MockLogger))
+
+	id := uuid.New()
+	repo.On("GetWebhookSubscriptionById", mock.Anything, id).Return(model.WebhookSubscription{}, repository.ErrNotFound)
+
+	_, err := svc.GetWebhookDeliveries(context.Background(), id, "", 10, 0)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestCloseReception_EnqueuesWebhookDeliveries(t *testing.T) {
+	mockRepo := new(mocks.MockReceptionRepository)
+	mockPvzRepo := new(mocks.MockPvzRepository)
+	mockOutbox := new(mocks.MockOutboxRepository)
+	mockWebhook := new(mocks.MockWebhookRepository)
+	mockLogger := new(mocks.MockLogger)
+	uow := &mocks.MockUnitOfWork{Repos: &repository.Repository{
+		Reception: mockRepo, Pvz: mockPvzRepo, Outbox: mockOutbox, Webhook: mockWebhook,
+	}}
+	receptionService := newReceptionService(uow, mockLogger)
+
+	pvzID, receptionID := uuid.New(), uuid.New()
+	mockPvzRepo.On("LockPvz", mock.Anything, pvzID).Return(model.Pvz{Id: pvzID, Status: model.PvzStatusActive}, nil)
+	mockRepo.On("GetInProgressReception", mock.Anything, pvzID).Return(receptionID, nil)
+	mockRepo.On("GetReceptionById", mock.Anything, receptionID).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusInProgress}, nil)
+	mockRepo.On("UpdateReceptionStatus", mock.Anything, mock.Anything).
+		Return(model.Reception{Id: receptionID, PvzId: pvzID, Status: model.ReceptionStatusClose}, nil)
+	mockLogger.On("Infow", mock.Anything, mock.Anything, mock.Anything)
+
+	event := model.Event{Id: uuid.New(), Type: model.EventReceptionClosed, PvzId: pvzID, Payload: json.RawMessage(`{}`)}
+	mockOutbox.On("CreateOutboxEvent", mock.Anything, model.EventReceptionClosed, pvzID, mock.Anything).Return(event, nil)
+
+	var body string
+	mockWebhook.On("CreateWebhookDeliveries", mock.Anything, event, mock.Anything).
+		Run(func(args mock.Arguments) { body = args.Get(2).(string) }).
+		Return(int64(1), nil).Once()
+
+	require.NoError(t, receptionService.CloseReception(context.Background(), pvzID))
+
+	// Тело вебхука - событие целиком, с тем же Id, что в outbox
+	assert.True(t, strings.Contains(body, event.Id.String()))
+	assert.Contains(t, body, `"type":"ReceptionClosed"`)
+	mockWebhook.AssertExpectations(t)
+}
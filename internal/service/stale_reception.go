@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pvz/internal/live"
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/metrics"
+)
+
+// StaleReceptionConfig - параметры обработки забытых приёмок
+type StaleReceptionConfig struct {
+	// IdleFor - сколько приёмка может простоять без активности
+	IdleFor time.Duration
+	// Action - model.StaleActionClose или model.StaleActionFlag
+	Action string
+	// BatchSize - сколько приёмок обрабатывается за один запуск
+	BatchSize int
+}
+
+// StaleReceptionService закрывает или помечает приёмки, которые сотрудники
+// забыли закрыть: незакрытая приёмка не даёт открыть следующую.
+type StaleReceptionService struct {
+	uow    repository.UnitOfWork
+	hub    *live.Hub
+	cfg    StaleReceptionConfig
+	logger logger.Logger
+}
+
+func NewStaleReceptionService(repos *repository.Repository, cfg StaleReceptionConfig, hub *live.Hub, log logger.Logger) *StaleReceptionService {
+	return &StaleReceptionService{
+		uow:    repos.UnitOfWork,
+		hub:    hub,
+		cfg:    cfg,
+		logger: log,
+	}
+}
+
+// staleReception - обработанная приёмка и её последняя активность для лога
+type staleReception struct {
+	reception      model.Reception
+	lastActivityAt time.Time
+}
+
+// ProcessStaleReceptions закрывает или помечает очередную пачку приёмок без
+// активности дольше IdleFor. Закрытие записывается в аудит и публикует
+// ReceptionClosed, как и ручное. Реплики обрабатывают приёмки по очереди.
+func (s *StaleReceptionService) ProcessStaleReceptions(ctx context.Context) error {
+	closing := s.cfg.Action == model.StaleActionClose
+	reason := fmt.Sprintf("no activity for %s", s.cfg.IdleFor)
+	auditAction := model.AuditReceptionFlagStale
+	if closing {
+		auditAction = model.AuditReceptionAutoClose
+	}
+
+	var processed []staleReception
+	var events []model.Event
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		locked, err := repos.Reception.TryLockStaleReceptions(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		candidates, err := repos.Reception.GetStaleReceptions(ctx, s.cfg.IdleFor, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, candidate := range candidates {
+			// Изменения приёмки блокируют её ПВЗ, так что активность
+			// не появится между проверкой и закрытием
+			if _, err := lockPvz(ctx, repos, candidate.PvzId); err != nil {
+				return err
+			}
+
+			after, err := repos.Reception.MarkReceptionStale(ctx, candidate.Id, s.cfg.IdleFor, reason, closing)
+			if errors.Is(err, repository.ErrNotFound) {
+				// После выборки приёмку закрыли или в ней появилась активность
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := recordAudit(ctx, repos, auditAction, model.AuditEntityReception, candidate.Id.String(), candidate.Reception, after); err != nil {
+				return err
+			}
+			if closing {
+				event, err := recordEvent(ctx, repos, model.EventReceptionClosed, after.PvzId, receptionPayload(after))
+				if err != nil {
+					return err
+				}
+				events = append(events, event)
+			}
+			processed = append(processed, staleReception{reception: after, lastActivityAt: candidate.LastActivityAt})
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to process stale receptions", "action", s.cfg.Action, "error", err)
+		return err
+	}
+
+	for _, event := range events {
+		s.hub.Publish(event)
+	}
+
+	message := "Stale reception flagged"
+	if closing {
+		message = "Stale reception closed"
+	}
+	for _, stale := range processed {
+		metrics.StaleReceptions.WithLabelValues(s.cfg.Action).Inc()
+		s.logger.Warnw(message, "receptionId", stale.reception.Id, "pvzId", stale.reception.PvzId,
+			"lastActivityAt", stale.lastActivityAt, "reason", reason)
+	}
+	return nil
+}
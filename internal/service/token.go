@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+)
+
+// TokenSigner подписывает claims текущим ключом
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// TokenConfig - параметры выпуска токенов
+type TokenConfig struct {
+	Signer     TokenSigner
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// RefreshTokens обменивает refresh-токен на новую пару токенов, отзывая
+// предъявленный. Повторное предъявление уже обменянного токена означает его
+// утечку, поэтому отзывается всё семейство.
+func (s *UserService) RefreshTokens(ctx context.Context, refreshToken string) (model.TokenPair, error) {
+	var (
+		pair   model.TokenPair
+		reused *model.RefreshToken
+	)
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		current, err := repos.Token.GetRefreshTokenForUpdate(ctx, hashToken(refreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.Unauthorized("invalid refresh token")
+		}
+		if err != nil {
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			// Отзыв семейства должен зафиксироваться, поэтому ошибку возвращаем после транзакции
+			reused = &current
+			return repos.Token.RevokeTokenFamily(ctx, current.FamilyId)
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return apperror.Unauthorized("refresh token expired")
+		}
+
+		user, err := repos.User.GetUserById(ctx, current.UserId)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.Unauthorized("invalid refresh token")
+		}
+		if err != nil {
+			return err
+		}
+
+		var next model.RefreshToken
+		pair, next, err = s.issueTokens(user, current.FamilyId)
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Token.CreateRefreshToken(ctx, next); err != nil {
+			return err
+		}
+		return repos.Token.RevokeRefreshToken(ctx, current.Id, &next.Id)
+	})
+	if err != nil {
+		s.logger.Warnw("Failed to refresh tokens", "error", err)
+		return model.TokenPair{}, err
+	}
+
+	if reused != nil {
+		s.logger.Warnw("Refresh token reuse detected, token family revoked",
+			"userID", reused.UserId, "familyId", reused.FamilyId)
+		return model.TokenPair{}, apperror.Unauthorized("invalid refresh token")
+	}
+
+	s.logger.Infow("Tokens refreshed")
+	return pair, nil
+}
+
+// Logout отзывает текущий access-токен и, если передан, refresh-токен вместе с его семейством
+func (s *UserService) Logout(ctx context.Context, claims *model.TokenClaims, refreshToken string) error {
+	if err := s.revocations.Revoke(ctx, claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return err
+	}
+
+	if refreshToken != "" {
+		err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+			token, err := repos.Token.GetRefreshTokenForUpdate(ctx, hashToken(refreshToken))
+			if errors.Is(err, repository.ErrNotFound) || (err == nil && token.UserId != claims.UserId) {
+				return apperror.Unauthorized("invalid refresh token")
+			}
+			if err != nil {
+				return err
+			}
+			return repos.Token.RevokeTokenFamily(ctx, token.FamilyId)
+		})
+		if err != nil {
+			s.logger.Warnw("Failed to revoke refresh token on logout", "userID", claims.UserId, "error", err)
+			return err
+		}
+	}
+
+	s.logger.Infow("User logged out", "userID", claims.UserId)
+	return nil
+}
+
+// DeleteExpiredTokens удаляет истёкшие refresh-токены и записи об отзыве
+// истёкших access-токенов
+func (s *UserService) DeleteExpiredTokens(ctx context.Context) error {
+	now := time.Now()
+	refresh, err := s.repoToken.DeleteExpiredRefreshTokens(ctx, now)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.repoToken.DeleteExpiredRevokedTokens(ctx, now)
+	if err != nil {
+		return err
+	}
+	if refresh > 0 || revoked > 0 {
+		s.logger.Infow("Expired tokens deleted", "refresh", refresh, "revoked", revoked)
+	}
+	return nil
+}
+
+// issueTokens выпускает access-токен и новый refresh-токен семейства familyId.
+// Refresh-токен нужно сохранить вызывающему.
+func (s *UserService) issueTokens(user model.User, familyId uuid.UUID) (model.TokenPair, model.RefreshToken, error) {
+	accessToken, err := s.signAccessToken(model.TokenClaims{UserId: user.Id, Role: user.Role})
+	if err != nil {
+		return model.TokenPair{}, model.RefreshToken{}, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return model.TokenPair{}, model.RefreshToken{}, err
+	}
+
+	stored := model.RefreshToken{
+		Id:        uuid.New(),
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.tokens.RefreshTTL),
+	}
+
+	return model.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, stored, nil
+}
+
+// signAccessToken подписывает claims, проставляя jti и срок жизни access-токена
+func (s *UserService) signAccessToken(claims model.TokenClaims) (string, error) {
+	claims.StandardClaims = jwt.StandardClaims{
+		Id:        uuid.NewString(),
+		ExpiresAt: time.Now().Add(s.tokens.AccessTTL).Unix(),
+		IssuedAt:  time.Now().Unix(),
+	}
+
+	signedToken, err := s.tokens.Signer.Sign(&claims)
+	if err != nil {
+		return "", fmt.Errorf("could not sign token: %w", err)
+	}
+
+	return signedToken, nil
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// В БД хранится только хэш: утечка таблицы не даёт действующих токенов
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
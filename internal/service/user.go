@@ -3,31 +3,61 @@ package service
 import (
 	"context"
 	"fmt"
-	"os"
-	"time"
+	"slices"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"pvz/internal/apperror"
 	"pvz/internal/logger"
+	"pvz/internal/rbac"
 	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 )
 
-const tokenTTL = time.Hour * 24
-
 type UserService struct {
-	repoUser repository.User
-	logger   logger.Logger
+	repoUser    repository.User
+	repoToken   repository.Token
+	uow         repository.UnitOfWork
+	revocations *RevocationCache
+	tokens      TokenConfig
+	policy      *rbac.Policy
+	logger      logger.Logger
 }
 
-func NewUserService(repoUser repository.User, log logger.Logger) *UserService {
+func NewUserService(repos *repository.Repository, revocations *RevocationCache, tokens TokenConfig, policy *rbac.Policy, log logger.Logger) *UserService {
 	return &UserService{
-		repoUser: repoUser,
-		logger:   log,
+		repoUser:    repos.User,
+		repoToken:   repos.Token,
+		uow:         repos.UnitOfWork,
+		revocations: revocations,
+		tokens:      tokens,
+		policy:      policy,
+		logger:      log,
 	}
 }
 
+// selfServiceRoles - роли, которые можно получить без авторизации через
+// /register и /dummyLogin. Остальные роли политики назначает администратор.
+var selfServiceRoles = []string{model.RoleEmployee, model.RoleModerator}
+
+// CreateUser регистрирует пользователя с одной из selfServiceRoles
 func (s *UserService) CreateUser(ctx context.Context, user model.User) (model.User, error) {
+	if err := s.checkSelfServiceRole(user.Role); err != nil {
+		return model.User{}, err
+	}
+	return s.createUser(ctx, user)
+}
+
+// CreateUserWithRole создаёт пользователя с любой ролью политики, в том числе
+// admin и auditor. Доступен только через маршрут с правом user:create.
+func (s *UserService) CreateUserWithRole(ctx context.Context, user model.User) (model.User, error) {
+	if err := s.checkRole(user.Role); err != nil {
+		return model.User{}, err
+	}
+	return s.createUser(ctx, user)
+}
+
+func (s *UserService) createUser(ctx context.Context, user model.User) (model.User, error) {
 	hashedPassword, err := GeneratePasswordHash(user.Password)
 	if err != nil {
 		s.logger.Errorw("Password hashing failed", "error", err)
@@ -46,6 +76,27 @@ func (s *UserService) CreateUser(ctx context.Context, user model.User) (model.Us
 	return user, nil
 }
 
+// checkRole отклоняет роли, которых нет в политике RBAC: токен с такой
+// ролью не прошёл бы ни одну проверку прав
+func (s *UserService) checkRole(role string) error {
+	if !s.policy.HasRole(role) {
+		return apperror.Validation("unknown role %q", role)
+	}
+	return nil
+}
+
+// checkSelfServiceRole разрешает анонимному клиенту только selfServiceRoles
+func (s *UserService) checkSelfServiceRole(role string) error {
+	if err := s.checkRole(role); err != nil {
+		return err
+	}
+	if !slices.Contains(selfServiceRoles, role) {
+		s.logger.Warnw("Self-service role rejected", "role", role)
+		return apperror.Forbidden("role %q cannot be self-assigned", role)
+	}
+	return nil
+}
+
 func GeneratePasswordHash(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -55,55 +106,44 @@ func GeneratePasswordHash(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-func (s *UserService) LoginUser(ctx context.Context, email, password string) (string, error) {
+func (s *UserService) LoginUser(ctx context.Context, email, password string) (model.TokenPair, error) {
 	user, err := s.repoUser.GetUserByEmail(ctx, email)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user: %w", err)
+		return model.TokenPair{}, apperror.Wrap(apperror.ErrUnauthorized, fmt.Errorf("failed to get user: %w", err), "invalid credentials")
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		s.logger.Warnw("Incorrect password attempt", "email", email)
-		return "", fmt.Errorf("invalid credentials")
-	}
-
-	claims := &model.TokenClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		UserId: user.Id,
-		Role:   user.Role,
+		return model.TokenPair{}, apperror.Unauthorized("invalid credentials")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	signedToken, err := token.SignedString([]byte(os.Getenv("SIGNING_KEY")))
+	pair, refreshToken, err := s.issueTokens(user, uuid.New())
 	if err != nil {
 		s.logger.Errorw("Failed to sign JWT", "userID", user.Id, "error", err)
-		return "", fmt.Errorf("could not sign token: %w", err)
+		return model.TokenPair{}, err
+	}
+
+	if err := s.repoToken.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return model.TokenPair{}, err
 	}
 
 	s.logger.Infow("User authentication successful", "userID", user.Id, "email", user.Email)
-	return signedToken, nil
+	return pair, nil
 }
 
-func (s *UserService) DummyLogin(ctx context.Context, role string) (string, error) {
-	claims := &model.TokenClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		Role: role,
+// DummyLogin выдаёт тестовый токен без пользователя. pvzScope ограничивает
+// ПВЗ, с которыми может работать сотрудник; без него токен не ограничен.
+func (s *UserService) DummyLogin(ctx context.Context, role string, pvzScope []uuid.UUID) (string, error) {
+	if err := s.checkSelfServiceRole(role); err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	signedToken, err := token.SignedString([]byte(os.Getenv("SIGNING_KEY")))
+	signedToken, err := s.signAccessToken(model.TokenClaims{UserId: uuid.Nil, Role: role, PvzScope: pvzScope})
 	if err != nil {
 		s.logger.Errorw("Failed to sign dummy token", "role", role, "error", err)
-		return "", fmt.Errorf("could not sign token: %w", err)
+		return "", err
 	}
 
-	s.logger.Infow("Dummy token created", "role", role)
+	s.logger.Infow("Dummy token created", "role", role, "pvzScope", pvzScope)
 	return signedToken, nil
 }
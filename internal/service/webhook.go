@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"pvz/internal/apperror"
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+	"pvz/internal/repository/model"
+	"pvz/internal/webhook"
+	"pvz/metrics"
+)
+
+// Первая повторная попытка доставки; дальше интервал удваивается до MaxBackoff
+const webhookInitialBackoff = 10 * time.Second
+
+// Запас аренды доставок сверх времени отправки всей пачки
+const webhookLeaseMargin = time.Minute
+
+// Секрет короче этого легко подобрать
+const minWebhookSecretLength = 16
+
+// WebhookConfig - параметры доставки вебхуков
+type WebhookConfig struct {
+	// Timeout - сколько ждать ответа получателя
+	Timeout time.Duration
+	// BatchSize - сколько доставок выполняется за один запуск
+	BatchSize int
+	// MaxAttempts - после стольких неудачных попыток доставка уходит в dead
+	MaxAttempts int
+	MaxBackoff  time.Duration
+}
+
+// WebhookService ведёт подписки партнёров и доставляет им события.
+// Доставки создаёт recordEvent в транзакции изменения.
+type WebhookService struct {
+	repo   repository.Webhook
+	uow    repository.UnitOfWork
+	client *webhook.Client
+	cfg    WebhookConfig
+	logger logger.Logger
+}
+
+func NewWebhookService(repos *repository.Repository, cfg WebhookConfig, log logger.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repos.Webhook,
+		uow:    repos.UnitOfWork,
+		client: webhook.NewClient(cfg.Timeout),
+		cfg:    cfg,
+		logger: log,
+	}
+}
+
+// CreateWebhookSubscription создаёт подписку. Если секрет не задан, он
+// генерируется; возвращённая подписка - единственное место, где его видно.
+func (s *WebhookService) CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	if err := validateWebhookUrl(subscription.Url); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if err := validateEventTypes(subscription.EventTypes); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if subscription.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return model.WebhookSubscription{}, err
+		}
+		subscription.Secret = secret
+	} else if len(subscription.Secret) < minWebhookSecretLength {
+		return model.WebhookSubscription{}, apperror.Validation("secret must be at least %d characters", minWebhookSecretLength)
+	}
+
+	var created model.WebhookSubscription
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		if subscription.PvzId != nil {
+			if _, err := lockPvz(ctx, repos, *subscription.PvzId); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if created, err = repos.Webhook.CreateWebhookSubscription(ctx, subscription); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, model.AuditWebhookCreate, model.AuditEntityWebhook, created.Id.String(), nil, created)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to create webhook subscription", "url", subscription.Url, "error", err)
+		return model.WebhookSubscription{}, err
+	}
+
+	s.logger.Infow("Webhook subscription created", "id", created.Id, "url", created.Url)
+	return created, nil
+}
+
+func (s *WebhookService) GetWebhookSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	subscriptions, err := s.repo.GetWebhookSubscriptions(ctx)
+	if err != nil {
+		s.logger.Errorw("Failed to get webhook subscriptions", "error", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookService) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (model.WebhookSubscription, error) {
+	subscription, err := s.repo.GetWebhookSubscriptionById(ctx, id)
+	if err != nil {
+		return model.WebhookSubscription{}, webhookNotFound(err, id)
+	}
+	return subscription, nil
+}
+
+func (s *WebhookService) UpdateWebhookSubscription(ctx context.Context, id uuid.UUID, update model.WebhookSubscriptionUpdate) (model.WebhookSubscription, error) {
+	if update.Url != nil {
+		if err := validateWebhookUrl(*update.Url); err != nil {
+			return model.WebhookSubscription{}, err
+		}
+	}
+	if err := validateEventTypes(update.EventTypes); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	var after model.WebhookSubscription
+
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		before, err := repos.Webhook.GetWebhookSubscriptionById(ctx, id)
+		if err != nil {
+			return webhookNotFound(err, id)
+		}
+		if update.PvzId != nil {
+			if _, err := lockPvz(ctx, repos, *update.PvzId); err != nil {
+				return err
+			}
+		}
+
+		if after, err = repos.Webhook.UpdateWebhookSubscription(ctx, id, update); err != nil {
+			return webhookNotFound(err, id)
+		}
+		return recordAudit(ctx, repos, model.AuditWebhookUpdate, model.AuditEntityWebhook, id.String(), before, after)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to update webhook subscription", "id", id, "error", err)
+		return model.WebhookSubscription{}, err
+	}
+
+	s.logger.Infow("Webhook subscription updated", "id", id)
+	return after, nil
+}
+
+// DeleteWebhookSubscription удаляет подписку; недоставленные события ей больше не отправляются
+func (s *WebhookService) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	err := s.uow.Do(ctx, func(repos *repository.Repository) error {
+		before, err := repos.Webhook.GetWebhookSubscriptionById(ctx, id)
+		if err != nil {
+			return webhookNotFound(err, id)
+		}
+		if err := repos.Webhook.DeleteWebhookSubscription(ctx, id); err != nil {
+			return webhookNotFound(err, id)
+		}
+		return recordAudit(ctx, repos, model.AuditWebhookDelete, model.AuditEntityWebhook, id.String(), before, nil)
+	})
+	if err != nil {
+		s.logger.Errorw("Failed to delete webhook subscription", "id", id, "error", err)
+		return err
+	}
+
+	s.logger.Infow("Webhook subscription deleted", "id", id)
+	return nil
+}
+
+// GetWebhookDeliveries возвращает журнал доставок подписки
+func (s *WebhookService) GetWebhookDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit, offset int) ([]model.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhookSubscriptionById(ctx, subscriptionId); err != nil {
+		return nil, webhookNotFound(err, subscriptionId)
+	}
+
+	deliveries, err := s.repo.GetWebhookDeliveries(ctx, subscriptionId, status, min(limit, maxPageLimit), offset)
+	if err != nil {
+		s.logger.Errorw("Failed to get webhook deliveries", "subscriptionId", subscriptionId, "error", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// DeliverWebhooks выполняет очередную пачку доставок. Неудачная попытка
+// откладывается с экспоненциальной задержкой, после MaxAttempts попыток
+// доставка уходит в dead.
+//
+// Пачка арендуется одним коротким запросом: до конца аренды другие реплики
+// её не берут. Запросы к получателям идут уже без транзакции и блокировок,
+// а каждая попытка записывается отдельно. Если реплика упадёт посреди
+// пачки, неотправленные доставки снова станут доступны после аренды.
+func (s *WebhookService) DeliverWebhooks(ctx context.Context) error {
+	lease := s.cfg.Timeout*time.Duration(s.cfg.BatchSize) + webhookLeaseMargin
+	deliveries, err := s.repo.ClaimWebhookDeliveries(ctx, lease, s.cfg.BatchSize)
+	if err != nil {
+		s.logger.Errorw("Failed to deliver webhooks", "error", err)
+		return err
+	}
+
+	results := make(map[string]int)
+	var saveErrs []error
+	for _, delivery := range deliveries {
+		attempt := s.deliver(ctx, delivery)
+		if err := s.repo.SaveWebhookAttempt(ctx, delivery.Id, attempt); err != nil {
+			saveErrs = append(saveErrs, err)
+			continue
+		}
+
+		result := attempt.Status
+		if result == model.WebhookDeliveryPending {
+			result = "failed"
+		}
+		results[result]++
+		metrics.WebhookDeliveries.WithLabelValues(result).Inc()
+	}
+	if err := errors.Join(saveErrs...); err != nil {
+		s.logger.Errorw("Failed to deliver webhooks", "error", err)
+		return err
+	}
+
+	if len(results) > 0 {
+		s.logger.Infow("Webhooks delivered", "delivered", results[model.WebhookDeliveryDelivered],
+			"failed", results["failed"], "dead", results[model.WebhookDeliveryDead])
+	}
+	return nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, delivery model.PendingWebhookDelivery) model.WebhookAttempt {
+	statusCode, err := s.client.Send(ctx, webhook.Request{
+		Url:        delivery.Url,
+		Secret:     delivery.Secret,
+		DeliveryId: delivery.Id.String(),
+		EventType:  delivery.EventType,
+		Body:       []byte(delivery.Body),
+	})
+	if err == nil {
+		return model.WebhookAttempt{Status: model.WebhookDeliveryDelivered, StatusCode: statusCode}
+	}
+
+	attempt := delivery.Attempts + 1
+	result := model.WebhookAttempt{
+		Status:     model.WebhookDeliveryPending,
+		StatusCode: statusCode,
+		Error:      err.Error(),
+		RetryAfter: retryBackoff(webhookInitialBackoff, attempt, s.cfg.MaxBackoff),
+	}
+	if attempt >= s.cfg.MaxAttempts {
+		result.Status = model.WebhookDeliveryDead
+		s.logger.Warnw("Webhook delivery dead-lettered", "deliveryId", delivery.Id,
+			"subscriptionId", delivery.SubscriptionId, "attempts", attempt, "error", err)
+		return result
+	}
+
+	s.logger.Warnw("Failed to deliver webhook", "deliveryId", delivery.Id,
+		"subscriptionId", delivery.SubscriptionId, "attempt", attempt, "error", err)
+	return result
+}
+
+func validateWebhookUrl(rawUrl string) error {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return apperror.Validation("url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(model.EventTypes, eventType) {
+			return apperror.Validation("unknown event type %q, expected one of: %s",
+				eventType, strings.Join(model.EventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate webhook secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func webhookNotFound(err error, id uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(apperror.ErrNotFound, err, "webhook subscription %s not found", id)
+	}
+	return err
+}
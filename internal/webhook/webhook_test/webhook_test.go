@@ -0,0 +1,94 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvz/internal/webhook"
+)
+
+func TestSign_KnownVector(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	signature := webhook.Sign("secret", time.Unix(1700000000, 0), []byte(`{"a":1}`))
+
+	assert.Equal(t, "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686", signature)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"ReceptionClosed"}`)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := webhook.Sign("secret", now, body)
+
+	assert.NoError(t, webhook.Verify("secret", timestamp, signature, body, time.Minute))
+	assert.ErrorIs(t, webhook.Verify("other", timestamp, signature, body, time.Minute), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", timestamp, signature, []byte(`{}`), time.Minute), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", "not-a-number", signature, body, time.Minute), webhook.ErrInvalidSignature)
+
+	// Повтор старого запроса отклоняется даже с верной подписью
+	old := now.Add(-time.Hour)
+	oldSignature := webhook.Sign("secret", old, body)
+	err := webhook.Verify("secret", strconv.FormatInt(old.Unix(), 10), oldSignature, body, time.Minute)
+	assert.ErrorIs(t, err, webhook.ErrStaleTimestamp)
+}
+
+func TestClient_Send(t *testing.T) {
+	var body []byte
+	var header http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	client := webhook.NewClient(time.Second)
+	status, err := client.Send(context.Background(), webhook.Request{
+		Url:        receiver.URL,
+		Secret:     "secret",
+		DeliveryId: "delivery-1",
+		EventType:  "ReceptionClosed",
+		Body:       []byte(`{"id":"1"}`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, `{"id":"1"}`, string(body))
+	assert.Equal(t, "ReceptionClosed", header.Get(webhook.HeaderEvent))
+	assert.Equal(t, "delivery-1", header.Get(webhook.HeaderDelivery))
+	assert.NoError(t, webhook.Verify("secret", header.Get(webhook.HeaderTimestamp),
+		header.Get(webhook.HeaderSignature), body, time.Minute))
+}
+
+func TestClient_Send_ErrorStatus(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer receiver.Close()
+
+	status, err := webhook.NewClient(time.Second).Send(context.Background(), webhook.Request{Url: receiver.URL})
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusGone, status)
+}
+
+func TestClient_Send_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	status, err := webhook.NewClient(50*time.Millisecond).Send(context.Background(), webhook.Request{Url: receiver.URL})
+
+	assert.Error(t, err)
+	assert.Zero(t, status)
+}
@@ -47,11 +47,63 @@ var (
 			Help: "Количество добавленных товаров",
 		},
 	)
+
+	// Публикация доменных событий
+	OutboxEventsPublished = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Количество опубликованных доменных событий",
+		},
+		[]string{"type"},
+	)
+
+	OutboxPublishErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_errors_total",
+			Help: "Количество неудачных попыток публикации событий",
+		},
+	)
+
+	// Попытки доставки вебхуков: delivered, failed (будет повтор) или dead
+	WebhookDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Количество попыток доставки вебхуков по результату",
+		},
+		[]string{"result"},
+	)
+
+	StaleReceptions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stale_receptions_total",
+			Help: "Количество приёмок без активности, закрытых или помеченных фоновой задачей",
+		},
+		[]string{"action"},
+	)
+
+	LiveSubscribers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "live_subscribers",
+			Help: "Количество открытых потоков событий ПВЗ",
+		},
+	)
+
+	LiveSubscribersDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "live_subscribers_dropped_total",
+			Help: "Количество потоков событий, отключённых из-за медленного клиента",
+		},
+	)
 )
 
-func Init() {
-	prometheus.MustRegister(RequestCount, ResponseDuration, CreatedPvz, CreatedReceptions, ProductsAdded)
+func Register() {
+	prometheus.MustRegister(RequestCount, ResponseDuration, CreatedPvz, CreatedReceptions, ProductsAdded,
+		OutboxEventsPublished, OutboxPublishErrors, WebhookDeliveries, StaleReceptions, LiveSubscribers, LiveSubscribersDropped)
+}
 
-	http.Handle("/metrics", promhttp.Handler())
-	go http.ListenAndServe(":9000", nil) // Порт 9000
+// Handler возвращает обработчик для отдельного сервера метрик
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
 }
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS reception_one_in_progress_per_pvz;
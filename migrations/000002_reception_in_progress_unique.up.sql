@@ -0,0 +1,3 @@
+CREATE UNIQUE INDEX reception_one_in_progress_per_pvz
+    ON reception (pvzId)
+    WHERE status = 'in_progress';
@@ -0,0 +1,17 @@
+CREATE TABLE refresh_token (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    familyId UUID NOT NULL,
+    tokenHash VARCHAR(64) NOT NULL UNIQUE,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expiresAt TIMESTAMP NOT NULL,
+    revokedAt TIMESTAMP,
+    replacedBy UUID REFERENCES refresh_token(id) ON DELETE SET NULL
+);
+
+CREATE INDEX refresh_token_family ON refresh_token (familyId);
+
+CREATE TABLE revoked_token (
+    jti UUID PRIMARY KEY,
+    expiresAt TIMESTAMP NOT NULL
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS pvz_status;
+
+ALTER TABLE pvz
+    DROP COLUMN IF EXISTS deactivatedAt,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS address,
+    DROP COLUMN IF EXISTS name;
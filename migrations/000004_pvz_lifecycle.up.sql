@@ -0,0 +1,7 @@
+ALTER TABLE pvz
+    ADD COLUMN name VARCHAR(256) NOT NULL DEFAULT '',
+    ADD COLUMN address VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive')),
+    ADD COLUMN deactivatedAt TIMESTAMP;
+
+CREATE INDEX pvz_status ON pvz (status);
@@ -0,0 +1,10 @@
+ALTER TABLE product
+    DROP CONSTRAINT IF EXISTS product_type_fkey,
+    ADD CONSTRAINT product_type_check CHECK (type IN ('электроника', 'одежда', 'обувь'));
+
+ALTER TABLE pvz
+    DROP CONSTRAINT IF EXISTS pvz_city_fkey,
+    ADD CONSTRAINT pvz_city_check CHECK (city IN ('Москва', 'Казань', 'Санкт-Петербург'));
+
+DROP TABLE IF EXISTS product_type;
+DROP TABLE IF EXISTS city;
@@ -0,0 +1,26 @@
+CREATE TABLE city (
+    name VARCHAR(256) PRIMARY KEY,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE product_type (
+    name VARCHAR(256) PRIMARY KEY,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO city (name) VALUES ('Москва'), ('Казань'), ('Санкт-Петербург');
+INSERT INTO product_type (name) VALUES ('электроника'), ('одежда'), ('обувь');
+
+-- Значения, уже попавшие в данные, тоже переносим в справочники
+INSERT INTO city (name) SELECT DISTINCT city FROM pvz ON CONFLICT DO NOTHING;
+INSERT INTO product_type (name) SELECT DISTINCT type FROM product ON CONFLICT DO NOTHING;
+
+ALTER TABLE pvz
+    DROP CONSTRAINT IF EXISTS pvz_city_check,
+    ADD CONSTRAINT pvz_city_fkey FOREIGN KEY (city) REFERENCES city(name) ON UPDATE CASCADE;
+
+ALTER TABLE product
+    DROP CONSTRAINT IF EXISTS product_type_check,
+    ADD CONSTRAINT product_type_fkey FOREIGN KEY (type) REFERENCES product_type(name) ON UPDATE CASCADE;
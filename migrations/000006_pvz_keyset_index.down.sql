@@ -0,0 +1 @@
+DROP INDEX IF EXISTS pvz_registration_date_id;
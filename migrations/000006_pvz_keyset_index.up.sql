@@ -0,0 +1,2 @@
+-- Порядок выборки GET /pvz и граница страницы по курсору
+CREATE INDEX pvz_registration_date_id ON pvz (registrationDate DESC, id DESC);
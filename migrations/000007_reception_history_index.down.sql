@@ -0,0 +1 @@
+DROP INDEX IF EXISTS reception_pvz_date;
@@ -0,0 +1,2 @@
+-- История приёмок ПВЗ: GET /pvz/{pvzId}/receptions
+CREATE INDEX reception_pvz_date ON reception (pvzId, dateTime DESC, id DESC);
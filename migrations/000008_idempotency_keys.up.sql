@@ -0,0 +1,14 @@
+-- Ответы на запросы с заголовком Idempotency-Key. statusCode пуст, пока
+-- первый запрос с ключом ещё выполняется.
+CREATE TABLE idempotency_key (
+    userId UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    requestHash VARCHAR(64) NOT NULL,
+    statusCode INT,
+    responseBody BYTEA,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expiresAt TIMESTAMP NOT NULL,
+    PRIMARY KEY (userId, key)
+);
+
+CREATE INDEX idempotency_key_expires_at ON idempotency_key (expiresAt);
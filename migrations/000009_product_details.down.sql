@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS product_barcode;
+DROP INDEX IF EXISTS product_reception_barcode;
+
+ALTER TABLE product
+    DROP COLUMN IF EXISTS attributes,
+    DROP COLUMN IF EXISTS weight,
+    DROP COLUMN IF EXISTS orderId,
+    DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,12 @@
+-- Штрихкод, номер заказа, вес в граммах и произвольные атрибуты товара
+ALTER TABLE product
+    ADD COLUMN barcode VARCHAR(64),
+    ADD COLUMN orderId VARCHAR(64),
+    ADD COLUMN weight INT CHECK (weight > 0),
+    ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+-- Штрихкод уникален в пределах приёмки
+CREATE UNIQUE INDEX product_reception_barcode ON product (receptionId, barcode) WHERE barcode IS NOT NULL;
+
+-- Поиск товара по штрихкоду по всем ПВЗ: GET /products?barcode=
+CREATE INDEX product_barcode ON product (barcode) WHERE barcode IS NOT NULL;
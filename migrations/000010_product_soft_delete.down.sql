@@ -0,0 +1,10 @@
+DELETE FROM product WHERE deletedAt IS NOT NULL;
+
+DROP INDEX IF EXISTS product_reception_barcode;
+CREATE UNIQUE INDEX product_reception_barcode ON product (receptionId, barcode) WHERE barcode IS NOT NULL;
+
+ALTER TABLE product
+    DROP COLUMN IF EXISTS deletedBy,
+    DROP COLUMN IF EXISTS deletedAt,
+    DROP COLUMN IF EXISTS updatedBy,
+    DROP COLUMN IF EXISTS updatedAt;
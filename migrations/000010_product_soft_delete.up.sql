@@ -0,0 +1,12 @@
+-- Кто и когда последним исправил товар и кто его удалил. Удалённые товары
+-- остаются в таблице и не попадают в выборки.
+ALTER TABLE product
+    ADD COLUMN updatedAt TIMESTAMP,
+    ADD COLUMN updatedBy UUID,
+    ADD COLUMN deletedAt TIMESTAMP,
+    ADD COLUMN deletedBy UUID;
+
+-- Удалённый товар не занимает штрихкод в приёмке
+DROP INDEX product_reception_barcode;
+CREATE UNIQUE INDEX product_reception_barcode ON product (receptionId, barcode)
+    WHERE barcode IS NOT NULL AND deletedAt IS NULL;
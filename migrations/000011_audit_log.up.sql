@@ -0,0 +1,18 @@
+-- Журнал изменений: кто, когда и что сделал с сущностью. before и after -
+-- JSON-снимки сущности до и после изменения, пусты при создании и удалении.
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actorId UUID,
+    actorRole VARCHAR(32) NOT NULL DEFAULT '',
+    action VARCHAR(64) NOT NULL,
+    entityType VARCHAR(32) NOT NULL,
+    entityId VARCHAR(256) NOT NULL,
+    before JSONB,
+    after JSONB,
+    requestId VARCHAR(128) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_log_created_at ON audit_log (createdAt DESC, id DESC);
+CREATE INDEX audit_log_entity ON audit_log (entityType, entityId, createdAt DESC);
+CREATE INDEX audit_log_actor ON audit_log (actorId, createdAt DESC);
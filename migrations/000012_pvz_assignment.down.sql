@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pvz_assignment;
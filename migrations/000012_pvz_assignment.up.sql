@@ -0,0 +1,9 @@
+-- ПВЗ, на которых может работать сотрудник. Модераторы работают со всеми ПВЗ.
+CREATE TABLE pvz_assignment (
+    userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pvzId UUID NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (userId, pvzId)
+);
+
+CREATE INDEX pvz_assignment_pvz ON pvz_assignment (pvzId);
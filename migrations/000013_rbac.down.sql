@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS role_permission;
+
+-- NOT VALID: пользователи новых ролей остаются, проверяются только новые записи
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('employee', 'moderator')) NOT VALID;
@@ -0,0 +1,41 @@
+-- Допустимые роли теперь задаёт политика RBAC (internal/rbac), а не CHECK
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+-- Права ролей для rbac.source = db. Разрешение "*" даёт доступ ко всему.
+CREATE TABLE role_permission (
+    role VARCHAR(64) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO role_permission (role, permission) VALUES
+    ('employee', 'pvz:read'),
+    ('employee', 'reception:create'),
+    ('employee', 'reception:read'),
+    ('employee', 'reception:close'),
+    ('employee', 'product:create'),
+    ('employee', 'product:read'),
+    ('employee', 'product:update'),
+    ('employee', 'product:delete'),
+    ('employee', 'catalog:read'),
+    ('employee', 'session:logout'),
+    ('moderator', 'pvz:create'),
+    ('moderator', 'pvz:read'),
+    ('moderator', 'pvz:update'),
+    ('moderator', 'pvz:delete'),
+    ('moderator', 'reception:read'),
+    ('moderator', 'product:read'),
+    ('moderator', 'catalog:read'),
+    ('moderator', 'catalog:manage'),
+    ('moderator', 'audit:read'),
+    ('moderator', 'assignment:read'),
+    ('moderator', 'assignment:manage'),
+    ('moderator', 'session:logout'),
+    ('admin', '*'),
+    ('auditor', 'pvz:read'),
+    ('auditor', 'reception:read'),
+    ('auditor', 'product:read'),
+    ('auditor', 'catalog:read'),
+    ('auditor', 'audit:read'),
+    ('auditor', 'assignment:read'),
+    ('auditor', 'session:logout');
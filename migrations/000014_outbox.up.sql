@@ -0,0 +1,18 @@
+-- Доменные события для внешних систем (transactional outbox). Событие пишется
+-- в транзакции изменения, фоновая задача публикует его и проставляет publishedAt.
+-- pvzId без внешнего ключа: события удалённого ПВЗ тоже должны дойти.
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    eventId UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(),
+    eventType VARCHAR(64) NOT NULL,
+    pvzId UUID NOT NULL,
+    payload JSONB NOT NULL,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    nextAttemptAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lastError TEXT,
+    publishedAt TIMESTAMP
+);
+
+CREATE INDEX outbox_pending ON outbox (pvzId, id) WHERE publishedAt IS NULL;
+CREATE INDEX outbox_published ON outbox (publishedAt) WHERE publishedAt IS NOT NULL;
@@ -0,0 +1,3 @@
+DELETE FROM role_permission WHERE permission IN ('webhook:read', 'webhook:manage');
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_subscription;
@@ -0,0 +1,40 @@
+-- Подписки партнёров на доменные события. Пустой eventTypes - все типы,
+-- пустой pvzId - все ПВЗ. secret хранится открыто: им подписываются запросы.
+CREATE TABLE webhook_subscription (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    eventTypes TEXT[] NOT NULL DEFAULT '{}',
+    pvzId UUID REFERENCES pvz(id) ON DELETE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Доставки событий подписчикам. Строка создаётся в транзакции изменения вместе
+-- с событием outbox; после maxAttempts неудачных попыток статус становится dead.
+CREATE TABLE webhook_delivery (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscriptionId UUID NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+    eventId UUID NOT NULL,
+    eventType VARCHAR(64) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    nextAttemptAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lastStatusCode INT,
+    lastError TEXT,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deliveredAt TIMESTAMP,
+    UNIQUE (subscriptionId, eventId)
+);
+
+CREATE INDEX webhook_delivery_pending ON webhook_delivery (nextAttemptAt) WHERE status = 'pending';
+CREATE INDEX webhook_delivery_subscription ON webhook_delivery (subscriptionId, createdAt DESC);
+
+-- Права на подписки для rbac.source = db, как во встроенной политике
+INSERT INTO role_permission (role, permission) VALUES
+    ('moderator', 'webhook:read'),
+    ('moderator', 'webhook:manage'),
+    ('auditor', 'webhook:read')
+ON CONFLICT DO NOTHING;
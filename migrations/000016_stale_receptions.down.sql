@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS product_reception;
+
+ALTER TABLE reception
+    DROP COLUMN IF EXISTS staleReason,
+    DROP COLUMN IF EXISTS staleAt;
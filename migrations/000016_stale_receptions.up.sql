@@ -0,0 +1,8 @@
+-- Приёмка, в которой дольше порога не было активности, закрывается или
+-- помечается фоновой задачей. staleAt - когда это произошло, staleReason - почему.
+ALTER TABLE reception
+    ADD COLUMN staleAt TIMESTAMP,
+    ADD COLUMN staleReason TEXT;
+
+-- Последняя активность приёмки считается по её товарам
+CREATE INDEX product_reception ON product (receptionId);
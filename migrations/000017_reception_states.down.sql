@@ -0,0 +1,16 @@
+DELETE FROM role_permission WHERE permission IN ('reception:cancel', 'reception:verify', 'reception:reopen');
+
+ALTER TABLE reception
+    DROP COLUMN IF EXISTS reopenedBy,
+    DROP COLUMN IF EXISTS reopenedAt,
+    DROP COLUMN IF EXISTS cancelledBy,
+    DROP COLUMN IF EXISTS cancelledAt,
+    DROP COLUMN IF EXISTS verifiedBy,
+    DROP COLUMN IF EXISTS verifiedAt,
+    DROP COLUMN IF EXISTS closedBy,
+    DROP COLUMN IF EXISTS closedAt;
+
+UPDATE reception SET status = 'close' WHERE status IN ('verified', 'cancelled');
+ALTER TABLE reception DROP CONSTRAINT IF EXISTS reception_status_check;
+ALTER TABLE reception
+    ADD CONSTRAINT reception_status_check CHECK (status IN ('in_progress', 'close'));
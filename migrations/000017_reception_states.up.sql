@@ -0,0 +1,26 @@
+-- Статусы приёмки: in_progress -> close -> verified, in_progress -> cancelled,
+-- close -> in_progress (повторное открытие). Переходы проверяет сервис.
+ALTER TABLE reception DROP CONSTRAINT IF EXISTS reception_status_check;
+ALTER TABLE reception
+    ADD CONSTRAINT reception_status_check CHECK (status IN ('in_progress', 'close', 'verified', 'cancelled'));
+
+-- Время и автор каждого перехода. Автор пуст, если переход сделала фоновая задача.
+ALTER TABLE reception
+    ADD COLUMN closedAt TIMESTAMP,
+    ADD COLUMN closedBy UUID,
+    ADD COLUMN verifiedAt TIMESTAMP,
+    ADD COLUMN verifiedBy UUID,
+    ADD COLUMN cancelledAt TIMESTAMP,
+    ADD COLUMN cancelledBy UUID,
+    ADD COLUMN reopenedAt TIMESTAMP,
+    ADD COLUMN reopenedBy UUID;
+
+-- Для приёмок, закрытых до миграции, время закрытия известно только у автозакрытых
+UPDATE reception SET closedAt = staleAt WHERE status = 'close' AND staleAt IS NOT NULL;
+
+-- Права на переходы для rbac.source = db, как во встроенной политике
+INSERT INTO role_permission (role, permission) VALUES
+    ('employee', 'reception:cancel'),
+    ('moderator', 'reception:verify'),
+    ('moderator', 'reception:reopen')
+ON CONFLICT DO NOTHING;
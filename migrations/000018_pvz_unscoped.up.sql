@@ -0,0 +1,4 @@
+-- Работа с любым ПВЗ без назначения теперь задаётся правом, а не ролью
+INSERT INTO role_permission (role, permission) VALUES
+    ('moderator', 'pvz:unscoped')
+ON CONFLICT DO NOTHING;
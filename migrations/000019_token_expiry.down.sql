@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS revoked_token_expires_at;
+DROP INDEX IF EXISTS refresh_token_expires_at;
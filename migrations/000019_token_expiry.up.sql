@@ -0,0 +1,3 @@
+-- Фоновая задача удаляет истёкшие токены по expiresAt
+CREATE INDEX refresh_token_expires_at ON refresh_token (expiresAt);
+CREATE INDEX revoked_token_expires_at ON revoked_token (expiresAt);
@@ -2,10 +2,12 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
+	"pvz/internal/repository"
 	"pvz/internal/repository/model"
 )
 
@@ -23,6 +25,55 @@ func (m *MockUserPostgres) GetUserByEmail(ctx context.Context, email string) (mo
 	return args.Get(0).(model.User), args.Error(1)
 }
 
+func (m *MockUserPostgres) GetUserById(ctx context.Context, id uuid.UUID) (model.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) CreateRefreshToken(ctx context.Context, token model.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(model.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTokenRepository) DeleteExpiredRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeRefreshToken(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) error {
+	args := m.Called(ctx, id, replacedBy)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeTokenFamily(ctx context.Context, familyId uuid.UUID) error {
+	args := m.Called(ctx, familyId)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	args := m.Called(ctx, jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
 type MockPvzRepository struct {
 	mock.Mock
 }
@@ -32,14 +83,47 @@ func (m *MockPvzRepository) CreatePvz(ctx context.Context, city string) (model.P
 	return args.Get(0).(model.Pvz), args.Error(1)
 }
 
-func (m *MockPvzRepository) GetPvzListByReceptionDate(ctx context.Context, limit, offset int, startDate, endDate *time.Time) ([]model.Pvz, error) {
-	args := m.Called(ctx, limit, offset, startDate, endDate)
+func (m *MockPvzRepository) GetPvzById(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	args := m.Called(ctx, pvzId)
+	return args.Get(0).(model.Pvz), args.Error(1)
+}
+
+func (m *MockPvzRepository) GetPvzListByReceptionDate(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]model.Pvz, error) {
+	args := m.Called(ctx, limit, offset, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]model.Pvz), args.Error(1)
 }
 
+func (m *MockPvzRepository) GetPvzListWithReceptions(ctx context.Context, limit, offset int, filter model.PvzFilter) ([]model.PvzWithReceptions, error) {
+	args := m.Called(ctx, limit, offset, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PvzWithReceptions), args.Error(1)
+}
+
+func (m *MockPvzRepository) UpdatePvz(ctx context.Context, pvzId uuid.UUID, update model.PvzUpdate) (model.Pvz, error) {
+	args := m.Called(ctx, pvzId, update)
+	return args.Get(0).(model.Pvz), args.Error(1)
+}
+
+func (m *MockPvzRepository) SetPvzStatus(ctx context.Context, pvzId uuid.UUID, status string) (model.Pvz, error) {
+	args := m.Called(ctx, pvzId, status)
+	return args.Get(0).(model.Pvz), args.Error(1)
+}
+
+func (m *MockPvzRepository) DeletePvz(ctx context.Context, pvzId uuid.UUID) error {
+	args := m.Called(ctx, pvzId)
+	return args.Error(0)
+}
+
+func (m *MockPvzRepository) LockPvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	args := m.Called(ctx, pvzId)
+	return args.Get(0).(model.Pvz), args.Error(1)
+}
+
 type MockReceptionRepository struct {
 	mock.Mock
 }
@@ -62,9 +146,50 @@ func (m *MockReceptionRepository) GetInProgressReception(ctx context.Context, pv
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockReceptionRepository) CloseReception(ctx context.Context, pvzId uuid.UUID) error {
+func (m *MockReceptionRepository) HasReceptions(ctx context.Context, pvzId uuid.UUID) (bool, error) {
 	args := m.Called(ctx, pvzId)
-	return args.Error(0)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetReceptionById(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	args := m.Called(ctx, receptionId)
+	return args.Get(0).(model.Reception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetReceptionList(ctx context.Context, pvzId uuid.UUID, limit, offset int, filter model.ReceptionFilter) ([]model.Reception, error) {
+	args := m.Called(ctx, pvzId, limit, offset, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Reception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) ReceptionClosedWithin(ctx context.Context, receptionId uuid.UUID, window time.Duration) (bool, error) {
+	args := m.Called(ctx, receptionId, window)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReceptionRepository) UpdateReceptionStatus(ctx context.Context, change model.ReceptionStatusChange) (model.Reception, error) {
+	args := m.Called(ctx, change)
+	return args.Get(0).(model.Reception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) TryLockStaleReceptions(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetStaleReceptions(ctx context.Context, idleFor time.Duration, limit int) ([]model.StaleReception, error) {
+	args := m.Called(ctx, idleFor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.StaleReception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) MarkReceptionStale(ctx context.Context, receptionId uuid.UUID, idleFor time.Duration, reason string, closeReception bool) (model.Reception, error) {
+	args := m.Called(ctx, receptionId, idleFor, reason, closeReception)
+	return args.Get(0).(model.Reception), args.Error(1)
 }
 
 type MockProductRepository struct {
@@ -79,17 +204,247 @@ func (m *MockProductRepository) GetProductsByReceptionID(ctx context.Context, re
 	return args.Get(0).([]model.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) GetProductsByBarcode(ctx context.Context, barcode string) ([]model.ProductWithPvz, error) {
+	args := m.Called(ctx, barcode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProductWithPvz), args.Error(1)
+}
+
 func (m *MockProductRepository) CreateProduct(ctx context.Context, product model.Product) (model.Product, error) {
 	args := m.Called(ctx, product)
 	return args.Get(0).(model.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) CreateProducts(ctx context.Context, receptionId uuid.UUID, products []model.Product) ([]model.Product, error) {
+	args := m.Called(ctx, receptionId, products)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Product), args.Error(1)
+}
+
 func (m *MockProductRepository) GetLastProductIdByReception(ctx context.Context, receptionId uuid.UUID) (uuid.UUID, error) {
 	args := m.Called(ctx, receptionId)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockProductRepository) DeleteProductById(ctx context.Context, productId uuid.UUID) error {
+func (m *MockProductRepository) GetProductById(ctx context.Context, productId uuid.UUID) (model.ProductWithPvz, error) {
 	args := m.Called(ctx, productId)
+	return args.Get(0).(model.ProductWithPvz), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateProduct(ctx context.Context, productId uuid.UUID, update model.ProductUpdate, updatedBy uuid.UUID) (model.Product, error) {
+	args := m.Called(ctx, productId, update, updatedBy)
+	return args.Get(0).(model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) DeleteProductById(ctx context.Context, productId uuid.UUID, deletedBy uuid.UUID) error {
+	args := m.Called(ctx, productId, deletedBy)
 	return args.Error(0)
 }
+
+type MockCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *MockCatalogRepository) ListCatalog(ctx context.Context, kind model.CatalogKind) ([]model.CatalogItem, error) {
+	args := m.Called(ctx, kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CatalogItem), args.Error(1)
+}
+
+func (m *MockCatalogRepository) AddCatalogItem(ctx context.Context, kind model.CatalogKind, name string) (model.CatalogItem, error) {
+	args := m.Called(ctx, kind, name)
+	return args.Get(0).(model.CatalogItem), args.Error(1)
+}
+
+func (m *MockCatalogRepository) SetCatalogItemActive(ctx context.Context, kind model.CatalogKind, name string, active bool) (model.CatalogItem, error) {
+	args := m.Called(ctx, kind, name, active)
+	return args.Get(0).(model.CatalogItem), args.Error(1)
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) CreateAuditEntry(ctx context.Context, entry model.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) GetAuditLog(ctx context.Context, limit, offset int, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	args := m.Called(ctx, limit, offset, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AuditEntry), args.Error(1)
+}
+
+type MockAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentRepository) AssignPvz(ctx context.Context, userId, pvzId uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userId, pvzId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) UnassignPvz(ctx context.Context, userId, pvzId uuid.UUID) error {
+	args := m.Called(ctx, userId, pvzId)
+	return args.Error(0)
+}
+
+func (m *MockAssignmentRepository) GetAssignedPvzIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) IsPvzAssigned(ctx context.Context, userId, pvzId uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userId, pvzId)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) CreateOutboxEvent(ctx context.Context, eventType string, pvzId uuid.UUID, payload json.RawMessage) (model.Event, error) {
+	args := m.Called(ctx, eventType, pvzId, payload)
+	return args.Get(0).(model.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) DelayOutboxEvents(ctx context.Context, seqs []int64, delay time.Duration) error {
+	args := m.Called(ctx, seqs, delay)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) TryLockOutbox(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) GetPendingOutboxEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkOutboxEventPublished(ctx context.Context, seq int64) error {
+	args := m.Called(ctx, seq)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkOutboxEventFailed(ctx context.Context, seq int64, lastError string, retryAfter time.Duration) error {
+	args := m.Called(ctx, seq, lastError, retryAfter)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) DeletePublishedOutboxEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	args := m.Called(ctx, retention)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookSubscriptionById(ctx context.Context, id uuid.UUID) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateWebhookSubscription(ctx context.Context, id uuid.UUID, update model.WebhookSubscriptionUpdate) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id, update)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CreateWebhookDeliveries(ctx context.Context, event model.Event, body string) (int64, error) {
+	args := m.Called(ctx, event, body)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, lease time.Duration, limit int) ([]model.PendingWebhookDelivery, error) {
+	args := m.Called(ctx, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PendingWebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) SaveWebhookAttempt(ctx context.Context, id uuid.UUID, attempt model.WebhookAttempt) error {
+	args := m.Called(ctx, id, attempt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetWebhookDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit, offset int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionId, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) AcquireIdempotencyKey(ctx context.Context, key model.IdempotencyKey, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, key, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) (model.IdempotencyKey, error) {
+	args := m.Called(ctx, userId, key)
+	return args.Get(0).(model.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) SaveIdempotentResponse(ctx context.Context, userId uuid.UUID, key string, statusCode int, body []byte) error {
+	args := m.Called(ctx, userId, key, statusCode, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) error {
+	args := m.Called(ctx, userId, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockUnitOfWork выполняет fn без транзакции, передавая в неё заданные репозитории
+type MockUnitOfWork struct {
+	Repos *repository.Repository
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repository) error) error {
+	return fn(m.Repos)
+}
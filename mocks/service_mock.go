@@ -3,10 +3,11 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
-	time "time"
 
 	response "pvz/internal/api/response"
+	live "pvz/internal/live"
 	model "pvz/internal/repository/model"
+	service "pvz/internal/service"
 
 	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
@@ -51,26 +52,55 @@ func (mr *MockUserMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUser)(nil).CreateUser), ctx, user)
 }
 
+// CreateUserWithRole mocks base method.
+func (m *MockUser) CreateUserWithRole(ctx context.Context, user model.User) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserWithRole", ctx, user)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserWithRole indicates an expected call of CreateUserWithRole.
+func (mr *MockUserMockRecorder) CreateUserWithRole(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithRole", reflect.TypeOf((*MockUser)(nil).CreateUserWithRole), ctx, user)
+}
+
+// DeleteExpiredTokens mocks base method.
+func (m *MockUser) DeleteExpiredTokens(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTokens", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredTokens indicates an expected call of DeleteExpiredTokens.
+func (mr *MockUserMockRecorder) DeleteExpiredTokens(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokens", reflect.TypeOf((*MockUser)(nil).DeleteExpiredTokens), ctx)
+}
+
 // DummyLogin mocks base method.
-func (m *MockUser) DummyLogin(ctx context.Context, role string) (string, error) {
+func (m *MockUser) DummyLogin(ctx context.Context, role string, pvzScope []uuid.UUID) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DummyLogin", ctx, role)
+	ret := m.ctrl.Call(m, "DummyLogin", ctx, role, pvzScope)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DummyLogin indicates an expected call of DummyLogin.
-func (mr *MockUserMockRecorder) DummyLogin(ctx, role any) *gomock.Call {
+func (mr *MockUserMockRecorder) DummyLogin(ctx, role, pvzScope any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DummyLogin", reflect.TypeOf((*MockUser)(nil).DummyLogin), ctx, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DummyLogin", reflect.TypeOf((*MockUser)(nil).DummyLogin), ctx, role, pvzScope)
 }
 
 // LoginUser mocks base method.
-func (m *MockUser) LoginUser(ctx context.Context, email, password string) (string, error) {
+func (m *MockUser) LoginUser(ctx context.Context, email, password string) (model.TokenPair, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "LoginUser", ctx, email, password)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(model.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -81,6 +111,74 @@ func (mr *MockUserMockRecorder) LoginUser(ctx, email, password any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockUser)(nil).LoginUser), ctx, email, password)
 }
 
+// Logout mocks base method.
+func (m *MockUser) Logout(ctx context.Context, claims *model.TokenClaims, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, claims, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUserMockRecorder) Logout(ctx, claims, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUser)(nil).Logout), ctx, claims, refreshToken)
+}
+
+// RefreshTokens mocks base method.
+func (m *MockUser) RefreshTokens(ctx context.Context, refreshToken string) (model.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", ctx, refreshToken)
+	ret0, _ := ret[0].(model.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens.
+func (mr *MockUserMockRecorder) RefreshTokens(ctx, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockUser)(nil).RefreshTokens), ctx, refreshToken)
+}
+
+// MockRevocation is a mock of Revocation interface.
+type MockRevocation struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevocationMockRecorder
+	isgomock struct{}
+}
+
+// MockRevocationMockRecorder is the mock recorder for MockRevocation.
+type MockRevocationMockRecorder struct {
+	mock *MockRevocation
+}
+
+// NewMockRevocation creates a new mock instance.
+func NewMockRevocation(ctrl *gomock.Controller) *MockRevocation {
+	mock := &MockRevocation{ctrl: ctrl}
+	mock.recorder = &MockRevocationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevocation) EXPECT() *MockRevocationMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockRevocation) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockRevocationMockRecorder) IsRevoked(ctx, jti any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockRevocation)(nil).IsRevoked), ctx, jti)
+}
+
 // MockPvz is a mock of Pvz interface.
 type MockPvz struct {
 	ctrl     *gomock.Controller
@@ -105,6 +203,21 @@ func (m *MockPvz) EXPECT() *MockPvzMockRecorder {
 	return m.recorder
 }
 
+// ActivatePvz mocks base method.
+func (m *MockPvz) ActivatePvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivatePvz", ctx, pvzId)
+	ret0, _ := ret[0].(model.Pvz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivatePvz indicates an expected call of ActivatePvz.
+func (mr *MockPvzMockRecorder) ActivatePvz(ctx, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivatePvz", reflect.TypeOf((*MockPvz)(nil).ActivatePvz), ctx, pvzId)
+}
+
 // CreatePvz mocks base method.
 func (m *MockPvz) CreatePvz(ctx context.Context, pvz model.Pvz) (model.Pvz, error) {
 	m.ctrl.T.Helper()
@@ -120,19 +233,93 @@ func (mr *MockPvzMockRecorder) CreatePvz(ctx, pvz any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePvz", reflect.TypeOf((*MockPvz)(nil).CreatePvz), ctx, pvz)
 }
 
+// DeactivatePvz mocks base method.
+func (m *MockPvz) DeactivatePvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivatePvz", ctx, pvzId)
+	ret0, _ := ret[0].(model.Pvz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivatePvz indicates an expected call of DeactivatePvz.
+func (mr *MockPvzMockRecorder) DeactivatePvz(ctx, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivatePvz", reflect.TypeOf((*MockPvz)(nil).DeactivatePvz), ctx, pvzId)
+}
+
+// DeletePvz mocks base method.
+func (m *MockPvz) DeletePvz(ctx context.Context, pvzId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePvz", ctx, pvzId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePvz indicates an expected call of DeletePvz.
+func (mr *MockPvzMockRecorder) DeletePvz(ctx, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePvz", reflect.TypeOf((*MockPvz)(nil).DeletePvz), ctx, pvzId)
+}
+
+// GetPvz mocks base method.
+func (m *MockPvz) GetPvz(ctx context.Context, pvzId uuid.UUID) (model.Pvz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPvz", ctx, pvzId)
+	ret0, _ := ret[0].(model.Pvz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPvz indicates an expected call of GetPvz.
+func (mr *MockPvzMockRecorder) GetPvz(ctx, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPvz", reflect.TypeOf((*MockPvz)(nil).GetPvz), ctx, pvzId)
+}
+
 // GetPvzList mocks base method.
-func (m *MockPvz) GetPvzList(ctx context.Context, limit, offset int, startDate, endDate *time.Time) ([]response.PvzFullResponse, error) {
+func (m *MockPvz) GetPvzList(ctx context.Context, limit int, offset int, filter model.PvzFilter) ([]response.PvzFullResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPvzList", ctx, limit, offset, startDate, endDate)
+	ret := m.ctrl.Call(m, "GetPvzList", ctx, limit, offset, filter)
 	ret0, _ := ret[0].([]response.PvzFullResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPvzList indicates an expected call of GetPvzList.
-func (mr *MockPvzMockRecorder) GetPvzList(ctx, limit, offset, startDate, endDate any) *gomock.Call {
+func (mr *MockPvzMockRecorder) GetPvzList(ctx, limit, offset, filter any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPvzList", reflect.TypeOf((*MockPvz)(nil).GetPvzList), ctx, limit, offset, startDate, endDate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPvzList", reflect.TypeOf((*MockPvz)(nil).GetPvzList), ctx, limit, offset, filter)
+}
+
+// GetPvzPage mocks base method.
+func (m *MockPvz) GetPvzPage(ctx context.Context, limit int, cursor string, filter model.PvzFilter) (response.PvzPageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPvzPage", ctx, limit, cursor, filter)
+	ret0, _ := ret[0].(response.PvzPageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPvzPage indicates an expected call of GetPvzPage.
+func (mr *MockPvzMockRecorder) GetPvzPage(ctx, limit, cursor, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPvzPage", reflect.TypeOf((*MockPvz)(nil).GetPvzPage), ctx, limit, cursor, filter)
+}
+
+// UpdatePvz mocks base method.
+func (m *MockPvz) UpdatePvz(ctx context.Context, pvzId uuid.UUID, update model.PvzUpdate) (model.Pvz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePvz", ctx, pvzId, update)
+	ret0, _ := ret[0].(model.Pvz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePvz indicates an expected call of UpdatePvz.
+func (mr *MockPvzMockRecorder) UpdatePvz(ctx, pvzId, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePvz", reflect.TypeOf((*MockPvz)(nil).UpdatePvz), ctx, pvzId, update)
 }
 
 // MockReception is a mock of Reception interface.
@@ -159,6 +346,21 @@ func (m *MockReception) EXPECT() *MockReceptionMockRecorder {
 	return m.recorder
 }
 
+// CancelReception mocks base method.
+func (m *MockReception) CancelReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReception", ctx, receptionId)
+	ret0, _ := ret[0].(model.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelReception indicates an expected call of CancelReception.
+func (mr *MockReceptionMockRecorder) CancelReception(ctx, receptionId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReception", reflect.TypeOf((*MockReception)(nil).CancelReception), ctx, receptionId)
+}
+
 // CloseReception mocks base method.
 func (m *MockReception) CloseReception(ctx context.Context, pvzId uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -173,6 +375,21 @@ func (mr *MockReceptionMockRecorder) CloseReception(ctx, pvzId any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockReception)(nil).CloseReception), ctx, pvzId)
 }
 
+// CloseReceptionById mocks base method.
+func (m *MockReception) CloseReceptionById(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseReceptionById", ctx, receptionId)
+	ret0, _ := ret[0].(model.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseReceptionById indicates an expected call of CloseReceptionById.
+func (mr *MockReceptionMockRecorder) CloseReceptionById(ctx, receptionId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReceptionById", reflect.TypeOf((*MockReception)(nil).CloseReceptionById), ctx, receptionId)
+}
+
 // CreateReception mocks base method.
 func (m *MockReception) CreateReception(ctx context.Context, pvzId uuid.UUID) (model.Reception, error) {
 	m.ctrl.T.Helper()
@@ -188,6 +405,81 @@ func (mr *MockReceptionMockRecorder) CreateReception(ctx, pvzId any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReception", reflect.TypeOf((*MockReception)(nil).CreateReception), ctx, pvzId)
 }
 
+// GetCurrentReception mocks base method.
+func (m *MockReception) GetCurrentReception(ctx context.Context, pvzId uuid.UUID) (model.ReceptionWithProducts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentReception", ctx, pvzId)
+	ret0, _ := ret[0].(model.ReceptionWithProducts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentReception indicates an expected call of GetCurrentReception.
+func (mr *MockReceptionMockRecorder) GetCurrentReception(ctx, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentReception", reflect.TypeOf((*MockReception)(nil).GetCurrentReception), ctx, pvzId)
+}
+
+// GetReception mocks base method.
+func (m *MockReception) GetReception(ctx context.Context, receptionId uuid.UUID) (model.ReceptionWithProducts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReception", ctx, receptionId)
+	ret0, _ := ret[0].(model.ReceptionWithProducts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReception indicates an expected call of GetReception.
+func (mr *MockReceptionMockRecorder) GetReception(ctx, receptionId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReception", reflect.TypeOf((*MockReception)(nil).GetReception), ctx, receptionId)
+}
+
+// GetReceptionList mocks base method.
+func (m *MockReception) GetReceptionList(ctx context.Context, pvzId uuid.UUID, limit int, offset int, filter model.ReceptionFilter) ([]model.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionList", ctx, pvzId, limit, offset, filter)
+	ret0, _ := ret[0].([]model.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionList indicates an expected call of GetReceptionList.
+func (mr *MockReceptionMockRecorder) GetReceptionList(ctx, pvzId, limit, offset, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionList", reflect.TypeOf((*MockReception)(nil).GetReceptionList), ctx, pvzId, limit, offset, filter)
+}
+
+// ReopenReception mocks base method.
+func (m *MockReception) ReopenReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenReception", ctx, receptionId)
+	ret0, _ := ret[0].(model.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReopenReception indicates an expected call of ReopenReception.
+func (mr *MockReceptionMockRecorder) ReopenReception(ctx, receptionId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenReception", reflect.TypeOf((*MockReception)(nil).ReopenReception), ctx, receptionId)
+}
+
+// VerifyReception mocks base method.
+func (m *MockReception) VerifyReception(ctx context.Context, receptionId uuid.UUID) (model.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyReception", ctx, receptionId)
+	ret0, _ := ret[0].(model.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyReception indicates an expected call of VerifyReception.
+func (mr *MockReceptionMockRecorder) VerifyReception(ctx, receptionId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyReception", reflect.TypeOf((*MockReception)(nil).VerifyReception), ctx, receptionId)
+}
+
 // MockProduct is a mock of Product interface.
 type MockProduct struct {
 	ctrl     *gomock.Controller
@@ -213,30 +505,525 @@ func (m *MockProduct) EXPECT() *MockProductMockRecorder {
 }
 
 // AddProduct mocks base method.
-func (m *MockProduct) AddProduct(ctx context.Context, pvzId uuid.UUID, productType string) (model.Product, error) {
+func (m *MockProduct) AddProduct(ctx context.Context, pvzId uuid.UUID, product model.Product) (model.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProduct", ctx, pvzId, productType)
+	ret := m.ctrl.Call(m, "AddProduct", ctx, pvzId, product)
 	ret0, _ := ret[0].(model.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddProduct indicates an expected call of AddProduct.
-func (mr *MockProductMockRecorder) AddProduct(ctx, pvzId, productType any) *gomock.Call {
+func (mr *MockProductMockRecorder) AddProduct(ctx, pvzId, product any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProduct)(nil).AddProduct), ctx, pvzId, product)
+}
+
+// AddProducts mocks base method.
+func (m *MockProduct) AddProducts(ctx context.Context, pvzId uuid.UUID, products []model.Product) ([]model.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProducts", ctx, pvzId, products)
+	ret0, _ := ret[0].([]model.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProducts indicates an expected call of AddProducts.
+func (mr *MockProductMockRecorder) AddProducts(ctx, pvzId, products any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProduct)(nil).AddProduct), ctx, pvzId, productType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProducts", reflect.TypeOf((*MockProduct)(nil).AddProducts), ctx, pvzId, products)
 }
 
 // DeleteLastProduct mocks base method.
-func (m *MockProduct) DeleteLastProduct(ctx context.Context, pvzId uuid.UUID) error {
+func (m *MockProduct) DeleteLastProduct(ctx context.Context, pvzId uuid.UUID, userId uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteLastProduct", ctx, pvzId)
+	ret := m.ctrl.Call(m, "DeleteLastProduct", ctx, pvzId, userId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteLastProduct indicates an expected call of DeleteLastProduct.
-func (mr *MockProductMockRecorder) DeleteLastProduct(ctx, pvzId any) *gomock.Call {
+func (mr *MockProductMockRecorder) DeleteLastProduct(ctx, pvzId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLastProduct", reflect.TypeOf((*MockProduct)(nil).DeleteLastProduct), ctx, pvzId, userId)
+}
+
+// DeleteProduct mocks base method.
+func (m *MockProduct) DeleteProduct(ctx context.Context, productId uuid.UUID, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockProductMockRecorder) DeleteProduct(ctx, productId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProduct)(nil).DeleteProduct), ctx, productId, userId)
+}
+
+// FindProductsByBarcode mocks base method.
+func (m *MockProduct) FindProductsByBarcode(ctx context.Context, barcode string) ([]model.ProductWithPvz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductsByBarcode", ctx, barcode)
+	ret0, _ := ret[0].([]model.ProductWithPvz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductsByBarcode indicates an expected call of FindProductsByBarcode.
+func (mr *MockProductMockRecorder) FindProductsByBarcode(ctx, barcode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductsByBarcode", reflect.TypeOf((*MockProduct)(nil).FindProductsByBarcode), ctx, barcode)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockProduct) UpdateProduct(ctx context.Context, productId uuid.UUID, update model.ProductUpdate, userId uuid.UUID) (model.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, productId, update, userId)
+	ret0, _ := ret[0].(model.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockProductMockRecorder) UpdateProduct(ctx, productId, update, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, productId, update, userId)
+}
+
+// MockCatalog is a mock of Catalog interface.
+type MockCatalog struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogMockRecorder
+	isgomock struct{}
+}
+
+// MockCatalogMockRecorder is the mock recorder for MockCatalog.
+type MockCatalogMockRecorder struct {
+	mock *MockCatalog
+}
+
+// NewMockCatalog creates a new mock instance.
+func NewMockCatalog(ctrl *gomock.Controller) *MockCatalog {
+	mock := &MockCatalog{ctrl: ctrl}
+	mock.recorder = &MockCatalogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalog) EXPECT() *MockCatalogMockRecorder {
+	return m.recorder
+}
+
+// AddCatalogItem mocks base method.
+func (m *MockCatalog) AddCatalogItem(ctx context.Context, kind model.CatalogKind, name string) (model.CatalogItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCatalogItem", ctx, kind, name)
+	ret0, _ := ret[0].(model.CatalogItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCatalogItem indicates an expected call of AddCatalogItem.
+func (mr *MockCatalogMockRecorder) AddCatalogItem(ctx, kind, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCatalogItem", reflect.TypeOf((*MockCatalog)(nil).AddCatalogItem), ctx, kind, name)
+}
+
+// ListCatalog mocks base method.
+func (m *MockCatalog) ListCatalog(ctx context.Context, kind model.CatalogKind) ([]model.CatalogItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCatalog", ctx, kind)
+	ret0, _ := ret[0].([]model.CatalogItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCatalog indicates an expected call of ListCatalog.
+func (mr *MockCatalogMockRecorder) ListCatalog(ctx, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCatalog", reflect.TypeOf((*MockCatalog)(nil).ListCatalog), ctx, kind)
+}
+
+// SetCatalogItemActive mocks base method.
+func (m *MockCatalog) SetCatalogItemActive(ctx context.Context, kind model.CatalogKind, name string, active bool) (model.CatalogItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCatalogItemActive", ctx, kind, name, active)
+	ret0, _ := ret[0].(model.CatalogItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCatalogItemActive indicates an expected call of SetCatalogItemActive.
+func (mr *MockCatalogMockRecorder) SetCatalogItemActive(ctx, kind, name, active any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCatalogItemActive", reflect.TypeOf((*MockCatalog)(nil).SetCatalogItemActive), ctx, kind, name, active)
+}
+
+// Validate mocks base method.
+func (m *MockCatalog) Validate(ctx context.Context, kind model.CatalogKind, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, kind, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockCatalogMockRecorder) Validate(ctx, kind, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockCatalog)(nil).Validate), ctx, kind, value)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+	isgomock struct{}
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// AcquireIdempotencyKey mocks base method.
+func (m *MockIdempotency) AcquireIdempotencyKey(ctx context.Context, userId uuid.UUID, key string, requestHash string) (*service.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireIdempotencyKey", ctx, userId, key, requestHash)
+	ret0, _ := ret[0].(*service.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireIdempotencyKey indicates an expected call of AcquireIdempotencyKey.
+func (mr *MockIdempotencyMockRecorder) AcquireIdempotencyKey(ctx, userId, key, requestHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireIdempotencyKey", reflect.TypeOf((*MockIdempotency)(nil).AcquireIdempotencyKey), ctx, userId, key, requestHash)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockIdempotency) DeleteExpiredIdempotencyKeys(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockIdempotencyMockRecorder) DeleteExpiredIdempotencyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockIdempotency)(nil).DeleteExpiredIdempotencyKeys), ctx)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockIdempotency) ReleaseIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, userId, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockIdempotencyMockRecorder) ReleaseIdempotencyKey(ctx, userId, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockIdempotency)(nil).ReleaseIdempotencyKey), ctx, userId, key)
+}
+
+// SaveIdempotentResponse mocks base method.
+func (m *MockIdempotency) SaveIdempotentResponse(ctx context.Context, userId uuid.UUID, key string, statusCode int, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotentResponse", ctx, userId, key, statusCode, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotentResponse indicates an expected call of SaveIdempotentResponse.
+func (mr *MockIdempotencyMockRecorder) SaveIdempotentResponse(ctx, userId, key, statusCode, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotentResponse", reflect.TypeOf((*MockIdempotency)(nil).SaveIdempotentResponse), ctx, userId, key, statusCode, body)
+}
+
+// MockAudit is a mock of Audit interface.
+type MockAudit struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditMockRecorder is the mock recorder for MockAudit.
+type MockAuditMockRecorder struct {
+	mock *MockAudit
+}
+
+// NewMockAudit creates a new mock instance.
+func NewMockAudit(ctrl *gomock.Controller) *MockAudit {
+	mock := &MockAudit{ctrl: ctrl}
+	mock.recorder = &MockAuditMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudit) EXPECT() *MockAuditMockRecorder {
+	return m.recorder
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAudit) GetAuditLog(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", ctx, limit, offset, filter)
+	ret0, _ := ret[0].([]model.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAuditMockRecorder) GetAuditLog(ctx, limit, offset, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAudit)(nil).GetAuditLog), ctx, limit, offset, filter)
+}
+
+// MockAssignment is a mock of Assignment interface.
+type MockAssignment struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssignmentMockRecorder
+	isgomock struct{}
+}
+
+// MockAssignmentMockRecorder is the mock recorder for MockAssignment.
+type MockAssignmentMockRecorder struct {
+	mock *MockAssignment
+}
+
+// NewMockAssignment creates a new mock instance.
+func NewMockAssignment(ctrl *gomock.Controller) *MockAssignment {
+	mock := &MockAssignment{ctrl: ctrl}
+	mock.recorder = &MockAssignmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssignment) EXPECT() *MockAssignmentMockRecorder {
+	return m.recorder
+}
+
+// AssignPvz mocks base method.
+func (m *MockAssignment) AssignPvz(ctx context.Context, userId uuid.UUID, pvzId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignPvz", ctx, userId, pvzId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignPvz indicates an expected call of AssignPvz.
+func (mr *MockAssignmentMockRecorder) AssignPvz(ctx, userId, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignPvz", reflect.TypeOf((*MockAssignment)(nil).AssignPvz), ctx, userId, pvzId)
+}
+
+// GetAssignedPvzIds mocks base method.
+func (m *MockAssignment) GetAssignedPvzIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssignedPvzIds", ctx, userId)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssignedPvzIds indicates an expected call of GetAssignedPvzIds.
+func (mr *MockAssignmentMockRecorder) GetAssignedPvzIds(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssignedPvzIds", reflect.TypeOf((*MockAssignment)(nil).GetAssignedPvzIds), ctx, userId)
+}
+
+// UnassignPvz mocks base method.
+func (m *MockAssignment) UnassignPvz(ctx context.Context, userId uuid.UUID, pvzId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignPvz", ctx, userId, pvzId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnassignPvz indicates an expected call of UnassignPvz.
+func (mr *MockAssignmentMockRecorder) UnassignPvz(ctx, userId, pvzId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignPvz", reflect.TypeOf((*MockAssignment)(nil).UnassignPvz), ctx, userId, pvzId)
+}
+
+// MockWebhook is a mock of Webhook interface.
+type MockWebhook struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookMockRecorder is the mock recorder for MockWebhook.
+type MockWebhookMockRecorder struct {
+	mock *MockWebhook
+}
+
+// NewMockWebhook creates a new mock instance.
+func NewMockWebhook(ctrl *gomock.Controller) *MockWebhook {
+	mock := &MockWebhook{ctrl: ctrl}
+	mock.recorder = &MockWebhookMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhook) EXPECT() *MockWebhookMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockWebhook) CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, subscription)
+	ret0, _ := ret[0].(model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockWebhookMockRecorder) CreateWebhookSubscription(ctx, subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockWebhook)(nil).CreateWebhookSubscription), ctx, subscription)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockWebhook) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockWebhookMockRecorder) DeleteWebhookSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockWebhook)(nil).DeleteWebhookSubscription), ctx, id)
+}
+
+// DeliverWebhooks mocks base method.
+func (m *MockWebhook) DeliverWebhooks(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverWebhooks", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeliverWebhooks indicates an expected call of DeliverWebhooks.
+func (mr *MockWebhookMockRecorder) DeliverWebhooks(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverWebhooks", reflect.TypeOf((*MockWebhook)(nil).DeliverWebhooks), ctx)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhook) GetWebhookDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit int, offset int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, subscriptionId, status, limit, offset)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookMockRecorder) GetWebhookDeliveries(ctx, subscriptionId, status, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhook)(nil).GetWebhookDeliveries), ctx, subscriptionId, status, limit, offset)
+}
+
+// GetWebhookSubscription mocks base method.
+func (m *MockWebhook) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", ctx, id)
+	ret0, _ := ret[0].(model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockWebhookMockRecorder) GetWebhookSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockWebhook)(nil).GetWebhookSubscription), ctx, id)
+}
+
+// GetWebhookSubscriptions mocks base method.
+func (m *MockWebhook) GetWebhookSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptions", ctx)
+	ret0, _ := ret[0].([]model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptions indicates an expected call of GetWebhookSubscriptions.
+func (mr *MockWebhookMockRecorder) GetWebhookSubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptions", reflect.TypeOf((*MockWebhook)(nil).GetWebhookSubscriptions), ctx)
+}
+
+// UpdateWebhookSubscription mocks base method.
+func (m *MockWebhook) UpdateWebhookSubscription(ctx context.Context, id uuid.UUID, update model.WebhookSubscriptionUpdate) (model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookSubscription", ctx, id, update)
+	ret0, _ := ret[0].(model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookSubscription indicates an expected call of UpdateWebhookSubscription.
+func (mr *MockWebhookMockRecorder) UpdateWebhookSubscription(ctx, id, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookSubscription", reflect.TypeOf((*MockWebhook)(nil).UpdateWebhookSubscription), ctx, id, update)
+}
+
+// MockLive is a mock of Live interface.
+type MockLive struct {
+	ctrl     *gomock.Controller
+	recorder *MockLiveMockRecorder
+	isgomock struct{}
+}
+
+// MockLiveMockRecorder is the mock recorder for MockLive.
+type MockLiveMockRecorder struct {
+	mock *MockLive
+}
+
+// NewMockLive creates a new mock instance.
+func NewMockLive(ctrl *gomock.Controller) *MockLive {
+	mock := &MockLive{ctrl: ctrl}
+	mock.recorder = &MockLiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLive) EXPECT() *MockLiveMockRecorder {
+	return m.recorder
+}
+
+// SubscribePvzEvents mocks base method.
+func (m *MockLive) SubscribePvzEvents(ctx context.Context, pvzId uuid.UUID, lastEventId string) (*live.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribePvzEvents", ctx, pvzId, lastEventId)
+	ret0, _ := ret[0].(*live.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribePvzEvents indicates an expected call of SubscribePvzEvents.
+func (mr *MockLiveMockRecorder) SubscribePvzEvents(ctx, pvzId, lastEventId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLastProduct", reflect.TypeOf((*MockProduct)(nil).DeleteLastProduct), ctx, pvzId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePvzEvents", reflect.TypeOf((*MockLive)(nil).SubscribePvzEvents), ctx, pvzId, lastEventId)
 }
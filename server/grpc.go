@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+type GrpcServer struct {
+	port       string
+	grpcServer *grpc.Server
+}
+
+func NewGrpcServer(port string, server *grpc.Server) *GrpcServer {
+	return &GrpcServer{
+		port:       port,
+		grpcServer: server,
+	}
+}
+
+// Run блокируется до остановки сервера. После Shutdown возвращает nil.
+func (s *GrpcServer) Run() error {
+	listener, err := net.Listen("tcp", ":"+s.port)
+	if err != nil {
+		return err
+	}
+
+	if err := s.grpcServer.Serve(listener); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown дожидается завершения активных RPC. Если дедлайн ctx истёк раньше,
+// оставшиеся соединения закрываются принудительно.
+func (s *GrpcServer) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
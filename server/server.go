@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 )
@@ -10,17 +11,34 @@ type Server struct {
 	httpSever *http.Server
 }
 
-func (s *Server) Run(port string, handler http.Handler) error {
-	s.httpSever = &http.Server{
-		Addr:           ":" + port,
-		Handler:        handler,
-		MaxHeaderBytes: 1 << 20,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+func NewServer(port string, handler http.Handler, readTimeout, writeTimeout time.Duration) *Server {
+	return &Server{
+		httpSever: &http.Server{
+			Addr:           ":" + port,
+			Handler:        handler,
+			MaxHeaderBytes: 1 << 20,
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+		},
 	}
-	return s.httpSever.ListenAndServe()
 }
 
+// Run блокируется до остановки сервера. После Shutdown возвращает nil.
+func (s *Server) Run() error {
+	if err := s.httpSever.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// RegisterOnShutdown регистрирует f, которая вызывается в начале Shutdown.
+// Нужна долгоживущим запросам: Shutdown ждёт их завершения.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.httpSever.RegisterOnShutdown(f)
+}
+
+// Shutdown перестаёт принимать новые соединения и ждёт завершения
+// текущих запросов, но не дольше дедлайна ctx.
 func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpSever.Shutdown(ctx)
 }
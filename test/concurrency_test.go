@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+const workers = 20
+
+func TestConcurrentReceptionCreation(t *testing.T) {
+	employeeToken := login(t, "employee")
+	moderatorToken := login(t, "moderator")
+	pvzId := createPvz(t, moderatorToken)
+
+	codes := hammer(workers, func() (int, error) {
+		return doRequest(http.MethodPost, baseURL+"/receptions", employeeToken,
+			map[string]string{"pvzId": pvzId})
+	})
+
+	created := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Fatalf("Ожидалась ровно одна открытая приёмка, создано %d", created)
+	}
+
+	if !closeReception(t, employeeToken, pvzId) {
+		t.Fatal("Не удалось закрыть приёмку заказов")
+	}
+}
+
+func TestConcurrentProductAddAndDelete(t *testing.T) {
+	employeeToken := login(t, "employee")
+	moderatorToken := login(t, "moderator")
+	pvzId := createPvz(t, moderatorToken)
+
+	if createReception(t, employeeToken, pvzId) == "" {
+		t.Fatal("Не удалось создать приёмку заказов")
+	}
+
+	codes := hammer(workers, func() (int, error) {
+		return doRequest(http.MethodPost, baseURL+"/products", employeeToken,
+			map[string]string{"type": "обувь", "pvzId": pvzId})
+	})
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("Не удалось добавить товар, статус %d", code)
+		}
+	}
+
+	deleteURL := fmt.Sprintf("%s/pvz/%s/delete_last_product", baseURL, pvzId)
+	codes = hammer(workers+5, func() (int, error) {
+		return doRequest(http.MethodDelete, deleteURL, employeeToken, nil)
+	})
+
+	deleted := 0
+	for _, code := range codes {
+		if code == http.StatusOK {
+			deleted++
+		}
+	}
+	if deleted != workers {
+		t.Fatalf("Ожидалось удаление %d товаров, удалено %d", workers, deleted)
+	}
+
+	if !closeReception(t, employeeToken, pvzId) {
+		t.Fatal("Не удалось закрыть приёмку заказов")
+	}
+}
+
+// hammer запускает fn одновременно из n горутин и возвращает статусы ответов
+func hammer(n int, fn func() (int, error)) []int {
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		codes = make([]int, n)
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			code, err := fn()
+			if err != nil {
+				code = 0
+			}
+			codes[i] = code
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+	return codes
+}
+
+func doRequest(method, url, token string, body interface{}) (int, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
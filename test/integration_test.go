@@ -43,7 +43,7 @@ func TestIntegration(t *testing.T) {
 }
 
 func login(t *testing.T, role string) string {
-	url := "http://localhost:8080/dummyLogin"
+	url := baseURL + "/dummyLogin"
 	body := map[string]string{"role": role}
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -65,7 +65,7 @@ func login(t *testing.T, role string) string {
 }
 
 func createPvz(t *testing.T, token string) string {
-	url := "http://localhost:8080/pvz"
+	url := baseURL + "/pvz"
 	body := map[string]string{"city": "Москва"}
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -99,7 +99,7 @@ func createPvz(t *testing.T, token string) string {
 }
 
 func createReception(t *testing.T, token, pvzId string) string {
-	url := "http://localhost:8080/receptions"
+	url := baseURL + "/receptions"
 	body := map[string]string{"pvzId": pvzId}
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -128,7 +128,7 @@ func createReception(t *testing.T, token, pvzId string) string {
 }
 
 func addProduct(t *testing.T, token, pvzId, productType string) bool {
-	url := "http://localhost:8080/products"
+	url := baseURL + "/products"
 	body := map[string]string{
 		"type":  productType,
 		"pvzId": pvzId,
@@ -155,7 +155,7 @@ func addProduct(t *testing.T, token, pvzId, productType string) bool {
 }
 
 func closeReception(t *testing.T, token, pvzId string) bool {
-	url := fmt.Sprintf("http://localhost:8080/pvz/%s/close_last_reception", pvzId)
+	url := fmt.Sprintf("%s/pvz/%s/close_last_reception", baseURL, pvzId)
 	req, err := http.NewRequest("PATCH", url, nil)
 	if err != nil {
 		t.Fatalf("Ошибка при создании запроса: %v", err)
@@ -0,0 +1,22 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// baseURL - адрес запущенного сервиса. Тесты пакета обращаются к живому
+// серверу, поэтому без PVZ_TEST_URL они не запускаются и go test ./... не
+// падает там, где сервиса нет.
+var baseURL string
+
+func TestMain(m *testing.M) {
+	baseURL = strings.TrimSuffix(os.Getenv("PVZ_TEST_URL"), "/")
+	if baseURL == "" {
+		fmt.Println("PVZ_TEST_URL is not set, skipping tests against a running server")
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}